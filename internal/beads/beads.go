@@ -34,6 +34,7 @@ type Issue struct {
 	CreatedBy   string   `json:"created_by,omitempty"`
 	UpdatedAt   string   `json:"updated_at"`
 	ClosedAt    string   `json:"closed_at,omitempty"`
+	CloseReason string   `json:"close_reason,omitempty"`
 	Parent      string   `json:"parent,omitempty"`
 	Assignee    string   `json:"assignee,omitempty"`
 	Children    []string `json:"children,omitempty"`
@@ -609,6 +610,16 @@ func (b *Beads) CloseWithReason(reason string, ids ...string) error {
 	return err
 }
 
+// Reopen reopens a closed issue, recording the reason.
+func (b *Beads) Reopen(id, reason string) error {
+	args := []string{"reopen", id}
+	if reason != "" {
+		args = append(args, "--reason="+reason)
+	}
+	_, err := b.run(args...)
+	return err
+}
+
 // Release moves an in_progress issue back to open status.
 // This is used to recover stuck steps when a worker dies mid-task.
 // It clears the assignee so the step can be claimed by another worker.
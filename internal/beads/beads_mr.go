@@ -2,6 +2,7 @@
 package beads
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -31,6 +32,27 @@ func (b *Beads) FindMRForBranch(branch string) (*Issue, error) {
 	return nil, nil
 }
 
+// FindMRForIssue finds the open merge-request bead for a source issue.
+// Returns nil, nil if the issue has no MR in the queue.
+func (b *Beads) FindMRForIssue(issueID string) (*Issue, error) {
+	issues, err := b.List(ListOptions{
+		Status:   "open",
+		Label:    "gt:merge-request",
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, issue := range issues {
+		if fields := ParseMRFields(issue); fields != nil && fields.SourceIssue == issueID {
+			return issue, nil
+		}
+	}
+
+	return nil, nil
+}
+
 // AddGateWaiter registers an agent as a waiter on a gate bead.
 // When the gate closes, the waiter will receive a wake notification via gt gate wake.
 // The waiter is typically the polecat's address (e.g., "gastown/polecats/Toast").
@@ -43,3 +65,17 @@ func (b *Beads) AddGateWaiter(gateID, waiter string) error {
 	}
 	return nil
 }
+
+// CreateGate creates a gate bead that waits on the given condition
+// (e.g. "timer:30m", "human:approval") and returns it.
+func (b *Beads) CreateGate(await, title string) (*Issue, error) {
+	out, err := b.run("gate", "create", "--await="+await, "--title="+title, "--json")
+	if err != nil {
+		return nil, fmt.Errorf("creating gate: %w", err)
+	}
+	var gate Issue
+	if err := json.Unmarshal(out, &gate); err != nil {
+		return nil, fmt.Errorf("parsing bd gate create output: %w", err)
+	}
+	return &gate, nil
+}
@@ -568,6 +568,7 @@ func TestMRFieldsRoundTrip(t *testing.T) {
 		Rig:         "gastown",
 		MergeCommit: "abc123def789",
 		CloseReason: "merged",
+		RevertedBy:  "gt-mr-rev",
 	}
 
 	// Format to string
@@ -18,9 +18,12 @@ func ParseAgentFieldsFromDescription(description string) *AgentFields {
 // These fields track which molecule is attached to a handoff/pinned bead.
 type AttachmentFields struct {
 	AttachedMolecule string // Root issue ID of the attached molecule
+	AttachedFormula  string // Formula the attached molecule was instantiated from
 	AttachedAt       string // ISO 8601 timestamp when attached
 	AttachedArgs     string // Natural language args passed via gt sling --args (no-tmux mode)
 	DispatchedBy     string // Agent ID that dispatched this work (for completion notification)
+	StackedOn        string // Open MR whose branch this work started from (stacked work)
+	StackBase        string // Commit the work's branch started from when stacked
 }
 
 // ParseAttachmentFields extracts attachment fields from an issue's description.
@@ -56,6 +59,9 @@ func ParseAttachmentFields(issue *Issue) *AttachmentFields {
 		case "attached_molecule", "attached-molecule", "attachedmolecule":
 			fields.AttachedMolecule = value
 			hasFields = true
+		case "attached_formula", "attached-formula", "attachedformula":
+			fields.AttachedFormula = value
+			hasFields = true
 		case "attached_at", "attached-at", "attachedat":
 			fields.AttachedAt = value
 			hasFields = true
@@ -65,6 +71,12 @@ func ParseAttachmentFields(issue *Issue) *AttachmentFields {
 		case "dispatched_by", "dispatched-by", "dispatchedby":
 			fields.DispatchedBy = value
 			hasFields = true
+		case "stacked_on", "stacked-on", "stackedon":
+			fields.StackedOn = value
+			hasFields = true
+		case "stack_base", "stack-base", "stackbase":
+			fields.StackBase = value
+			hasFields = true
 		}
 	}
 
@@ -86,6 +98,9 @@ func FormatAttachmentFields(fields *AttachmentFields) string {
 	if fields.AttachedMolecule != "" {
 		lines = append(lines, "attached_molecule: "+fields.AttachedMolecule)
 	}
+	if fields.AttachedFormula != "" {
+		lines = append(lines, "attached_formula: "+fields.AttachedFormula)
+	}
 	if fields.AttachedAt != "" {
 		lines = append(lines, "attached_at: "+fields.AttachedAt)
 	}
@@ -95,6 +110,12 @@ func FormatAttachmentFields(fields *AttachmentFields) string {
 	if fields.DispatchedBy != "" {
 		lines = append(lines, "dispatched_by: "+fields.DispatchedBy)
 	}
+	if fields.StackedOn != "" {
+		lines = append(lines, "stacked_on: "+fields.StackedOn)
+	}
+	if fields.StackBase != "" {
+		lines = append(lines, "stack_base: "+fields.StackBase)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -108,6 +129,9 @@ func SetAttachmentFields(issue *Issue, fields *AttachmentFields) string {
 		"attached_molecule": true,
 		"attached-molecule": true,
 		"attachedmolecule":  true,
+		"attached_formula":  true,
+		"attached-formula":  true,
+		"attachedformula":   true,
 		"attached_at":       true,
 		"attached-at":       true,
 		"attachedat":        true,
@@ -117,6 +141,12 @@ func SetAttachmentFields(issue *Issue, fields *AttachmentFields) string {
 		"dispatched_by":     true,
 		"dispatched-by":     true,
 		"dispatchedby":      true,
+		"stacked_on":        true,
+		"stacked-on":        true,
+		"stackedon":         true,
+		"stack_base":        true,
+		"stack-base":        true,
+		"stackbase":         true,
 	}
 
 	// Collect non-attachment lines from existing description
@@ -187,6 +217,15 @@ type MRFields struct {
 	// Convoy tracking (for priority scoring - convoy starvation prevention)
 	ConvoyID        string // Parent convoy ID if part of a convoy
 	ConvoyCreatedAt string // Convoy creation time (ISO 8601) for starvation prevention
+
+	// Revert linkage (gt mq revert)
+	Reverts    string // On a revert MR: the merged MR it reverts
+	RevertedBy string // On a merged MR: the MR that reverts it
+
+	// Stacked work: the branch started from another MR's unmerged branch
+	StackedOn       string // MR this one is stacked on (merges first)
+	StackBase       string // Commit the branch started from; commits after it are this MR's own
+	StackParentHead string // Parent branch head the MR was last stacked on (restacked when it moves)
 }
 
 // ParseMRFields extracts structured merge-request fields from an issue's description.
@@ -261,6 +300,21 @@ func ParseMRFields(issue *Issue) *MRFields {
 		case "convoy_created_at", "convoy-created-at", "convoycreatedat":
 			fields.ConvoyCreatedAt = value
 			hasFields = true
+		case "reverts":
+			fields.Reverts = value
+			hasFields = true
+		case "reverted_by", "reverted-by", "revertedby":
+			fields.RevertedBy = value
+			hasFields = true
+		case "stacked_on", "stacked-on", "stackedon":
+			fields.StackedOn = value
+			hasFields = true
+		case "stack_base", "stack-base", "stackbase":
+			fields.StackBase = value
+			hasFields = true
+		case "stack_parent_head", "stack-parent-head", "stackparenthead":
+			fields.StackParentHead = value
+			hasFields = true
 		}
 	}
 
@@ -325,6 +379,21 @@ func FormatMRFields(fields *MRFields) string {
 	if fields.ConvoyCreatedAt != "" {
 		lines = append(lines, "convoy_created_at: "+fields.ConvoyCreatedAt)
 	}
+	if fields.Reverts != "" {
+		lines = append(lines, "reverts: "+fields.Reverts)
+	}
+	if fields.RevertedBy != "" {
+		lines = append(lines, "reverted_by: "+fields.RevertedBy)
+	}
+	if fields.StackedOn != "" {
+		lines = append(lines, "stacked_on: "+fields.StackedOn)
+	}
+	if fields.StackBase != "" {
+		lines = append(lines, "stack_base: "+fields.StackBase)
+	}
+	if fields.StackParentHead != "" {
+		lines = append(lines, "stack_parent_head: "+fields.StackParentHead)
+	}
 
 	return strings.Join(lines, "\n")
 }
@@ -371,6 +440,19 @@ func SetMRFields(issue *Issue, fields *MRFields) string {
 		"convoy_created_at":  true,
 		"convoy-created-at":  true,
 		"convoycreatedat":    true,
+		"reverts":            true,
+		"reverted_by":        true,
+		"reverted-by":        true,
+		"revertedby":         true,
+		"stacked_on":         true,
+		"stacked-on":         true,
+		"stackedon":          true,
+		"stack_base":         true,
+		"stack-base":         true,
+		"stackbase":          true,
+		"stack_parent_head":  true,
+		"stack-parent-head":  true,
+		"stackparenthead":    true,
 	}
 
 	// Collect non-MR lines from existing description
@@ -502,6 +584,127 @@ func FormatSynthesisFields(fields *SynthesisFields) string {
 	return strings.Join(lines, "\n")
 }
 
+// ReviewFields holds structured fields for review beads, which gate a
+// merge request until a reviewer approves it.
+type ReviewFields struct {
+	ReviewOf string // MR bead under review
+	Branch   string // Source branch of the MR
+	Target   string // Target branch of the MR
+	Author   string // Worker who wrote the change (may not review it)
+	Reviewer string // Agent that recorded the verdict
+	Verdict  string // pending, approved, changes_requested
+}
+
+// reviewKeys are the description keys owned by ReviewFields (lowercase).
+var reviewKeys = map[string]bool{
+	"review_of": true,
+	"review-of": true,
+	"reviewof":  true,
+	"branch":    true,
+	"target":    true,
+	"author":    true,
+	"reviewer":  true,
+	"verdict":   true,
+}
+
+// ParseReviewFields extracts review fields from an issue's description.
+// Returns nil if the issue has no review_of field.
+func ParseReviewFields(issue *Issue) *ReviewFields {
+	if issue == nil || issue.Description == "" {
+		return nil
+	}
+
+	fields := &ReviewFields{}
+	for _, line := range strings.Split(issue.Description, "\n") {
+		line = strings.TrimSpace(line)
+		colonIdx := strings.Index(line, ":")
+		if colonIdx == -1 {
+			continue
+		}
+
+		key := strings.TrimSpace(line[:colonIdx])
+		value := strings.TrimSpace(line[colonIdx+1:])
+		if value == "" {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "review_of", "review-of", "reviewof":
+			fields.ReviewOf = value
+		case "branch":
+			fields.Branch = value
+		case "target":
+			fields.Target = value
+		case "author":
+			fields.Author = value
+		case "reviewer":
+			fields.Reviewer = value
+		case "verdict":
+			fields.Verdict = value
+		}
+	}
+
+	if fields.ReviewOf == "" {
+		return nil
+	}
+	return fields
+}
+
+// FormatReviewFields formats ReviewFields as a string for issue description.
+func FormatReviewFields(fields *ReviewFields) string {
+	if fields == nil {
+		return ""
+	}
+
+	var lines []string
+	if fields.ReviewOf != "" {
+		lines = append(lines, "review_of: "+fields.ReviewOf)
+	}
+	if fields.Branch != "" {
+		lines = append(lines, "branch: "+fields.Branch)
+	}
+	if fields.Target != "" {
+		lines = append(lines, "target: "+fields.Target)
+	}
+	if fields.Author != "" {
+		lines = append(lines, "author: "+fields.Author)
+	}
+	if fields.Reviewer != "" {
+		lines = append(lines, "reviewer: "+fields.Reviewer)
+	}
+	if fields.Verdict != "" {
+		lines = append(lines, "verdict: "+fields.Verdict)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// SetReviewFields updates an issue's description with the given review
+// fields. Existing review field lines are replaced; other content is kept.
+func SetReviewFields(issue *Issue, fields *ReviewFields) string {
+	formatted := FormatReviewFields(fields)
+	if issue == nil || issue.Description == "" {
+		return formatted
+	}
+
+	var otherLines []string
+	for _, line := range strings.Split(issue.Description, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if colonIdx := strings.Index(trimmed, ":"); colonIdx != -1 {
+			if reviewKeys[strings.ToLower(strings.TrimSpace(trimmed[:colonIdx]))] {
+				continue
+			}
+		}
+		otherLines = append(otherLines, line)
+	}
+	other := strings.TrimSpace(strings.Join(otherLines, "\n"))
+
+	if other == "" {
+		return formatted
+	}
+	return formatted + "\n\n" + other
+}
+
 // RoleConfig holds structured lifecycle configuration for role beads.
 // These fields are stored as "key: value" lines in the role bead description.
 // This enables agents to self-register their lifecycle configuration,
@@ -1022,3 +1225,158 @@ func SetHookFields(issue *Issue, fields *HookFields) string {
 
 	return formatted + "\n\n" + strings.Join(otherLines, "\n")
 }
+
+// FanoutFields records a fan-out of parallel molecule steps. Each fanned-out
+// step carries the gate and its siblings; the molecule root carries the gate
+// so the agent that fanned out can find it again.
+type FanoutFields struct {
+	Gate     string   // Gate that closes when every sibling step is closed
+	Siblings []string // Step IDs fanned out together (including this one)
+}
+
+// ParseFanoutFields extracts fan-out fields from an issue's description.
+// Returns nil if no fan-out fields are found.
+func ParseFanoutFields(issue *Issue) *FanoutFields {
+	if issue == nil || issue.Description == "" {
+		return nil
+	}
+
+	fields := &FanoutFields{}
+	hasFields := false
+
+	for _, line := range strings.Split(issue.Description, "\n") {
+		line = strings.TrimSpace(line)
+		colonIdx := strings.Index(line, ":")
+		if colonIdx == -1 {
+			continue
+		}
+
+		key := strings.TrimSpace(line[:colonIdx])
+		value := strings.TrimSpace(line[colonIdx+1:])
+		if value == "" {
+			continue
+		}
+
+		switch strings.ToLower(key) {
+		case "fanout_gate", "fanout-gate":
+			fields.Gate = value
+			hasFields = true
+		case "fanout_siblings", "fanout-siblings":
+			for _, id := range strings.Split(value, ",") {
+				if id = strings.TrimSpace(id); id != "" {
+					fields.Siblings = append(fields.Siblings, id)
+				}
+			}
+			hasFields = true
+		}
+	}
+
+	if !hasFields {
+		return nil
+	}
+	return fields
+}
+
+// FormatFanoutFields formats FanoutFields as description lines.
+// Only non-empty fields are included.
+func FormatFanoutFields(fields *FanoutFields) string {
+	if fields == nil {
+		return ""
+	}
+
+	var lines []string
+	if fields.Gate != "" {
+		lines = append(lines, "fanout_gate: "+fields.Gate)
+	}
+	if len(fields.Siblings) > 0 {
+		lines = append(lines, "fanout_siblings: "+strings.Join(fields.Siblings, ","))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetFanoutFields updates an issue's description with the given fan-out
+// fields. Existing fan-out lines are replaced; other content is preserved.
+func SetFanoutFields(issue *Issue, fields *FanoutFields) string {
+	fanoutKeys := map[string]bool{
+		"fanout_gate":     true,
+		"fanout-gate":     true,
+		"fanout_siblings": true,
+		"fanout-siblings": true,
+	}
+
+	var otherLines []string
+	if issue != nil && issue.Description != "" {
+		for _, line := range strings.Split(issue.Description, "\n") {
+			trimmed := strings.TrimSpace(line)
+			if colonIdx := strings.Index(trimmed, ":"); colonIdx != -1 {
+				if fanoutKeys[strings.ToLower(strings.TrimSpace(trimmed[:colonIdx]))] {
+					continue
+				}
+			}
+			otherLines = append(otherLines, line)
+		}
+	}
+
+	for len(otherLines) > 0 && strings.TrimSpace(otherLines[len(otherLines)-1]) == "" {
+		otherLines = otherLines[:len(otherLines)-1]
+	}
+	for len(otherLines) > 0 && strings.TrimSpace(otherLines[0]) == "" {
+		otherLines = otherLines[1:]
+	}
+
+	formatted := FormatFanoutFields(fields)
+	if formatted == "" {
+		return strings.Join(otherLines, "\n")
+	}
+	if len(otherLines) == 0 {
+		return formatted
+	}
+	return formatted + "\n\n" + strings.Join(otherLines, "\n")
+}
+
+// ParseFormulaStep returns the formula step a molecule step bead was made
+// from ("formula_step: <id>"), or "" if none was recorded.
+func ParseFormulaStep(issue *Issue) string {
+	if issue == nil {
+		return ""
+	}
+	for _, line := range strings.Split(issue.Description, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "formula_step", "formula-step":
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// SetFormulaStep records the formula step a molecule step bead was made
+// from. An existing formula_step line is replaced; other content is
+// preserved. Returns the new description.
+func SetFormulaStep(issue *Issue, stepID string) string {
+	var otherLines []string
+	if issue != nil && issue.Description != "" {
+		for _, line := range strings.Split(issue.Description, "\n") {
+			key, _, ok := strings.Cut(strings.TrimSpace(line), ":")
+			if k := strings.ToLower(strings.TrimSpace(key)); ok && (k == "formula_step" || k == "formula-step") {
+				continue
+			}
+			otherLines = append(otherLines, line)
+		}
+	}
+	for len(otherLines) > 0 && strings.TrimSpace(otherLines[0]) == "" {
+		otherLines = otherLines[1:]
+	}
+
+	if stepID == "" {
+		return strings.Join(otherLines, "\n")
+	}
+	formatted := "formula_step: " + stepID
+	if len(otherLines) == 0 {
+		return formatted
+	}
+	return formatted + "\n\n" + strings.Join(otherLines, "\n")
+}
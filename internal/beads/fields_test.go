@@ -367,6 +367,88 @@ func TestSetConvoyFields(t *testing.T) {
 	}
 }
 
+// TestReviewFieldsRoundTrip tests review field parsing and replacement.
+func TestReviewFieldsRoundTrip(t *testing.T) {
+	issue := &Issue{Description: "review_of: gt-mr1\nbranch: polecat/nux/gt-abc\ntarget: main\nauthor: nux\nverdict: pending\n\nReview notes"}
+
+	fields := ParseReviewFields(issue)
+	if fields == nil {
+		t.Fatal("ParseReviewFields() = nil")
+	}
+	if fields.ReviewOf != "gt-mr1" || fields.Author != "nux" || fields.Verdict != "pending" {
+		t.Errorf("ParseReviewFields() = %+v", fields)
+	}
+
+	fields.Reviewer = "gastown/polecats/toast"
+	fields.Verdict = "approved"
+	want := "review_of: gt-mr1\nbranch: polecat/nux/gt-abc\ntarget: main\nauthor: nux\nreviewer: gastown/polecats/toast\nverdict: approved\n\nReview notes"
+	if got := SetReviewFields(issue, fields); got != want {
+		t.Errorf("SetReviewFields() = %q, want %q", got, want)
+	}
+
+	// MR beads also have branch/target but no review_of
+	if got := ParseReviewFields(&Issue{Description: "branch: b\ntarget: main"}); got != nil {
+		t.Errorf("ParseReviewFields(MR) = %+v, want nil", got)
+	}
+}
+
+func TestStackFieldsRoundTrip(t *testing.T) {
+	mr := &Issue{Description: "branch: polecat/toast/gt-b\ntarget: main\nstacked_on: gt-mr1\nstack_base: abc123"}
+	fields := ParseMRFields(mr)
+	if fields == nil || fields.StackedOn != "gt-mr1" || fields.StackBase != "abc123" {
+		t.Fatalf("ParseMRFields() = %+v", fields)
+	}
+
+	// Restacking clears the stack fields
+	fields.StackedOn, fields.StackBase = "", ""
+	if got, want := SetMRFields(mr, fields), "branch: polecat/toast/gt-b\ntarget: main"; got != want {
+		t.Errorf("SetMRFields() = %q, want %q", got, want)
+	}
+
+	issue := &Issue{Description: "Do the thing"}
+	desc := SetAttachmentFields(issue, &AttachmentFields{StackedOn: "gt-mr1", StackBase: "abc123"})
+	if want := "stacked_on: gt-mr1\nstack_base: abc123\n\nDo the thing"; desc != want {
+		t.Errorf("SetAttachmentFields() = %q, want %q", desc, want)
+	}
+	got := ParseAttachmentFields(&Issue{Description: desc})
+	if got == nil || got.StackedOn != "gt-mr1" || got.StackBase != "abc123" {
+		t.Errorf("ParseAttachmentFields() = %+v", got)
+	}
+}
+
+func TestFanoutFieldsRoundTrip(t *testing.T) {
+	step := &Issue{Description: "Build the backend\nfanout_gate: gt-old"}
+	desc := SetFanoutFields(step, &FanoutFields{Gate: "gt-gate", Siblings: []string{"gt-mol.2", "gt-mol.3"}})
+	if want := "fanout_gate: gt-gate\nfanout_siblings: gt-mol.2,gt-mol.3\n\nBuild the backend"; desc != want {
+		t.Errorf("SetFanoutFields() = %q, want %q", desc, want)
+	}
+
+	got := ParseFanoutFields(&Issue{Description: desc})
+	if got == nil || got.Gate != "gt-gate" || len(got.Siblings) != 2 || got.Siblings[1] != "gt-mol.3" {
+		t.Errorf("ParseFanoutFields() = %+v", got)
+	}
+	if ParseFanoutFields(&Issue{Description: "Build the backend"}) != nil {
+		t.Error("ParseFanoutFields() on plain description should be nil")
+	}
+	if desc := SetFanoutFields(&Issue{Description: desc}, nil); desc != "Build the backend" {
+		t.Errorf("SetFanoutFields(nil) = %q", desc)
+	}
+}
+
+func TestFormulaStepRoundTrip(t *testing.T) {
+	step := &Issue{Description: "Run the tests\nformula_step: old"}
+	desc := SetFormulaStep(step, "test-final")
+	if want := "formula_step: test-final\n\nRun the tests"; desc != want {
+		t.Errorf("SetFormulaStep() = %q, want %q", desc, want)
+	}
+	if got := ParseFormulaStep(&Issue{Description: desc}); got != "test-final" {
+		t.Errorf("ParseFormulaStep() = %q", got)
+	}
+	if got := ParseFormulaStep(&Issue{Description: "Run the tests"}); got != "" {
+		t.Errorf("ParseFormulaStep() on plain description = %q", got)
+	}
+}
+
 // TestParseHookFields tests hook field parsing.
 func TestParseHookFields(t *testing.T) {
 	tests := []struct {
@@ -14,6 +14,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/townlog"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -22,6 +23,7 @@ import (
 // Audit command flags
 var (
 	auditActor string
+	auditBead  string
 	auditSince string
 	auditLimit int
 	auditJSON  bool
@@ -34,24 +36,31 @@ var auditCmd = &cobra.Command{
 	Long: `Query provenance data across git commits, beads, and events.
 
 Shows a unified timeline of work performed by an actor including:
-  - Git commits authored by the actor
+  - Git commits authored by the actor, or stamped with the actor's
+    Gt-Agent provenance trailer
   - Beads (issues) created by the actor
   - Beads closed by the actor (via assignee)
   - Town log events (spawn, done, handoff, etc.)
   - Activity feed events
 
+With --bead, the timeline is narrowed to one work item: commits stamped
+with its Gt-Bead trailer (on any branch), the bead itself, and events
+that reference it.
+
 Examples:
   gt audit --actor=greenplace/crew/joe       # Show all work by joe
   gt audit --actor=greenplace/polecats/toast # Show polecat toast's work
   gt audit --actor=mayor                  # Show mayor's activity
   gt audit --since=24h                    # Show all activity in last 24h
   gt audit --actor=joe --since=1h         # Combined filters
+  gt audit --bead=gt-abc                  # Everything done for gt-abc
   gt audit --json                         # Output as JSON`,
 	RunE: runAudit,
 }
 
 func init() {
 	auditCmd.Flags().StringVar(&auditActor, "actor", "", "Filter by actor (agent address or partial match)")
+	auditCmd.Flags().StringVar(&auditBead, "bead", "", "Filter to work on a bead (commits by Gt-Bead trailer)")
 	auditCmd.Flags().StringVar(&auditSince, "since", "", "Show events since duration (e.g., 1h, 24h, 7d)")
 	auditCmd.Flags().IntVarP(&auditLimit, "limit", "n", 50, "Maximum number of entries to show")
 	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "Output as JSON")
@@ -90,7 +99,7 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	var allEntries []AuditEntry
 
 	// 1. Git commits
-	gitEntries, err := collectGitCommits(townRoot, auditActor, sinceTime)
+	gitEntries, err := collectGitCommits(townRoot, auditActor, auditBead, sinceTime)
 	if err != nil {
 		// Non-fatal: log and continue
 		fmt.Fprintf(os.Stderr, "Warning: could not query git commits: %v\n", err)
@@ -98,21 +107,21 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	allEntries = append(allEntries, gitEntries...)
 
 	// 2. Beads (created_by, assignee)
-	beadsEntries, err := collectBeadsActivity(townRoot, auditActor, sinceTime)
+	beadsEntries, err := collectBeadsActivity(townRoot, auditActor, auditBead, sinceTime)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not query beads: %v\n", err)
 	}
 	allEntries = append(allEntries, beadsEntries...)
 
 	// 3. Town log events
-	townlogEntries, err := collectTownlogEvents(townRoot, auditActor, sinceTime)
+	townlogEntries, err := collectTownlogEvents(townRoot, auditActor, auditBead, sinceTime)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not query town log: %v\n", err)
 	}
 	allEntries = append(allEntries, townlogEntries...)
 
 	// 4. Activity feed events
-	feedEntries, err := collectFeedEvents(townRoot, auditActor, sinceTime)
+	feedEntries, err := collectFeedEvents(townRoot, auditActor, auditBead, sinceTime)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: could not query events feed: %v\n", err)
 	}
@@ -129,7 +138,9 @@ func runAudit(cmd *cobra.Command, args []string) error {
 	}
 
 	if len(allEntries) == 0 {
-		if auditActor != "" {
+		if auditBead != "" {
+			fmt.Printf("%s No activity found for bead %q\n", style.Dim.Render("○"), auditBead)
+		} else if auditActor != "" {
 			fmt.Printf("%s No activity found for actor %q\n", style.Dim.Render("○"), auditActor)
 		} else {
 			fmt.Printf("%s No activity found\n", style.Dim.Render("○"))
@@ -158,9 +169,16 @@ func parseDuration(s string) (time.Duration, error) {
 	return time.ParseDuration(s)
 }
 
-// collectGitCommits queries git log for commits by the actor.
-func collectGitCommits(townRoot, actor string, since time.Time) ([]AuditEntry, error) { //nolint:unparam // error return kept for future use
+// collectGitCommits queries git log for commits by the actor: commits it
+// authored plus commits stamped with its Gt-Agent trailer. With bead set,
+// only commits stamped with that Gt-Bead trailer are returned.
+func collectGitCommits(townRoot, actor, bead string, since time.Time) ([]AuditEntry, error) { //nolint:unparam // error return kept for future use
+	if bead != "" {
+		return collectStampedCommits(townRoot, git.Provenance{Bead: bead, Agent: actor}, since, nil), nil
+	}
+
 	var entries []AuditEntry
+	seen := make(map[string]bool)
 
 	// Build git log command
 	args := []string{"log", "--format=%H|%aI|%an|%s", "--all"}
@@ -206,6 +224,7 @@ func collectGitCommits(townRoot, actor string, since time.Time) ([]AuditEntry, e
 			continue
 		}
 
+		seen[hash] = true
 		entries = append(entries, AuditEntry{
 			Timestamp: timestamp,
 			Source:    "git",
@@ -216,9 +235,54 @@ func collectGitCommits(townRoot, actor string, since time.Time) ([]AuditEntry, e
 		})
 	}
 
+	if actor != "" {
+		entries = append(entries, collectStampedCommits(townRoot, git.Provenance{Agent: actor}, since, seen)...)
+	}
+
 	return entries, nil
 }
 
+// collectStampedCommits returns commits whose provenance trailers match
+// want, skipping hashes in seen.
+func collectStampedCommits(townRoot string, want git.Provenance, since time.Time, seen map[string]bool) []AuditEntry {
+	commits, err := git.NewGit(townRoot).LogByProvenance(want, since, 100)
+	if err != nil {
+		// Git might fail if not a repo - not fatal
+		return nil
+	}
+
+	var entries []AuditEntry
+	for _, c := range commits {
+		if seen[c.Hash] {
+			continue
+		}
+		actor := c.Agent
+		if actor == "" {
+			actor = c.Author
+		}
+		var details []string
+		if c.Bead != "" {
+			details = append(details, "bead="+c.Bead)
+		}
+		if c.Convoy != "" {
+			details = append(details, "convoy="+c.Convoy)
+		}
+		if c.MR != "" {
+			details = append(details, "mr="+c.MR)
+		}
+		entries = append(entries, AuditEntry{
+			Timestamp: c.Date,
+			Source:    "git",
+			Type:      "commit",
+			Actor:     actor,
+			Summary:   c.Subject,
+			Details:   strings.Join(details, " "),
+			ID:        c.Hash[:8],
+		})
+	}
+	return entries
+}
+
 // extractAuthorName extracts the likely git author name from an actor address.
 func extractAuthorName(actor string) string {
 	// Actor format: "greenplace/crew/joe" -> "joe"
@@ -255,7 +319,8 @@ func matchesActor(name, actor string) bool {
 }
 
 // collectBeadsActivity queries beads for issues created or closed by the actor.
-func collectBeadsActivity(townRoot, actor string, since time.Time) ([]AuditEntry, error) {
+// With bead set, only that issue is considered.
+func collectBeadsActivity(townRoot, actor, bead string, since time.Time) ([]AuditEntry, error) {
 	var entries []AuditEntry
 
 	// Find the gastown beads path (where gt- prefix issues live)
@@ -272,6 +337,10 @@ func collectBeadsActivity(townRoot, actor string, since time.Time) ([]AuditEntry
 	}
 
 	for _, issue := range issues {
+		if bead != "" && issue.ID != bead {
+			continue
+		}
+
 		// Check created_by
 		if issue.CreatedBy != "" {
 			if actor == "" || matchesActor(issue.CreatedBy, actor) {
@@ -334,7 +403,8 @@ func parseBeadsTimestamp(s string) time.Time {
 }
 
 // collectTownlogEvents queries the town log for agent lifecycle events.
-func collectTownlogEvents(townRoot, actor string, since time.Time) ([]AuditEntry, error) {
+// With bead set, only events whose context mentions it are returned.
+func collectTownlogEvents(townRoot, actor, bead string, since time.Time) ([]AuditEntry, error) {
 	var entries []AuditEntry
 
 	allEvents, err := townlog.ReadEvents(townRoot)
@@ -348,6 +418,11 @@ func collectTownlogEvents(townRoot, actor string, since time.Time) ([]AuditEntry
 			continue
 		}
 
+		// Apply bead filter
+		if bead != "" && !strings.Contains(e.Context, bead) {
+			continue
+		}
+
 		// Apply since filter
 		if !since.IsZero() && e.Timestamp.Before(since) {
 			continue
@@ -400,7 +475,8 @@ func formatTownlogSummary(e townlog.Event) string {
 }
 
 // collectFeedEvents queries the activity feed for events.
-func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, error) {
+// With bead set, only events whose payload references it are returned.
+func collectFeedEvents(townRoot, actor, bead string, since time.Time) ([]AuditEntry, error) {
 	var entries []AuditEntry
 
 	eventsPath := filepath.Join(townRoot, events.EventsFile)
@@ -425,6 +501,11 @@ func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, e
 			continue
 		}
 
+		// Apply bead filter
+		if bead != "" && !payloadMentions(e.Payload, bead) {
+			continue
+		}
+
 		// Parse timestamp
 		ts, _ := time.Parse(time.RFC3339, e.Timestamp)
 
@@ -445,6 +526,16 @@ func collectFeedEvents(townRoot, actor string, since time.Time) ([]AuditEntry, e
 	return entries, nil
 }
 
+// payloadMentions returns true if any string value in an event payload is id.
+func payloadMentions(payload map[string]interface{}, id string) bool {
+	for _, v := range payload {
+		if str, ok := v.(string); ok && str == id {
+			return true
+		}
+	}
+	return false
+}
+
 // formatFeedSummary creates a readable summary from a feed event.
 func formatFeedSummary(e events.Event) string {
 	switch e.Type {
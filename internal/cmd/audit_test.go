@@ -148,3 +148,16 @@ func TestFormatType(t *testing.T) {
 		}
 	}
 }
+
+func TestPayloadMentions(t *testing.T) {
+	payload := map[string]interface{}{"bead": "gt-abc", "target": "gastown/polecats/nux", "count": 3}
+	if !payloadMentions(payload, "gt-abc") {
+		t.Error("payloadMentions(gt-abc) = false, want true")
+	}
+	if payloadMentions(payload, "gt-ab") {
+		t.Error("payloadMentions matched a prefix, want exact match")
+	}
+	if payloadMentions(nil, "gt-abc") {
+		t.Error("payloadMentions(nil) = true, want false")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/rig"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
@@ -233,7 +234,13 @@ func runDone(cmd *cobra.Command, args []string) error {
 
 	// For COMPLETED, we need an issue ID and branch must not be the default branch
 	var mrID string
-	if exitType == ExitCompleted {
+	if exitType == ExitCompleted && isReviewBead(cwd, issueID) {
+		// Reviewers record a verdict with gt mq review; there is no branch to submit
+		if err := checkReviewRecorded(cwd, issueID); err != nil {
+			return err
+		}
+		fmt.Printf("%s Review %s recorded, nothing to merge\n", style.Bold.Render("✓"), issueID)
+	} else if exitType == ExitCompleted {
 		if branch == defaultBranch || branch == "master" {
 			return fmt.Errorf("cannot submit %s/master branch to merge queue", defaultBranch)
 		}
@@ -259,9 +266,23 @@ func runDone(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("cannot complete: uncommitted changes would be lost\nCommit your changes first, or use --status DEFERRED to exit without completing\nUncommitted: %s", workStatus.String())
 		}
 
+		// Stacked work (see gt sling): the branch started from a queued MR's
+		// branch, and only commits after the stack base are this work's own
+		var stack *beads.AttachmentFields
+		if issueID != "" {
+			if src, err := beads.New(beads.ResolveBeadsDir(cwd)).Show(issueID); err == nil {
+				if fields := beads.ParseAttachmentFields(src); fields != nil && fields.StackedOn != "" {
+					stack = fields
+				}
+			}
+		}
+
 		// Check that branch has commits ahead of origin/default (not local default)
 		// This ensures we compare against the remote, not a potentially stale local copy
 		originDefault := "origin/" + defaultBranch
+		if stack != nil && stack.StackBase != "" {
+			originDefault = stack.StackBase
+		}
 		aheadCount, err := g.CommitsAhead(originDefault, "HEAD")
 		if err != nil {
 			// Fallback to local branch comparison if origin not available
@@ -335,6 +356,11 @@ func runDone(cmd *cobra.Command, args []string) error {
 			if agentBeadID != "" {
 				description += fmt.Sprintf("\nagent_bead: %s", agentBeadID)
 			}
+			if stack != nil {
+				// The branch sits on the parent's head as of the stack base; the
+				// refinery restacks it if the parent's branch moves
+				description += fmt.Sprintf("\nstacked_on: %s\nstack_base: %s\nstack_parent_head: %s", stack.StackedOn, stack.StackBase, stack.StackBase)
+			}
 
 			// Add conflict resolution tracking fields (initialized, updated by Refinery)
 			description += "\nretry_count: 0"
@@ -354,6 +380,15 @@ func runDone(cmd *cobra.Command, args []string) error {
 			}
 			mrID = mrIssue.ID
 
+			// A stacked MR waits for the MR it builds on to merge first
+			if stack != nil {
+				if parent, err := bd.Show(stack.StackedOn); err == nil && parent.Status != "closed" {
+					if err := bd.AddDependency(mrID, stack.StackedOn); err != nil {
+						style.PrintWarning("could not block MR on %s: %v", stack.StackedOn, err)
+					}
+				}
+			}
+
 			// Update agent bead with active_mr reference (for traceability)
 			if agentBeadID != "" {
 				if err := bd.UpdateAgentActiveMR(agentBeadID, mrID); err != nil {
@@ -365,8 +400,14 @@ func runDone(cmd *cobra.Command, args []string) error {
 			fmt.Printf("%s Work submitted to merge queue\n", style.Bold.Render("✓"))
 			fmt.Printf("  MR ID: %s\n", style.Bold.Render(mrID))
 		}
+
+		// Gate the MR on review when the rig requires approvals (idempotent)
+		requestMRReviews(rigName, mrID)
 		fmt.Printf("  Source: %s\n", branch)
 		fmt.Printf("  Target: %s\n", target)
+		if stack != nil {
+			fmt.Printf("  Stacked on: %s %s\n", stack.StackedOn, style.Dim.Render("(merges first)"))
+		}
 		fmt.Printf("  Issue: %s\n", issueID)
 		if worker != "" {
 			fmt.Printf("  Worker: %s\n", worker)
@@ -628,6 +669,31 @@ func updateAgentStateOnDone(cwd, townRoot, exitType, _ string) { // issueID unus
 	}
 }
 
+// isReviewBead returns true if issueID is a review bead (see gt mq review).
+func isReviewBead(cwd, issueID string) bool {
+	if issueID == "" {
+		return false
+	}
+	issue, err := beads.New(beads.ResolveBeadsDir(cwd)).Show(issueID)
+	if err != nil {
+		return false
+	}
+	return beads.ParseReviewFields(issue) != nil
+}
+
+// checkReviewRecorded returns an error if the review bead has no verdict yet.
+func checkReviewRecorded(cwd, reviewID string) error {
+	issue, err := beads.New(beads.ResolveBeadsDir(cwd)).Show(reviewID)
+	if err != nil {
+		return fmt.Errorf("checking review %s: %w", reviewID, err)
+	}
+	fields := beads.ParseReviewFields(issue)
+	if fields == nil || fields.Verdict == "" || fields.Verdict == refinery.ReviewPending {
+		return fmt.Errorf("review %s has no verdict yet\nRecord one first: gt mq review %s --approve | --request-changes -m <feedback>", reviewID, reviewID)
+	}
+	return nil
+}
+
 // getIssueFromAgentHook retrieves the issue ID from an agent's hook_bead field.
 // This is the authoritative source for what work a polecat is doing, since branch
 // names may not contain the issue ID (e.g., "polecat/furiosa-mkb0vq9f").
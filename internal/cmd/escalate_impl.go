@@ -119,6 +119,7 @@ func runEscalate(cmd *cobra.Command, args []string) error {
 
 	// Log to activity feed
 	payload := events.EscalationPayload(issue.ID, agentID, strings.Join(targets, ","), description)
+	payload["escalation_id"] = issue.ID
 	payload["severity"] = severity
 	payload["actions"] = strings.Join(actions, ",")
 	if escalateSource != "" {
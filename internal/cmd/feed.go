@@ -6,6 +6,7 @@ import (
 	"os/exec"
 	"strings"
 	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
@@ -25,6 +26,9 @@ var (
 	feedNoFollow bool
 	feedWindow   bool
 	feedPlain    bool
+	feedReplay   bool
+	feedUntil    string
+	feedSpeed    string
 )
 
 func init() {
@@ -33,12 +37,15 @@ func init() {
 	feedCmd.Flags().BoolVarP(&feedFollow, "follow", "f", false, "Stream events in real-time (default when no other flags)")
 	feedCmd.Flags().BoolVar(&feedNoFollow, "no-follow", false, "Show events once and exit")
 	feedCmd.Flags().IntVarP(&feedLimit, "limit", "n", 100, "Maximum number of events to show")
-	feedCmd.Flags().StringVar(&feedSince, "since", "", "Show events since duration (e.g., 5m, 1h, 30s)")
+	feedCmd.Flags().StringVar(&feedSince, "since", "", "Show events since duration (e.g., 5m, 1h, 30s); with --replay also a time (22:00, 2026-01-31 22:00)")
 	feedCmd.Flags().StringVar(&feedMol, "mol", "", "Filter by molecule/issue ID prefix")
 	feedCmd.Flags().StringVar(&feedType, "type", "", "Filter by event type (create, update, delete, comment)")
 	feedCmd.Flags().StringVar(&feedRig, "rig", "", "Run from specific rig's beads directory")
 	feedCmd.Flags().BoolVarP(&feedWindow, "window", "w", false, "Open in dedicated tmux window (creates 'feed' window)")
 	feedCmd.Flags().BoolVar(&feedPlain, "plain", false, "Use plain text output (bd activity) instead of TUI")
+	feedCmd.Flags().BoolVar(&feedReplay, "replay", false, "Replay recorded gt events in the TUI instead of following live activity")
+	feedCmd.Flags().StringVar(&feedUntil, "until", "", "End of the replay window (same formats as --since)")
+	feedCmd.Flags().StringVar(&feedSpeed, "speed", "1x", "Replay speed multiplier (e.g., 20x)")
 }
 
 var feedCmd = &cobra.Command{
@@ -53,6 +60,14 @@ By default, launches an interactive TUI dashboard with:
   - Event stream (bottom): Chronological feed you can scroll through
   - Vim-style navigation: j/k to scroll, tab to switch panels, 1/2/3 for panels, q to quit
 
+Actions on the selected agent (tree) or event (feed):
+  n  nudge      - Send a message to the agent's session (prompts for text)
+  p  peek       - Show recent output from the agent's session
+  a  attach     - Attach to the agent's tmux session (confirms first)
+  b  show bead  - Show the event's bead, or the agent's hooked bead
+  s  sling      - Sling the event's bead if open and unassigned (or the rig's top ready bead)
+  A  ack        - Acknowledge the selected escalation (confirms first)
+
 The feed combines multiple event sources:
   - Beads activity: Issue creates, updates, completions (from bd activity)
   - GT events: Agent activity like patrol, sling, handoff (from .events.jsonl)
@@ -60,6 +75,18 @@ The feed combines multiple event sources:
 
 Use --plain for simple text output (wraps bd activity only).
 
+Replay:
+  Use --replay to investigate past incidents. The TUI is rebuilt from the
+  town's .events.jsonl (or the curated .feed.jsonl if the raw log is
+  missing) as it was at each moment: the agent tree and event stream grow
+  as events are replayed, and the convoy panel shows each convoy's progress
+  at the replay time. --since/--until bound the window; clock times refer
+  to their most recent occurrence and --until wraps past midnight.
+  Live bd activity is not replayed, and actions that change the town
+  (nudge, attach, sling, ack) are disabled.
+
+  Replay keys: space pause/resume, . step to next event, +/- speed up/down
+
 Tmux Integration:
   Use --window to open the feed in a dedicated tmux window named 'feed'.
   This creates a persistent window you can cycle to with C-b n/p.
@@ -80,13 +107,15 @@ MQ (Merge Queue) event symbols:
   ✓  merged          - MR successfully merged (green)
   ✗  merge_failed    - Merge failed (conflict, tests, etc.) (red)
   ⊘  merge_skipped   - MR skipped (already merged, etc.)
+  ↶  merge_reverted  - Merged MR reverted (gt mq revert)
 
 Examples:
   gt feed                       # Launch TUI dashboard
   gt feed --plain               # Plain text output (bd activity)
   gt feed --window              # Open in dedicated tmux window
   gt feed --since 1h            # Events from last hour
-  gt feed --rig greenplace         # Use gastown rig's beads`,
+  gt feed --rig greenplace         # Use gastown rig's beads
+  gt feed --replay --since 22:00 --until 02:00 --speed 20x   # Replay last night`,
 	RunE: runFeed,
 }
 
@@ -128,6 +157,16 @@ func runFeed(cmd *cobra.Command, args []string) error {
 	// Build bd activity command (without argv[0] for buildFeedCommand)
 	bdArgs := buildFeedArgs()
 
+	if feedReplay {
+		if !term.IsTerminal(int(os.Stdout.Fd())) {
+			return fmt.Errorf("--replay requires a terminal")
+		}
+		return runFeedReplay(townRoot)
+	}
+	if feedUntil != "" {
+		return fmt.Errorf("--until requires --replay")
+	}
+
 	// Handle --window mode: open in dedicated tmux window
 	if feedWindow {
 		return runFeedInWindow(workDir, bdArgs)
@@ -242,6 +281,42 @@ func runFeedTUI(workDir string) error {
 	return nil
 }
 
+// runFeedReplay runs the TUI over recorded events instead of live sources.
+func runFeedReplay(townRoot string) error {
+	window, err := feed.ParseReplayWindow(feedSince, feedUntil, time.Now())
+	if err != nil {
+		return err
+	}
+	speed, err := feed.ParseReplaySpeed(feedSpeed)
+	if err != nil {
+		return err
+	}
+
+	files, err := feed.ReplayFiles(townRoot)
+	if err != nil {
+		return err
+	}
+	replaySource, err := feed.NewReplaySource(files, window, speed)
+	if err != nil {
+		return err
+	}
+
+	multiSource := feed.NewMultiSource(replaySource)
+	defer func() { _ = multiSource.Close() }()
+
+	m := feed.NewModel()
+	m.SetEventChannel(multiSource.Events())
+	m.SetTownRoot(townRoot)
+	m.SetReplay(replaySource)
+
+	p := tea.NewProgram(m, tea.WithAltScreen())
+	if _, err := p.Run(); err != nil {
+		return fmt.Errorf("running TUI: %w", err)
+	}
+
+	return nil
+}
+
 // runFeedInWindow opens the feed in a dedicated tmux window.
 func runFeedInWindow(workDir string, bdArgs []string) error {
 	// Check if we're in tmux
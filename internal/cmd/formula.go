@@ -2,17 +2,24 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/base32"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/formula"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 	"golang.org/x/text/cases"
@@ -23,9 +30,12 @@ import (
 var (
 	formulaListJSON   bool
 	formulaShowJSON   bool
+	formulaShowFlat   bool
 	formulaRunPR      int
 	formulaRunRig     string
 	formulaRunDryRun  bool
+	formulaRunVars    []string
+	formulaRunOn      string
 	formulaCreateType string
 )
 
@@ -42,15 +52,21 @@ and composition rules. They can be "poured" to create molecules or "wisped"
 for ephemeral patrol cycles.
 
 Commands:
-  list    List available formulas from all search paths
-  show    Display formula details (steps, variables, composition)
-  run     Execute a formula (pour and dispatch)
-  create  Create a new formula template
+  list     List available formulas from all search paths
+  show     Display formula details (steps, variables, composition)
+  run      Execute a formula (pour and dispatch)
+  create   Create a new formula template
+  lint     Check formulas for undeclared and unused variables
+  graph    Draw a formula's dependency graph (ascii, dot, mermaid)
+  simulate Estimate wall-clock time and bottlenecks before running
+  install  Install a formula pack from a git repository
+  update   Update formula packs to their latest commits
 
 Search paths (in order):
   1. .beads/formulas/ (project)
   2. ~/.beads/formulas/ (user)
   3. $GT_ROOT/.beads/formulas/ (orchestrator)
+  4. formula packs installed in the town (named <pack>/<formula>)
 
 Examples:
   gt formula list                    # List all formulas
@@ -85,10 +101,16 @@ Shows:
   - Variables with defaults and constraints
   - Steps with dependencies
   - Composition rules (extends, aspects)
+  - Step exit criteria ([steps.verify] blocks)
+  - The agent each step or leg runs on (agent / role_agent keys)
+
+With --flattened, shows the formula as it runs: the formulas it extends
+and includes resolved into one list of steps and variables.
 
 Examples:
   gt formula show shiny
-  gt formula show rule-of-five --json`,
+  gt formula show rule-of-five --json
+  gt formula show shiny-secure --flattened`,
 	Args: cobra.ExactArgs(1),
 	RunE: runFormulaShow,
 }
@@ -100,8 +122,18 @@ var formulaRunCmd = &cobra.Command{
 
 This command:
   1. Looks up the formula by name (or uses default from rig config)
-  2. Pours it to create a molecule (or uses existing proto)
-  3. Dispatches the molecule to available workers
+  2. Parses and validates it, resolving extends and include
+  3. Dispatches the work according to the formula type:
+       convoy     one polecat per leg, plus a synthesis bead blocked on them
+       aspect     one polecat per [[aspects]] entry, like a convoy
+       workflow   cooked into a molecule and slung to one polecat
+       expansion  applied to an existing bead (--on) and slung; with a
+                  [matrix], one polecat per item, like a convoy
+
+Legs and parallel steps that set agent (an agent name from the rig's or
+town's agents, or a built-in preset) or role_agent (use that role's
+configured agent) start their polecats on that agent; the rest use the
+rig's default.
 
 For PR-based workflows, use --pr to specify the GitHub PR number.
 
@@ -109,15 +141,23 @@ If no formula name is provided, uses the default formula configured in
 the rig's settings/config.json under workflow.default_formula.
 
 Options:
-  --pr=N      Run formula on GitHub PR #N
+  --pr=N      Run formula on GitHub PR #N (the formula's pr input, if it has one)
+  --var K=V   Set a formula variable or input (repeatable)
+  --on=BEAD   Target bead for an expansion formula
   --rig=NAME  Target specific rig (default: current or gastown)
   --dry-run   Show what would happen without executing
 
+Variables are checked against the formula's declared types (int, bool, enum,
+bead-id, path, pattern-constrained string) and required flags before
+anything is dispatched.
+
 Examples:
   gt formula run shiny                    # Run formula in current rig
   gt formula run                          # Run default formula from rig config
   gt formula run shiny --pr=123           # Run on PR #123
-  gt formula run security-audit --rig=beads  # Run in specific rig
+  gt formula run design --var problem="Rate limiting"
+  gt formula run code-review --rig=beads  # Run in specific rig
+  gt formula run rule-of-five --on gt-abc # Expand onto a bead
   gt formula run release --dry-run        # Preview execution`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runFormulaRun,
@@ -150,11 +190,14 @@ func init() {
 
 	// Show flags
 	formulaShowCmd.Flags().BoolVar(&formulaShowJSON, "json", false, "Output as JSON")
+	formulaShowCmd.Flags().BoolVar(&formulaShowFlat, "flattened", false, "Show the formula with extends/include resolved")
 
 	// Run flags
 	formulaRunCmd.Flags().IntVar(&formulaRunPR, "pr", 0, "GitHub PR number to run formula on")
 	formulaRunCmd.Flags().StringVar(&formulaRunRig, "rig", "", "Target rig (default: current or gastown)")
 	formulaRunCmd.Flags().BoolVar(&formulaRunDryRun, "dry-run", false, "Preview execution without running")
+	formulaRunCmd.Flags().StringArrayVar(&formulaRunVars, "var", nil, "Formula variable or input (key=value), can be repeated")
+	formulaRunCmd.Flags().StringVar(&formulaRunOn, "on", "", "Target bead for an expansion formula")
 
 	// Create flags
 	formulaCreateCmd.Flags().StringVar(&formulaCreateType, "type", "task", "Formula type: task, workflow, or patrol")
@@ -178,12 +221,53 @@ func runFormulaList(cmd *cobra.Command, args []string) error {
 	bdCmd := exec.Command("bd", bdArgs...)
 	bdCmd.Stdout = os.Stdout
 	bdCmd.Stderr = os.Stderr
-	return bdCmd.Run()
+	if err := bdCmd.Run(); err != nil {
+		return err
+	}
+
+	// bd doesn't know about formula packs; list them after its output
+	if packs, err := townFormulaPacks(); err == nil && !formulaListJSON {
+		names, _ := packs.Installed()
+		for _, name := range names {
+			fmt.Printf("\n%s\n", style.Bold.Render("Pack "+name+":"))
+			printPackFormulas(packs, name)
+		}
+	}
+	return nil
 }
 
 // runFormulaShow delegates to bd formula show
 func runFormulaShow(cmd *cobra.Command, args []string) error {
 	formulaName := args[0]
+	if formulaShowFlat {
+		if formulaShowJSON {
+			return fmt.Errorf("--json is not supported with --flattened")
+		}
+		f, err := loadFlattenedFormula(formulaName)
+		if err != nil {
+			return err
+		}
+		printFlattenedFormula(f)
+		return nil
+	}
+
+	// Local formulas must pass our parser before bd renders them, so show
+	// never displays a formula that run would reject
+	var f *formula.Formula
+	if path, err := findFormulaFile(formulaName); err == nil {
+		if f, err = loadFlattenedFormula(formulaName); err != nil {
+			return err
+		}
+		// bd can't see pack formulas
+		if isPackFormula(path) {
+			if formulaShowJSON {
+				return fmt.Errorf("--json is not supported for pack formulas")
+			}
+			printFlattenedFormula(f)
+			return nil
+		}
+	}
+
 	bdArgs := []string{"formula", "show", formulaName}
 	if formulaShowJSON {
 		bdArgs = append(bdArgs, "--json")
@@ -192,12 +276,168 @@ func runFormulaShow(cmd *cobra.Command, args []string) error {
 	bdCmd := exec.Command("bd", bdArgs...)
 	bdCmd.Stdout = os.Stdout
 	bdCmd.Stderr = os.Stderr
-	return bdCmd.Run()
+	if err := bdCmd.Run(); err != nil {
+		return err
+	}
+
+	// bd doesn't know about step exit criteria or agents; show them after
+	// its output
+	if f != nil && !formulaShowJSON {
+		printFormulaAgents(f)
+		printFormulaVerify(f)
+	}
+	return nil
 }
 
-// runFormulaRun executes a formula by spawning a convoy of polecats.
-// For convoy-type formulas, it creates a convoy bead, creates leg beads,
-// and slings each leg to a separate polecat with leg-specific prompts.
+// printFlattenedFormula prints a flattened formula's variables and its steps
+// in dependency order.
+func printFlattenedFormula(f *formula.Formula) {
+	fmt.Printf("%s %s\n", style.Bold.Render(f.Name), style.Dim.Render("("+string(f.Type)+", flattened)"))
+	if f.Description != "" {
+		fmt.Printf("  %s\n", f.Description)
+	}
+
+	if len(f.Vars) > 0 {
+		fmt.Printf("\n%s\n", style.Bold.Render("Variables:"))
+		names := make([]string, 0, len(f.Vars))
+		for name := range f.Vars {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v := f.Vars[name]
+			var attrs []string
+			if v.Required {
+				attrs = append(attrs, "required")
+			}
+			if v.Default != "" {
+				attrs = append(attrs, "default="+v.Default)
+			}
+			line := "  " + name
+			if len(attrs) > 0 {
+				line += " " + style.Dim.Render("("+strings.Join(attrs, ", ")+")")
+			}
+			if v.Description != "" {
+				line += ": " + v.Description
+			}
+			fmt.Println(line)
+		}
+	}
+
+	if len(f.Steps) > 0 {
+		order, err := f.TopologicalSort()
+		if err != nil {
+			order = f.GetAllIDs()
+		}
+		fmt.Printf("\n%s\n", style.Bold.Render("Steps:"))
+		for i, id := range order {
+			step := f.GetStep(id)
+			line := fmt.Sprintf("  %d. %s: %s", i+1, step.ID, step.Title)
+			if step.IsParallel() {
+				line += " " + style.Dim.Render("(parallel)")
+			}
+			line += agentSuffix(step.Agent, step.RoleAgent)
+			fmt.Println(line)
+			if len(step.Needs) > 0 {
+				fmt.Printf("     %s\n", style.Dim.Render("needs: "+strings.Join(step.Needs, ", ")))
+			}
+		}
+	}
+
+	for _, leg := range f.Legs {
+		fmt.Printf("  leg %s: %s%s\n", leg.ID, leg.Title, agentSuffix(leg.Agent, leg.RoleAgent))
+	}
+	for _, tmpl := range f.Template {
+		fmt.Printf("  template %s: %s\n", tmpl.ID, tmpl.Title)
+	}
+	for _, aspect := range f.Aspects {
+		fmt.Printf("  aspect %s: %s\n", aspect.ID, aspect.Title)
+	}
+	if m := f.Matrix; m != nil {
+		source := strings.Join(m.Items, ", ")
+		if m.Var != "" {
+			source = "{{" + m.Var + "}}"
+		} else if m.Command != "" {
+			source = "$ " + m.Command
+		}
+		fmt.Printf("  matrix over %s\n", source)
+		if m.FanIn != nil {
+			fmt.Printf("  fan-in %s: %s\n", m.FanIn.ID, m.FanIn.Title)
+		}
+	}
+
+	printFormulaVerify(f)
+}
+
+// printFormulaAgents lists the steps and legs that run on a chosen agent.
+// Inside a town, role_agent choices show the agent they resolve to.
+func printFormulaAgents(f *formula.Formula) {
+	type choice struct{ id, agent, roleAgent string }
+	var choices []choice
+	for _, step := range f.Steps {
+		if step.Agent != "" || step.RoleAgent != "" {
+			choices = append(choices, choice{step.ID, step.Agent, step.RoleAgent})
+		}
+	}
+	for _, leg := range f.Legs {
+		if leg.Agent != "" || leg.RoleAgent != "" {
+			choices = append(choices, choice{leg.ID, leg.Agent, leg.RoleAgent})
+		}
+	}
+	if len(choices) == 0 {
+		return
+	}
+
+	townRoot, _ := workspace.FindFromCwd()
+	rigPath := ""
+	if cwd, err := os.Getwd(); err == nil && townRoot != "" {
+		_, rigPath = rigPathForCwd(cwd, townRoot)
+	}
+	fmt.Printf("\n%s\n", style.Bold.Render("Agents:"))
+	for _, c := range choices {
+		line := fmt.Sprintf("  %s%s", c.id, agentSuffix(c.agent, c.roleAgent))
+		if townRoot != "" {
+			if agent, err := resolveFormulaAgent(townRoot, rigPath, c.agent, c.roleAgent); err != nil {
+				line += " " + style.Warning.Render(err.Error())
+			} else if c.roleAgent != "" {
+				line += " → " + agent
+			}
+		}
+		fmt.Println(line)
+	}
+}
+
+// printFormulaVerify lists the verify blocks of a formula's steps.
+func printFormulaVerify(f *formula.Formula) {
+	var steps []formula.Step
+	for _, step := range f.Steps {
+		if step.Verify != nil {
+			steps = append(steps, step)
+		}
+	}
+	if len(steps) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Exit criteria (checked by gt mol step done):"))
+	for _, step := range steps {
+		v := step.Verify
+		fmt.Printf("  %s %s\n", step.ID, style.Dim.Render("(timeout "+v.TimeoutDuration().String()+")"))
+		for _, path := range v.FileExists {
+			fmt.Printf("    file exists: %s\n", path)
+		}
+		for _, g := range v.Grep {
+			fmt.Printf("    grep: %s =~ /%s/\n", g.File, g.Pattern)
+		}
+		if v.Command != "" {
+			fmt.Printf("    command: %s (exit %d)\n", v.Command, v.ExitCode)
+		}
+	}
+}
+
+// runFormulaRun executes a formula. Convoy and aspect formulas get a convoy
+// bead and a polecat per leg; workflow and expansion formulas are handed to
+// gt sling, which cooks them into a molecule.
 func runFormulaRun(cmd *cobra.Command, args []string) error {
 	// Determine target rig first (needed for default formula lookup)
 	targetRig := formulaRunRig
@@ -245,42 +485,132 @@ func runFormulaRun(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Using default formula: %s\n", style.Dim.Render("Note:"), formulaName)
 	}
 
-	// Find the formula file
-	formulaPath, err := findFormulaFile(formulaName)
+	// Parse and validate the formula, resolving composition
+	f, err := loadFlattenedFormula(formulaName)
 	if err != nil {
-		return fmt.Errorf("finding formula: %w", err)
+		return err
 	}
 
-	// Parse the formula
-	f, err := parseFormulaFile(formulaPath)
+	// Check variables before dispatching anything
+	vars, err := parseVarFlags(formulaRunVars)
 	if err != nil {
-		return fmt.Errorf("parsing formula: %w", err)
+		return err
+	}
+	if formulaRunPR > 0 && formulaDeclares(f, "pr") {
+		if _, set := vars["pr"]; !set {
+			vars["pr"] = strconv.Itoa(formulaRunPR)
+		}
+	}
+	if err := f.CheckVars(vars); err != nil {
+		return fmt.Errorf("invalid variables for formula %s:\n%w", formulaName, err)
+	}
+	if f.Matrix != nil {
+		if formulaRunOn != "" {
+			return fmt.Errorf("--on does not apply to matrix formula %s; it expands over its own items", formulaName)
+		}
+		if f, err = expandFormulaMatrix(f, vars); err != nil {
+			return err
+		}
+	}
+	if f.Type == formula.TypeExpansion {
+		if formulaRunOn == "" {
+			return fmt.Errorf("expansion formula %s needs a target bead: gt formula run %s --on <bead-id>", formulaName, formulaName)
+		}
+		if len(vars) > 0 {
+			return fmt.Errorf("--var cannot be used with expansion formulas (they take their input from the --on bead)")
+		}
+	} else if formulaRunOn != "" {
+		return fmt.Errorf("--on only applies to expansion formulas; %s is a %s formula", formulaName, f.Type)
+	}
+	if f.Type == formula.TypeAspect && len(f.Aspects) == 0 {
+		return fmt.Errorf("aspect formula %s only has advice, which applies to other formulas' steps; use it with [compose] aspects = [\"%s\"]", formulaName, formulaName)
+	}
+
+	// Workflows and expansions are cooked by bd, which only searches the
+	// formula directories
+	if f.Type == formula.TypeWorkflow || f.Type == formula.TypeExpansion {
+		if path, err := findFormulaFile(formulaName); err == nil && isPackFormula(path) {
+			return fmt.Errorf("%s formula %s comes from a formula pack, which bd can't cook; copy %s into .beads/formulas/ to run it", f.Type, formulaName, path)
+		}
 	}
 
 	// Handle dry-run mode
 	if formulaRunDryRun {
-		return dryRunFormula(f, formulaName, targetRig)
-	}
-
-	// Currently only convoy formulas are supported for execution
-	if f.Type != "convoy" {
-		fmt.Printf("%s Formula type '%s' not yet supported for execution.\n",
-			style.Dim.Render("Note:"), f.Type)
-		fmt.Printf("Currently only 'convoy' formulas can be run.\n")
-		fmt.Printf("\nTo run '%s' manually:\n", formulaName)
-		fmt.Printf("  1. View formula:   gt formula show %s\n", formulaName)
-		fmt.Printf("  2. Cook to proto:  bd cook %s\n", formulaName)
-		fmt.Printf("  3. Pour molecule:  bd pour %s\n", formulaName)
-		fmt.Printf("  4. Sling to rig:   gt sling <mol-id> %s\n", targetRig)
-		return nil
+		return dryRunFormula(f, formulaName, targetRig, vars)
+	}
+
+	switch f.Type {
+	case formula.TypeConvoy:
+		return executeConvoyFormula(f, f.Legs, formulaName, targetRig, vars)
+	case formula.TypeAspect:
+		return executeConvoyFormula(f, aspectLegs(f), formulaName, targetRig, vars)
+	case formula.TypeWorkflow:
+		return executeSlingFormula(formulaName, targetRig, "", vars)
+	case formula.TypeExpansion:
+		return executeSlingFormula(formulaName, targetRig, formulaRunOn, nil)
+	default:
+		return fmt.Errorf("formula %s has unsupported type %q", formulaName, f.Type)
+	}
+}
+
+// expandFormulaMatrix resolves a matrix expansion formula's items (running
+// its command in the current directory) and stamps its templates out into a
+// convoy, one leg per item walking through that item's chain of steps.
+func expandFormulaMatrix(f *formula.Formula, vars map[string]string) (*formula.Formula, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting current directory: %w", err)
+	}
+	items, err := f.MatrixItems(context.Background(), cwd, vars)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("%s Matrix over %d items: %s\n", style.Dim.Render("Note:"), len(items), strings.Join(items, ", "))
+	return f.MatrixConvoy(items)
+}
+
+// formulaDeclares reports whether a formula has a var or input by name.
+func formulaDeclares(f *formula.Formula, name string) bool {
+	if _, ok := f.Vars[name]; ok {
+		return true
+	}
+	_, ok := f.Inputs[name]
+	return ok
+}
+
+// aspectLegs turns an aspect formula's aspects into convoy legs, so each
+// aspect is analyzed by its own polecat.
+func aspectLegs(f *formula.Formula) []formula.Leg {
+	legs := make([]formula.Leg, len(f.Aspects))
+	for i, a := range f.Aspects {
+		legs[i] = formula.Leg{ID: a.ID, Title: a.Title, Focus: a.Focus, Description: a.Description}
+	}
+	return legs
+}
+
+// executeSlingFormula hands a workflow or expansion formula to gt sling,
+// which cooks it, wisps the molecule and hooks it to a polecat. onBead
+// applies an expansion formula to an existing bead.
+func executeSlingFormula(formulaName, targetRig, onBead string, vars map[string]string) error {
+	slingArgs := []string{"sling", formulaName, targetRig}
+	if onBead != "" {
+		slingArgs = append(slingArgs, "--on", onBead)
+	}
+	for _, line := range formatRunVars(vars) {
+		slingArgs = append(slingArgs, "--var", line)
 	}
 
-	// Execute convoy formula
-	return executeConvoyFormula(f, formulaName, targetRig)
+	slingCmd := exec.Command("gt", slingArgs...)
+	slingCmd.Stdout = os.Stdout
+	slingCmd.Stderr = os.Stderr
+	if err := slingCmd.Run(); err != nil {
+		return fmt.Errorf("slinging formula %s: %w", formulaName, err)
+	}
+	return nil
 }
 
 // dryRunFormula shows what would happen without executing
-func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
+func dryRunFormula(f *formula.Formula, formulaName, targetRig string, vars map[string]string) error {
 	fmt.Printf("%s Would execute formula:\n", style.Dim.Render("[dry-run]"))
 	fmt.Printf("  Formula: %s\n", style.Bold.Render(formulaName))
 	fmt.Printf("  Type:    %s\n", f.Type)
@@ -288,23 +618,50 @@ func dryRunFormula(f *formulaData, formulaName, targetRig string) error {
 	if formulaRunPR > 0 {
 		fmt.Printf("  PR:      #%d\n", formulaRunPR)
 	}
+	if formulaRunOn != "" {
+		fmt.Printf("  On:      %s\n", formulaRunOn)
+	}
+	for _, line := range formatRunVars(vars) {
+		fmt.Printf("  Var:     %s\n", line)
+	}
 
-	if f.Type == "convoy" && len(f.Legs) > 0 {
-		fmt.Printf("\n  Legs (%d parallel):\n", len(f.Legs))
-		for _, leg := range f.Legs {
-			fmt.Printf("    • %s: %s\n", leg.ID, leg.Title)
+	switch f.Type {
+	case formula.TypeConvoy, formula.TypeAspect:
+		legs := f.Legs
+		if f.Type == formula.TypeAspect {
+			legs = aspectLegs(f)
+		}
+		fmt.Printf("\n  Legs (%d parallel):\n", len(legs))
+		for _, leg := range legs {
+			fmt.Printf("    • %s: %s%s\n", leg.ID, leg.Title, agentSuffix(leg.Agent, leg.RoleAgent))
 		}
 		if f.Synthesis != nil {
 			fmt.Printf("\n  Synthesis:\n")
 			fmt.Printf("    • %s\n", f.Synthesis.Title)
 		}
+	case formula.TypeWorkflow:
+		order, err := f.TopologicalSort()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n  Steps (%d, slung to one polecat):\n", len(order))
+		for _, id := range order {
+			step := f.GetStep(id)
+			fmt.Printf("    • %s: %s%s\n", id, step.Title, agentSuffix(step.Agent, step.RoleAgent))
+		}
+	case formula.TypeExpansion:
+		fmt.Printf("\n  Templates (%d, expanded on %s):\n", len(f.Template), formulaRunOn)
+		for _, tmpl := range f.Template {
+			fmt.Printf("    • %s: %s\n", tmpl.ID, tmpl.Title)
+		}
 	}
 
 	return nil
 }
 
-// executeConvoyFormula spawns a convoy of polecats to execute a convoy formula
-func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
+// executeConvoyFormula spawns a convoy of polecats, one per leg, for a
+// convoy formula (or an aspect formula's aspects)
+func executeConvoyFormula(f *formula.Formula, legs []formula.Leg, formulaName, targetRig string, vars map[string]string) error {
 	fmt.Printf("%s Executing convoy formula: %s\n\n",
 		style.Bold.Render("🚚"), formulaName)
 
@@ -315,19 +672,33 @@ func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
 	}
 	townBeads := filepath.Join(townRoot, ".beads")
 
+	// Resolve leg agents before creating anything
+	legAgents := make(map[string]string)
+	for _, leg := range legs {
+		agent, err := resolveFormulaAgent(townRoot, filepath.Join(townRoot, targetRig), leg.Agent, leg.RoleAgent)
+		if err != nil {
+			return fmt.Errorf("leg %s: %w", leg.ID, err)
+		}
+		legAgents[leg.ID] = agent
+	}
+
 	// Step 1: Create convoy bead
 	convoyID := fmt.Sprintf("hq-cv-%s", generateFormulaShortID())
-	convoyTitle := fmt.Sprintf("%s: %s", formulaName, f.Description)
+	convoyTitle := fmt.Sprintf("%s: %s", formulaName, strings.TrimSpace(f.Description))
 	if len(convoyTitle) > 80 {
 		convoyTitle = convoyTitle[:77] + "..."
 	}
 
 	// Build description with formula context
 	description := fmt.Sprintf("Formula convoy: %s\n\nLegs: %d\nRig: %s",
-		formulaName, len(f.Legs), targetRig)
+		formulaName, len(legs), targetRig)
 	if formulaRunPR > 0 {
 		description += fmt.Sprintf("\nPR: #%d", formulaRunPR)
 	}
+	varLines := formatRunVars(vars)
+	if len(varLines) > 0 {
+		description += "\nVars:\n  " + strings.Join(varLines, "\n  ")
+	}
 
 	createArgs := []string{
 		"create",
@@ -351,7 +722,7 @@ func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
 
 	// Step 2: Create leg beads and track them
 	legBeads := make(map[string]string) // leg.ID -> bead ID
-	for _, leg := range f.Legs {
+	for _, leg := range legs {
 		legBeadID := fmt.Sprintf("hq-leg-%s", generateFormulaShortID())
 
 		// Build leg description with prompt if available
@@ -361,6 +732,9 @@ func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
 				legDesc = fmt.Sprintf("%s\n\n---\nBase Prompt:\n%s", leg.Description, basePrompt)
 			}
 		}
+		if len(varLines) > 0 {
+			legDesc += "\n\n---\nInputs:\n  " + strings.Join(varLines, "\n  ")
+		}
 
 		legArgs := []string{
 			"create",
@@ -445,7 +819,7 @@ func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
 	fmt.Printf("\n%s Dispatching legs to polecats...\n\n", style.Bold.Render("→"))
 
 	slingCount := 0
-	for _, leg := range f.Legs {
+	for _, leg := range legs {
 		legBeadID, ok := legBeads[leg.ID]
 		if !ok {
 			continue
@@ -460,6 +834,9 @@ func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
 			"-a", leg.Description,
 			"-s", leg.Title,
 		}
+		if agent := legAgents[leg.ID]; agent != "" {
+			slingArgs = append(slingArgs, "--agent", agent)
+		}
 
 		slingCmd := exec.Command("gt", slingArgs...)
 		slingCmd.Stdout = os.Stdout
@@ -492,52 +869,53 @@ func executeConvoyFormula(f *formulaData, formulaName, targetRig string) error {
 	return nil
 }
 
-// formulaData holds parsed formula information
-type formulaData struct {
-	Name        string
-	Description string
-	Type        string
-	Legs        []formulaLeg
-	Synthesis   *formulaSynthesis
-	Prompts     map[string]string
-}
-
-type formulaLeg struct {
-	ID          string
-	Title       string
-	Focus       string
-	Description string
+// resolveFormulaAgent returns the agent a step or leg asks for: agent by
+// name (rig agents, town agents, then built-in presets), or role_agent via
+// the rig's or town's role_agents. Empty when it asks for neither, so the
+// rig's default applies.
+func resolveFormulaAgent(townRoot, rigPath, agent, roleAgent string) (string, error) {
+	switch {
+	case agent != "":
+		if _, _, err := config.ResolveAgentConfigWithOverride(townRoot, rigPath, agent); err != nil {
+			return "", err
+		}
+		return agent, nil
+	case roleAgent != "":
+		if !slices.Contains(config.AllRoles(), roleAgent) {
+			return "", fmt.Errorf("role_agent %q is not a role (%s)", roleAgent, strings.Join(config.AllRoles(), ", "))
+		}
+		name, _ := config.ResolveRoleAgentName(roleAgent, townRoot, rigPath)
+		return name, nil
+	}
+	return "", nil
 }
 
-type formulaSynthesis struct {
-	Title       string
-	Description string
-	DependsOn   []string
+// agentSuffix describes a step's or leg's agent choice for display, or is
+// empty when it has none.
+func agentSuffix(agent, roleAgent string) string {
+	switch {
+	case agent != "":
+		return " " + style.Dim.Render("(agent: "+agent+")")
+	case roleAgent != "":
+		return " " + style.Dim.Render("(role_agent: "+roleAgent+")")
+	}
+	return ""
 }
 
 // findFormulaFile searches for a formula file by name
 func findFormulaFile(name string) (string, error) {
-	// Search paths in order
-	searchPaths := []string{}
-
-	// 1. Project .beads/formulas/
-	if cwd, err := os.Getwd(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(cwd, ".beads", "formulas"))
-	}
-
-	// 2. Town .beads/formulas/
-	if townRoot, err := workspace.FindFromCwd(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(townRoot, ".beads", "formulas"))
-	}
-
-	// 3. User ~/.beads/formulas/
-	if home, err := os.UserHomeDir(); err == nil {
-		searchPaths = append(searchPaths, filepath.Join(home, ".beads", "formulas"))
+	// pack/name lives in an installed formula pack
+	if strings.Contains(name, "/") {
+		packs, err := townFormulaPacks()
+		if err != nil {
+			return "", err
+		}
+		return packs.Find(name)
 	}
 
 	// Try each path with common extensions
 	extensions := []string{".formula.toml", ".formula.json"}
-	for _, basePath := range searchPaths {
+	for _, basePath := range formulaSearchPaths() {
 		for _, ext := range extensions {
 			path := filepath.Join(basePath, name+ext)
 			if _, err := os.Stat(path); err == nil {
@@ -546,181 +924,132 @@ func findFormulaFile(name string) (string, error) {
 		}
 	}
 
+	// Local formulas shadow pack formulas; a bare name only reaches a pack
+	// if exactly one pack has it
+	if packs, err := townFormulaPacks(); err == nil {
+		path, err := packs.Find(name)
+		if !errors.Is(err, formula.ErrNotFound) {
+			return path, err
+		}
+	}
+
 	return "", fmt.Errorf("formula '%s' not found in search paths", name)
 }
 
-// parseFormulaFile parses a formula file into formulaData
-func parseFormulaFile(path string) (*formulaData, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	// Use simple TOML parsing for the fields we need
-	// (avoids importing the full formula package which might cause cycles)
-	f := &formulaData{
-		Prompts: make(map[string]string),
-	}
+// isPackFormula reports whether a formula file comes from a formula pack.
+// bd only searches the formula directories, so it can't cook pack formulas.
+func isPackFormula(path string) bool {
+	return strings.Contains(filepath.ToSlash(path), "/.beads/"+formula.PacksDirName+"/")
+}
 
-	content := string(data)
+// formulaSearchPaths returns the formula directories, in search order.
+func formulaSearchPaths() []string {
+	searchPaths := []string{}
 
-	// Parse formula name
-	if match := extractTOMLValue(content, "formula"); match != "" {
-		f.Name = match
+	// 1. Project .beads/formulas/
+	if cwd, err := os.Getwd(); err == nil {
+		searchPaths = append(searchPaths, filepath.Join(cwd, ".beads", "formulas"))
 	}
 
-	// Parse description
-	if match := extractTOMLMultiline(content, "description"); match != "" {
-		f.Description = match
+	// 2. Town .beads/formulas/
+	if townRoot, err := workspace.FindFromCwd(); err == nil {
+		searchPaths = append(searchPaths, filepath.Join(townRoot, ".beads", "formulas"))
 	}
 
-	// Parse type
-	if match := extractTOMLValue(content, "type"); match != "" {
-		f.Type = match
+	// 3. User ~/.beads/formulas/
+	if home, err := os.UserHomeDir(); err == nil {
+		searchPaths = append(searchPaths, filepath.Join(home, ".beads", "formulas"))
 	}
 
-	// Parse legs (convoy formulas)
-	f.Legs = extractLegs(content)
-
-	// Parse synthesis
-	f.Synthesis = extractSynthesis(content)
-
-	// Parse prompts
-	f.Prompts = extractPrompts(content)
+	return searchPaths
+}
 
-	return f, nil
+// formatRunVars renders variables as sorted key=value lines.
+func formatRunVars(vars map[string]string) []string {
+	lines := make([]string, 0, len(vars))
+	for k, v := range vars {
+		lines = append(lines, k+"="+v)
+	}
+	sort.Strings(lines)
+	return lines
 }
 
-// extractTOMLValue extracts a simple quoted value from TOML
-func extractTOMLValue(content, key string) string {
-	// Match: key = "value" or key = 'value'
-	for _, line := range strings.Split(content, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, key+" =") || strings.HasPrefix(line, key+"=") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				val := strings.TrimSpace(parts[1])
-				// Remove quotes
-				if len(val) >= 2 && (val[0] == '"' || val[0] == '\'') {
-					return val[1 : len(val)-1]
-				}
-				return val
-			}
+// parseVarFlags parses repeated --var key=value flags.
+func parseVarFlags(flags []string) (map[string]string, error) {
+	values := make(map[string]string, len(flags))
+	for _, flag := range flags {
+		key, value, ok := strings.Cut(flag, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --var %q (expected key=value)", flag)
 		}
+		values[strings.TrimSpace(key)] = value
 	}
-	return ""
+	return values, nil
 }
 
-// extractTOMLMultiline extracts a multiline string (""" ... """)
-func extractTOMLMultiline(content, key string) string {
-	// Look for key = """
-	keyPattern := key + ` = """`
-	idx := strings.Index(content, keyPattern)
-	if idx == -1 {
-		// Try single-line
-		return extractTOMLValue(content, key)
+// checkFormulaVars validates variable values against a formula's declared
+// vars and inputs. Formulas not in the local search paths are left to bd.
+func checkFormulaVars(name string, values map[string]string) error {
+	if _, err := findFormulaFile(name); err != nil {
+		return nil
 	}
-
-	start := idx + len(keyPattern)
-	end := strings.Index(content[start:], `"""`)
-	if end == -1 {
-		return ""
+	f, err := loadFlattenedFormula(name)
+	if err != nil {
+		return err
 	}
-
-	return strings.TrimSpace(content[start : start+end])
-}
-
-// extractLegs parses [[legs]] sections from TOML
-func extractLegs(content string) []formulaLeg {
-	var legs []formulaLeg
-
-	// Split by [[legs]]
-	sections := strings.Split(content, "[[legs]]")
-	for i, section := range sections {
-		if i == 0 {
-			continue // Skip content before first [[legs]]
-		}
-
-		// Find where this section ends (next [[ or EOF)
-		endIdx := strings.Index(section, "[[")
-		if endIdx == -1 {
-			endIdx = len(section)
-		}
-		section = section[:endIdx]
-
-		leg := formulaLeg{
-			ID:          extractTOMLValue(section, "id"),
-			Title:       extractTOMLValue(section, "title"),
-			Focus:       extractTOMLValue(section, "focus"),
-			Description: extractTOMLMultiline(section, "description"),
-		}
-
-		if leg.ID != "" {
-			legs = append(legs, leg)
-		}
+	if err := f.CheckVars(values); err != nil {
+		return fmt.Errorf("invalid variables for formula %s:\n%w", name, err)
 	}
-
-	return legs
+	return nil
 }
 
-// extractSynthesis parses [synthesis] section from TOML
-func extractSynthesis(content string) *formulaSynthesis {
-	idx := strings.Index(content, "[synthesis]")
-	if idx == -1 {
-		return nil
+// cookableFormula returns what to hand bd cook for a formula. bd cooks the
+// raw formula file and knows nothing of [[include]], so a formula that
+// includes others is flattened into a temporary file and cooked from there;
+// everything else is cooked by name. cleanup removes the temporary file.
+func cookableFormula(name string) (ref string, cleanup func(), err error) {
+	cleanup = func() {}
+	if _, err := findFormulaFile(name); err != nil {
+		return name, cleanup, nil
 	}
-
-	section := content[idx:]
-	// Find where section ends
-	if endIdx := strings.Index(section[1:], "\n["); endIdx != -1 {
-		section = section[:endIdx+1]
+	f, err := loadFlattenedFormula(name)
+	if err != nil {
+		return "", cleanup, err
 	}
-
-	syn := &formulaSynthesis{
-		Title:       extractTOMLValue(section, "title"),
-		Description: extractTOMLMultiline(section, "description"),
+	if len(f.Included) == 0 {
+		return name, cleanup, nil
 	}
 
-	// Parse depends_on array
-	if depsLine := extractTOMLValue(section, "depends_on"); depsLine != "" {
-		// Simple array parsing: ["a", "b", "c"]
-		depsLine = strings.Trim(depsLine, "[]")
-		for _, dep := range strings.Split(depsLine, ",") {
-			dep = strings.Trim(strings.TrimSpace(dep), `"'`)
-			if dep != "" {
-				syn.DependsOn = append(syn.DependsOn, dep)
-			}
-		}
+	dir, err := os.MkdirTemp("", "gt-formula-*")
+	if err != nil {
+		return "", cleanup, fmt.Errorf("flattening formula %s: %w", name, err)
 	}
-
-	if syn.Title == "" && syn.Description == "" {
-		return nil
+	cleanup = func() { _ = os.RemoveAll(dir) }
+	var buf bytes.Buffer
+	if err := f.Encode(&buf); err != nil {
+		cleanup()
+		return "", func() {}, err
 	}
-
-	return syn
-}
-
-// extractPrompts parses [prompts] section from TOML
-func extractPrompts(content string) map[string]string {
-	prompts := make(map[string]string)
-
-	idx := strings.Index(content, "[prompts]")
-	if idx == -1 {
-		return prompts
+	path := filepath.Join(dir, f.Name+".formula.toml")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		cleanup()
+		return "", func() {}, fmt.Errorf("flattening formula %s: %w", name, err)
 	}
+	return path, cleanup, nil
+}
 
-	section := content[idx:]
-	// Find where section ends
-	if endIdx := strings.Index(section[1:], "\n["); endIdx != -1 {
-		section = section[:endIdx+1]
+// loadFlattenedFormula finds a formula by name and resolves its extends and
+// include from the formula's own directory, then the usual search paths.
+func loadFlattenedFormula(name string) (*formula.Formula, error) {
+	path, err := findFormulaFile(name)
+	if err != nil {
+		return nil, err
 	}
-
-	// Extract base prompt
-	if base := extractTOMLMultiline(section, "base"); base != "" {
-		prompts["base"] = base
+	var loader formula.Loader = append(formula.DirLoader{filepath.Dir(path)}, formulaSearchPaths()...)
+	if packs, err := townFormulaPacks(); err == nil {
+		loader = formula.Loaders{loader, packs}
 	}
-
-	return prompts
+	return formula.ParseFileWith(path, loader)
 }
 
 // generateFormulaShortID generates a short random ID (5 lowercase chars)
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+)
+
+var formulaGraphFormat string
+
+var formulaGraphCmd = &cobra.Command{
+	Use:   "graph <name>",
+	Short: "Draw a formula's dependency graph",
+	Long: `Draw the steps (or legs and synthesis) of a formula and their dependencies.
+
+Formats:
+  ascii    Steps by level, with what each needs (default)
+  dot      Graphviz: gt formula graph shiny --format dot | dot -Tsvg > shiny.svg
+  mermaid  Mermaid flowchart, for Markdown and GitHub
+
+The output includes an analysis of the graph:
+  max parallelism   most steps that can run at the same time
+  critical path     longest dependency chain; no run takes fewer rounds
+  redundant edges   dependencies already implied by a longer path
+  unreachable       steps that can never become ready
+
+The critical path is highlighted and redundant edges are dashed. For dot and
+mermaid the analysis is written as comments, so the output stays valid.
+Composed formulas are drawn after flattening.
+
+Examples:
+  gt formula graph shiny
+  gt formula graph code-review --format mermaid
+  gt formula graph mol-polecat-work --format dot`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaGraph,
+}
+
+func init() {
+	formulaGraphCmd.Flags().StringVar(&formulaGraphFormat, "format", formula.FormatASCII, "Output format: ascii, dot or mermaid")
+	formulaCmd.AddCommand(formulaGraphCmd)
+}
+
+func runFormulaGraph(cmd *cobra.Command, args []string) error {
+	f, err := loadFlattenedFormula(args[0])
+	if err != nil {
+		return err
+	}
+
+	g := f.Graph()
+	if len(g.Nodes) == 0 {
+		return fmt.Errorf("formula %s has no steps to draw", f.Name)
+	}
+	out, err := g.Render(formulaGraphFormat, g.Analyze())
+	if err != nil {
+		return err
+	}
+	fmt.Print(out)
+	return nil
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var formulaLintCmd = &cobra.Command{
+	Use:   "lint [name...]",
+	Short: "Check formulas for variable mistakes",
+	Long: `Check formulas for problems that parsing lets through.
+
+Reports, per formula:
+  - errors:   {{var}} references in step text that aren't declared in [vars]
+  - warnings: declared vars that no step uses
+
+Names the agent fills in while working (report fields, computed values)
+belong in the formula's placeholders list rather than [vars]:
+  placeholders = ["bytes_freed", "total_cleaned"]
+
+Invalid formulas (bad var types, enum defaults, cycles, ...) are reported as
+errors too. Composed formulas are checked after flattening.
+
+With no names, lints every formula in the search paths and installed packs.
+
+Examples:
+  gt formula lint
+  gt formula lint shiny mol-polecat-work`,
+	RunE: runFormulaLint,
+}
+
+func init() {
+	formulaCmd.AddCommand(formulaLintCmd)
+}
+
+func runFormulaLint(cmd *cobra.Command, args []string) error {
+	names := args
+	if len(names) == 0 {
+		names = localFormulaNames()
+		if len(names) == 0 {
+			fmt.Printf("%s No formulas found in search paths\n", style.Dim.Render("○"))
+			return nil
+		}
+	}
+
+	var failed int
+	for _, name := range names {
+		f, err := loadFlattenedFormula(name)
+		if err != nil {
+			failed++
+			fmt.Printf("%s %s: %v\n", style.Error.Render("✗"), name, err)
+			continue
+		}
+
+		errs, warnings := f.Lint()
+		switch {
+		case len(errs) > 0:
+			failed++
+			fmt.Printf("%s %s\n", style.Error.Render("✗"), name)
+		case len(warnings) > 0:
+			fmt.Printf("%s %s\n", style.Warning.Render("⚠"), name)
+		default:
+			fmt.Printf("%s %s\n", style.Success.Render("✓"), name)
+		}
+		for _, e := range errs {
+			fmt.Printf("    error: %s\n", e)
+		}
+		for _, w := range warnings {
+			fmt.Printf("    %s\n", style.Dim.Render("warning: "+w))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d formula(s) have errors", failed)
+	}
+	return nil
+}
+
+// localFormulaNames lists the TOML formulas in the search paths, by name,
+// and those of installed packs as pack/name.
+func localFormulaNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range formulaSearchPaths() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutSuffix(e.Name(), ".formula.toml")
+			if !ok || e.IsDir() || seen[name] {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if packs, err := townFormulaPacks(); err == nil {
+		installed, _ := packs.Installed()
+		for _, pack := range installed {
+			formulas, _ := packs.Formulas(pack)
+			for _, name := range formulas {
+				names = append(names, pack+"/"+name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var formulaInstallName string
+
+var formulaInstallCmd = &cobra.Command{
+	Use:   "install [<git-url>[@ref]]",
+	Short: "Install a formula pack from a git repository",
+	Long: `Install a pack of formulas shared from a git repository.
+
+The pack is checked out under the town's .beads/formula-packs/<pack>, and
+its commit is pinned in .beads/formula-packs.lock.json. Commit the lockfile
+to give every checkout of the town the same formulas.
+
+Formulas are read from the repository's formulas/ (or .beads/formulas/)
+directory, else its root. Name them <pack>/<formula>; a bare name works too
+when no local formula and no other pack has it.
+
+The ref (branch, tag or commit) defaults to the remote's default branch and
+is what 'gt formula update' follows. With no URL, installs every pack in
+the lockfile at its pinned commit.
+
+Examples:
+  gt formula install https://github.com/acme/formulas.git
+  gt formula install git@github.com:acme/formulas.git@v2 --name acme
+  gt formula install file:///srv/git/team-formulas
+  gt formula install                        # restore packs from the lockfile
+  gt formula run acme/release`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runFormulaInstall,
+}
+
+var formulaUpdateCmd = &cobra.Command{
+	Use:   "update [pack...]",
+	Short: "Update formula packs to their latest commits",
+	Long: `Fetch formula packs and re-pin them to the newest commit of their ref.
+
+With no names, updates every installed pack.
+
+Examples:
+  gt formula update
+  gt formula update acme`,
+	RunE: runFormulaUpdate,
+}
+
+func init() {
+	formulaInstallCmd.Flags().StringVar(&formulaInstallName, "name", "", "Pack name (default: repository name)")
+	formulaCmd.AddCommand(formulaInstallCmd)
+	formulaCmd.AddCommand(formulaUpdateCmd)
+}
+
+func runFormulaInstall(cmd *cobra.Command, args []string) error {
+	packs, err := townFormulaPacks()
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		if formulaInstallName != "" {
+			return fmt.Errorf("--name needs a repository URL")
+		}
+		synced, err := packs.Sync()
+		for _, name := range synced {
+			fmt.Printf("%s Restored pack %s\n", style.Success.Render("✓"), name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(synced) == 0 {
+			fmt.Printf("%s All packs match the lockfile\n", style.Dim.Render("○"))
+		}
+		return nil
+	}
+
+	url, ref := formula.ParsePackSpec(args[0])
+	name := formulaInstallName
+	if name == "" {
+		name = formula.PackNameFromURL(url)
+	}
+	pin, err := packs.Install(name, url, ref)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Installed pack %s at %s\n", style.Success.Render("✓"), style.Bold.Render(name), shortCommit(pin.Commit))
+	printPackFormulas(packs, name)
+	return nil
+}
+
+func runFormulaUpdate(cmd *cobra.Command, args []string) error {
+	packs, err := townFormulaPacks()
+	if err != nil {
+		return err
+	}
+
+	names := args
+	if len(names) == 0 {
+		if names, err = packs.Installed(); err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Printf("%s No formula packs installed\n", style.Dim.Render("○"))
+			return nil
+		}
+	}
+
+	var failed int
+	for _, name := range names {
+		old, pin, err := packs.Update(name)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Printf("%s %s: %v\n", style.Error.Render("✗"), name, err)
+		case old.Commit == pin.Commit:
+			fmt.Printf("%s %s is up to date (%s)\n", style.Dim.Render("○"), name, shortCommit(pin.Commit))
+		default:
+			fmt.Printf("%s %s: %s → %s\n", style.Success.Render("✓"), name, shortCommit(old.Commit), shortCommit(pin.Commit))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d packs failed to update", failed, len(names))
+	}
+	return nil
+}
+
+// printPackFormulas lists the formulas a pack provides.
+func printPackFormulas(packs *formula.Packs, name string) {
+	names, err := packs.Formulas(name)
+	if err != nil || len(names) == 0 {
+		style.PrintWarning("pack %s has no *.formula.toml files", name)
+		return
+	}
+	for _, f := range names {
+		fmt.Printf("  %s/%s\n", name, f)
+	}
+}
+
+// townFormulaPacks returns the formula packs of the current town.
+func townFormulaPacks() (*formula.Packs, error) {
+	townRoot, err := workspace.FindFromCwd()
+	if err != nil || townRoot == "" {
+		return nil, fmt.Errorf("formula packs are installed per town; run this inside a Gas Town workspace")
+	}
+	return formula.NewPacks(filepath.Join(townRoot, ".beads")), nil
+}
+
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/formula"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	formulaSimPolecats        int
+	formulaSimDurations       []string
+	formulaSimDefaultDuration time.Duration
+	formulaSimFails           []string
+	formulaSimDefaultFail     float64
+	formulaSimAttempts        int
+	formulaSimRuns            int
+	formulaSimSeed            uint64
+	formulaSimVars            []string
+	formulaSimJSON            bool
+)
+
+var formulaSimulateCmd = &cobra.Command{
+	Use:   "simulate <name>",
+	Short: "Estimate a formula's wall-clock time before running it",
+	Long: `Simulate a run of a formula without dispatching anything.
+
+Walks the formula's steps (or legs and synthesis) as they become ready,
+running them on a limited number of polecats, and reports:
+  - the wall-clock time when nothing fails, and the schedule
+  - with failure rates, the mean, p50 and p90 over many randomized runs,
+    and how often a run gives up after --attempts tries at a step
+  - the steps holding up the most downstream work, and the longest chain
+
+Every step takes --default-duration unless --duration sets it; a failed
+attempt takes the full duration and is retried. Matrix formulas are
+expanded first (use --var to set the matrix variable).
+
+Examples:
+  gt formula simulate code-review --polecats 3
+  gt formula simulate shiny --duration implement=2h --duration review=20m
+  gt formula simulate release --fail test=0.3 --default-fail 0.05 --runs 5000`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFormulaSimulate,
+}
+
+func init() {
+	formulaSimulateCmd.Flags().IntVar(&formulaSimPolecats, "polecats", 0, "Polecats working at once (0: one per ready step)")
+	formulaSimulateCmd.Flags().StringArrayVar(&formulaSimDurations, "duration", nil, "Step duration (step=30m), can be repeated")
+	formulaSimulateCmd.Flags().DurationVar(&formulaSimDefaultDuration, "default-duration", formula.DefaultSimDuration, "Duration of steps without --duration")
+	formulaSimulateCmd.Flags().StringArrayVar(&formulaSimFails, "fail", nil, "Step failure probability (step=0.2), can be repeated")
+	formulaSimulateCmd.Flags().Float64Var(&formulaSimDefaultFail, "default-fail", 0, "Failure probability of steps without --fail")
+	formulaSimulateCmd.Flags().IntVar(&formulaSimAttempts, "attempts", formula.DefaultSimMaxAttempts, "Attempts at a step before the run gives up")
+	formulaSimulateCmd.Flags().IntVar(&formulaSimRuns, "runs", formula.DefaultSimRuns, "Randomized runs when steps can fail")
+	formulaSimulateCmd.Flags().Uint64Var(&formulaSimSeed, "seed", 1, "Random seed")
+	formulaSimulateCmd.Flags().StringArrayVar(&formulaSimVars, "var", nil, "Formula variable (key=value), for matrix items")
+	formulaSimulateCmd.Flags().BoolVar(&formulaSimJSON, "json", false, "Output as JSON")
+	formulaCmd.AddCommand(formulaSimulateCmd)
+}
+
+func runFormulaSimulate(cmd *cobra.Command, args []string) error {
+	f, err := loadFlattenedFormula(args[0])
+	if err != nil {
+		return err
+	}
+	if f.Matrix != nil {
+		vars, err := parseVarFlags(formulaSimVars)
+		if err != nil {
+			return err
+		}
+		cwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("getting current directory: %w", err)
+		}
+		items, err := f.MatrixItems(context.Background(), cwd, vars)
+		if err != nil {
+			return err
+		}
+		if f, err = f.ExpandMatrix(items); err != nil {
+			return err
+		}
+	}
+
+	opts := formula.SimOptions{
+		Concurrency:     formulaSimPolecats,
+		DefaultDuration: formulaSimDefaultDuration,
+		DefaultFailRate: formulaSimDefaultFail,
+		MaxAttempts:     formulaSimAttempts,
+		Runs:            formulaSimRuns,
+		Seed:            formulaSimSeed,
+		Durations:       make(map[string]time.Duration),
+		FailRates:       make(map[string]float64),
+	}
+	for _, flag := range formulaSimDurations {
+		id, value, ok := strings.Cut(flag, "=")
+		d, err := time.ParseDuration(value)
+		if !ok || err != nil {
+			return fmt.Errorf("invalid --duration %q (expected step=duration, e.g. build=45m)", flag)
+		}
+		opts.Durations[id] = d
+	}
+	for _, flag := range formulaSimFails {
+		id, value, ok := strings.Cut(flag, "=")
+		rate, err := strconv.ParseFloat(value, 64)
+		if !ok || err != nil {
+			return fmt.Errorf("invalid --fail %q (expected step=probability, e.g. test=0.2)", flag)
+		}
+		opts.FailRates[id] = rate
+	}
+
+	res, err := f.Simulate(opts)
+	if err != nil {
+		return err
+	}
+
+	if formulaSimJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+	printSimulation(f, res)
+	return nil
+}
+
+// printSimulation prints a simulation's timing, schedule and blockers.
+func printSimulation(f *formula.Formula, res *formula.SimResult) {
+	polecats := "one per ready step"
+	if formulaSimPolecats > 0 {
+		polecats = fmt.Sprintf("%d polecats", formulaSimPolecats)
+	}
+	fmt.Printf("%s Simulating %s %s\n\n", style.Bold.Render("🧪"), style.Bold.Render(f.Name),
+		style.Dim.Render(fmt.Sprintf("(%s, %d steps, %s)", f.Type, len(res.Schedule), polecats)))
+
+	fmt.Printf("  Wall-clock:  %s with no failures (peak %d busy)\n", simDuration(res.Ideal), res.Peak)
+	fmt.Printf("  Total work:  %s (one polecat)\n", simDuration(res.Work))
+	if res.Runs > 1 {
+		fmt.Printf("  With failures, %d runs:\n", res.Runs)
+		if res.Succeeded > 0 {
+			fmt.Printf("    mean %s, p50 %s, p90 %s\n", simDuration(res.Mean), simDuration(res.P50), simDuration(res.P90))
+		}
+		rate := float64(res.Succeeded) / float64(res.Runs) * 100
+		line := fmt.Sprintf("    %.1f%% finish within %d attempts per step", rate, formulaSimAttempts)
+		if res.Succeeded < res.Runs {
+			line = style.Warning.Render(line)
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Printf("\n%s\n", style.Bold.Render("Schedule (no failures):"))
+	g := f.Graph()
+	for _, slot := range res.Schedule {
+		title := ""
+		if n := g.Node(slot.ID); n != nil && n.Title != "" {
+			title = ": " + n.Title
+		}
+		fmt.Printf("  %s  polecat %-2d  %s%s\n",
+			style.Dim.Render(fmt.Sprintf("%8s → %-8s", simDuration(slot.Start), simDuration(slot.End))),
+			slot.Polecat, slot.ID, title)
+	}
+
+	width := 0
+	for _, b := range res.Blockers[:min(5, len(res.Blockers))] {
+		width = max(width, len(b.ID))
+	}
+	var shown int
+	for _, b := range res.Blockers {
+		if b.Downstream == 0 || shown == 5 {
+			break
+		}
+		if shown == 0 {
+			fmt.Printf("\n%s\n", style.Bold.Render("Blocking the most work:"))
+		}
+		shown++
+		steps := "steps"
+		if b.Downstream == 1 {
+			steps = "step"
+		}
+		line := fmt.Sprintf("  %-*s  %d %s, %s downstream", width, b.ID, b.Downstream, steps, simDuration(b.DownstreamWork))
+		if b.Critical {
+			line += " " + style.Warning.Render("(critical path)")
+		}
+		if a := res.Attempts[b.ID]; a > 1.005 {
+			line += style.Dim.Render(fmt.Sprintf(", %.2f attempts on average", a))
+		}
+		fmt.Println(line)
+	}
+}
+
+// simDuration formats a simulated duration to the minute, e.g. 2h30m.
+func simDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.String()
+	}
+	return strings.TrimSuffix(d.Round(time.Minute).String(), "0s")
+}
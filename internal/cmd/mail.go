@@ -30,11 +30,14 @@ var (
 	mailReplyMessage  string
 
 	// Search flags
-	mailSearchFrom    string
-	mailSearchSubject bool
-	mailSearchBody    bool
-	mailSearchArchive bool
-	mailSearchJSON    bool
+	mailSearchFrom     string
+	mailSearchSubject  bool
+	mailSearchBody     bool
+	mailSearchArchive  bool
+	mailSearchArchOnly bool
+	mailSearchJSON     bool
+	mailSearchLimit    int
+	mailSearchReindex  bool
 
 	// Announces flags
 	mailAnnouncesJSON bool
@@ -356,28 +359,53 @@ Examples:
 var mailSearchCmd = &cobra.Command{
 	Use:   "search <query>",
 	Short: "Search messages by content",
-	Long: `Search inbox for messages matching a pattern.
+	Long: `Search inbox and archive for messages matching a query.
 
 SYNTAX:
   gt mail search <query> [flags]
 
-The query is a regular expression pattern. Search is case-insensitive by default.
+Searches use an incremental on-disk index over the inbox and the mail
+archive, so they stay fast for large mailboxes. Results are ranked by
+relevance (subject hits count more than body hits), newest first on ties.
+
+QUERY SYNTAX (terms are combined with AND, case-insensitive):
+  word                 Subject or body contains word
+  prefix*              Any word starting with prefix
+  "exact phrase"       Literal phrase
+  from:<addr>          Sender contains <addr>
+  to:<addr>            Recipient or CC contains <addr>
+  type:<type>          task, scavenge, notification, reply
+  thread:<id>          Messages in a thread
+  priority:<p>         urgent, high, normal, low (or 0-4)
+  after:<date>         Sent at or after date (alias: since:)
+  before:<date>        Sent before date (alias: until:)
+  date:<d>[..<d>]      Sent on a day, or within an inclusive day range
+  is:<state>           read, unread, inbox, archived
+
+Dates: 2026-01-31, 2026-01-31T14:00, today, yesterday, or ages like 24h, 7d, 2w.
+
+Words match anywhere in a word, as plain text rather than regular
+expressions: "deploy" also finds "deployment" and "redeploy", and part of a
+bead ID finds the whole ID.
 
 FLAGS:
   --from <sender>   Filter by sender address (substring match)
   --subject         Only search subject lines
   --body            Only search message body
-  --archive         Include archived (closed) messages
+  --archive         Include archived messages (always on; kept for scripts)
+  --archived-only   Only search archived messages (same as is:archived)
+  --limit <n>       Show at most n results
+  --reindex         Rebuild the search index before searching
   --json            Output as JSON
 
-By default, searches both subject and body text.
-
 Examples:
-  gt mail search "urgent"                    # Find messages with "urgent"
-  gt mail search "status.*check" --subject   # Regex in subjects only
-  gt mail search "error" --from witness      # From witness, containing "error"
-  gt mail search "handoff" --archive         # Include archived messages
-  gt mail search "" --from mayor/            # All messages from mayor`,
+  gt mail search "urgent"                         # Find messages with "urgent"
+  gt mail search "status" --subject               # Subjects only
+  gt mail search "error from:witness since:24h"   # Recent witness errors
+  gt mail search "type:task priority:high"        # High-priority tasks
+  gt mail search "date:2026-01-10..2026-01-12"    # Three-day window
+  gt mail search "deploy" --archived-only         # Archived deploy/deployment mail
+  gt mail search "" --from mayor/                 # All messages from mayor`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMailSearch,
 }
@@ -457,8 +485,11 @@ func init() {
 	mailSearchCmd.Flags().StringVar(&mailSearchFrom, "from", "", "Filter by sender address")
 	mailSearchCmd.Flags().BoolVar(&mailSearchSubject, "subject", false, "Only search subject lines")
 	mailSearchCmd.Flags().BoolVar(&mailSearchBody, "body", false, "Only search message body")
-	mailSearchCmd.Flags().BoolVar(&mailSearchArchive, "archive", false, "Include archived messages")
+	mailSearchCmd.Flags().BoolVar(&mailSearchArchive, "archive", false, "Include archived messages (always on)")
+	mailSearchCmd.Flags().BoolVar(&mailSearchArchOnly, "archived-only", false, "Only search archived messages")
 	mailSearchCmd.Flags().BoolVar(&mailSearchJSON, "json", false, "Output as JSON")
+	mailSearchCmd.Flags().IntVar(&mailSearchLimit, "limit", 0, "Maximum number of results (0 = all)")
+	mailSearchCmd.Flags().BoolVar(&mailSearchReindex, "reindex", false, "Rebuild the search index before searching")
 
 	// Announces flags
 	mailAnnouncesCmd.Flags().BoolVar(&mailAnnouncesJSON, "json", false, "Output as JSON")
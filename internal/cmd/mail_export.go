@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Export/import command flags
+var (
+	mailExportFormat   string
+	mailExportOutput   string
+	mailExportThread   string
+	mailExportSince    string
+	mailExportUntil    string
+	mailExportIdentity string
+
+	mailImportFormat   string
+	mailImportIdentity string
+	mailImportDryRun   bool
+)
+
+var mailExportCmd = &cobra.Command{
+	Use:   "export [address]",
+	Short: "Export mail to Maildir, mbox, or JSONL",
+	Long: `Export an identity's inbox and archived mail for audits and post-mortems.
+
+Messages are written oldest first. Thread structure is preserved via
+Message-ID, In-Reply-To and References headers, CC recipients via Cc,
+and Gas Town metadata (thread, type, priority, read state) via X-Gt-* headers,
+so the export can be opened in any mail client and re-imported losslessly.
+
+FORMATS:
+  maildir   Directory with cur/, new/, tmp/ (requires --output)
+  mbox      Single mboxrd file (default: stdout)
+  jsonl     One JSON message per line, same as the mail archive (default: stdout)
+
+Dates for --since/--until accept 2026-01-31, 2026-01-31T14:00, RFC3339,
+today, yesterday, or ages like 24h, 7d, 2w.
+
+Examples:
+  gt mail export --format mbox > mayor.mbox
+  gt mail export gastown/Toast --format maildir -o ./toast-mail
+  gt mail export --thread thread-abc123 --format jsonl
+  gt mail export mayor/ --since 2026-01-10 --until 2026-01-12 --format mbox -o incident.mbox`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMailExport,
+}
+
+var mailImportCmd = &cobra.Command{
+	Use:   "import <path>",
+	Short: "Import mail from Maildir, mbox, or JSONL into the archive",
+	Long: `Rehydrate exported mail into an identity's mail archive.
+
+Imported messages are appended to the archive (not the inbox), so they are
+visible to 'gt mail search' without waking anyone up. Messages whose ID is
+already archived are skipped, so importing the same export twice is safe.
+
+The format is detected from the path unless --format is given:
+directories are Maildir, *.jsonl files are JSONL, anything else is mbox.
+
+Examples:
+  gt mail import ./toast-mail --identity gastown/Toast
+  gt mail import incident.mbox
+  gt mail import archive.jsonl --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMailImport,
+}
+
+func init() {
+	mailExportCmd.Flags().StringVar(&mailExportFormat, "format", "mbox", "Output format: maildir, mbox, jsonl")
+	mailExportCmd.Flags().StringVarP(&mailExportOutput, "output", "o", "", "Output file (mbox/jsonl) or directory (maildir)")
+	mailExportCmd.Flags().StringVar(&mailExportThread, "thread", "", "Only export this thread")
+	mailExportCmd.Flags().StringVar(&mailExportSince, "since", "", "Only export messages sent at or after this time")
+	mailExportCmd.Flags().StringVar(&mailExportUntil, "until", "", "Only export messages sent before this time")
+	mailExportCmd.Flags().StringVar(&mailExportIdentity, "identity", "", "Explicit identity to export (e.g., greenplace/Toast)")
+
+	mailImportCmd.Flags().StringVar(&mailImportFormat, "format", "", "Input format: maildir, mbox, jsonl (default: detect)")
+	mailImportCmd.Flags().StringVar(&mailImportIdentity, "identity", "", "Identity whose archive receives the mail (default: auto-detect)")
+	mailImportCmd.Flags().BoolVar(&mailImportDryRun, "dry-run", false, "Parse and count messages without importing")
+
+	mailCmd.AddCommand(mailExportCmd)
+	mailCmd.AddCommand(mailImportCmd)
+}
+
+func runMailExport(cmd *cobra.Command, args []string) error {
+	format, err := mail.ParseExportFormat(mailExportFormat)
+	if err != nil {
+		return err
+	}
+	if format == mail.FormatMaildir && mailExportOutput == "" {
+		return fmt.Errorf("--output is required for maildir export")
+	}
+
+	filter := mail.ExportFilter{ThreadID: mailExportThread}
+	if mailExportSince != "" {
+		if filter.Since, err = mail.ParseExportTime(mailExportSince); err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if mailExportUntil != "" {
+		if filter.Until, err = mail.ParseExportTime(mailExportUntil); err != nil {
+			return fmt.Errorf("invalid --until: %w", err)
+		}
+	}
+
+	// Priority: --identity flag, positional arg, auto-detect
+	address := mailExportIdentity
+	if address == "" && len(args) > 0 {
+		address = args[0]
+	}
+	if address == "" {
+		address = detectSender()
+	}
+
+	mailbox, err := getMailbox(address)
+	if err != nil {
+		return err
+	}
+
+	messages, err := mailbox.CollectForExport(filter)
+	if err != nil {
+		return fmt.Errorf("collecting messages: %w", err)
+	}
+
+	if format == mail.FormatMaildir {
+		if err := mail.WriteMaildir(mailExportOutput, messages); err != nil {
+			return fmt.Errorf("writing maildir: %w", err)
+		}
+	} else {
+		var w io.Writer = os.Stdout
+		if mailExportOutput != "" {
+			f, err := os.Create(mailExportOutput)
+			if err != nil {
+				return fmt.Errorf("creating output: %w", err)
+			}
+			defer func() { _ = f.Close() }()
+			w = f
+		}
+
+		if format == mail.FormatMbox {
+			err = mail.WriteMbox(w, messages)
+		} else {
+			err = mail.WriteJSONL(w, messages)
+		}
+		if err != nil {
+			return fmt.Errorf("writing %s: %w", format, err)
+		}
+	}
+
+	// Keep stdout clean when the export itself goes there.
+	if mailExportOutput != "" {
+		fmt.Printf("%s Exported %d message(s) for %s to %s (%s)\n",
+			style.Bold.Render("✓"), len(messages), address, mailExportOutput, format)
+	} else {
+		fmt.Fprintf(os.Stderr, "Exported %d message(s) for %s (%s)\n", len(messages), address, format)
+	}
+	return nil
+}
+
+func runMailImport(cmd *cobra.Command, args []string) error {
+	path := args[0]
+
+	var format mail.ExportFormat
+	var err error
+	if mailImportFormat != "" {
+		format, err = mail.ParseExportFormat(mailImportFormat)
+	} else {
+		format, err = mail.DetectImportFormat(path)
+	}
+	if err != nil {
+		return err
+	}
+
+	var messages []*mail.Message
+	if format == mail.FormatMaildir {
+		messages, err = mail.ReadMaildir(path)
+	} else {
+		f, openErr := os.Open(path) //nolint:gosec // G304: user-chosen import path
+		if openErr != nil {
+			return fmt.Errorf("opening %s: %w", path, openErr)
+		}
+		defer func() { _ = f.Close() }()
+		if format == mail.FormatMbox {
+			messages, err = mail.ReadMbox(f)
+		} else {
+			messages, err = mail.ReadJSONL(f)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", format, err)
+	}
+
+	if mailImportDryRun {
+		fmt.Printf("Would import %d message(s) from %s (%s)\n", len(messages), path, format)
+		return nil
+	}
+
+	address := mailImportIdentity
+	if address == "" {
+		address = detectSender()
+	}
+	mailbox, err := getMailbox(address)
+	if err != nil {
+		return err
+	}
+
+	imported, err := mailbox.ImportArchive(messages)
+	if err != nil {
+		return fmt.Errorf("importing messages (%d imported before failure): %w", imported, err)
+	}
+
+	fmt.Printf("%s Imported %d message(s) into %s archive", style.Bold.Render("✓"), imported, address)
+	if skipped := len(messages) - imported; skipped > 0 {
+		fmt.Printf(" %s", style.Dim.Render(fmt.Sprintf("(%d already archived)", skipped)))
+	}
+	fmt.Println()
+	return nil
+}
@@ -32,10 +32,13 @@ func runMailSearch(cmd *cobra.Command, args []string) error {
 
 	// Build search options
 	opts := mail.SearchOptions{
-		Query:       query,
-		FromFilter:  mailSearchFrom,
-		SubjectOnly: mailSearchSubject,
-		BodyOnly:    mailSearchBody,
+		Query:        query,
+		FromFilter:   mailSearchFrom,
+		SubjectOnly:  mailSearchSubject,
+		BodyOnly:     mailSearchBody,
+		ArchivedOnly: mailSearchArchOnly,
+		Limit:        mailSearchLimit,
+		Rebuild:      mailSearchReindex,
 	}
 
 	// Execute search
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Parallel step dispatch.
+//
+// When a step closes and several steps become ready at once, the ones marked
+// parallel in the formula (or all of them, with workflow.parallel_steps in
+// the rig settings) are slung to their own polecats. They share a gate bead,
+// recorded on the molecule root with the sibling step IDs. The agent keeps
+// working any ready steps that weren't fanned out; with none left it parks on
+// the gate. Whichever step done closes the last sibling closes the gate and
+// wakes the parked agent, which resumes on the molecule's next ready step.
+
+// planFanout picks the ready steps to hand to other polecats, and the agent
+// each should run on (by step bead ID) when its formula step names one.
+func planFanout(townRoot, rigPath string, root *beads.Issue, ready []*beads.Issue) ([]*beads.Issue, map[string]string) {
+	if len(ready) < 2 {
+		return nil, nil
+	}
+	all := rigPath != "" && config.GetParallelSteps(rigPath)
+
+	fields := beads.ParseAttachmentFields(root)
+	if fields == nil || fields.AttachedFormula == "" {
+		if all {
+			return ready, nil
+		}
+		return nil, nil
+	}
+	f, err := loadStepFormula(townRoot, rigPath, fields.AttachedFormula)
+	if err != nil {
+		if all {
+			return ready, nil
+		}
+		return nil, nil
+	}
+
+	// Map step beads to formula steps; unmatched beads can still be fanned
+	// out when everything is
+	byStep := make(map[string]*beads.Issue, len(ready))
+	ids := make([]string, 0, len(ready))
+	for _, issue := range ready {
+		id := issue.ID
+		if step := formulaStepFor(f, issue); step != nil {
+			id = step.ID
+		}
+		byStep[id] = issue
+		ids = append(ids, id)
+	}
+
+	var out []*beads.Issue
+	agents := make(map[string]string)
+	for _, id := range f.FanOut(ids, all) {
+		issue := byStep[id]
+		out = append(out, issue)
+		step := f.GetStep(id)
+		if step == nil {
+			continue
+		}
+		agent, err := resolveFormulaAgent(townRoot, rigPath, step.Agent, step.RoleAgent)
+		if err != nil {
+			style.PrintWarning("step %s: %v; using the rig's default agent", id, err)
+			continue
+		}
+		if agent != "" {
+			agents[issue.ID] = agent
+		}
+	}
+	return out, agents
+}
+
+// dispatchFanout creates the gate for a fan-out, records it on the molecule
+// root and the sibling steps, and slings each sibling to its own polecat.
+// Steps with an entry in agents start on that agent. Returns the gate ID and
+// the steps that were dispatched; steps that fail to sling stay with the
+// current agent.
+func dispatchFanout(b *beads.Beads, root *beads.Issue, rigName string, steps []*beads.Issue, agents map[string]string, dryRun bool) (string, []*beads.Issue, error) {
+	ids := make([]string, len(steps))
+	for i, step := range steps {
+		ids[i] = step.ID
+	}
+
+	if dryRun {
+		fmt.Printf("[dry-run] Would fan out %d parallel steps to polecats in %s: %s\n",
+			len(steps), rigName, strings.Join(ids, ", "))
+		return "", steps, nil
+	}
+
+	gate, err := b.CreateGate("human:fanout-"+root.ID, fmt.Sprintf("Parallel steps of %s", root.ID))
+	if err != nil {
+		return "", nil, err
+	}
+
+	fmt.Printf("\n%s Fanning out %d parallel steps (gate %s)\n", style.Bold.Render("🔀"), len(steps), gate.ID)
+	var dispatched []*beads.Issue
+	for _, step := range steps {
+		desc := beads.SetFanoutFields(step, &beads.FanoutFields{Gate: gate.ID})
+		if err := b.Update(step.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+			style.PrintWarning("could not mark %s as a parallel step: %v", step.ID, err)
+			continue
+		}
+
+		slingArgs := []string{"sling", step.ID, rigName, "--no-convoy",
+			"-s", step.Title,
+			"-a", fmt.Sprintf("Parallel step of molecule %s. When finished, run 'gt mol step done %s'.", root.ID, step.ID)}
+		if agent := agents[step.ID]; agent != "" {
+			slingArgs = append(slingArgs, "--agent", agent)
+		}
+		slingCmd := exec.Command("gt", slingArgs...)
+		slingCmd.Stdout = os.Stdout
+		slingCmd.Stderr = os.Stderr
+		if err := slingCmd.Run(); err != nil {
+			style.PrintWarning("could not sling %s, keeping it: %v", step.ID, err)
+			desc := beads.SetFanoutFields(step, nil)
+			_ = b.Update(step.ID, beads.UpdateOptions{Description: &desc})
+			continue
+		}
+		dispatched = append(dispatched, step)
+		fmt.Printf("  %s %s: %s%s\n", style.Dim.Render("○"), step.ID, step.Title, agentSuffix(agents[step.ID], ""))
+	}
+
+	// The root lists every sibling, dispatched or not: steps kept by this
+	// agent still have to close before the gate opens
+	desc := beads.SetFanoutFields(root, &beads.FanoutFields{Gate: gate.ID, Siblings: ids})
+	if err := b.Update(root.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+		return gate.ID, dispatched, fmt.Errorf("recording fan-out on %s: %w", root.ID, err)
+	}
+	return gate.ID, dispatched, nil
+}
+
+// settleFanout closes a molecule's fan-out gate once all its sibling steps
+// are closed, and wakes the agents parked on it.
+func settleFanout(b *beads.Beads, moleculeID string, dryRun bool) {
+	gateID, siblings := openFanout(b, moleculeID)
+	if gateID == "" {
+		return
+	}
+	steps, err := b.ShowMultiple(siblings)
+	if err != nil {
+		return
+	}
+	for _, id := range siblings {
+		if step, ok := steps[id]; !ok || step.Status != "closed" {
+			return
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("[dry-run] Would close fan-out gate %s and wake waiters\n", gateID)
+		return
+	}
+	if err := b.CloseWithReason("all parallel steps closed", gateID); err != nil {
+		style.PrintWarning("could not close fan-out gate %s: %v", gateID, err)
+		return
+	}
+	fmt.Printf("%s Parallel steps done, gate %s closed\n", style.Bold.Render("🚦"), gateID)
+
+	wakeCmd := exec.Command("gt", "gate", "wake", gateID)
+	wakeCmd.Stdout = os.Stdout
+	wakeCmd.Stderr = os.Stderr
+	if err := wakeCmd.Run(); err != nil {
+		style.PrintWarning("could not wake waiters on %s: %v", gateID, err)
+	}
+}
+
+// openFanout returns a molecule's fan-out gate and sibling steps, if the
+// gate is still open.
+func openFanout(b *beads.Beads, moleculeID string) (string, []string) {
+	root, err := b.Show(moleculeID)
+	if err != nil {
+		return "", nil
+	}
+	fields := beads.ParseFanoutFields(root)
+	if fields == nil || fields.Gate == "" {
+		return "", nil
+	}
+	gate, err := b.Show(fields.Gate)
+	if err != nil || gate.Status == "closed" {
+		return "", nil
+	}
+	return fields.Gate, fields.Siblings
+}
+
+// parkOnFanout parks the current agent on a molecule's fan-out gate. On
+// resume, the agent continues with the molecule's next ready step.
+func parkOnFanout(cwd, townRoot, moleculeID, gateID string, dryRun bool) error {
+	agentID, cloneRoot, err := currentAgentAndRoot(cwd, townRoot)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		fmt.Printf("[dry-run] Would park %s on gate %s until parallel steps close\n", agentID, gateID)
+		return nil
+	}
+
+	parked := &ParkedWork{
+		AgentID:  agentID,
+		GateID:   gateID,
+		Molecule: moleculeID,
+		Context:  fmt.Sprintf("Waiting on parallel steps of %s", moleculeID),
+		ParkedAt: time.Now(),
+	}
+	if err := parkWork(cloneRoot, parked); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%s Parked on gate %s until the parallel steps close\n", style.Bold.Render("🅿️"), gateID)
+	fmt.Printf("%s You can now safely exit. You'll get wake mail; then run 'gt resume'.\n",
+		style.Dim.Render("→"))
+	return nil
+}
+
+// currentAgentAndRoot returns the agent identity for cwd and the clone root
+// its hook and parked state live in.
+func currentAgentAndRoot(cwd, townRoot string) (string, string, error) {
+	roleInfo, err := GetRoleWithContext(cwd, townRoot)
+	if err != nil {
+		return "", "", fmt.Errorf("detecting role: %w", err)
+	}
+	agentID := buildAgentIdentity(RoleContext{
+		Role:     roleInfo.Role,
+		Rig:      roleInfo.Rig,
+		Polecat:  roleInfo.Polecat,
+		TownRoot: townRoot,
+		WorkDir:  cwd,
+	})
+	if agentID == "" {
+		return "", "", fmt.Errorf("cannot determine agent identity (role: %s)", roleInfo.Role)
+	}
+	cloneRoot := roleInfo.Home
+	if cloneRoot == "" {
+		if cloneRoot, err = getGitRoot(); err != nil {
+			return "", "", fmt.Errorf("finding git root: %w", err)
+		}
+	}
+	return agentID, cloneRoot, nil
+}
+
+// rigPathForCwd returns the name and path of the rig containing cwd, or
+// empty strings outside a rig.
+func rigPathForCwd(cwd, townRoot string) (string, string) {
+	roleInfo, err := GetRoleWithContext(cwd, townRoot)
+	if err != nil || roleInfo.Rig == "" {
+		return "", ""
+	}
+	return roleInfo.Rig, filepath.Join(townRoot, roleInfo.Rig)
+}
@@ -1,14 +1,18 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/formula"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -22,17 +26,33 @@ var moleculeStepDoneCmd = &cobra.Command{
 
 This command handles the step-to-step transition for polecats:
 
-1. Closes the completed step (bd close <step-id>)
-2. Extracts the molecule ID from the step
-3. Finds the next ready step (dependency-aware)
-4. If next step exists:
+1. Extracts the molecule ID from the step
+2. Runs the step's verify block, if its formula has one
+3. Closes the completed step (bd close <step-id>)
+4. Finds the next ready step (dependency-aware)
+5. If several steps are ready and some are parallel:
+   - Slings each parallel step to its own polecat, sharing a gate
+   - Continues with any ready step that wasn't fanned out, or else
+     parks on the gate until the parallel steps close
+6. If next step exists:
    - Updates the hook to point to the next step
    - Respawns the pane for a fresh session
-5. If molecule complete:
+7. If molecule complete:
    - Clears the hook
    - Sends POLECAT_DONE to witness
    - Exits the session
 
+Steps are parallel when their formula says so (parallel = true), or, with
+workflow.parallel_steps in the rig settings, whenever more than one step is
+ready. A polecat given a parallel step runs 'gt mol step done' on it and
+then 'gt done'; the last one to close opens the gate and wakes the agent
+that fanned out, which resumes on the next ready step with 'gt resume'.
+
+Formula steps may declare machine-checked exit criteria ([steps.verify]: a
+command and its expected exit code, files that must exist, patterns that
+must appear, and a timeout). They run in the agent's worktree; if any check
+fails the step stays open and the failing check's output is shown.
+
 IMPORTANT: This is the canonical way to complete molecule steps. Do NOT manually
 close steps with 'bd close' - it skips the auto-continuation logic.
 
@@ -59,7 +79,9 @@ type StepDoneResult struct {
 	NextStepID   string `json:"next_step_id,omitempty"`
 	NextStepTitle string `json:"next_step_title,omitempty"`
 	Complete     bool   `json:"complete"`
-	Action       string `json:"action"` // "continue", "done", "no_more_ready"
+	Action       string `json:"action"` // "continue", "done", "park", "parallel_done", "no_more_ready"
+	FannedOut    []string `json:"fanned_out,omitempty"` // Parallel steps slung to other polecats
+	Gate         string   `json:"gate,omitempty"`       // Fan-out gate the remaining steps wait on
 }
 
 func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
@@ -104,7 +126,13 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		MoleculeID: moleculeID,
 	}
 
-	// Step 3: Close the step
+	// Step 3: Check the step's exit criteria, if its formula declares any
+	rigName, rigPath := rigPathForCwd(cwd, townRoot)
+	if err := verifyMoleculeStep(b, townRoot, rigPath, cwd, moleculeID, step, moleculeStepDryRun); err != nil {
+		return err
+	}
+
+	// Step 4: Close the step
 	if moleculeStepDryRun {
 		fmt.Printf("[dry-run] Would close step: %s\n", stepID)
 		result.StepClosed = true
@@ -116,32 +144,68 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 		fmt.Printf("%s Closed step %s: %s\n", style.Bold.Render("✓"), stepID, step.Title)
 	}
 
-	// Step 4: Find the next ready step
-	nextStep, allComplete, err := findNextReadyStep(b, moleculeID)
+	// A fan-out gate opens once its last sibling closes, whoever closed it
+	settleFanout(b, moleculeID, moleculeStepDryRun)
+
+	// A fanned-out parallel step is this polecat's whole job; the agent
+	// that fanned it out carries on with the molecule
+	if fields := beads.ParseFanoutFields(step); fields != nil && fields.Gate != "" {
+		result.Action = "parallel_done"
+		if moleculeJSON {
+			return printStepDoneJSON(result)
+		}
+		return handleParallelStepDone(cwd, townRoot, moleculeStepDryRun)
+	}
+
+	// Step 5: Find the ready steps, fanning out parallel ones
+	ready, allComplete, err := findReadySteps(b, moleculeID)
 	if err != nil {
 		return fmt.Errorf("finding next step: %w", err)
 	}
 
-	if allComplete {
+	var fanoutGate string
+	if root, err := b.Show(moleculeID); err == nil && rigName != "" {
+		if fan, agents := planFanout(townRoot, rigPath, root, ready); len(fan) > 0 {
+			gateID, dispatched, err := dispatchFanout(b, root, rigName, fan, agents, moleculeStepDryRun)
+			if err != nil {
+				return fmt.Errorf("fanning out parallel steps: %w", err)
+			}
+			fanoutGate = gateID
+			for _, d := range dispatched {
+				result.FannedOut = append(result.FannedOut, d.ID)
+			}
+			ready = withoutSteps(ready, dispatched)
+		}
+	}
+	if fanoutGate == "" && len(ready) == 0 && !allComplete {
+		fanoutGate, _ = openFanout(b, moleculeID)
+	}
+	result.Gate = fanoutGate
+
+	var nextStep *beads.Issue
+	switch {
+	case allComplete:
 		result.Complete = true
 		result.Action = "done"
-	} else if nextStep != nil {
+	case len(ready) > 0:
+		nextStep = ready[0]
 		result.NextStepID = nextStep.ID
 		result.NextStepTitle = nextStep.Title
 		result.Action = "continue"
-	} else {
+	case fanoutGate != "" || len(result.FannedOut) > 0:
+		// Everything left waits on parallel steps other polecats are working
+		result.Action = "park"
+	default:
 		// There are more steps but none are ready (blocked on dependencies)
 		result.Action = "no_more_ready"
 	}
 
 	// JSON output
 	if moleculeJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(result)
+		return printStepDoneJSON(result)
 	}
 
-	// Step 5: Handle next action
+	// Step 6: Handle next action
 	switch result.Action {
 	case "continue":
 		return handleStepContinue(cwd, townRoot, workDir, nextStep, moleculeStepDryRun)
@@ -149,6 +213,9 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 	case "done":
 		return handleMoleculeComplete(cwd, townRoot, moleculeID, moleculeStepDryRun)
 
+	case "park":
+		return parkOnFanout(cwd, townRoot, moleculeID, fanoutGate, moleculeStepDryRun)
+
 	case "no_more_ready":
 		fmt.Printf("\n%s All remaining steps are blocked - waiting on dependencies\n",
 			style.Dim.Render("ℹ"))
@@ -159,6 +226,164 @@ func runMoleculeStepDone(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func printStepDoneJSON(result StepDoneResult) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// withoutSteps returns steps minus the ones in drop.
+func withoutSteps(steps, drop []*beads.Issue) []*beads.Issue {
+	dropped := make(map[string]bool, len(drop))
+	for _, d := range drop {
+		dropped[d.ID] = true
+	}
+	var out []*beads.Issue
+	for _, s := range steps {
+		if !dropped[s.ID] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// handleParallelStepDone finishes a polecat that was slung one fanned-out
+// parallel step: polecats submit their work with gt done.
+func handleParallelStepDone(cwd, townRoot string, dryRun bool) error {
+	fmt.Printf("\n%s Parallel step complete\n", style.Bold.Render("✓"))
+
+	roleInfo, err := GetRoleWithContext(cwd, townRoot)
+	if err != nil {
+		return fmt.Errorf("detecting role: %w", err)
+	}
+	if roleInfo.Role != RolePolecat {
+		fmt.Printf("The agent that fanned it out continues the molecule.\n")
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("[dry-run] Would run gt done\n")
+		return nil
+	}
+
+	doneCmd := exec.Command("gt", "done")
+	doneCmd.Stdout = os.Stdout
+	doneCmd.Stderr = os.Stderr
+	return doneCmd.Run()
+}
+
+// verifyMoleculeStep runs the verify block of the formula step a step bead
+// was created from, in the worktree containing cwd. The formula is the one
+// recorded on the molecule root when it was slung; molecules without one,
+// and steps without a verify block, pass. A failing check refuses completion,
+// and so does a recorded formula that can't be loaded: exit criteria that
+// can't be checked don't count as met.
+func verifyMoleculeStep(b *beads.Beads, townRoot, rigPath, cwd, moleculeID string, step *beads.Issue, dryRun bool) error {
+	root, err := b.Show(moleculeID)
+	if err != nil {
+		return fmt.Errorf("cannot check step %s: reading molecule %s: %w", step.ID, moleculeID, err)
+	}
+	fields := beads.ParseAttachmentFields(root)
+	if fields == nil || fields.AttachedFormula == "" {
+		return nil
+	}
+
+	f, err := loadStepFormula(townRoot, rigPath, fields.AttachedFormula)
+	if err != nil {
+		return fmt.Errorf("cannot check step %s against formula %s: %w", step.ID, fields.AttachedFormula, err)
+	}
+	var formulaStep *formula.Step
+	if id := beads.ParseFormulaStep(step); id != "" {
+		if formulaStep = f.GetStep(id); formulaStep == nil {
+			return fmt.Errorf("cannot check step %s: formula %s has no step %s", step.ID, fields.AttachedFormula, id)
+		}
+	} else {
+		// Molecules slung before steps were recorded: match by title, and
+		// refuse when steps sharing the title have exit criteria
+		candidates := f.StepsForTitle(step.Title)
+		if len(candidates) == 1 {
+			formulaStep = candidates[0]
+		}
+		for _, c := range candidates {
+			if len(candidates) > 1 && c.Verify != nil {
+				return fmt.Errorf("cannot check step %s: its title matches several steps of formula %s", step.ID, fields.AttachedFormula)
+			}
+		}
+	}
+	if formulaStep == nil || formulaStep.Verify == nil {
+		return nil
+	}
+
+	if dryRun {
+		fmt.Printf("[dry-run] Would verify step %s (%s)\n", step.ID, formulaStep.ID)
+		return nil
+	}
+
+	dir := cwd
+	if top, err := getGitRootForMolStatus(); err == nil && top != "" {
+		dir = top
+	}
+
+	if !moleculeJSON {
+		fmt.Printf("%s Verifying step %s...\n", style.Dim.Render("○"), formulaStep.ID)
+	}
+	if err := formulaStep.Verify.Run(context.Background(), dir); err != nil {
+		var verr *formula.VerifyError
+		if errors.As(err, &verr) && verr.Output != "" {
+			fmt.Fprintf(os.Stderr, "%s\n", verr.Output)
+		}
+		return fmt.Errorf("step %s is not done: verify %s failed: %w", step.ID, formulaStep.ID, err)
+	}
+	if !moleculeJSON {
+		fmt.Printf("%s Verified step %s\n", style.Bold.Render("✓"), formulaStep.ID)
+	}
+	return nil
+}
+
+// formulaStepFor returns the formula step a step bead was made from: the
+// one recorded when the molecule was slung or, for older molecules, the only
+// step with its title. nil if there is none.
+func formulaStepFor(f *formula.Formula, step *beads.Issue) *formula.Step {
+	if id := beads.ParseFormulaStep(step); id != "" {
+		return f.GetStep(id)
+	}
+	return f.StepForTitle(step.Title)
+}
+
+// stepFormulaDirs are where a molecule's formula is read from to check and
+// fan out its steps: the rig's and town's formulas (where bd cooked it from)
+// and the user's. Never the agent's worktree, whose copy the agent being
+// checked could have edited.
+func stepFormulaDirs(townRoot, rigPath string) formula.DirLoader {
+	var dirs formula.DirLoader
+	if rigPath != "" {
+		dirs = append(dirs, filepath.Join(beads.ResolveBeadsDir(rigPath), "formulas"))
+	}
+	dirs = append(dirs, filepath.Join(townRoot, ".beads", "formulas"))
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".beads", "formulas"))
+	}
+	return dirs
+}
+
+// loadStepFormula parses a molecule's formula from stepFormulaDirs, then
+// the town's formula packs.
+func loadStepFormula(townRoot, rigPath, name string) (*formula.Formula, error) {
+	dirs := stepFormulaDirs(townRoot, rigPath)
+	packs := formula.NewPacks(filepath.Join(townRoot, ".beads"))
+	loader := formula.Loaders{dirs, packs}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name+".formula.toml")
+		if _, err := os.Stat(path); err == nil {
+			return formula.ParseFileWith(path, loader)
+		}
+	}
+	path, err := packs.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return formula.ParseFileWith(path, loader)
+}
+
 // extractMoleculeIDFromStep extracts the molecule ID from a step ID.
 // Step IDs have format: mol-id.N where N is the step number.
 // Examples:
@@ -191,6 +416,15 @@ func extractMoleculeIDFromStep(stepID string) string {
 // If all steps are complete, returns (nil, true, nil).
 // If no steps are ready but some are blocked/in_progress, returns (nil, false, nil).
 func findNextReadyStep(b *beads.Beads, moleculeID string) (*beads.Issue, bool, error) {
+	ready, allComplete, err := findReadySteps(b, moleculeID)
+	if err != nil || len(ready) == 0 {
+		return nil, allComplete, err
+	}
+	return ready[0], false, nil
+}
+
+// findReadySteps lists a molecule's steps and returns the ready ones.
+func findReadySteps(b *beads.Beads, moleculeID string) ([]*beads.Issue, bool, error) {
 	// Get all children of the molecule
 	children, err := b.List(beads.ListOptions{
 		Parent:   moleculeID,
@@ -200,11 +434,13 @@ func findNextReadyStep(b *beads.Beads, moleculeID string) (*beads.Issue, bool, e
 	if err != nil {
 		return nil, false, fmt.Errorf("listing molecule steps: %w", err)
 	}
+	ready, allComplete := readySteps(children)
+	return ready, allComplete, nil
+}
 
-	if len(children) == 0 {
-		return nil, true, nil // No steps = complete
-	}
-
+// readySteps returns the open steps whose dependencies are all closed, in
+// order, and whether every step is closed.
+func readySteps(children []*beads.Issue) ([]*beads.Issue, bool) {
 	// Build set of closed step IDs and collect open steps
 	// Note: "open" means not started. "in_progress" means someone's working on it.
 	// We only consider "open" steps as candidates for the next step.
@@ -227,10 +463,11 @@ func findNextReadyStep(b *beads.Beads, moleculeID string) (*beads.Issue, bool, e
 
 	// Check if all complete
 	if !hasNonClosedSteps {
-		return nil, true, nil
+		return nil, true
 	}
 
 	// Find ready steps (open steps with all dependencies closed)
+	var ready []*beads.Issue
 	for _, step := range openSteps {
 		allDepsClosed := true
 		for _, depID := range step.DependsOn {
@@ -239,14 +476,11 @@ func findNextReadyStep(b *beads.Beads, moleculeID string) (*beads.Issue, bool, e
 				break
 			}
 		}
-
-		if len(step.DependsOn) == 0 || allDepsClosed {
-			return step, false, nil
+		if allDepsClosed {
+			ready = append(ready, step)
 		}
 	}
-
-	// No ready steps (all blocked or in_progress)
-	return nil, false, nil
+	return ready, false
 }
 
 // handleStepContinue handles continuing to the next step.
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/steveyegge/gastown/internal/beads"
@@ -433,3 +436,75 @@ func TestStepDoneScenarios(t *testing.T) {
 		})
 	}
 }
+
+func TestReadySteps(t *testing.T) {
+	children := []*beads.Issue{
+		makeStepIssue("gt-mol.1", "Design", "gt-mol", "closed", nil),
+		makeStepIssue("gt-mol.2", "Backend", "gt-mol", "open", []string{"gt-mol.1"}),
+		makeStepIssue("gt-mol.3", "Frontend", "gt-mol", "open", []string{"gt-mol.1"}),
+		makeStepIssue("gt-mol.4", "Docs", "gt-mol", "hooked", []string{"gt-mol.1"}),
+		makeStepIssue("gt-mol.5", "Ship", "gt-mol", "open", []string{"gt-mol.2", "gt-mol.3"}),
+	}
+
+	ready, allComplete := readySteps(children)
+	if allComplete {
+		t.Fatal("allComplete = true, want false")
+	}
+	var ids []string
+	for _, step := range ready {
+		ids = append(ids, step.ID)
+	}
+	if got := strings.Join(ids, ","); got != "gt-mol.2,gt-mol.3" {
+		t.Errorf("ready = %s, want gt-mol.2,gt-mol.3", got)
+	}
+
+	if rest := withoutSteps(ready, ready[:1]); len(rest) != 1 || rest[0].ID != "gt-mol.3" {
+		t.Errorf("withoutSteps = %v, want [gt-mol.3]", rest)
+	}
+
+	for _, c := range children {
+		c.Status = "closed"
+	}
+	if ready, allComplete := readySteps(children); !allComplete || len(ready) != 0 {
+		t.Errorf("readySteps(all closed) = %v, %v; want none, true", ready, allComplete)
+	}
+}
+
+func TestLoadStepFormula(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "gastown")
+	worktree := filepath.Join(rigPath, "polecats", "nux", "gastown")
+	write := func(dir, title string) {
+		t.Helper()
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := "formula = \"work\"\n[[steps]]\nid = \"build\"\ntitle = \"" + title + "\"\n"
+		if err := os.WriteFile(filepath.Join(dir, "work.formula.toml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := loadStepFormula(townRoot, rigPath, "work"); err == nil {
+		t.Fatal("missing formula should be an error")
+	}
+
+	// The agent's worktree copy is never used, even when it's the only one
+	write(filepath.Join(worktree, ".beads", "formulas"), "edited by the agent")
+	t.Chdir(worktree)
+	if _, err := loadStepFormula(townRoot, rigPath, "work"); err == nil {
+		t.Error("worktree formula should not be loaded")
+	}
+
+	write(filepath.Join(townRoot, ".beads", "formulas"), "town")
+	f, err := loadStepFormula(townRoot, rigPath, "work")
+	if err != nil || f.Steps[0].Title != "town" {
+		t.Fatalf("loadStepFormula = %v, %v; want the town formula", f, err)
+	}
+
+	write(filepath.Join(rigPath, ".beads", "formulas"), "rig")
+	if f, _ := loadStepFormula(townRoot, rigPath, "work"); f.Steps[0].Title != "rig" {
+		t.Errorf("title = %q, want the rig formula first", f.Steps[0].Title)
+	}
+}
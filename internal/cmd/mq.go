@@ -37,6 +37,7 @@ var (
 	mqListEpic    string
 	mqListJSON    bool
 	mqListVerify  bool
+	mqListTUI     bool
 
 	// Status command flags
 	mqStatusJSON bool
@@ -135,11 +136,17 @@ Output format:
   gt-mr-003   blocked      P1        polecat/Capable/gt-def    Capable 8m
               (waiting on gt-mr-001)
 
+Use --interactive (-i) for a live view of the queue in scoring order, the
+MR the Engineer is processing (checkout, conflict check, tests, merge,
+push) with its output and test log, and keys to retry, reject or bump
+the priority of the selected MR.
+
 Examples:
   gt mq list greenplace
   gt mq list greenplace --ready
   gt mq list greenplace --status=open
-  gt mq list greenplace --worker=Nux`,
+  gt mq list greenplace --worker=Nux
+  gt mq list greenplace -i`,
 	Args: cobra.ExactArgs(1),
 	RunE: runMQList,
 }
@@ -288,6 +295,7 @@ func init() {
 	mqListCmd.Flags().StringVar(&mqListEpic, "epic", "", "Show MRs targeting integration/<epic>")
 	mqListCmd.Flags().BoolVar(&mqListJSON, "json", false, "Output as JSON")
 	mqListCmd.Flags().BoolVar(&mqListVerify, "verify", false, "Verify branches exist in git (shows MISSING for deleted branches)")
+	mqListCmd.Flags().BoolVarP(&mqListTUI, "interactive", "i", false, "Live merge queue view with Engineer progress")
 
 	// Reject flags
 	mqRejectCmd.Flags().StringVarP(&mqRejectReason, "reason", "r", "", "Reason for rejection (required)")
@@ -8,11 +8,13 @@ import (
 	"strings"
 	"time"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/tui/mq"
 )
 
 func runMQList(cmd *cobra.Command, args []string) error {
@@ -23,6 +25,12 @@ func runMQList(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if mqListTUI {
+		p := tea.NewProgram(mq.New(r), tea.WithAltScreen())
+		_, err := p.Run()
+		return err
+	}
+
 	// Create beads wrapper for the rig - use BeadsPath() to get the git-synced location
 	b := beads.New(r.BeadsPath())
 
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var mqRestackCmd = &cobra.Command{
+	Use:   "restack [rig]",
+	Short: "Move stacked MRs onto their target once their parent merges",
+	Long: `Restack merge requests whose branch was started from another MR's branch.
+
+gt sling branches a polecat from a dependency's unmerged branch when the
+dependency is still in the queue. The resulting MR records the MR it is
+stacked on and is blocked until that MR merges. Restacking then replays
+only the MR's own commits onto its target, so the parent's commits are not
+merged twice even if the parent was rebased or reworked on the way in.
+
+For each stacked MR in the queue:
+  - parent still open: left waiting; if the parent's branch was pushed
+    again (e.g. review rework), own commits are replayed onto its new head
+  - parent merged: own commits rebased onto the target, branch force-pushed,
+    stack cleared (an ordinary MR from then on)
+  - parent closed without merging: follows the parent's issue to its
+    replacement MR, or waits while it is reworked; if the work was abandoned
+    the MR stands alone
+
+The refinery restacks automatically after each merge, and the patrol runs
+this every cycle to follow parents that change; run it after merging by
+hand.
+
+Examples:
+  gt mq restack
+  gt mq restack greenplace`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMQRestack,
+}
+
+func init() {
+	mqCmd.AddCommand(mqRestackCmd)
+}
+
+func runMQRestack(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	stacked, err := eng.StackedMRs("")
+	if err != nil {
+		return err
+	}
+	if len(stacked) == 0 {
+		fmt.Printf("%s No stacked MRs in the queue\n", style.Dim.Render("○"))
+		return nil
+	}
+
+	var failed int
+	for _, mr := range stacked {
+		parent := mr.StackedOn
+		err := eng.Restack(mr)
+		switch {
+		case err == nil:
+			fmt.Printf("%s %s restacked onto %s (was on %s)\n", style.Bold.Render("✓"), mr.ID, mr.Target, parent)
+		case errors.Is(err, refinery.ErrStackPending):
+			fmt.Printf("%s %s %s\n", style.Dim.Render("○"), mr.ID, style.Dim.Render(err.Error()))
+		default:
+			failed++
+			fmt.Printf("%s %s: %v\n", style.Error.Render("✗"), mr.ID, err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d MR(s) could not be restacked", failed)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	mqRevertReason string
+	mqRevertRig    string
+	mqRevertJSON   bool
+)
+
+var mqRevertCmd = &cobra.Command{
+	Use:   "revert <mr-id>",
+	Short: "Revert a merged merge request",
+	Long: `Revert a merge request that has already landed on its target branch.
+
+Creates a revert commit for the MR's recorded merge commit on a
+revert/<mr-id> branch and submits it to the merge queue as a P0 merge
+request, so it goes through the same tests as any other change.
+
+Also:
+  - reopens the MR's source issue with a note pointing at the revert
+  - records reverted_by on the original MR bead and reverts on the new one
+
+The revert is built in a scratch worktree; the refinery's checkout is not
+touched. If later changes conflict with the revert, nothing is submitted
+and the revert must be done by hand.
+
+Examples:
+  gt mq revert gt-mr-abc123 --reason "broke login on staging"
+  gt mq revert gt-mr-abc123 -r "flaky migration" --rig gastown`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQRevert,
+}
+
+func init() {
+	mqRevertCmd.Flags().StringVarP(&mqRevertReason, "reason", "r", "", "Reason for the revert (required)")
+	mqRevertCmd.Flags().StringVar(&mqRevertRig, "rig", "", "Rig of the merge request (default: infer from current directory)")
+	mqRevertCmd.Flags().BoolVar(&mqRevertJSON, "json", false, "Output as JSON")
+	_ = mqRevertCmd.MarkFlagRequired("reason") // cobra flags: error only at runtime if missing
+
+	mqCmd.AddCommand(mqRevertCmd)
+}
+
+func runMQRevert(cmd *cobra.Command, args []string) error {
+	mrID := args[0]
+
+	mgr, _, _, err := getRefineryManager(mqRevertRig)
+	if err != nil {
+		return err
+	}
+	if mqRevertJSON {
+		mgr.SetOutput(os.Stderr)
+	}
+
+	result, err := mgr.RevertMR(mrID, mqRevertReason)
+	if err != nil {
+		return fmt.Errorf("reverting MR: %w", err)
+	}
+
+	if mqRevertJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	commit := result.MergeCommit
+	if len(commit) > 8 {
+		commit = commit[:8]
+	}
+
+	fmt.Printf("%s Reverted: %s\n", style.Bold.Render("✓"), result.MRID)
+	fmt.Printf("  Revert MR: %s %s\n", result.RevertMRID, style.Dim.Render("(P0)"))
+	fmt.Printf("  Branch:    %s → %s\n", result.Branch, result.Target)
+	fmt.Printf("  Reverts:   %s\n", commit)
+	fmt.Printf("  Reason:    %s\n", mqRevertReason)
+	if result.SourceIssue != "" {
+		if result.Reopened {
+			fmt.Printf("  Issue:     %s %s\n", result.SourceIssue, style.Dim.Render("(reopened)"))
+		} else {
+			fmt.Printf("  Issue:     %s %s\n", result.SourceIssue, style.Warning.Render("(could not reopen)"))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	mqReviewApprove        bool
+	mqReviewRequestChanges bool
+	mqReviewMessage        string
+	mqReviewRig            string
+	mqReviewJSON           bool
+)
+
+var mqReviewCmd = &cobra.Command{
+	Use:   "review <review-or-mr-id>",
+	Short: "Approve or request changes on a merge request",
+	Long: `Record a review verdict on a merge request.
+
+Rigs can require approvals before the refinery merges an MR:
+
+  "merge_queue": {
+    "required_reviews": 1,
+    "review_formula": "mol-polecat-review-mr"
+  }
+
+When an MR is submitted (gt done, gt mq submit), one review bead per
+required approval is created, the MR is blocked on them, and the review
+formula is slung on each so a fresh polecat reviews the change. The MR's
+author may not review it.
+
+--approve closes the review. Once every required review is approved the MR
+unblocks and the refinery merges it as usual.
+
+--request-changes sends the work back to its author: the MR is closed (the
+branch is kept), other pending reviews are closed as superseded, the source
+issue is released with your feedback for rework, and the Witness notifies
+the worker. The reworked branch is submitted as a new MR and reviewed again.
+
+The argument is a review bead, or an MR (uses your assigned review, else the
+first pending one). See review state with gt mq status <mr-id>.
+
+Examples:
+  gt mq review gt-rev-abc --approve -m "LGTM"
+  gt mq review gt-mr-xyz --request-changes -m "handle the nil config case"`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMQReview,
+}
+
+func init() {
+	mqReviewCmd.Flags().BoolVar(&mqReviewApprove, "approve", false, "Approve the merge request")
+	mqReviewCmd.Flags().BoolVar(&mqReviewRequestChanges, "request-changes", false, "Send the work back to its author")
+	mqReviewCmd.Flags().StringVarP(&mqReviewMessage, "message", "m", "", "Review comment (required with --request-changes)")
+	mqReviewCmd.Flags().StringVar(&mqReviewRig, "rig", "", "Rig of the merge request (default: infer from current directory)")
+	mqReviewCmd.Flags().BoolVar(&mqReviewJSON, "json", false, "Output as JSON")
+
+	mqCmd.AddCommand(mqReviewCmd)
+}
+
+func runMQReview(cmd *cobra.Command, args []string) error {
+	if mqReviewApprove == mqReviewRequestChanges {
+		return fmt.Errorf("specify exactly one of --approve or --request-changes")
+	}
+	verdict := refinery.ReviewApproved
+	if mqReviewRequestChanges {
+		verdict = refinery.ReviewChangesRequested
+	}
+
+	mgr, _, _, err := getRefineryManager(mqReviewRig)
+	if err != nil {
+		return err
+	}
+	if mqReviewJSON {
+		mgr.SetOutput(os.Stderr)
+	}
+
+	result, err := mgr.SubmitReview(args[0], detectSender(), verdict, mqReviewMessage)
+	if err != nil {
+		return fmt.Errorf("recording review: %w", err)
+	}
+
+	if mqReviewJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	if verdict == refinery.ReviewApproved {
+		fmt.Printf("%s Approved: %s\n", style.Bold.Render("✓"), result.MRID)
+	} else {
+		fmt.Printf("%s Changes requested: %s\n", style.Bold.Render("↩"), result.MRID)
+	}
+	fmt.Printf("  Review:   %s\n", result.ReviewID)
+	fmt.Printf("  Reviewer: %s\n", result.Reviewer)
+	if result.State != nil {
+		fmt.Printf("  Reviews:  %s\n", result.State)
+		if verdict == refinery.ReviewApproved && result.State.Satisfied() {
+			fmt.Printf("  %s\n", style.Dim.Render("Review gate passed; the refinery will merge this MR"))
+		}
+	}
+	if result.Released {
+		fmt.Printf("  Issue:    %s %s\n", result.SourceIssue, style.Dim.Render("(sent back for rework)"))
+	}
+
+	return nil
+}
+
+// requestMRReviews creates the review beads a newly submitted MR needs and
+// slings the rig's review formula on each. Failures are warnings: the MR
+// exists and stays blocked until someone reviews it.
+func requestMRReviews(rigName, mrID string) {
+	mgr, _, _, err := getRefineryManager(rigName)
+	if err != nil {
+		style.PrintWarning("could not request reviews: %v", err)
+		return
+	}
+	pending, err := mgr.RequestReviews(mrID)
+	if err != nil {
+		style.PrintWarning("could not request reviews: %v", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	formula := mgr.ReviewFormula()
+	fmt.Printf("%s Review required: %d reviewer(s) to dispatch\n", style.Bold.Render("→"), len(pending))
+	for _, review := range pending {
+		slingCmd := exec.Command("gt", "sling", formula, "--on", review.ID, rigName)
+		slingCmd.Stdout = os.Stdout
+		slingCmd.Stderr = os.Stderr
+		if err := slingCmd.Run(); err != nil {
+			style.PrintWarning("could not dispatch review %s: %v", review.ID, err)
+			continue
+		}
+		fmt.Printf("  %s Review %s dispatched\n", style.Bold.Render("✓"), review.ID)
+	}
+}
@@ -3,12 +3,14 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -33,6 +35,13 @@ type MRStatusOutput struct {
 	Rig         string `json:"rig,omitempty"`
 	MergeCommit string `json:"merge_commit,omitempty"`
 	CloseReason string `json:"close_reason,omitempty"`
+	Reverts     string `json:"reverts,omitempty"`
+	RevertedBy  string `json:"reverted_by,omitempty"`
+	StackedOn   string `json:"stacked_on,omitempty"`
+	StackBase   string `json:"stack_base,omitempty"`
+
+	// Review gate (merge_queue.required_reviews)
+	Reviews *refinery.ReviewState `json:"reviews,omitempty"`
 
 	// Dependencies
 	DependsOn []DependencyInfo `json:"depends_on,omitempty"`
@@ -95,6 +104,11 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 		output.Rig = mrFields.Rig
 		output.MergeCommit = mrFields.MergeCommit
 		output.CloseReason = mrFields.CloseReason
+		output.Reverts = mrFields.Reverts
+		output.RevertedBy = mrFields.RevertedBy
+		output.StackedOn = mrFields.StackedOn
+		output.StackBase = mrFields.StackBase
+		output.Reviews = mrReviewState(issue.ID, mrFields.Rig)
 	}
 
 	// Add dependency info from the issue's Dependencies field
@@ -127,11 +141,29 @@ func runMqStatus(cmd *cobra.Command, args []string) error {
 	}
 
 	// Human-readable output
-	return printMqStatus(issue, mrFields)
+	return printMqStatus(issue, mrFields, output.Reviews)
+}
+
+// mrReviewState returns an MR's review state, or nil if the rig doesn't
+// require reviews and none exist.
+func mrReviewState(mrID, rigName string) *refinery.ReviewState {
+	if rigName == "" {
+		return nil
+	}
+	mgr, _, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return nil
+	}
+	mgr.SetOutput(io.Discard)
+	state, err := mgr.ReviewState(mrID)
+	if err != nil || (state.Required == 0 && len(state.Reviews) == 0) {
+		return nil
+	}
+	return state
 }
 
 // printMqStatus prints detailed MR status in human-readable format.
-func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
+func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields, reviews *refinery.ReviewState) error {
 	// Header
 	fmt.Printf("%s %s\n", style.Bold.Render("📋 Merge Request:"), issue.ID)
 	fmt.Printf("   %s\n\n", issue.Title)
@@ -184,6 +216,35 @@ func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
 		if mrFields.CloseReason != "" {
 			fmt.Printf("   Close Reason: %s\n", mrFields.CloseReason)
 		}
+		if mrFields.Reverts != "" {
+			fmt.Printf("   Reverts:      %s\n", mrFields.Reverts)
+		}
+		if mrFields.RevertedBy != "" {
+			fmt.Printf("   Reverted By:  %s\n", mrFields.RevertedBy)
+		}
+		if mrFields.StackedOn != "" {
+			fmt.Printf("   Stacked On:   %s %s\n", mrFields.StackedOn, style.Dim.Render("(merges first)"))
+		}
+	}
+
+	// Review gate
+	if reviews != nil {
+		gate := style.Warning.Render(reviews.String())
+		if reviews.Satisfied() {
+			gate = style.Success.Render(reviews.String())
+		}
+		fmt.Printf("\n%s\n", style.Bold.Render("Reviews"))
+		fmt.Printf("   Gate: %s\n", gate)
+		for _, r := range reviews.Reviews {
+			who := r.Reviewer
+			if who == "" {
+				who = r.Assignee
+			}
+			if who == "" {
+				who = "unassigned"
+			}
+			fmt.Printf("   %s %s: %s %s\n", reviewVerdictIcon(r.Verdict), r.ID, r.Verdict, style.Dim.Render("("+who+")"))
+		}
 	}
 
 	// Dependencies (what this MR is waiting on)
@@ -225,6 +286,18 @@ func printMqStatus(issue *beads.Issue, mrFields *beads.MRFields) error {
 	return nil
 }
 
+// reviewVerdictIcon returns an icon for a review verdict.
+func reviewVerdictIcon(verdict string) string {
+	switch verdict {
+	case refinery.ReviewApproved:
+		return "✓"
+	case refinery.ReviewChangesRequested:
+		return "↩"
+	default:
+		return "○"
+	}
+}
+
 // formatStatus formats the status with appropriate styling.
 func formatStatus(status string) string {
 	switch status {
@@ -327,6 +400,8 @@ func getDescriptionWithoutMRFields(description string) string {
 		"close_reason": true,
 		"close-reason": true,
 		"closereason":  true,
+		"stacked_on":   true,
+		"stack_base":   true,
 		"type":         true,
 	}
 
@@ -203,6 +203,9 @@ func runMqSubmit(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Printf("  Priority: P%d\n", priority)
 
+	// Gate the MR on review when the rig requires approvals (idempotent)
+	requestMRReviews(rigName, mrIssue.ID)
+
 	// Auto-cleanup for polecats: if this is a polecat branch and cleanup not disabled,
 	// send lifecycle request and wait for termination
 	if worker != "" && !mqSubmitNoCleanup {
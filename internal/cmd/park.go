@@ -75,6 +75,10 @@ type ParkedWork struct {
 	// Formula is the formula attached to the work (if any)
 	Formula string `json:"formula,omitempty"`
 
+	// Molecule is set when work was parked between molecule steps (waiting
+	// on fanned-out parallel steps); resume pins its next ready step.
+	Molecule string `json:"molecule,omitempty"`
+
 	// Context is additional context notes from the agent
 	Context string `json:"context,omitempty"`
 
@@ -167,21 +171,8 @@ func runPark(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Add agent as waiter on the gate
-	waitCmd := exec.Command("bd", "gate", "wait", gateID, "--notify", agentID)
-	if err := waitCmd.Run(); err != nil {
-		// Not fatal - might already be a waiter
-		fmt.Printf("%s Note: could not add as waiter (may already be registered)\n", style.Dim.Render("⚠"))
-	}
-
-	// Store parked work in a file (alongside hook files)
-	parkedPath := parkedWorkPath(cloneRoot, agentID)
-	parkedJSON, err := json.MarshalIndent(parked, "", "  ")
-	if err != nil {
-		return fmt.Errorf("marshaling parked work: %w", err)
-	}
-	if err := os.WriteFile(parkedPath, parkedJSON, 0644); err != nil {
-		return fmt.Errorf("writing parked state: %w", err)
+	if err := parkWork(cloneRoot, parked); err != nil {
+		return err
 	}
 
 	fmt.Printf("%s Parked work on gate %s\n", style.Bold.Render("🅿️"), gateID)
@@ -202,6 +193,25 @@ func runPark(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// parkWork adds the agent as a waiter on the gate and saves the parked
+// work state alongside the hook files.
+func parkWork(cloneRoot string, parked *ParkedWork) error {
+	waitCmd := exec.Command("bd", "gate", "wait", parked.GateID, "--notify", parked.AgentID)
+	if err := waitCmd.Run(); err != nil {
+		// Not fatal - might already be a waiter
+		fmt.Printf("%s Note: could not add as waiter (may already be registered)\n", style.Dim.Render("⚠"))
+	}
+
+	parkedJSON, err := json.MarshalIndent(parked, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling parked work: %w", err)
+	}
+	if err := os.WriteFile(parkedWorkPath(cloneRoot, parked.AgentID), parkedJSON, 0644); err != nil {
+		return fmt.Errorf("writing parked state: %w", err)
+	}
+	return nil
+}
+
 // parkedWorkPath returns the file path for an agent's parked work state.
 func parkedWorkPath(cloneRoot, agentID string) string {
 	return filepath.Join(cloneRoot, ".beads", fmt.Sprintf("parked-%s.json", strings.ReplaceAll(agentID, "/", "_")))
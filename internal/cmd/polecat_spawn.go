@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
 	"github.com/steveyegge/gastown/internal/events"
@@ -39,6 +40,7 @@ type SlingSpawnOptions struct {
 	Create   bool   // Create polecat if it doesn't exist (currently always true for sling)
 	HookBead string // Bead ID to set as hook_bead at spawn time (atomic assignment)
 	Agent    string // Agent override for this spawn (e.g., "gemini", "codex", "claude-haiku")
+	NoStack  bool   // Start from the default branch even if a dependency's MR is still queued
 }
 
 // SpawnPolecatForSling creates a fresh polecat and optionally starts its session.
@@ -89,6 +91,19 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		HookBead: opts.HookBead,
 	}
 
+	// Stacked work: branch from a dependency's MR that hasn't merged yet
+	var stack *slingStack
+	bd := beads.New(beads.ResolveBeadsDir(r.Path))
+	if opts.HookBead != "" && !opts.NoStack {
+		var stackErr error
+		if stack, stackErr = resolveSlingStack(bd, opts.HookBead); stackErr != nil {
+			style.PrintWarning("not stacking: %v", stackErr)
+		} else if stack != nil {
+			addOpts.StartPoint = stack.StartPoint()
+			fmt.Printf("Stacking on %s (%s, branch %s)\n", stack.MR, stack.Issue, stack.Branch)
+		}
+	}
+
 	if err == nil {
 		// Stale state: polecat exists despite fresh name allocation - repair it
 		// Check for uncommitted work first
@@ -105,10 +120,17 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 			return nil, fmt.Errorf("repairing stale polecat: %w", err)
 		}
 	} else if err == polecat.ErrPolecatNotFound {
-		// Create new polecat
-		fmt.Printf("Creating polecat %s...\n", polecatName)
-		if _, err = polecatMgr.AddWithOptions(polecatName, addOpts); err != nil {
-			return nil, fmt.Errorf("creating polecat: %w", err)
+		// Create new polecat, preferring a pre-provisioned worktree from the warm pool
+		if _, err = polecatMgr.ClaimWarm(polecatName, addOpts); err == nil {
+			fmt.Printf("Claimed warm worktree for polecat %s\n", polecatName)
+		} else {
+			if err != polecat.ErrNoWarmWorktree {
+				fmt.Printf("Warning: could not claim warm worktree: %v\n", err)
+			}
+			fmt.Printf("Creating polecat %s...\n", polecatName)
+			if _, err = polecatMgr.AddWithOptions(polecatName, addOpts); err != nil {
+				return nil, fmt.Errorf("creating polecat: %w", err)
+			}
 		}
 	} else {
 		return nil, fmt.Errorf("getting polecat: %w", err)
@@ -120,6 +142,18 @@ func SpawnPolecatForSling(rigName string, opts SlingSpawnOptions) (*SpawnedPolec
 		return nil, fmt.Errorf("getting polecat after creation: %w", err)
 	}
 
+	// Record where the stacked branch started so gt done can tell the
+	// refinery which commits are this polecat's own
+	if stack != nil {
+		base, err := git.NewGit(polecatObj.ClonePath).Rev("HEAD")
+		if err == nil {
+			err = storeStackInBead(bd, opts.HookBead, stack.MR, base)
+		}
+		if err != nil {
+			style.PrintWarning("could not record stack on %s: %v", opts.HookBead, err)
+		}
+	}
+
 	// Resolve account for runtime config
 	accountsPath := constants.MayorAccountsPath(townRoot)
 	claudeConfigDir, accountHandle, err := config.ResolveAccountConfigDir(accountsPath, opts.Account)
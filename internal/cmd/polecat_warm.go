@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/polecat"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+// Polecat warm pool command flags
+var (
+	polecatWarmFill  bool
+	polecatWarmDrain bool
+	polecatWarmJSON  bool
+)
+
+var polecatWarmCmd = &cobra.Command{
+	Use:   "warm <rig>",
+	Short: "Show or manage the rig's warm pool of pre-provisioned worktrees",
+	Long: `Show or manage the warm pool of idle, fully set-up polecat worktrees.
+
+Warm worktrees have already run the overlay copy, setup hooks and shared
+beads setup. When sling spawns a new polecat it claims a warm worktree,
+resets it onto a fresh branch at the latest default branch, and skips the
+slow setup. Without a warm worktree, sling falls back to a normal add.
+
+The pool size is configured in <rig>/settings/config.json:
+  "warm_pool": {"size": 2, "max_age": "12h"}
+
+The daemon replenishes the pool on each heartbeat. Ready worktrees older
+than max_age are discarded and rebuilt so dependency installs stay fresh.
+
+Examples:
+  gt polecat warm gastown           # Show pool status
+  gt polecat warm gastown --fill    # Provision up to the configured size now
+  gt polecat warm gastown --drain   # Remove all warm worktrees`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPolecatWarm,
+}
+
+func init() {
+	polecatWarmCmd.Flags().BoolVar(&polecatWarmFill, "fill", false, "Provision warm worktrees up to the configured size")
+	polecatWarmCmd.Flags().BoolVar(&polecatWarmDrain, "drain", false, "Remove all warm worktrees")
+	polecatWarmCmd.Flags().BoolVar(&polecatWarmJSON, "json", false, "Output as JSON")
+
+	polecatCmd.AddCommand(polecatWarmCmd)
+}
+
+func runPolecatWarm(cmd *cobra.Command, args []string) error {
+	if polecatWarmFill && polecatWarmDrain {
+		return fmt.Errorf("--fill and --drain are mutually exclusive")
+	}
+
+	rigName := args[0]
+	mgr, _, err := getPolecatManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	if polecatWarmDrain {
+		removed, err := mgr.DrainWarmPool()
+		if err != nil {
+			return fmt.Errorf("draining warm pool: %w", err)
+		}
+		fmt.Printf("%s Removed %d warm worktree(s) from %s\n", style.Bold.Render("✓"), removed, rigName)
+		return nil
+	}
+
+	cfg := mgr.WarmPoolConfig()
+	if polecatWarmFill {
+		if cfg == nil {
+			return fmt.Errorf("warm pool not configured for %s (set warm_pool.size in settings/config.json)", rigName)
+		}
+		fmt.Printf("Filling warm pool for %s (size %d)...\n", rigName, cfg.Size)
+		created, err := mgr.ReplenishWarmPool()
+		if err != nil {
+			return fmt.Errorf("filling warm pool: %w", err)
+		}
+		fmt.Printf("%s Provisioned %d warm worktree(s)\n", style.Bold.Render("✓"), created)
+	}
+
+	slots, err := mgr.WarmSlots()
+	if err != nil {
+		return err
+	}
+
+	if polecatWarmJSON {
+		if slots == nil {
+			slots = []polecat.WarmSlot{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(slots)
+	}
+
+	size := 0
+	if cfg != nil {
+		size = cfg.Size
+	}
+	ready := 0
+	for _, s := range slots {
+		if s.Ready {
+			ready++
+		}
+	}
+
+	fmt.Printf("%s %s: %d ready / %d configured\n", style.Bold.Render("Warm pool"), rigName, ready, size)
+	if cfg == nil {
+		fmt.Printf("  %s\n", style.Dim.Render("(disabled - set warm_pool.size in settings/config.json)"))
+	}
+	for _, s := range slots {
+		state := style.Success.Render("ready")
+		if !s.Ready {
+			state = style.Warning.Render("provisioning")
+		}
+		commit := s.Commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		fmt.Printf("  %-14s %-12s %s %s\n", s.ID, state,
+			style.Dim.Render(time.Since(s.CreatedAt).Round(time.Minute).String()+" old"),
+			style.Dim.Render(commit))
+	}
+	return nil
+}
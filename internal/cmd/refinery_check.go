@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	refineryCheckBranch string
+	refineryCheckJSON   bool
+)
+
+var refineryCheckCmd = &cobra.Command{
+	Use:   "check <mr-id> [rig]",
+	Short: "Run the pre-merge checks on a merge request",
+	Long: `Run the rig's pre-merge checks on a merge request's branch.
+
+The checks inspect the files the branch adds or changes since it left the
+target branch:
+
+  - secrets: .env files, private keys and token-shaped strings
+  - file-size: files larger than max_file_size
+  - each command in check_commands (changed files on stdin)
+
+Enable them in the rig's config.json:
+  "merge_queue": {
+    "scan_secrets": true,
+    "max_file_size": "5MB",
+    "check_commands": ["./scripts/lint-changed.sh"],
+    "check_allowlist": ["testdata/"]
+  }
+
+Lines containing "gt:allow-secret" are not scanned for secrets.
+
+The command exits non-zero when any check finds something, so the refinery
+patrol can block the merge on it. Use --branch to check the rebased branch
+that will actually be merged rather than the MR's original branch.
+
+Examples:
+  gt refinery check gt-mr-abc
+  gt refinery check gt-mr-abc gastown --branch temp
+  gt refinery check gt-mr-abc --json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runRefineryCheck,
+}
+
+func init() {
+	refineryCheckCmd.Flags().StringVar(&refineryCheckBranch, "branch", "", "Branch to check instead of the MR's branch")
+	refineryCheckCmd.Flags().BoolVar(&refineryCheckJSON, "json", false, "Output as JSON")
+
+	refineryCmd.AddCommand(refineryCheckCmd)
+}
+
+func runRefineryCheck(cmd *cobra.Command, args []string) error {
+	mrID := args[0]
+	rigName := ""
+	if len(args) > 1 {
+		rigName = args[1]
+	}
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+
+	result, err := eng.CheckMR(context.Background(), mrID, refineryCheckBranch)
+	if err != nil {
+		return fmt.Errorf("checking %s: %w", mrID, err)
+	}
+
+	if refineryCheckJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+		if !result.Passed() {
+			return NewSilentExit(1)
+		}
+		return nil
+	}
+
+	if len(result.Checks) == 0 {
+		fmt.Printf("%s No pre-merge checks configured\n", style.Dim.Render("○"))
+		return nil
+	}
+	if result.Passed() {
+		fmt.Printf("%s %s passed %s (%d file(s))\n", style.Bold.Render("✓"), result.Branch,
+			strings.Join(result.Checks, ", "), result.Files)
+		return nil
+	}
+
+	fmt.Printf("%s %s failed pre-merge checks:\n", style.Error.Render("✗"), result.Branch)
+	for _, f := range result.Findings {
+		fmt.Printf("  %s\n", f)
+	}
+	return fmt.Errorf("%s: %d pre-merge check finding(s); do not merge", mrID, len(result.Findings))
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// setupRefineryCheckRig creates a town with a gastown rig whose refinery
+// clone has a main branch and a polecat branch adding files, plus a bd stub
+// that shows gt-mr1 as an MR for that branch.
+func setupRefineryCheckRig(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("bd stub is a shell script")
+	}
+
+	townRoot := setupTestTownForDotDir(t)
+	addRigEntry(t, townRoot, "gastown")
+	rigPath := filepath.Join(townRoot, "gastown")
+	config := `{"merge_queue":{"scan_secrets":true,"max_file_size":"1KB"}}`
+	if err := os.MkdirAll(rigPath, 0755); err != nil {
+		t.Fatalf("mkdir rig: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(rigPath, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	clone := filepath.Join(rigPath, "refinery", "rig")
+	if err := os.MkdirAll(clone, 0755); err != nil {
+		t.Fatalf("mkdir clone: %v", err)
+	}
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = clone
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "Test")
+	if err := os.WriteFile(filepath.Join(clone, "README.md"), []byte("readme\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", ".")
+	run("commit", "-qm", "initial")
+	run("checkout", "-qb", "polecat/toast")
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(clone, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("add", ".")
+	run("commit", "-qm", "work")
+	run("checkout", "-q", "main")
+
+	binDir := t.TempDir()
+	writeScript(t, binDir, "bd", `#!/bin/sh
+for arg in "$@"; do
+  if [ "$arg" = "show" ]; then
+    printf '%s\n' '[{"id":"gt-mr1","title":"Merge: toast","status":"open","issue_type":"merge-request","description":"branch: polecat/toast\ntarget: main\nworker: toast"}]'
+    exit 0
+  fi
+done
+exit 0
+`)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	if err := os.Chdir(townRoot); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	prevBranch, prevJSON := refineryCheckBranch, refineryCheckJSON
+	t.Cleanup(func() { refineryCheckBranch, refineryCheckJSON = prevBranch, prevJSON })
+	refineryCheckBranch, refineryCheckJSON = "", false
+	return clone
+}
+
+func TestRefineryCheckBlocksFindings(t *testing.T) {
+	setupRefineryCheckRig(t, map[string]string{
+		".env":     "TOKEN=x\n",
+		"big.bin":  strings.Repeat("x", 2048),
+		"main.go":  "package main\n",
+		"notes.md": "nothing to see\n",
+	})
+
+	err := runRefineryCheck(nil, []string{"gt-mr1", "gastown"})
+	if err == nil {
+		t.Fatal("runRefineryCheck should fail on a branch with a .env and an oversized file")
+	}
+	if !strings.Contains(err.Error(), "2 pre-merge check finding(s)") {
+		t.Errorf("error = %v, want 2 findings", err)
+	}
+}
+
+func TestRefineryCheckPasses(t *testing.T) {
+	setupRefineryCheckRig(t, map[string]string{"main.go": "package main\n"})
+
+	if err := runRefineryCheck(nil, []string{"gt-mr1", "gastown"}); err != nil {
+		t.Fatalf("runRefineryCheck on a clean branch: %v", err)
+	}
+}
+
+func TestRefineryCheckBranchOverride(t *testing.T) {
+	clone := setupRefineryCheckRig(t, map[string]string{"main.go": "package main\n"})
+
+	// The rebased copy the patrol merges can differ from the MR's branch
+	cmd := exec.Command("git", "checkout", "-qb", "temp", "polecat/toast")
+	cmd.Dir = clone
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git checkout: %v\n%s", err, out)
+	}
+	if err := os.WriteFile(filepath.Join(clone, "id_rsa"), []byte("key\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"add", "."}, {"commit", "-qm", "key"}} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = clone
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	refineryCheckBranch = "temp"
+	err := runRefineryCheck(nil, []string{"gt-mr1", "gastown"})
+	if err == nil || !strings.Contains(err.Error(), "1 pre-merge check finding(s)") {
+		t.Errorf("runRefineryCheck --branch temp error = %v, want the id_rsa finding", err)
+	}
+}
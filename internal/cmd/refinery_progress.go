@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+var (
+	refineryProgressBranch string
+	refineryProgressTarget string
+	refineryProgressError  string
+	refineryProgressCommit string
+)
+
+var refineryProgressCmd = &cobra.Command{
+	Use:   "progress <mr-id> <step> [rig]",
+	Short: "Report which merge step the refinery is on",
+	Long: `Publish the refinery's progress on a merge request.
+
+The live merge queue view (gt mq list <rig> -i) follows the refinery
+through each MR. The patrol calls this as it moves through the merge, so
+the view shows the current step and how long it has taken.
+
+Steps, in order:
+  checkout        start of a new MR (use --branch and --target)
+  conflict_check  rebasing onto the target
+  checks          pre-merge checks (gt refinery check)
+  tests           running the test suite
+  merge           merging into the target
+  push            pushing the target
+  done            merged (use --commit)
+  failed          not merged (use --error)
+
+Examples:
+  gt refinery progress gt-mr-abc checkout --branch polecat/Nux/gt-xyz
+  gt refinery progress gt-mr-abc tests
+  gt refinery progress gt-mr-abc done --commit $(git rev-parse HEAD)
+  gt refinery progress gt-mr-abc failed --error "tests failed"`,
+	Args: cobra.RangeArgs(2, 3),
+	RunE: runRefineryProgress,
+}
+
+func init() {
+	refineryProgressCmd.Flags().StringVar(&refineryProgressBranch, "branch", "", "Branch being merged")
+	refineryProgressCmd.Flags().StringVar(&refineryProgressTarget, "target", "", "Target branch (default: rig's default branch)")
+	refineryProgressCmd.Flags().StringVar(&refineryProgressError, "error", "", "Why the MR failed")
+	refineryProgressCmd.Flags().StringVar(&refineryProgressCommit, "commit", "", "Merged commit")
+
+	refineryCmd.AddCommand(refineryProgressCmd)
+}
+
+func runRefineryProgress(cmd *cobra.Command, args []string) error {
+	mrID := args[0]
+	step, err := refinery.ParseMergeStep(args[1])
+	if err != nil {
+		return err
+	}
+	rigName := ""
+	if len(args) > 2 {
+		rigName = args[2]
+	}
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	target := refineryProgressTarget
+	if target == "" && step == refinery.StepCheckout {
+		target = r.DefaultBranch()
+	}
+	update := refinery.ProgressUpdate{
+		MRID:        mrID,
+		Branch:      refineryProgressBranch,
+		Target:      target,
+		Step:        step,
+		Error:       refineryProgressError,
+		MergeCommit: refineryProgressCommit,
+	}
+	if err := refinery.RecordProgress(r.Path, update); err != nil {
+		return fmt.Errorf("recording progress: %w", err)
+	}
+	return nil
+}
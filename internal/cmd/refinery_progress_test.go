@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+func TestRefineryProgress(t *testing.T) {
+	townRoot := setupTestTownForDotDir(t)
+	addRigEntry(t, townRoot, "gastown")
+	rigPath := filepath.Join(townRoot, "gastown")
+	if err := os.MkdirAll(rigPath, 0755); err != nil {
+		t.Fatalf("mkdir rig: %v", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	if err := os.Chdir(townRoot); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	prevBranch, prevTarget, prevError, prevCommit := refineryProgressBranch, refineryProgressTarget, refineryProgressError, refineryProgressCommit
+	t.Cleanup(func() {
+		refineryProgressBranch, refineryProgressTarget, refineryProgressError, refineryProgressCommit = prevBranch, prevTarget, prevError, prevCommit
+	})
+	report := func(step, branch, commit string) {
+		t.Helper()
+		refineryProgressBranch, refineryProgressTarget, refineryProgressError, refineryProgressCommit = branch, "", "", commit
+		if err := runRefineryProgress(nil, []string{"gt-mr1", step, "gastown"}); err != nil {
+			t.Fatalf("runRefineryProgress(%s): %v", step, err)
+		}
+	}
+
+	report("checkout", "polecat/toast", "")
+	report("tests", "", "")
+	p, err := refinery.LoadProgress(rigPath)
+	if err != nil || p == nil {
+		t.Fatalf("LoadProgress = %v, %v", p, err)
+	}
+	if p.MRID != "gt-mr1" || p.Branch != "polecat/toast" || p.Target != "main" || p.Step != refinery.StepTests {
+		t.Errorf("progress = %+v, want gt-mr1 on polecat/toast into main running tests", p)
+	}
+
+	report("done", "", "abc123")
+	p, _ = refinery.LoadProgress(rigPath)
+	if p.Step != refinery.StepDone || p.MergeCommit != "abc123" || p.Active() {
+		t.Errorf("progress = %+v, want done at abc123", p)
+	}
+
+	if err := runRefineryProgress(nil, []string{"gt-mr1", "bogus", "gastown"}); err == nil {
+		t.Error("runRefineryProgress should reject an unknown step")
+	}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/style"
+)
+
+var (
+	refineryVerifyForce bool
+	refineryVerifyJSON  bool
+)
+
+var refineryVerifyCmd = &cobra.Command{
+	Use:   "verify [rig]",
+	Short: "Verify the target branch after merges and bisect breakages",
+	Long: `Run post-merge verification on the rig's target branch.
+
+Pre-merge tests catch most breakage, but flaky tests and interactions
+between MRs can still leave the target branch red. Verification runs the
+merge queue's test_command on the target head. If it fails, the Engineer
+bisects across the MR merge commits since the last green head (recorded
+as merge_commit on closed MR beads) to find the culprit MR, then:
+
+  - opens a P1 bug bead linked to the culprit MR
+  - sends MERGE_FAILED (failure type post-merge) to the Witness, which
+    notifies the responsible worker
+
+A breakage is only reported once: while its bug bead is open, later runs
+don't bisect again.
+
+Enable periodic verification in the rig's config.json:
+  "merge_queue": {
+    "verify_after_merge": true,
+    "verify_interval": "30m",
+    "bisect_lookback": 20
+  }
+
+Without --force, verification is skipped when disabled, when it ran less
+than verify_interval ago, or when the target head has not moved. This makes
+the command cheap to call from every refinery patrol cycle.
+
+Examples:
+  gt refinery verify
+  gt refinery verify gastown --force
+  gt refinery verify --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRefineryVerify,
+}
+
+func init() {
+	refineryVerifyCmd.Flags().BoolVar(&refineryVerifyForce, "force", false, "Verify now, ignoring verify_after_merge and verify_interval")
+	refineryVerifyCmd.Flags().BoolVar(&refineryVerifyJSON, "json", false, "Output as JSON")
+
+	refineryCmd.AddCommand(refineryVerifyCmd)
+}
+
+func runRefineryVerify(cmd *cobra.Command, args []string) error {
+	rigName := ""
+	if len(args) > 0 {
+		rigName = args[0]
+	}
+
+	_, r, _, err := getRefineryManager(rigName)
+	if err != nil {
+		return err
+	}
+
+	eng := refinery.NewEngineer(r)
+	if err := eng.LoadConfig(); err != nil {
+		return fmt.Errorf("loading merge queue config: %w", err)
+	}
+	if refineryVerifyJSON {
+		eng.SetOutput(os.Stderr)
+	}
+
+	result, err := eng.VerifyTarget(context.Background(), refineryVerifyForce)
+	if err != nil {
+		return fmt.Errorf("verifying target: %w", err)
+	}
+
+	if refineryVerifyJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+
+	fmt.Println()
+	switch {
+	case result.Skipped != "":
+		fmt.Printf("%s Verification skipped: %s\n", style.Dim.Render("○"), result.Skipped)
+	case result.Green:
+		fmt.Printf("%s %s is green\n", style.Bold.Render("✓"), result.Target)
+	default:
+		fmt.Printf("%s %s is red (%d test run(s))\n", style.Error.Render("✗"), result.Target, result.TestRuns)
+		if result.Culprit != nil {
+			fmt.Printf("  Culprit: %s (%s, worker %s)\n", result.Culprit.ID, result.Culprit.Branch, result.Culprit.Worker)
+		} else if result.Unmatched != "" {
+			fmt.Printf("  Culprit: unknown - %s\n", result.Unmatched)
+		}
+		if result.BugID != "" {
+			fmt.Printf("  Bug: %s\n", result.BugID)
+		}
+	}
+	return nil
+}
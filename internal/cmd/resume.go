@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/style"
 )
 
@@ -168,6 +169,13 @@ func runResume(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Parked between molecule steps: pick up the next ready one
+	if parked.BeadID == "" && parked.Molecule != "" {
+		if err := resumeMolecule(cloneRoot, agentID, parked.Molecule); err != nil {
+			return err
+		}
+	}
+
 	// Show context
 	if parked.Context != "" {
 		fmt.Printf("\n%s Context:\n", style.Bold.Render("📝"))
@@ -184,6 +192,37 @@ func runResume(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// resumeMolecule pins the next ready step of a molecule to the agent.
+func resumeMolecule(cloneRoot, agentID, moleculeID string) error {
+	workDir, err := findLocalBeadsDir()
+	if err != nil {
+		return fmt.Errorf("not in a beads workspace: %w", err)
+	}
+	nextStep, allComplete, err := findNextReadyStep(beads.New(workDir), moleculeID)
+	if err != nil {
+		return fmt.Errorf("finding next step: %w", err)
+	}
+	if allComplete {
+		fmt.Printf("\n%s Molecule %s is complete; run 'gt done' to finish\n", style.Bold.Render("🎉"), moleculeID)
+		return nil
+	}
+	if nextStep == nil {
+		fmt.Printf("\n%s No step of %s is ready yet; run 'gt mol progress %s'\n",
+			style.Dim.Render("ℹ"), moleculeID, moleculeID)
+		return nil
+	}
+
+	pinCmd := exec.Command("bd", "update", nextStep.ID, "--status=pinned", "--assignee="+agentID)
+	pinCmd.Dir = cloneRoot
+	pinCmd.Stderr = os.Stderr
+	if err := pinCmd.Run(); err != nil {
+		return fmt.Errorf("pinning next step: %w", err)
+	}
+	fmt.Printf("\n%s Next step pinned: %s\n", style.Bold.Render("📌"), nextStep.ID)
+	fmt.Printf("  %s\n", nextStep.Title)
+	return nil
+}
+
 func outputResumeStatus(status ResumeStatus) error {
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/util"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	rigCachePruneAll     bool
+	rigCachePruneDryRun  bool
+	rigCachePruneMaxSize string
+)
+
+var rigCacheCmd = &cobra.Command{
+	Use:   "cache [rig]",
+	Short: "Show the rig's shared build and dependency caches",
+	Long: `Show the shared caches polecat and refinery sessions use.
+
+Every worktree in a rig normally downloads modules and rebuilds from cold.
+With a cache configured, sessions get env vars pointing build tools at
+shared directories under the rig, so work done once is reused:
+
+  "cache": {
+    "enabled": true,
+    "kinds": ["go", "npm"],
+    "env": {"GRADLE_USER_HOME": "gradle"},
+    "max_size": "20GB"
+  }
+
+in <rig>/settings/config.json. Kinds: go (GOMODCACHE, GOCACHE), npm, yarn,
+pnpm, cargo (CARGO_HOME, which also holds cargo's config and installed
+binaries) and pip; only the listed kinds are enabled. env adds extra
+variables, each pointing at a subdirectory of the cache root (dir, default
+<rig>/.cache). Sandboxed polecats get the root writable.
+
+Changes apply to sessions started afterwards.
+
+Examples:
+  gt rig cache gastown
+  gt rig cache prune gastown`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRigCache,
+}
+
+var rigCachePruneCmd = &cobra.Command{
+	Use:   "prune [rig]",
+	Short: "Clear caches until the rig is under its size limit",
+	Long: `Clear shared caches, least recently used first, until the total
+fits in the rig's cache.max_size (or --max-size).
+
+Each cache directory (e.g. go/mod, npm) is cleared as a whole; tools
+refill it on the next build. Use --all to clear everything.
+
+Examples:
+  gt rig cache prune gastown
+  gt rig cache prune gastown --max-size 5GB --dry-run
+  gt rig cache prune gastown --all`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRigCachePrune,
+}
+
+func init() {
+	rigCachePruneCmd.Flags().BoolVar(&rigCachePruneAll, "all", false, "Clear every cache regardless of size")
+	rigCachePruneCmd.Flags().BoolVarP(&rigCachePruneDryRun, "dry-run", "n", false, "Show what would be cleared")
+	rigCachePruneCmd.Flags().StringVar(&rigCachePruneMaxSize, "max-size", "", "Size limit to prune to (overrides cache.max_size)")
+
+	rigCacheCmd.AddCommand(rigCachePruneCmd)
+	rigCmd.AddCommand(rigCacheCmd)
+}
+
+// loadRigCaches resolves the rig (from args or cwd) and returns its measured caches.
+func loadRigCaches(args []string) (*rig.Rig, []*rig.Cache, int64, error) {
+	var rigName string
+	if len(args) > 0 {
+		rigName = args[0]
+	} else {
+		townRoot, err := workspace.FindFromCwdOrError()
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("not in a Gas Town workspace: %w", err)
+		}
+		rigName, err = inferRigFromCwd(townRoot)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("could not determine rig (specify one): %w", err)
+		}
+	}
+
+	_, r, err := getRig(rigName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	cfg := rig.LoadCacheConfig(r.Path)
+	if cfg == nil {
+		return nil, nil, 0, fmt.Errorf("rig %s has no shared cache (set cache.enabled in settings/config.json)", r.Name)
+	}
+
+	var maxSize int64
+	if cfg.MaxSize != "" {
+		if maxSize, err = util.ParseSize(cfg.MaxSize); err != nil {
+			return nil, nil, 0, fmt.Errorf("cache.max_size: %w", err)
+		}
+	}
+
+	caches, err := rig.Caches(r.Path, cfg)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	if err := rig.MeasureCaches(caches); err != nil {
+		return nil, nil, 0, err
+	}
+	return r, caches, maxSize, nil
+}
+
+func runRigCache(cmd *cobra.Command, args []string) error {
+	r, caches, maxSize, err := loadRigCaches(args)
+	if err != nil {
+		return err
+	}
+
+	total := rig.TotalCacheSize(caches)
+	limit := "no limit"
+	if maxSize > 0 {
+		limit = util.FormatSize(maxSize)
+	}
+	fmt.Printf("%s %s: %s of %s\n", style.Bold.Render("Cache"), r.Name, util.FormatSize(total), limit)
+	for _, c := range caches {
+		used := style.Dim.Render("empty")
+		if c.Size > 0 {
+			used = fmt.Sprintf("%s, used %s", util.FormatSize(c.Size), relativeTime(c.LastUsed))
+		}
+		fmt.Printf("  %-10s %s %s\n", c.Name, used, style.Dim.Render(fmt.Sprintf("%v", c.Env)))
+	}
+	if maxSize > 0 && total > maxSize {
+		fmt.Printf("\n%s Over limit; run %s\n", style.Warning.Render("⚠"), style.Bold.Render("gt rig cache prune "+r.Name))
+	}
+	return nil
+}
+
+func runRigCachePrune(cmd *cobra.Command, args []string) error {
+	r, caches, maxSize, err := loadRigCaches(args)
+	if err != nil {
+		return err
+	}
+
+	if rigCachePruneMaxSize != "" {
+		if maxSize, err = util.ParseSize(rigCachePruneMaxSize); err != nil {
+			return fmt.Errorf("--max-size: %w", err)
+		}
+	}
+	if rigCachePruneAll {
+		maxSize = 0
+	} else if maxSize <= 0 {
+		return fmt.Errorf("rig %s has no cache.max_size; use --max-size or --all", r.Name)
+	}
+
+	prune := rig.CachesToPrune(caches, maxSize)
+	if len(prune) == 0 {
+		fmt.Printf("%s Cache is within limit (%s)\n", style.Success.Render("✓"), util.FormatSize(rig.TotalCacheSize(caches)))
+		return nil
+	}
+
+	var freed int64
+	for _, c := range prune {
+		if rigCachePruneDryRun {
+			fmt.Printf("  Would clear %s (%s)\n", c.Name, util.FormatSize(c.Size))
+			freed += c.Size
+			continue
+		}
+		size := c.Size
+		if err := rig.ClearCache(c); err != nil {
+			style.PrintWarning("%v", err)
+			continue
+		}
+		fmt.Printf("  %s Cleared %s (%s)\n", style.Success.Render("✓"), c.Name, util.FormatSize(size))
+		freed += size
+	}
+
+	if rigCachePruneDryRun {
+		fmt.Printf("Would free %s\n", util.FormatSize(freed))
+	} else {
+		fmt.Printf("Freed %s\n", util.FormatSize(freed))
+	}
+	return nil
+}
@@ -50,6 +50,14 @@ Spawning Options (when target is a rig):
   gt sling gp-abc greenplace --force                # Ignore unread mail
   gt sling gp-abc greenplace --account work         # Use specific Claude account
 
+Stacked Work:
+  If the bead depends on work whose MR is still in the merge queue, the new
+  polecat branches from that MR's branch instead of the default branch, so
+  it can build on the unmerged changes. The refinery merges the dependency
+  first, then replays only this work's commits onto the target.
+
+  gt sling gp-def greenplace --no-stack             # Start from the default branch
+
 Natural Language Args:
   gt sling gt-abc --args "patch release"
   gt sling code-review --args "focus on security"
@@ -95,6 +103,7 @@ var (
 	slingAccount  string // --account: Claude Code account handle to use
 	slingAgent    string // --agent: override runtime agent for this sling/spawn
 	slingNoConvoy bool   // --no-convoy: skip auto-convoy creation
+	slingNoStack  bool   // --no-stack: don't branch from a queued dependency's MR
 )
 
 func init() {
@@ -111,6 +120,7 @@ func init() {
 	slingCmd.Flags().StringVar(&slingAccount, "account", "", "Claude Code account handle to use")
 	slingCmd.Flags().StringVar(&slingAgent, "agent", "", "Override agent/runtime for this sling (e.g., claude, gemini, codex, or custom alias)")
 	slingCmd.Flags().BoolVar(&slingNoConvoy, "no-convoy", false, "Skip auto-convoy creation for single-issue sling")
+	slingCmd.Flags().BoolVar(&slingNoStack, "no-stack", false, "Start from the default branch even if a dependency's MR is still queued")
 
 	rootCmd.AddCommand(slingCmd)
 }
@@ -244,6 +254,7 @@ func runSling(cmd *cobra.Command, args []string) error {
 					Create:   slingCreate,
 					HookBead: beadID, // Set atomically at spawn time
 					Agent:    slingAgent,
+					NoStack:  slingNoStack,
 				}
 				spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 				if spawnErr != nil {
@@ -275,6 +286,7 @@ func runSling(cmd *cobra.Command, args []string) error {
 							Create:   slingCreate,
 							HookBead: beadID,
 							Agent:    slingAgent,
+							NoStack:  slingNoStack,
 						}
 						spawnInfo, spawnErr := SpawnPolecatForSling(rigName, spawnOpts)
 						if spawnErr != nil {
@@ -333,6 +345,7 @@ func runSling(cmd *cobra.Command, args []string) error {
 
 	// Auto-convoy: check if issue is already tracked by a convoy
 	// If not, create one for dashboard visibility (unless --no-convoy is set)
+	var convoyID string // Stamped on the agent's commits as Gt-Convoy
 	if !slingNoConvoy && formulaName == "" {
 		existingConvoy := isTrackedByConvoy(beadID)
 		if existingConvoy == "" {
@@ -340,16 +353,18 @@ func runSling(cmd *cobra.Command, args []string) error {
 				fmt.Printf("Would create convoy 'Work: %s'\n", info.Title)
 				fmt.Printf("Would add tracking relation to %s\n", beadID)
 			} else {
-				convoyID, err := createAutoConvoy(beadID, info.Title)
+				newConvoy, err := createAutoConvoy(beadID, info.Title)
 				if err != nil {
 					// Log warning but don't fail - convoy is optional
 					fmt.Printf("%s Could not create auto-convoy: %v\n", style.Dim.Render("Warning:"), err)
 				} else {
+					convoyID = newConvoy
 					fmt.Printf("%s Created convoy 🚚 %s\n", style.Bold.Render("→"), convoyID)
 					fmt.Printf("  Tracking: %s\n", beadID)
 				}
 			}
 		} else {
+			convoyID = existingConvoy
 			fmt.Printf("%s Already tracked by convoy %s\n", style.Dim.Render("○"), existingConvoy)
 		}
 	}
@@ -377,6 +392,10 @@ func runSling(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// The original bead is the work item commits are stamped with, even when
+	// a formula wisp ends up on the hook
+	workBeadID := beadID
+
 	// Formula-on-bead mode: instantiate formula and bond to original bead
 	if formulaName != "" {
 		fmt.Printf("  Instantiating formula %s...\n", formulaName)
@@ -388,10 +407,16 @@ func runSling(cmd *cobra.Command, args []string) error {
 
 		// Step 1: Cook the formula (ensures proto exists)
 		// Cook runs from rig directory to access the correct formula database
-		cookCmd := exec.Command("bd", "--no-daemon", "cook", formulaName)
+		cookRef, cleanupCook, err := cookableFormula(formulaName)
+		if err != nil {
+			return err
+		}
+		cookCmd := exec.Command("bd", "--no-daemon", "cook", cookRef)
 		cookCmd.Dir = formulaWorkDir
 		cookCmd.Stderr = os.Stderr
-		if err := cookCmd.Run(); err != nil {
+		err = cookCmd.Run()
+		cleanupCook()
+		if err != nil {
 			return fmt.Errorf("cooking formula %s: %w", formulaName, err)
 		}
 
@@ -466,6 +491,9 @@ func runSling(cmd *cobra.Command, args []string) error {
 	// Update agent bead's hook_bead field (ZFC: agents track their current work)
 	updateAgentHookBead(targetAgent, beadID, hookWorkDir, townBeadsDir)
 
+	// Record the work for the agent's commit provenance trailers
+	recordCommitProvenance(hookWorkDir, workBeadID, convoyID)
+
 	// Auto-attach mol-polecat-work to polecat agent beads
 	// This ensures polecats have the standard work molecule attached for guidance
 	if strings.Contains(targetAgent, "/polecats/") {
@@ -494,10 +522,14 @@ func runSling(cmd *cobra.Command, args []string) error {
 	// Record the attached molecule in the wisp's description.
 	// This is required for gt hook to recognize the molecule attachment.
 	if attachedMoleculeID != "" {
-		if err := storeAttachedMoleculeInBead(beadID, attachedMoleculeID); err != nil {
+		if err := storeAttachedMoleculeInBead(beadID, attachedMoleculeID, formulaName); err != nil {
 			// Warn but don't fail - polecat can still work through steps
 			fmt.Printf("%s Could not store attached_molecule: %v\n", style.Dim.Render("Warning:"), err)
 		}
+		vars := map[string]string{"feature": info.Title, "issue": workBeadID}
+		if err := recordFormulaSteps(beads.ResolveHookDir(townRoot, attachedMoleculeID, hookWorkDir), attachedMoleculeID, formulaName, vars); err != nil {
+			fmt.Printf("%s Could not record formula steps: %v\n", style.Dim.Render("Warning:"), err)
+		}
 	}
 
 	// Try to inject the "start now" prompt (graceful if no tmux)
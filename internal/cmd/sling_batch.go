@@ -65,6 +65,7 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 			Create:   slingCreate,
 			HookBead: beadID, // Set atomically at spawn time
 			Agent:    slingAgent,
+			NoStack:  slingNoStack,
 		}
 		spawnInfo, err := SpawnPolecatForSling(rigName, spawnOpts)
 		if err != nil {
@@ -77,16 +78,19 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 		hookWorkDir := spawnInfo.ClonePath
 
 		// Auto-convoy: check if issue is already tracked
+		var convoyID string
 		if !slingNoConvoy {
 			existingConvoy := isTrackedByConvoy(beadID)
 			if existingConvoy == "" {
-				convoyID, err := createAutoConvoy(beadID, info.Title)
+				newConvoy, err := createAutoConvoy(beadID, info.Title)
 				if err != nil {
 					fmt.Printf("  %s Could not create auto-convoy: %v\n", style.Dim.Render("Warning:"), err)
 				} else {
+					convoyID = newConvoy
 					fmt.Printf("  %s Created convoy 🚚 %s\n", style.Bold.Render("→"), convoyID)
 				}
 			} else {
+				convoyID = existingConvoy
 				fmt.Printf("  %s Already tracked by convoy %s\n", style.Dim.Render("○"), existingConvoy)
 			}
 		}
@@ -110,6 +114,7 @@ func runBatchSling(beadIDs []string, rigName string, townBeadsDir string) error
 
 		// Update agent bead state
 		updateAgentHookBead(targetAgent, beadID, hookWorkDir, townBeadsDir)
+		recordCommitProvenance(hookWorkDir, beadID, convoyID)
 
 		// Auto-attach mol-polecat-work molecule to polecat agent bead
 		if err := attachPolecatWorkMolecule(targetAgent, hookWorkDir, townRoot); err != nil {
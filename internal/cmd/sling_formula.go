@@ -81,6 +81,16 @@ func runSlingFormula(args []string) error {
 	}
 	townBeadsDir := filepath.Join(townRoot, ".beads")
 
+	// Check --var values against the formula's declared types before
+	// spawning anything, so a typo fails here rather than mid-run
+	vars, err := parseVarFlags(slingVars)
+	if err != nil {
+		return err
+	}
+	if err := checkFormulaVars(formulaName, vars); err != nil {
+		return err
+	}
+
 	// Determine target (self or specified)
 	var target string
 	if len(args) > 1 {
@@ -180,10 +190,16 @@ func runSlingFormula(args []string) error {
 
 	// Step 1: Cook the formula (ensures proto exists)
 	fmt.Printf("  Cooking formula...\n")
-	cookArgs := []string{"--no-daemon", "cook", formulaName}
+	cookRef, cleanupCook, err := cookableFormula(formulaName)
+	if err != nil {
+		return err
+	}
+	cookArgs := []string{"--no-daemon", "cook", cookRef}
 	cookCmd := exec.Command("bd", cookArgs...)
 	cookCmd.Stderr = os.Stderr
-	if err := cookCmd.Run(); err != nil {
+	err = cookCmd.Run()
+	cleanupCook()
+	if err != nil {
 		return fmt.Errorf("cooking formula: %w", err)
 	}
 
@@ -248,10 +264,13 @@ func runSlingFormula(args []string) error {
 
 	// Record the attached molecule after other description updates to avoid overwrite.
 	if attachedMoleculeID != "" {
-		if err := storeAttachedMoleculeInBead(wispRootID, attachedMoleculeID); err != nil {
+		if err := storeAttachedMoleculeInBead(wispRootID, attachedMoleculeID, formulaName); err != nil {
 			// Warn but don't fail - polecat can still work through steps
 			fmt.Printf("%s Could not store attached_molecule: %v\n", style.Dim.Render("Warning:"), err)
 		}
+		if err := recordFormulaSteps(beads.ResolveHookDir(townRoot, wispRootID, ""), wispRootID, formulaName, vars); err != nil {
+			fmt.Printf("%s Could not record formula steps: %v\n", style.Dim.Render("Warning:"), err)
+		}
 	}
 
 	// Step 4: Nudge to start (graceful if no tmux)
@@ -5,12 +5,15 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/tmux"
 	"github.com/steveyegge/gastown/internal/workspace"
@@ -179,8 +182,9 @@ func storeDispatcherInBead(beadID, dispatcher string) error {
 
 // storeAttachedMoleculeInBead sets the attached_molecule field in a bead's description.
 // This is required for gt hook to recognize that a molecule is attached to the bead.
+// The formula name, if known, lets gt mol step done find each step's verify block.
 // Called after bonding a formula wisp to a bead via "gt sling <formula> --on <bead>".
-func storeAttachedMoleculeInBead(beadID, moleculeID string) error {
+func storeAttachedMoleculeInBead(beadID, moleculeID, formulaName string) error {
 	if moleculeID == "" {
 		return nil
 	}
@@ -217,6 +221,9 @@ func storeAttachedMoleculeInBead(beadID, moleculeID string) error {
 
 	// Set the attached molecule
 	fields.AttachedMolecule = moleculeID
+	if formulaName != "" {
+		fields.AttachedFormula = formulaName
+	}
 	if fields.AttachedAt == "" {
 		fields.AttachedAt = time.Now().UTC().Format(time.RFC3339)
 	}
@@ -237,6 +244,58 @@ func storeAttachedMoleculeInBead(beadID, moleculeID string) error {
 	return nil
 }
 
+// recordFormulaSteps stamps each step bead of a newly slung molecule with
+// the formula step it was made from, so gt mol step done finds a step's exit
+// criteria and parallel flag by ID rather than by its (possibly repeated)
+// title. vars are the values the molecule was made with. Formulas only bd
+// can see are skipped.
+func recordFormulaSteps(beadsWorkDir, moleculeID, formulaName string, vars map[string]string) error {
+	if moleculeID == "" || formulaName == "" {
+		return nil
+	}
+	if _, err := findFormulaFile(formulaName); err != nil {
+		return nil
+	}
+	f, err := loadFlattenedFormula(formulaName)
+	if err != nil {
+		return err
+	}
+
+	b := beads.New(beadsWorkDir)
+	children, err := b.List(beads.ListOptions{Parent: moleculeID, Status: "all", Priority: -1})
+	if err != nil {
+		return fmt.Errorf("listing molecule steps: %w", err)
+	}
+	// Steps are numbered in the order bd created them from the formula
+	sort.SliceStable(children, func(i, j int) bool {
+		return stepNumber(children[i].ID) < stepNumber(children[j].ID)
+	})
+	titles := make([]string, len(children))
+	for i, child := range children {
+		titles[i] = child.Title
+	}
+
+	for i, id := range f.MatchSteps(titles, vars) {
+		if id == "" {
+			continue
+		}
+		desc := beads.SetFormulaStep(children[i], id)
+		if err := b.Update(children[i].ID, beads.UpdateOptions{Description: &desc}); err != nil {
+			return fmt.Errorf("recording formula step on %s: %w", children[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// stepNumber returns N for a molecule step ID of the form mol-id.N, or -1.
+func stepNumber(stepID string) int {
+	if extractMoleculeIDFromStep(stepID) == "" {
+		return -1
+	}
+	n, _ := strconv.Atoi(stepID[strings.LastIndex(stepID, ".")+1:])
+	return n
+}
+
 // injectStartPrompt sends a prompt to the target pane to start working.
 // Uses the reliable nudge pattern: literal mode + 500ms debounce + separate Enter.
 func injectStartPrompt(pane, beadID, subject, args string) error {
@@ -426,6 +485,19 @@ func updateAgentHookBead(agentID, beadID, workDir, townBeadsDir string) {
 	}
 }
 
+// recordCommitProvenance records the slung bead and its convoy in the target
+// agent's worktree, where the managed prepare-commit-msg hook stamps them on
+// the agent's commits as Gt-Bead and Gt-Convoy trailers. Best effort: a
+// worktree without the hook just gets no trailers.
+func recordCommitProvenance(workDir, beadID, convoyID string) {
+	if workDir == "" {
+		return
+	}
+	if err := git.WriteProvenance(workDir, git.Provenance{Bead: beadID, Convoy: convoyID}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: couldn't record commit provenance: %v\n", err)
+	}
+}
+
 // wakeRigAgents wakes the witness and refinery for a rig after polecat dispatch.
 // This ensures the patrol agents are ready to monitor and merge.
 func wakeRigAgents(rigName string) {
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// slingStack is unmerged work a new polecat's branch builds on: a
+// dependency of the slung bead whose MR is still in the merge queue.
+type slingStack struct {
+	MR     string // The dependency's open merge request
+	Issue  string // The dependency
+	Branch string // The MR's branch, which the polecat starts from
+}
+
+// StartPoint is the ref the polecat's branch is created from.
+func (s *slingStack) StartPoint() string {
+	return "origin/" + s.Branch
+}
+
+// resolveSlingStack finds the queued MR a bead should stack on. A bead
+// stacks when exactly one of its blocking dependencies has an open MR;
+// with several there is no single branch to start from, so it returns an
+// error and the caller starts from the default branch as usual.
+func resolveSlingStack(bd *beads.Beads, beadID string) (*slingStack, error) {
+	issue, err := bd.Show(beadID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", beadID, err)
+	}
+
+	var stacks []*slingStack
+	for _, dep := range issue.Dependencies {
+		if dep.Status == "closed" || (dep.DependencyType != "" && dep.DependencyType != "blocks") {
+			continue
+		}
+		mr, err := bd.FindMRForIssue(dep.ID)
+		if err != nil {
+			return nil, fmt.Errorf("finding MR for %s: %w", dep.ID, err)
+		}
+		if mr == nil {
+			continue
+		}
+		fields := beads.ParseMRFields(mr)
+		if fields == nil || fields.Branch == "" {
+			continue
+		}
+		stacks = append(stacks, &slingStack{MR: mr.ID, Issue: dep.ID, Branch: fields.Branch})
+	}
+
+	switch len(stacks) {
+	case 0:
+		return nil, nil
+	case 1:
+		return stacks[0], nil
+	default:
+		mrs := make([]string, len(stacks))
+		for i, s := range stacks {
+			mrs[i] = s.MR
+		}
+		return nil, fmt.Errorf("%s depends on %d unmerged MRs (%s); can only stack on one", beadID, len(stacks), strings.Join(mrs, ", "))
+	}
+}
+
+// storeStackInBead records on the slung bead which MR its branch is stacked
+// on and the commit it started from, so gt done can carry both to the MR.
+func storeStackInBead(bd *beads.Beads, beadID, mrID, base string) error {
+	issue, err := bd.Show(beadID)
+	if err != nil {
+		return fmt.Errorf("fetching bead: %w", err)
+	}
+
+	fields := beads.ParseAttachmentFields(issue)
+	if fields == nil {
+		fields = &beads.AttachmentFields{}
+	}
+	fields.StackedOn = mrID
+	fields.StackBase = base
+
+	newDesc := beads.SetAttachmentFields(issue, fields)
+	if err := bd.Update(beadID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		return fmt.Errorf("updating bead description: %w", err)
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/steveyegge/gastown/internal/formula"
 )
 
 func writeBDStub(t *testing.T, binDir string, unixScript string, windowsScript string) string {
@@ -1034,3 +1036,169 @@ exit /b 0
 			"Log output:\n%s\nAttached log:\n%s", string(logBytes), attachedLog)
 	}
 }
+
+// writeIncludingFormula writes a "composed" workflow that includes an
+// "audit" formula into townRoot's formula directory, which it returns.
+func writeIncludingFormula(t *testing.T, townRoot string) string {
+	t.Helper()
+	formulasDir := filepath.Join(townRoot, ".beads", "formulas")
+	if err := os.MkdirAll(formulasDir, 0755); err != nil {
+		t.Fatalf("mkdir formulas: %v", err)
+	}
+	files := map[string]string{
+		"audit": "formula = \"audit\"\n[[steps]]\nid = \"scan\"\ntitle = \"Scan\"\n",
+		"composed": "formula = \"composed\"\n[[include]]\nformula = \"audit\"\n" +
+			"[[steps]]\nid = \"build\"\ntitle = \"Build\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(formulasDir, name+".formula.toml"), []byte(content), 0644); err != nil {
+			t.Fatalf("write formula: %v", err)
+		}
+	}
+	return formulasDir
+}
+
+// TestSlingCooksIncludedFormula verifies that a formula using [[include]]
+// is flattened before bd cook sees it. bd reads the raw file and would make
+// a molecule without the included steps.
+func TestSlingCooksIncludedFormula(t *testing.T) {
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor", "rig"), 0755); err != nil {
+		t.Fatalf("mkdir mayor/rig: %v", err)
+	}
+	formulasDir := writeIncludingFormula(t, townRoot)
+
+	binDir := filepath.Join(townRoot, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("mkdir binDir: %v", err)
+	}
+	logPath := filepath.Join(townRoot, "bd.log")
+	cookedPath := filepath.Join(townRoot, "cooked.toml")
+	bdScript := `#!/bin/sh
+echo "$*" >> "${BD_LOG}"
+[ "$1" = "--no-daemon" ] && shift
+case "$1" in
+  show) exit 1 ;;
+  formula) echo '{"name":"composed"}' ;;
+  cook) cat "$2" > "${BD_COOKED}" ;;
+  mol) echo '{"new_epic_id":"gt-wisp-xyz"}' ;;
+esac
+exit 0
+`
+	bdScriptWindows := `@echo off
+echo %*>>"%BD_LOG%"
+set "cmd=%1"
+set "arg=%2"
+if "%cmd%"=="--no-daemon" (
+  set "cmd=%2"
+  set "arg=%3"
+)
+if "%cmd%"=="show" exit /b 1
+if "%cmd%"=="formula" echo {"name":"composed"}
+if "%cmd%"=="cook" type "%arg%" > "%BD_COOKED%"
+if "%cmd%"=="mol" echo {"new_epic_id":"gt-wisp-xyz"}
+exit /b 0
+`
+	_ = writeBDStub(t, binDir, bdScript, bdScriptWindows)
+	t.Setenv("BD_LOG", logPath)
+	t.Setenv("BD_COOKED", cookedPath)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("GT_TEST_NO_NUDGE", "1")
+	t.Setenv(EnvGTRole, "mayor")
+	t.Setenv("GT_POLECAT", "")
+	t.Setenv("GT_CREW", "")
+	t.Setenv("TMUX_PANE", "")
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	if err := os.Chdir(townRoot); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	prevOn, prevVars, prevDryRun := slingOnTarget, slingVars, slingDryRun
+	t.Cleanup(func() {
+		slingOnTarget, slingVars, slingDryRun = prevOn, prevVars, prevDryRun
+	})
+	slingOnTarget, slingVars, slingDryRun = "", nil, false
+
+	if err := runSling(nil, []string{"composed"}); err != nil {
+		t.Fatalf("runSling(composed): %v", err)
+	}
+
+	logBytes, _ := os.ReadFile(logPath)
+	var cookLine string
+	for _, line := range strings.Split(string(logBytes), "\n") {
+		if strings.Contains(line, " cook ") {
+			cookLine = line
+		}
+	}
+	if cookLine == "" || !strings.HasSuffix(cookLine, "composed.formula.toml") || strings.Contains(cookLine, formulasDir) {
+		t.Fatalf("bd cook should get a flattened copy of composed, got %q\nlog:\n%s", cookLine, logBytes)
+	}
+	cooked, err := os.ReadFile(cookedPath)
+	if err != nil {
+		t.Fatalf("read cooked formula: %v", err)
+	}
+	f, err := formula.Parse(cooked)
+	if err != nil {
+		t.Fatalf("cooked formula doesn't parse: %v\n%s", err, cooked)
+	}
+	if f.IsComposed() || f.GetStep("scan") == nil || f.GetStep("build") == nil {
+		t.Errorf("cooked formula should be flattened with scan and build, got steps %v:\n%s", f.GetAllIDs(), cooked)
+	}
+	if !strings.Contains(string(logBytes), "mol wisp composed") {
+		t.Errorf("wisp should use the cooked proto by name:\n%s", logBytes)
+	}
+}
+
+// TestFormulaRunSlingsIncludedFormula verifies that gt formula run hands a
+// formula using [[include]] to gt sling (which cooks it flattened) instead
+// of refusing it.
+func TestFormulaRunSlingsIncludedFormula(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("gt stub is a shell script")
+	}
+	townRoot := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(townRoot, "mayor", "rig"), 0755); err != nil {
+		t.Fatalf("mkdir mayor/rig: %v", err)
+	}
+	writeIncludingFormula(t, townRoot)
+
+	binDir := filepath.Join(townRoot, "bin")
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatalf("mkdir binDir: %v", err)
+	}
+	logPath := filepath.Join(townRoot, "gt.log")
+	gtScript := "#!/bin/sh\necho \"$*\" >> \"${GT_LOG}\"\nexit 0\n"
+	if err := os.WriteFile(filepath.Join(binDir, "gt"), []byte(gtScript), 0755); err != nil {
+		t.Fatalf("write gt stub: %v", err)
+	}
+	t.Setenv("GT_LOG", logPath)
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(cwd) })
+	if err := os.Chdir(townRoot); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	prevRig, prevVars, prevOn, prevDryRun := formulaRunRig, formulaRunVars, formulaRunOn, formulaRunDryRun
+	t.Cleanup(func() {
+		formulaRunRig, formulaRunVars, formulaRunOn, formulaRunDryRun = prevRig, prevVars, prevOn, prevDryRun
+	})
+	formulaRunRig, formulaRunVars, formulaRunOn, formulaRunDryRun = "gastown", nil, "", false
+
+	if err := runFormulaRun(nil, []string{"composed"}); err != nil {
+		t.Fatalf("runFormulaRun(composed): %v", err)
+	}
+	logBytes, _ := os.ReadFile(logPath)
+	if !strings.Contains(string(logBytes), "sling composed gastown") {
+		t.Errorf("formula run should sling composed, gt got:\n%s", logBytes)
+	}
+}
@@ -18,8 +18,11 @@ is violated. They're called before the tool runs, preventing the
 forbidden operation entirely.
 
 Available guards:
+  policy        - Enforce the declarative town/rig policy (settings/policy.json)
   pr-workflow   - Block PR creation and feature branches
 
+Use 'gt tap guard test' to dry-run a payload against the policy.
+
 Example hook configuration:
   {
     "PreToolUse": [{
@@ -0,0 +1,344 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/policy"
+	"github.com/steveyegge/gastown/internal/style"
+	"github.com/steveyegge/gastown/internal/workspace"
+)
+
+var (
+	tapGuardTestCommand string
+	tapGuardTestFile    string
+	tapGuardTestTool    string
+	tapGuardTestAs      string
+	tapGuardTestBranch  string
+	tapGuardTestJSON    bool
+)
+
+var tapGuardPolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Enforce the town/rig guard policy",
+	Long: `Evaluate a PreToolUse payload (read from stdin) against the guard policy.
+
+Policies are declared in settings/policy.json at town level and overlaid
+by <rig>/settings/policy.json. Rig rules replace town rules with the same
+name; "disabled": true drops a town rule for that rig.
+
+Each rule applies to a set of roles and can combine these checks:
+  commands            Regex patterns forbidden in Bash commands
+  worktree_only       Block Edit/Write outside the agent's own directory
+  allow_paths         Extra writable directories for worktree_only
+  protected_branches  Branch globs that may not be pushed to or deleted
+  destructive_git     Block force push, reset --hard, clean -f, branch -D, ...
+
+Example settings/policy.json:
+  {
+    "type": "policy",
+    "version": 1,
+    "rules": [
+      {
+        "name": "polecat-safety",
+        "roles": ["polecat"],
+        "worktree_only": true,
+        "allow_paths": ["/tmp"],
+        "protected_branches": ["main", "release/*"],
+        "destructive_git": true
+      },
+      {
+        "name": "no-prs",
+        "commands": ["^gh pr create"],
+        "message": "Gas Town workers push directly to main."
+      }
+    ]
+  }
+
+Every block (and warning) is recorded as a guard_blocked/guard_warned
+audit event. Outside a Gas Town agent context the guard allows everything.
+
+The guard fails closed: if the payload or a policy file cannot be read or
+parsed, the tool call is blocked and a guard_failed event is recorded.
+
+Exit codes:
+  0 - Operation allowed
+  2 - Operation BLOCKED
+
+Example hook configuration:
+  {
+    "PreToolUse": [{
+      "matcher": "Bash|Edit|Write|MultiEdit|NotebookEdit",
+      "hooks": [{"command": "gt tap guard policy"}]
+    }]
+  }`,
+	Args: cobra.NoArgs,
+	RunE: runTapGuardPolicy,
+}
+
+var tapGuardTestCmd = &cobra.Command{
+	Use:   "test [payload.json]",
+	Short: "Dry-run a payload against the guard policy",
+	Long: `Evaluate a PreToolUse payload against the guard policy without blocking
+or recording audit events.
+
+The payload is read from the given file, from stdin, or built from
+--command/--file. The agent identity defaults to the current role and can
+be overridden with --as.
+
+Examples:
+  gt tap guard test --as gastown/polecats/Toast --command "git push -f origin main"
+  gt tap guard test --as gastown/crew/max --file /etc/hosts
+  echo '{"tool_name":"Bash","tool_input":{"command":"gh pr create"}}' | gt tap guard test`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runTapGuardTest,
+}
+
+func init() {
+	tapGuardCmd.AddCommand(tapGuardPolicyCmd)
+	tapGuardCmd.AddCommand(tapGuardTestCmd)
+
+	tapGuardTestCmd.Flags().StringVar(&tapGuardTestCommand, "command", "", "Bash command to test")
+	tapGuardTestCmd.Flags().StringVar(&tapGuardTestFile, "file", "", "File path to test as a write")
+	tapGuardTestCmd.Flags().StringVar(&tapGuardTestTool, "tool", "", "Tool name (default: Bash for --command, Write for --file)")
+	tapGuardTestCmd.Flags().StringVar(&tapGuardTestAs, "as", "", "Agent identity (e.g. gastown/polecats/Toast, mayor)")
+	tapGuardTestCmd.Flags().StringVar(&tapGuardTestBranch, "branch", "", "Current branch (default: from git)")
+	tapGuardTestCmd.Flags().BoolVar(&tapGuardTestJSON, "json", false, "Output as JSON")
+}
+
+func runTapGuardPolicy(cmd *cobra.Command, args []string) error {
+	if !isGasTownAgentContext() {
+		return nil
+	}
+
+	info, err := GetRole()
+	if err != nil {
+		// Not resolvable as an agent - nothing to enforce
+		return nil
+	}
+	actor := info.ActorString()
+
+	// From here on the guard fails closed: exit 1 would let the call through
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		guardFailClosed(actor, "", "payload", fmt.Errorf("reading hook payload: %w", err))
+	}
+	payload, err := policy.ParsePayload(data)
+	if err != nil {
+		guardFailClosed(actor, "", "payload", err)
+	}
+
+	pol, ctx, err := loadGuardPolicy(info, payload, "")
+	if err != nil {
+		guardFailClosed(actor, payload.ToolName, "policy", err)
+	}
+
+	decision := pol.Evaluate(payload, ctx)
+	for _, v := range decision.Violations {
+		eventType := events.TypeGuardBlocked
+		if v.Action == policy.ActionWarn {
+			eventType = events.TypeGuardWarned
+		}
+		_ = events.LogAudit(eventType, actor, events.GuardPayload(v.Rule, payload.ToolName, v.Check, v.Detail))
+	}
+
+	if len(decision.Violations) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(os.Stderr, "")
+	for _, v := range decision.Violations {
+		label := "❌ BLOCKED"
+		if v.Action == policy.ActionWarn {
+			label = "⚠ WARNING"
+		}
+		fmt.Fprintf(os.Stderr, "%s by policy rule %q (%s: %s)\n", label, v.Rule, v.Check, v.Detail)
+		fmt.Fprintf(os.Stderr, "  %s\n", v.Message)
+	}
+	fmt.Fprintln(os.Stderr, "")
+
+	if decision.Blocked() {
+		os.Exit(2) // Exit 2 = BLOCK in Claude Code hooks
+	}
+	return nil
+}
+
+// guardFailClosed records a guard_failed audit event and blocks the tool
+// call when the guard cannot evaluate it, so an unreadable payload or a
+// broken policy file never silently disables enforcement.
+func guardFailClosed(actor, tool, check string, err error) {
+	_ = events.LogAudit(events.TypeGuardFailed, actor, events.GuardPayload("", tool, check, err.Error()))
+	fmt.Fprintf(os.Stderr, "\n❌ BLOCKED: guard policy could not be evaluated (%s): %v\n", check, err)
+	fmt.Fprintf(os.Stderr, "  Fix settings/policy.json (check with 'gt tap guard test') or ask the overseer.\n\n")
+	os.Exit(2) // Exit 2 = BLOCK in Claude Code hooks
+}
+
+func runTapGuardTest(cmd *cobra.Command, args []string) error {
+	payload, err := guardTestPayload(args)
+	if err != nil {
+		return err
+	}
+
+	var info RoleInfo
+	if tapGuardTestAs != "" {
+		townRoot, err := workspace.FindFromCwd()
+		if err != nil || townRoot == "" {
+			return fmt.Errorf("not in a Gas Town workspace")
+		}
+		role, rig, name := parseRoleString(tapGuardTestAs)
+		info = RoleInfo{Role: role, Rig: rig, Polecat: name, TownRoot: townRoot}
+	} else {
+		info, err = GetRole()
+		if err != nil {
+			return err
+		}
+	}
+
+	pol, ctx, err := loadGuardPolicy(info, payload, tapGuardTestBranch)
+	if err != nil {
+		return err
+	}
+	decision := pol.Evaluate(payload, ctx)
+
+	if tapGuardTestJSON {
+		out := struct {
+			Agent      string             `json:"agent"`
+			Role       string             `json:"role"`
+			Worktree   string             `json:"worktree,omitempty"`
+			Blocked    bool               `json:"blocked"`
+			Violations []policy.Violation `json:"violations"`
+		}{info.ActorString(), ctx.Role, ctx.Worktree, decision.Blocked(), decision.Violations}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	fmt.Printf("%s %s\n", style.Bold.Render("Agent:"), info.ActorString())
+	if ctx.Worktree != "" {
+		fmt.Printf("%s %s\n", style.Bold.Render("Worktree:"), ctx.Worktree)
+	}
+	var applicable []string
+	for i := range pol.Rules {
+		if r := &pol.Rules[i]; r.AppliesTo(ctx.Role) {
+			line := r.Name
+			if r.Description != "" {
+				line += " - " + r.Description
+			}
+			applicable = append(applicable, line)
+		}
+	}
+	fmt.Printf("%s %d applicable\n", style.Bold.Render("Rules:"), len(applicable))
+	for _, line := range applicable {
+		fmt.Printf("  %s\n", style.Dim.Render(line))
+	}
+	fmt.Println()
+
+	if len(decision.Violations) == 0 {
+		fmt.Printf("%s ALLOW\n", style.Bold.Render("✓"))
+		return nil
+	}
+	for _, v := range decision.Violations {
+		label := "✗ BLOCK"
+		if v.Action == policy.ActionWarn {
+			label = "⚠ WARN "
+		}
+		fmt.Printf("%s  %s [%s] %s\n", style.Bold.Render(label), v.Rule, v.Check, v.Detail)
+		fmt.Printf("         %s\n", style.Dim.Render(v.Message))
+	}
+	return nil
+}
+
+// guardTestPayload builds the payload for `gt tap guard test`.
+func guardTestPayload(args []string) (*policy.Payload, error) {
+	if tapGuardTestCommand != "" || tapGuardTestFile != "" {
+		cwd, _ := os.Getwd()
+		payload := &policy.Payload{Cwd: cwd, HookEventName: "PreToolUse", ToolName: tapGuardTestTool}
+		if tapGuardTestCommand != "" {
+			payload.ToolInput.Command = tapGuardTestCommand
+			if payload.ToolName == "" {
+				payload.ToolName = "Bash"
+			}
+		}
+		if tapGuardTestFile != "" {
+			payload.ToolInput.FilePath = tapGuardTestFile
+			if payload.ToolName == "" {
+				payload.ToolName = "Write"
+			}
+		}
+		return payload, nil
+	}
+
+	var data []byte
+	var err error
+	if len(args) == 1 {
+		data, err = os.ReadFile(args[0]) //nolint:gosec // G304: user-supplied payload file
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading payload: %w", err)
+	}
+	return policy.ParsePayload(data)
+}
+
+// loadGuardPolicy loads the policy for an agent and builds its evaluation context.
+func loadGuardPolicy(info RoleInfo, payload *policy.Payload, branch string) (*policy.Policy, policy.Context, error) {
+	var rigPath string
+	if info.Rig != "" {
+		rigPath = filepath.Join(info.TownRoot, info.Rig)
+	}
+	pol, err := policy.Load(info.TownRoot, rigPath)
+	if err != nil {
+		return nil, policy.Context{}, err
+	}
+
+	ctx := policy.Context{
+		Role:     string(info.Role),
+		Worktree: guardWorktree(info),
+		Branch:   branch,
+	}
+	if ctx.Branch == "" && payload.ToolName == "Bash" && strings.Contains(payload.ToolInput.Command, "git") {
+		dir := payload.Cwd
+		if dir == "" {
+			dir, _ = os.Getwd()
+		}
+		ctx.Branch, _ = git.NewGit(dir).CurrentBranch()
+	}
+	return pol, ctx, nil
+}
+
+// guardWorktree returns the directory an agent may write to under worktree_only.
+// Workers get their whole agent directory (not just the clone) so they can
+// maintain their own CLAUDE.md and scratch files.
+func guardWorktree(info RoleInfo) string {
+	switch info.Role {
+	case RoleMayor:
+		return filepath.Join(info.TownRoot, "mayor")
+	case RoleDeacon:
+		return filepath.Join(info.TownRoot, "deacon")
+	case RoleWitness:
+		if info.Rig != "" {
+			return filepath.Join(info.TownRoot, info.Rig, "witness")
+		}
+	case RoleRefinery:
+		if info.Rig != "" {
+			return filepath.Join(info.TownRoot, info.Rig, "refinery")
+		}
+	case RolePolecat:
+		if info.Rig != "" && info.Polecat != "" {
+			return filepath.Join(info.TownRoot, info.Rig, "polecats", info.Polecat)
+		}
+	case RoleCrew:
+		if info.Rig != "" && info.Polecat != "" {
+			return filepath.Join(info.TownRoot, info.Rig, "crew", info.Polecat)
+		}
+	}
+	return ""
+}
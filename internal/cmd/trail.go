@@ -10,15 +10,20 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
 
 var (
-	trailSince string
-	trailLimit int
-	trailJSON  bool
-	trailAll   bool
+	trailSince  string
+	trailLimit  int
+	trailJSON   bool
+	trailAll    bool
+	trailBead   string
+	trailAgent  string
+	trailConvoy string
+	trailMR     string
 )
 
 var trailCmd = &cobra.Command{
@@ -41,12 +46,20 @@ Flags:
   --json     Output as JSON
   --all      Include all activity (not just agents)
 
+Commit provenance (Gt-* trailers stamped on agent and merge commits):
+  --bead     Commits for a bead (Gt-Bead)
+  --agent    Commits by an agent (Gt-Agent, partial match)
+  --convoy   Commits for a convoy (Gt-Convoy)
+  --mr       Merge commit for a merge request (Gt-MR)
+
 Examples:
   gt trail                     # Recent commits (default)
   gt trail commits             # Same as above
   gt trail commits --since 1h  # Last hour
   gt trail beads               # Recent beads
   gt trail hooks               # Recent hook activity
+  gt trail --bead gt-abc       # Every commit made for gt-abc
+  gt trail --agent crew/joe    # Commits stamped by joe
   gt recent                    # Alias for gt trail
   gt recap --since 24h         # Activity from last 24 hours`,
 	RunE: runTrailCommits, // Default to commits
@@ -57,14 +70,23 @@ var trailCommitsCmd = &cobra.Command{
 	Short: "Show recent commits from agents",
 	Long: `Show recent git commits made by agents.
 
-By default, filters to commits from agents (using the configured
-email domain). Use --all to include all commits.
+By default, filters to commits from agents (a Gt-Agent trailer, or an
+author email in the configured agent domain). Use --all to include all
+commits.
+
+Agent commits carry provenance trailers (Gt-Bead, Gt-Agent, Gt-Convoy,
+Gt-MR) stamped by the prepare-commit-msg hook Gas Town installs in its
+clones; refinery merge commits carry them too. --bead, --agent, --convoy
+and --mr query history across all branches by those trailers.
 
 Examples:
-  gt trail commits              # Recent agent commits
-  gt trail commits --since 1h   # Last hour of commits
-  gt trail commits --all        # All commits (including non-agents)
-  gt trail commits --json       # JSON output`,
+  gt trail commits                    # Recent agent commits
+  gt trail commits --since 1h         # Last hour of commits
+  gt trail commits --all              # All commits (including non-agents)
+  gt trail commits --bead gt-abc      # Commits for a bead, on any branch
+  gt trail commits --convoy hq-cv-12  # Commits for a whole convoy
+  gt trail commits --mr gt-mr-xyz     # The merge commit for an MR
+  gt trail commits --json             # JSON output`,
 	RunE: runTrailCommits,
 }
 
@@ -98,6 +120,10 @@ func init() {
 	trailCmd.PersistentFlags().IntVar(&trailLimit, "limit", 20, "Maximum number of items to show")
 	trailCmd.PersistentFlags().BoolVar(&trailJSON, "json", false, "Output as JSON")
 	trailCmd.PersistentFlags().BoolVar(&trailAll, "all", false, "Include all activity (not just agents)")
+	trailCmd.PersistentFlags().StringVar(&trailBead, "bead", "", "Commits stamped with this bead (Gt-Bead trailer)")
+	trailCmd.PersistentFlags().StringVar(&trailAgent, "agent", "", "Commits stamped by this agent (Gt-Agent trailer, partial match)")
+	trailCmd.PersistentFlags().StringVar(&trailConvoy, "convoy", "", "Commits stamped with this convoy (Gt-Convoy trailer)")
+	trailCmd.PersistentFlags().StringVar(&trailMR, "mr", "", "Commits stamped with this merge request (Gt-MR trailer)")
 
 	// Add subcommands
 	trailCmd.AddCommand(trailCommitsCmd)
@@ -118,6 +144,10 @@ type CommitEntry struct {
 	DateRel   string    `json:"date_relative"`
 	Subject   string    `json:"subject"`
 	IsAgent   bool      `json:"is_agent"`
+	Agent     string    `json:"agent,omitempty"` // Gt-Agent trailer
+	Bead      string    `json:"bead,omitempty"`  // Gt-Bead trailer
+	Convoy    string    `json:"convoy,omitempty"`
+	MR        string    `json:"mr,omitempty"`
 }
 
 func runTrailCommits(cmd *cobra.Command, args []string) error {
@@ -131,26 +161,77 @@ func runTrailCommits(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Build git log command
-	gitArgs := []string{
-		"log",
-		"--format=%H|%h|%an|%ae|%aI|%ar|%s",
-		fmt.Sprintf("-n%d", trailLimit*2), // Get extra to filter
-	}
-
+	var since time.Time
 	if trailSince != "" {
 		duration, err := parseDuration(trailSince)
 		if err != nil {
 			return fmt.Errorf("invalid --since value: %w", err)
 		}
-		since := time.Now().Add(-duration)
+		since = time.Now().Add(-duration)
+	}
+
+	var commits []CommitEntry
+	want := git.Provenance{Bead: trailBead, Agent: trailAgent, Convoy: trailConvoy, MR: trailMR}
+	if !want.IsZero() {
+		commits, err = trailCommitsByProvenance(want, since)
+	} else {
+		commits, err = trailRecentCommits(domain, since)
+	}
+	if err != nil {
+		return err
+	}
+
+	if trailJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(commits)
+	}
+
+	// Text output
+	if len(commits) == 0 {
+		fmt.Println("No commits found")
+		return nil
+	}
+
+	fmt.Printf("%s\n\n", style.Bold.Render("Recent Commits"))
+	for _, c := range commits {
+		authorLabel := c.Author
+		if c.IsAgent {
+			authorLabel = style.Bold.Render(c.Author)
+		}
+
+		if c.Agent != "" {
+			authorLabel = style.Bold.Render(c.Agent)
+		}
+
+		fmt.Printf("%s %s\n", style.Dim.Render(c.ShortHash), c.Subject)
+		fmt.Printf("    %s %s", authorLabel, style.Dim.Render(c.DateRel))
+		if c.Bead != "" {
+			fmt.Printf(" %s", style.Dim.Render("["+c.Bead+"]"))
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// trailRecentCommits lists recent commits on the current branch, keeping
+// agent commits unless --all is set.
+func trailRecentCommits(domain string, since time.Time) ([]CommitEntry, error) {
+	// Build git log command. Trailers come before the subject, which may contain "|".
+	gitArgs := []string{
+		"log",
+		"--format=%H|%h|%an|%ae|%aI|%ar|%(trailers:key=Gt-Agent,valueonly,separator=%x2C)|%(trailers:key=Gt-Bead,valueonly,separator=%x2C)|%s",
+		fmt.Sprintf("-n%d", trailLimit*2), // Get extra to filter
+	}
+	if !since.IsZero() {
 		gitArgs = append(gitArgs, fmt.Sprintf("--since=%s", since.Format(time.RFC3339)))
 	}
 
 	gitCmd := exec.Command("git", gitArgs...)
 	output, err := gitCmd.Output()
 	if err != nil {
-		return fmt.Errorf("running git log: %w", err)
+		return nil, fmt.Errorf("running git log: %w", err)
 	}
 
 	// Parse commits
@@ -161,13 +242,13 @@ func runTrailCommits(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		parts := strings.SplitN(line, "|", 7)
-		if len(parts) < 7 {
+		parts := strings.SplitN(line, "|", 9)
+		if len(parts) < 9 {
 			continue
 		}
 
 		date, _ := time.Parse(time.RFC3339, parts[4])
-		isAgent := strings.HasSuffix(parts[3], "@"+domain)
+		isAgent := parts[6] != "" || strings.HasSuffix(parts[3], "@"+domain)
 
 		// Skip non-agents unless --all is set
 		if !trailAll && !isAgent {
@@ -181,39 +262,52 @@ func runTrailCommits(cmd *cobra.Command, args []string) error {
 			Email:     parts[3],
 			Date:      date,
 			DateRel:   parts[5],
-			Subject:   parts[6],
+			Subject:   parts[8],
 			IsAgent:   isAgent,
+			Agent:     parts[6],
+			Bead:      parts[7],
 		})
 
 		if len(commits) >= trailLimit {
 			break
 		}
 	}
+	return commits, nil
+}
 
-	if trailJSON {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(commits)
+// trailCommitsByProvenance lists commits on any branch whose Gt-* trailers
+// match want.
+func trailCommitsByProvenance(want git.Provenance, since time.Time) ([]CommitEntry, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting current directory: %w", err)
 	}
-
-	// Text output
-	if len(commits) == 0 {
-		fmt.Println("No commits found")
-		return nil
+	found, err := git.NewGit(cwd).LogByProvenance(want, since, trailLimit)
+	if err != nil {
+		return nil, fmt.Errorf("querying commit trailers: %w", err)
 	}
 
-	fmt.Printf("%s\n\n", style.Bold.Render("Recent Commits"))
-	for _, c := range commits {
-		authorLabel := c.Author
-		if c.IsAgent {
-			authorLabel = style.Bold.Render(c.Author)
+	commits := make([]CommitEntry, 0, len(found))
+	for _, c := range found {
+		short := c.Hash
+		if len(short) > 8 {
+			short = short[:8]
 		}
-
-		fmt.Printf("%s %s\n", style.Dim.Render(c.ShortHash), c.Subject)
-		fmt.Printf("    %s %s\n", authorLabel, style.Dim.Render(c.DateRel))
+		commits = append(commits, CommitEntry{
+			Hash:      c.Hash,
+			ShortHash: short,
+			Author:    c.Author,
+			Date:      c.Date,
+			DateRel:   relativeTime(c.Date),
+			Subject:   c.Subject,
+			IsAgent:   c.Agent != "",
+			Agent:     c.Agent,
+			Bead:      c.Bead,
+			Convoy:    c.Convoy,
+			MR:        c.MR,
+		})
 	}
-
-	return nil
+	return commits, nil
 }
 
 // BeadEntry represents a bead for output.
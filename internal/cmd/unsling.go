@@ -8,6 +8,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/steveyegge/gastown/internal/beads"
 	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/style"
 	"github.com/steveyegge/gastown/internal/workspace"
 )
@@ -72,15 +73,15 @@ func runUnsling(cmd *cobra.Command, args []string) error {
 	}
 
 	// Resolve target agent (default: self)
-	var agentID string
+	var agentID, agentWorkDir string
 	var err error
 	if targetAgent != "" {
-		agentID, _, _, err = resolveTargetAgent(targetAgent)
+		agentID, _, agentWorkDir, err = resolveTargetAgent(targetAgent)
 		if err != nil {
 			return fmt.Errorf("resolving target agent: %w", err)
 		}
 	} else {
-		agentID, _, _, err = resolveSelfTarget()
+		agentID, _, agentWorkDir, err = resolveSelfTarget()
 		if err != nil {
 			return fmt.Errorf("detecting agent identity: %w", err)
 		}
@@ -166,6 +167,11 @@ func runUnsling(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("clearing hook from agent bead %s: %w", agentBeadID, err)
 	}
 
+	// Stop stamping the unslung work on the agent's commits
+	if agentWorkDir != "" {
+		_ = git.ClearProvenance(agentWorkDir)
+	}
+
 	// Log unhook event
 	_ = events.LogFeed(events.TypeUnhook, agentID, events.UnhookPayload(hookedBeadID))
 
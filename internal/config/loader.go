@@ -1499,6 +1499,16 @@ func GetDefaultFormula(rigPath string) string {
 	return settings.Workflow.DefaultFormula
 }
 
+// GetParallelSteps reports whether a rig fans out every ready molecule step,
+// from workflow.parallel_steps in settings/config.json.
+func GetParallelSteps(rigPath string) bool {
+	settings, err := LoadRigSettings(RigSettingsPath(rigPath))
+	if err != nil || settings.Workflow == nil {
+		return false
+	}
+	return settings.Workflow.ParallelSteps
+}
+
 // GetRigPrefix returns the beads prefix for a rig from rigs.json.
 // Falls back to "gt" if the rig isn't found or has no prefix configured.
 // townRoot is the path to the town directory (e.g., ~/gt).
@@ -1878,6 +1878,23 @@ func TestGetDefaultFormula(t *testing.T) {
 	})
 }
 
+func TestGetParallelSteps(t *testing.T) {
+	t.Parallel()
+	if GetParallelSteps("/nonexistent/path") {
+		t.Error("GetParallelSteps() = true for nonexistent rig")
+	}
+
+	dir := t.TempDir()
+	settings := NewRigSettings()
+	settings.Workflow = &WorkflowConfig{ParallelSteps: true}
+	if err := SaveRigSettings(RigSettingsPath(dir), settings); err != nil {
+		t.Fatalf("SaveRigSettings: %v", err)
+	}
+	if !GetParallelSteps(dir) {
+		t.Error("GetParallelSteps() = false, want true")
+	}
+}
+
 // TestLookupAgentConfigWithRigSettings verifies that lookupAgentConfig checks
 // rig-level agents first, then town-level agents, then built-ins.
 func TestLookupAgentConfigWithRigSettings(t *testing.T) {
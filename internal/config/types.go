@@ -198,6 +198,10 @@ type WorkflowConfig struct {
 	// DefaultFormula is the formula to use when `gt formula run` is called without arguments.
 	// If empty, no default is set and a formula name must be provided.
 	DefaultFormula string `json:"default_formula,omitempty"`
+
+	// ParallelSteps hands every ready molecule step beyond the first to its
+	// own polecat, not just steps marked parallel in their formula.
+	ParallelSteps bool `json:"parallel_steps,omitempty"`
 }
 
 // RigSettings represents per-rig behavioral configuration (settings/config.json).
@@ -208,6 +212,9 @@ type RigSettings struct {
 	Theme      *ThemeConfig      `json:"theme,omitempty"`       // tmux theme settings
 	Namepool   *NamepoolConfig   `json:"namepool,omitempty"`    // polecat name pool settings
 	Crew       *CrewConfig       `json:"crew,omitempty"`        // crew startup settings
+	Sandbox    *SandboxConfig    `json:"sandbox,omitempty"`     // polecat sandbox settings
+	WarmPool   *WarmPoolConfig   `json:"warm_pool,omitempty"`   // pre-provisioned polecat worktrees
+	Cache      *CacheConfig      `json:"cache,omitempty"`       // shared build/dependency caches
 	Workflow   *WorkflowConfig   `json:"workflow,omitempty"`    // workflow settings
 	Runtime    *RuntimeConfig    `json:"runtime,omitempty"`     // LLM runtime settings (deprecated: use Agent)
 
@@ -245,6 +252,46 @@ type CrewConfig struct {
 	Startup string `json:"startup,omitempty"`
 }
 
+// SandboxConfig configures sandboxed polecat execution for a rig.
+// When enabled, polecat sessions run inside a bubblewrap user/mount namespace
+// where the host filesystem is read-only except for the polecat's own
+// directory, its git object store, the shared beads directories and the
+// allowlisted paths below. Linux only.
+type SandboxConfig struct {
+	// Enabled runs polecat sessions inside the sandbox.
+	Enabled bool `json:"enabled"`
+
+	// Binary is the bubblewrap executable. Default: "bwrap".
+	Binary string `json:"binary,omitempty"`
+
+	// Network is "host" (default) to share the host network, or "none"
+	// to run with networking disabled.
+	Network string `json:"network,omitempty"`
+
+	// Writable lists additional writable paths, typically toolchain caches
+	// (e.g. "~/go/pkg/mod", "~/.cache/go-build"). A leading ~ is expanded.
+	// Missing paths are skipped. If nil, DefaultSandboxWritable is used; a
+	// list replaces it, so include the runtime's state files. /tmp is
+	// private apart from the tmux socket directory, which gt commands need.
+	Writable []string `json:"writable,omitempty"`
+
+	// Hidden lists paths masked by an empty tmpfs (e.g. "~/.ssh", "~/.aws").
+	Hidden []string `json:"hidden,omitempty"`
+}
+
+// DefaultSandboxWritable is the writable allowlist used when SandboxConfig.Writable
+// is unset: the runtime state files Claude updates during a session. Whole
+// directories like ~/.claude or ~/.cache are left read-only, since they hold
+// other agents' state; the sandbox adds the session's own transcript
+// directory. /tmp is a private tmpfs with only the tmux socket dir bound in.
+var DefaultSandboxWritable = []string{
+	"~/.claude.json",
+	"~/.claude/.credentials.json",
+	"~/.claude/todos",
+	"~/.claude/statsig",
+	"~/.claude/shell-snapshots",
+}
+
 // RuntimeConfig represents LLM runtime configuration for agent sessions.
 // This allows switching between different LLM backends (claude, aider, etc.)
 // without modifying startup code.
@@ -673,6 +720,37 @@ type MergeQueueConfig struct {
 
 	// MaxConcurrent is the maximum number of concurrent merges.
 	MaxConcurrent int `json:"max_concurrent"`
+
+	// VerifyAfterMerge periodically runs the tests on the target head and
+	// bisects recent merges to find the MR that broke it.
+	VerifyAfterMerge bool `json:"verify_after_merge,omitempty"`
+
+	// VerifyInterval is the minimum time between post-merge verifications (e.g., "30m").
+	VerifyInterval string `json:"verify_interval,omitempty"`
+
+	// BisectLookback is the maximum number of recent merges to bisect.
+	BisectLookback int `json:"bisect_lookback,omitempty"`
+
+	// ScanSecrets enables the refinery's pre-merge secret scanner (default off).
+	ScanSecrets *bool `json:"scan_secrets,omitempty"`
+
+	// MaxFileSize limits files an MR may add or change (e.g., "10MB"; unset
+	// or "0", the default, disables the check).
+	MaxFileSize string `json:"max_file_size,omitempty"`
+
+	// CheckCommands are rig-defined pre-merge check commands. They get the
+	// changed files on stdin and fail the MR by exiting non-zero.
+	CheckCommands []string `json:"check_commands,omitempty"`
+
+	// CheckAllowlist holds path globs exempt from the built-in pre-merge checks.
+	CheckAllowlist []string `json:"check_allowlist,omitempty"`
+
+	// RequiredReviews is the number of review approvals an MR needs before
+	// the refinery merges it (0 = no review gate).
+	RequiredReviews int `json:"required_reviews,omitempty"`
+
+	// ReviewFormula is the molecule slung to reviewers (default: mol-polecat-review-mr).
+	ReviewFormula string `json:"review_formula,omitempty"`
 }
 
 // OnConflict strategy constants.
@@ -712,6 +790,47 @@ type NamepoolConfig struct {
 	MaxBeforeNumbering int `json:"max_before_numbering,omitempty"`
 }
 
+// WarmPoolConfig configures a pool of idle, fully set-up polecat worktrees
+// that sling can claim instead of provisioning from scratch. The daemon
+// replenishes the pool in the background.
+//
+// Warm worktrees are moved into place when claimed, so setup hooks should
+// avoid writing absolute paths to the worktree (e.g. relocatable venvs).
+type WarmPoolConfig struct {
+	// Size is the number of idle worktrees to keep ready. 0 disables the pool.
+	Size int `json:"size"`
+
+	// MaxAge discards idle worktrees older than this duration (e.g. "24h")
+	// so setup hook output doesn't drift too far from the default branch.
+	// Empty means no limit.
+	MaxAge string `json:"max_age,omitempty"`
+}
+
+// CacheConfig declares rig-level build and dependency caches shared by all
+// polecat and refinery sessions. Each session gets environment variables
+// pointing the toolchains at subdirectories of Dir, so worktrees stop
+// re-downloading and rebuilding dependencies from scratch.
+type CacheConfig struct {
+	// Enabled exports the cache environment into sessions.
+	Enabled bool `json:"enabled"`
+
+	// Dir is the cache root. Relative paths are resolved against the rig.
+	// Default: <rig>/.cache.
+	Dir string `json:"dir,omitempty"`
+
+	// Kinds selects built-in caches: "go", "npm", "yarn", "pnpm", "cargo",
+	// "pip". None are enabled unless listed.
+	Kinds []string `json:"kinds,omitempty"`
+
+	// Env maps additional environment variables to subdirectories of Dir
+	// (e.g. {"CCACHE_DIR": "ccache"}).
+	Env map[string]string `json:"env,omitempty"`
+
+	// MaxSize caps the total cache size (e.g. "20GB"). gt rig cache prune
+	// evicts the least recently used caches until under the limit.
+	MaxSize string `json:"max_size,omitempty"`
+}
+
 // DefaultNamepoolConfig returns a NamepoolConfig with sensible defaults.
 func DefaultNamepoolConfig() *NamepoolConfig {
 	return &NamepoolConfig{
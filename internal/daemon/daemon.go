@@ -23,6 +23,7 @@ import (
 	"github.com/steveyegge/gastown/internal/deacon"
 	"github.com/steveyegge/gastown/internal/events"
 	"github.com/steveyegge/gastown/internal/feed"
+	"github.com/steveyegge/gastown/internal/git"
 	"github.com/steveyegge/gastown/internal/mayor"
 	"github.com/steveyegge/gastown/internal/polecat"
 	"github.com/steveyegge/gastown/internal/refinery"
@@ -60,6 +61,10 @@ type Daemon struct {
 
 	// Restart tracking with exponential backoff
 	restartTracker *RestartTracker
+
+	// Warm pool replenishment runs in the background (setup hooks can take
+	// minutes); tracks rigs with a replenish in flight so heartbeats don't stack.
+	warmPoolInFlight sync.Map
 }
 
 // sessionDeath records a detected session death for mass death analysis.
@@ -292,7 +297,10 @@ func (d *Daemon) heartbeat(state *State) {
 	// This validates tmux sessions are still alive for polecats with work-on-hook
 	d.checkPolecatSessionHealth()
 
-	// 12. Clean up orphaned claude subagent processes (memory leak prevention)
+	// 13. Top up polecat warm pools (rigs with warm_pool.size > 0)
+	d.replenishWarmPools()
+
+	// 14. Clean up orphaned claude subagent processes (memory leak prevention)
 	// These are Task tool subagents that didn't clean up after completion.
 	// This is a safety net - Deacon patrol also does this more frequently.
 	d.cleanupOrphanedProcesses()
@@ -583,6 +591,39 @@ func (d *Daemon) ensureRefineryRunning(rigName string) {
 	d.logger.Printf("Refinery session for %s started successfully", rigName)
 }
 
+// replenishWarmPools tops up the warm pool of every operational rig that has
+// one configured. Provisioning runs setup hooks, so each rig is replenished
+// in its own goroutine and skipped while a previous run is still going.
+func (d *Daemon) replenishWarmPools() {
+	for _, rigName := range d.getKnownRigs() {
+		rigPath := filepath.Join(d.config.TownRoot, rigName)
+		settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+		if err != nil || settings.WarmPool == nil || settings.WarmPool.Size <= 0 {
+			continue
+		}
+		if operational, _ := d.isRigOperational(rigName); !operational {
+			continue
+		}
+		if _, busy := d.warmPoolInFlight.LoadOrStore(rigName, true); busy {
+			continue
+		}
+
+		go func(rigName, rigPath string) {
+			defer d.warmPoolInFlight.Delete(rigName)
+
+			r := &rig.Rig{Name: rigName, Path: rigPath}
+			mgr := polecat.NewManager(r, git.NewGit(rigPath), d.tmux)
+			created, err := mgr.ReplenishWarmPool()
+			if err != nil {
+				d.logger.Printf("Warm pool replenish for %s failed: %v", rigName, err)
+			}
+			if created > 0 {
+				d.logger.Printf("Warm pool for %s: provisioned %d worktree(s)", rigName, created)
+			}
+		}(rigName, rigPath)
+	}
+}
+
 // getKnownRigs returns list of registered rig names.
 func (d *Daemon) getKnownRigs() []string {
 	rigsPath := filepath.Join(d.config.TownRoot, "mayor", "rigs.json")
@@ -286,7 +286,8 @@ func (c *ClaudeSettingsCheck) findSettingsFiles(townRoot string) []staleSettings
 		if dirExists(polecatsDir) {
 			polecatEntries, _ := os.ReadDir(polecatsDir)
 			for _, pcEntry := range polecatEntries {
-				if !pcEntry.IsDir() || pcEntry.Name() == ".claude" {
+				// Skip .claude and warm pool slots (.warm-<id>)
+				if !pcEntry.IsDir() || strings.HasPrefix(pcEntry.Name(), ".") {
 					continue
 				}
 				// Check for wrong settings in both structures:
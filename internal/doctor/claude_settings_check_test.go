@@ -1082,3 +1082,23 @@ func TestClaudeSettingsCheck_TownRootSettingsWarnsInsteadOfKilling(t *testing.T)
 		t.Error("expected .claude directory at town root to be deleted")
 	}
 }
+
+func TestClaudeSettingsCheck_SkipsWarmSlots(t *testing.T) {
+	tmpDir := t.TempDir()
+	rigName := "testrig"
+
+	// Warm pool slots are pre-provisioned worktrees, not polecats
+	warmSettings := filepath.Join(tmpDir, rigName, "polecats", ".warm-abc123", rigName, ".claude", "settings.json")
+	createValidSettings(t, warmSettings)
+
+	check := NewClaudeSettingsCheck()
+	ctx := &CheckContext{TownRoot: tmpDir}
+
+	result := check.Run(ctx)
+
+	for _, d := range result.Details {
+		if strings.Contains(d, ".warm-") {
+			t.Errorf("warm slot treated as a polecat: %v", result.Details)
+		}
+	}
+}
@@ -261,7 +261,8 @@ func (c *PrimingCheck) checkRigPriming(townRoot string) []primingIssue {
 		if dirExists(polecatsDir) {
 			pcEntries, _ := os.ReadDir(polecatsDir)
 			for _, pcEntry := range pcEntries {
-				if !pcEntry.IsDir() || pcEntry.Name() == ".claude" {
+				// Skip .claude and warm pool slots (.warm-<id>)
+				if !pcEntry.IsDir() || strings.HasPrefix(pcEntry.Name(), ".") {
 					continue
 				}
 				polecatPath := filepath.Join(polecatsDir, pcEntry.Name())
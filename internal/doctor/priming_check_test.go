@@ -0,0 +1,38 @@
+package doctor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrimingCheck_SkipsWarmSlots(t *testing.T) {
+	townRoot := t.TempDir()
+	rigPath := filepath.Join(townRoot, "testrig")
+
+	for _, dir := range []string{
+		filepath.Join(rigPath, ".beads"),
+		filepath.Join(rigPath, "polecats", "Toast", ".beads"),
+		filepath.Join(rigPath, "polecats", ".warm-abc123"),
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{
+		filepath.Join(rigPath, ".beads", "PRIME.md"),
+		filepath.Join(rigPath, "polecats", "Toast", ".beads", "PRIME.md"),
+	} {
+		if err := os.WriteFile(f, []byte("# Gas Town\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	issues := NewPrimingCheck().checkRigPriming(townRoot)
+	for _, issue := range issues {
+		if strings.Contains(issue.location, "polecats/") {
+			t.Errorf("unexpected polecat issue: %+v", issue)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/steveyegge/gastown/internal/config"
 	"github.com/steveyegge/gastown/internal/constants"
+	"github.com/steveyegge/gastown/internal/git"
 )
 
 // RigIsGitRepoCheck verifies the rig has a valid mayor/rig git clone.
@@ -237,8 +238,9 @@ func (c *GitExcludeConfiguredCheck) Fix(ctx *CheckContext) error {
 	return nil
 }
 
-// HooksPathConfiguredCheck verifies all clones have core.hooksPath set to .githooks.
-// This ensures the pre-push hook blocks pushes to invalid branches (no internal PRs).
+// HooksPathConfiguredCheck verifies all clones use Gas Town's managed git hooks.
+// These stamp provenance trailers on agent commits and chain to the repo's
+// .githooks (e.g. the pre-push hook that blocks pushes to invalid branches).
 type HooksPathConfiguredCheck struct {
 	FixableCheck
 	unconfiguredClones []string
@@ -250,7 +252,7 @@ func NewHooksPathConfiguredCheck() *HooksPathConfiguredCheck {
 		FixableCheck: FixableCheck{
 			BaseCheck: BaseCheck{
 				CheckName:        "hooks-path-configured",
-				CheckDescription: "Check core.hooksPath is set for all clones",
+				CheckDescription: "Check managed git hooks are installed for all clones",
 				CheckCategory:    CategoryRig,
 			},
 		},
@@ -307,15 +309,7 @@ func (c *HooksPathConfiguredCheck) Run(ctx *CheckContext) *CheckResult {
 			continue
 		}
 
-		// Check core.hooksPath
-		cmd := exec.Command("git", "-C", clonePath, "config", "--get", "core.hooksPath")
-		output, err := cmd.Output()
-		if err != nil || strings.TrimSpace(string(output)) != ".githooks" {
-			// Get relative path for cleaner output
-			relPath, _ := filepath.Rel(rigPath, clonePath)
-			if relPath == "" {
-				relPath = clonePath
-			}
+		if !git.HooksPathConfigured(clonePath) {
 			c.unconfiguredClones = append(c.unconfiguredClones, clonePath)
 		}
 	}
@@ -347,11 +341,10 @@ func (c *HooksPathConfiguredCheck) Run(ctx *CheckContext) *CheckResult {
 	}
 }
 
-// Fix configures core.hooksPath for all unconfigured clones.
+// Fix installs the managed hooks for all unconfigured clones.
 func (c *HooksPathConfiguredCheck) Fix(ctx *CheckContext) error {
 	for _, clonePath := range c.unconfiguredClones {
-		cmd := exec.Command("git", "-C", clonePath, "config", "core.hooksPath", ".githooks")
-		if err := cmd.Run(); err != nil {
+		if err := git.ConfigureHooksPath(clonePath); err != nil {
 			return fmt.Errorf("failed to configure hooks for %s: %w", clonePath, err)
 		}
 	}
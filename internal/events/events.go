@@ -64,10 +64,16 @@ const (
 	TypePatrolComplete   = "patrol_complete"
 
 	// Merge queue events (emitted by refinery)
-	TypeMergeStarted = "merge_started"
-	TypeMerged       = "merged"
-	TypeMergeFailed  = "merge_failed"
-	TypeMergeSkipped = "merge_skipped"
+	TypeMergeStarted  = "merge_started"
+	TypeMerged        = "merged"
+	TypeMergeFailed   = "merge_failed"
+	TypeMergeSkipped  = "merge_skipped"
+	TypeMergeReverted = "merge_reverted"
+
+	// Policy guard events (emitted by gt tap guard policy)
+	TypeGuardBlocked = "guard_blocked"
+	TypeGuardWarned  = "guard_warned"
+	TypeGuardFailed  = "guard_failed" // payload or policy unreadable; call blocked
 )
 
 // EventsFile is the name of the raw events log.
@@ -200,7 +206,7 @@ func BootPayload(rig string, agents []string) map[string]interface{} {
 // mrID: merge request ID
 // worker: polecat name that submitted the work
 // branch: source branch being merged
-// reason: failure reason (for merge_failed/merge_skipped events)
+// reason: failure reason (for merge_failed/merge_skipped events) or revert reason
 func MergePayload(mrID, worker, branch, reason string) map[string]interface{} {
 	p := map[string]interface{}{
 		"mr":     mrID,
@@ -213,6 +219,21 @@ func MergePayload(mrID, worker, branch, reason string) map[string]interface{} {
 	return p
 }
 
+// GuardPayload creates a payload for policy guard events.
+// rule: name of the policy rule that matched (empty for guard_failed)
+// tool: Claude Code tool being invoked (e.g., "Bash", "Edit")
+// check: which check fired ("command", "path", "branch", "git"), or for
+// guard_failed what could not be read ("payload", "policy")
+// detail: the offending command, path or branch, or the error
+func GuardPayload(rule, tool, check, detail string) map[string]interface{} {
+	return map[string]interface{}{
+		"rule":   rule,
+		"tool":   tool,
+		"check":  check,
+		"detail": detail,
+	}
+}
+
 // PatrolPayload creates a payload for patrol start/complete events.
 func PatrolPayload(rig string, polecatCount int, message string) map[string]interface{} {
 	p := map[string]interface{}{
@@ -365,6 +365,12 @@ func (c *Curator) generateSummary(event *events.Event) string {
 		}
 		return "Merge failed"
 
+	case events.TypeMergeReverted:
+		if mr, ok := event.Payload["mr"].(string); ok {
+			return fmt.Sprintf("Reverted %s", mr)
+		}
+		return "Merge reverted"
+
 	case events.TypeSessionDeath:
 		session, _ := event.Payload["session"].(string)
 		reason, _ := event.Payload["reason"].(string)
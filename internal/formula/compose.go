@@ -0,0 +1,376 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Composition
+//
+// A formula can build on others instead of copying their steps:
+//
+//	formula = "shiny-secure"
+//	extends = "shiny"              # or ["shiny", ...]
+//
+//	[[include]]
+//	formula = "security-audit"
+//	prefix = "sec"                 # steps become sec.<id>
+//	after = "implement"            # included steps start after implement
+//	before = "submit"              # and finish before submit
+//
+//	[[steps]]
+//	id = "review"                  # same id: overrides the inherited step
+//	needs = ["implement", "sec.scan"]
+//
+// Flatten resolves all of this into a plain formula. Parents are merged in
+// order, included steps are added under their prefix, then the formula's own
+// steps override inherited ones field by field (title, description, needs,
+// verify, parallel, agent) or are appended. Vars, inputs and prompts merge
+// by name with the child winning; legs, templates and aspects merge by id;
+// placeholders are combined.
+
+// FormulaRefs names one or more formulas. In TOML it may be a string or an
+// array of strings.
+type FormulaRefs []string
+
+// UnmarshalTOML accepts both `extends = "a"` and `extends = ["a", "b"]`.
+func (r *FormulaRefs) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*r = FormulaRefs{v}
+	case []any:
+		refs := make(FormulaRefs, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("formula reference must be a string, got %T", item)
+			}
+			refs = append(refs, s)
+		}
+		*r = refs
+	default:
+		return fmt.Errorf("formula reference must be a string or array, got %T", v)
+	}
+	return nil
+}
+
+// Include adds another workflow formula's steps to this one.
+type Include struct {
+	Formula string `toml:"formula"` // Name of the included formula
+	Prefix  string `toml:"prefix"`  // Included step ids become <prefix>.<id>
+	After   string `toml:"after"`   // Step the included steps start after
+	Before  string `toml:"before"`  // Step that waits for the included steps
+}
+
+// ErrNotFound is returned (wrapped) by loaders that don't have a formula.
+var ErrNotFound = errors.New("not found")
+
+// Loader finds formulas by name when resolving composition.
+type Loader interface {
+	Load(name string) (*Formula, error)
+}
+
+// DirLoader loads <name>.formula.toml from the first directory that has it.
+type DirLoader []string
+
+// Load implements Loader.
+func (d DirLoader) Load(name string) (*Formula, error) {
+	for _, dir := range d {
+		path := filepath.Join(dir, name+".formula.toml")
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		return parseFile(path)
+	}
+	return nil, fmt.Errorf("formula %q %w", name, ErrNotFound)
+}
+
+// Loaders tries each loader in turn, so composition can reach several
+// kinds of formula source (directories, packs).
+type Loaders []Loader
+
+// Load implements Loader.
+func (ls Loaders) Load(name string) (*Formula, error) {
+	for _, l := range ls {
+		f, err := l.Load(name)
+		if !errors.Is(err, ErrNotFound) {
+			return f, err
+		}
+	}
+	return nil, fmt.Errorf("formula %q %w", name, ErrNotFound)
+}
+
+// IsComposed reports whether the formula extends or includes others and
+// must be flattened before use.
+func (f *Formula) IsComposed() bool {
+	return len(f.Extends) > 0 || len(f.Include) > 0
+}
+
+// Flatten resolves extends and include into a standalone, validated formula.
+// Formulas that are not composed are returned as is. A formula that
+// (indirectly) extends or includes itself is an error.
+func (f *Formula) Flatten(l Loader) (*Formula, error) {
+	if !f.IsComposed() {
+		return f, nil
+	}
+	flat, err := flatten(f, l, []string{f.Name})
+	if err != nil {
+		return nil, err
+	}
+	flat.inferType()
+	if err := flat.Validate(); err != nil {
+		return nil, fmt.Errorf("flattened %s: %w", f.Name, err)
+	}
+	return flat, nil
+}
+
+// flatten merges f over its parents and includes. stack holds the names
+// being resolved, for cycle detection.
+func flatten(f *Formula, l Loader, stack []string) (*Formula, error) {
+	load := func(name string) (*Formula, error) {
+		for _, seen := range stack {
+			if seen == name {
+				return nil, fmt.Errorf("formula composition cycle: %s -> %s", strings.Join(stack, " -> "), name)
+			}
+		}
+		g, err := l.Load(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if !g.IsComposed() {
+			return g, nil
+		}
+		return flatten(g, l, append(stack, name))
+	}
+
+	flat := &Formula{}
+	included := make([][]string, len(f.Include))
+	for _, parent := range f.Extends {
+		p, err := load(parent)
+		if err != nil {
+			return nil, err
+		}
+		flat.merge(p)
+		flat.Included = append(flat.Included, p.Included...)
+	}
+
+	for i, inc := range f.Include {
+		if inc.Formula == "" {
+			return nil, fmt.Errorf("%s: include missing required formula field", f.Name)
+		}
+		g, err := load(inc.Formula)
+		if err != nil {
+			return nil, err
+		}
+		if len(g.Steps) == 0 {
+			return nil, fmt.Errorf("%s: include %s: only workflow steps can be included", f.Name, inc.Formula)
+		}
+		flat.Included = append(flat.Included, inc.Formula)
+		flat.Included = append(flat.Included, g.Included...)
+		for _, step := range prefixSteps(g.Steps, inc.Prefix) {
+			if flat.GetStep(step.ID) != nil {
+				return nil, fmt.Errorf("%s: include %s: duplicate step id: %s (set a prefix)", f.Name, inc.Formula, step.ID)
+			}
+			flat.Steps = append(flat.Steps, step)
+			included[i] = append(included[i], step.ID)
+		}
+		for name, v := range g.Vars {
+			if _, ok := flat.Vars[name]; !ok {
+				if flat.Vars == nil {
+					flat.Vars = make(map[string]Var)
+				}
+				flat.Vars[name] = v
+			}
+		}
+		flat.addPlaceholders(g.Placeholders)
+	}
+
+	flat.merge(f)
+	flat.Extends = nil
+	flat.Include = nil
+
+	// Wire included steps in once every step they can be placed around exists
+	for i, inc := range f.Include {
+		if err := flat.insertInclude(inc, included[i]); err != nil {
+			return nil, fmt.Errorf("%s: include %s: %w", f.Name, inc.Formula, err)
+		}
+	}
+	return flat, nil
+}
+
+// merge lays g over f: g's scalars win when set, collections merge by key.
+func (f *Formula) merge(g *Formula) {
+	if g.Name != "" {
+		f.Name = g.Name
+	}
+	if g.Description != "" {
+		f.Description = g.Description
+	}
+	if g.Type != "" {
+		f.Type = g.Type
+	}
+	if g.Version != 0 {
+		f.Version = g.Version
+	}
+	if g.Output != nil {
+		f.Output = g.Output
+	}
+	if g.Synthesis != nil {
+		f.Synthesis = g.Synthesis
+	}
+	if g.Matrix != nil {
+		f.Matrix = g.Matrix
+	}
+	f.Inputs = mergeMap(f.Inputs, g.Inputs)
+	f.Prompts = mergeMap(f.Prompts, g.Prompts)
+	f.Vars = mergeMap(f.Vars, g.Vars)
+	f.addPlaceholders(g.Placeholders)
+
+	for _, step := range g.Steps {
+		step.Needs = slices.Clone(step.Needs)
+		if existing := f.GetStep(step.ID); existing != nil {
+			existing.override(step)
+		} else {
+			f.Steps = append(f.Steps, step)
+		}
+	}
+	for _, leg := range g.Legs {
+		if existing := f.GetLeg(leg.ID); existing != nil {
+			*existing = leg
+		} else {
+			f.Legs = append(f.Legs, leg)
+		}
+	}
+	for _, tmpl := range g.Template {
+		if existing := f.GetTemplate(tmpl.ID); existing != nil {
+			*existing = tmpl
+		} else {
+			f.Template = append(f.Template, tmpl)
+		}
+	}
+	for _, aspect := range g.Aspects {
+		if existing := f.GetAspect(aspect.ID); existing != nil {
+			*existing = aspect
+		} else {
+			f.Aspects = append(f.Aspects, aspect)
+		}
+	}
+}
+
+// addPlaceholders adds the names f doesn't list yet.
+func (f *Formula) addPlaceholders(names []string) {
+	for _, name := range names {
+		if !slices.Contains(f.Placeholders, name) {
+			f.Placeholders = append(f.Placeholders, name)
+		}
+	}
+}
+
+// override replaces the fields an overriding step sets. `needs = []`
+// clears inherited dependencies and `parallel = false` an inherited
+// parallel; leaving them out keeps them.
+func (s *Step) override(o Step) {
+	if o.Title != "" {
+		s.Title = o.Title
+	}
+	if o.Description != "" {
+		s.Description = o.Description
+	}
+	if o.Needs != nil {
+		s.Needs = o.Needs
+	}
+	if o.Verify != nil {
+		s.Verify = o.Verify
+	}
+	if o.Parallel != nil {
+		s.Parallel = o.Parallel
+	}
+	if o.Agent != "" || o.RoleAgent != "" {
+		s.Agent, s.RoleAgent = o.Agent, o.RoleAgent
+	}
+}
+
+// insertInclude places an include's steps (ids) between its after and
+// before steps: its first steps need after, and before needs its last steps.
+func (f *Formula) insertInclude(inc Include, ids []string) error {
+	own := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		own[id] = true
+	}
+
+	if inc.After != "" {
+		if f.GetStep(inc.After) == nil {
+			return fmt.Errorf("after references unknown step: %s", inc.After)
+		}
+		for _, id := range ids {
+			step := f.GetStep(id)
+			if !hasOwnNeed(step.Needs, own) {
+				step.Needs = append(step.Needs, inc.After)
+			}
+		}
+	}
+
+	if inc.Before != "" {
+		target := f.GetStep(inc.Before)
+		if target == nil {
+			return fmt.Errorf("before references unknown step: %s", inc.Before)
+		}
+		needed := make(map[string]bool)
+		for _, id := range ids {
+			for _, need := range f.GetStep(id).Needs {
+				needed[need] = true
+			}
+		}
+		for _, id := range ids {
+			if !needed[id] {
+				target.Needs = append(target.Needs, id)
+			}
+		}
+	}
+	return nil
+}
+
+// hasOwnNeed reports whether any of needs is one of the include's own steps.
+func hasOwnNeed(needs []string, own map[string]bool) bool {
+	for _, need := range needs {
+		if own[need] {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixSteps copies steps, renaming ids and needs to <prefix>.<id>.
+func prefixSteps(steps []Step, prefix string) []Step {
+	out := make([]Step, len(steps))
+	for i, step := range steps {
+		out[i] = step
+		if prefix == "" {
+			continue
+		}
+		out[i].ID = prefix + "." + step.ID
+		out[i].Needs = make([]string, len(step.Needs))
+		for j, need := range step.Needs {
+			out[i].Needs[j] = prefix + "." + need
+		}
+	}
+	return out
+}
+
+// mergeMap returns base with over's entries laid on top.
+func mergeMap[V any](base, over map[string]V) map[string]V {
+	if len(over) == 0 {
+		return base
+	}
+	if base == nil {
+		base = make(map[string]V, len(over))
+	}
+	for k, v := range over {
+		base[k] = v
+	}
+	return base
+}
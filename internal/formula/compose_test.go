@@ -0,0 +1,342 @@
+package formula
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFormulas writes name -> content as <name>.formula.toml into a temp dir.
+func writeFormulas(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name+".formula.toml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const composeBase = `
+formula = "base"
+type = "workflow"
+description = "Base workflow"
+
+[vars.feature]
+description = "The feature"
+required = true
+
+[[steps]]
+id = "design"
+title = "Design"
+
+[[steps]]
+id = "implement"
+title = "Implement"
+needs = ["design"]
+
+[[steps]]
+id = "submit"
+title = "Submit"
+needs = ["implement"]
+`
+
+const composeAudit = `
+formula = "audit"
+type = "workflow"
+
+[vars.severity]
+default = "high"
+
+[[steps]]
+id = "scan"
+title = "Scan"
+
+[[steps]]
+id = "report"
+title = "Report"
+needs = ["scan"]
+`
+
+func TestFlatten_ExtendsAndInclude(t *testing.T) {
+	dir := writeFormulas(t, map[string]string{
+		"base":  composeBase,
+		"audit": composeAudit,
+		"child": `
+formula = "child"
+extends = "base"
+description = "Base plus an audit"
+
+[[include]]
+formula = "audit"
+prefix = "sec"
+after = "implement"
+before = "submit"
+
+[[steps]]
+id = "implement"
+title = "Implement carefully"
+
+[[steps]]
+id = "docs"
+title = "Write docs"
+needs = ["implement"]
+`,
+	})
+
+	f, err := ParseFile(filepath.Join(dir, "child.formula.toml"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if f.Name != "child" || f.Type != TypeWorkflow || f.Description != "Base plus an audit" {
+		t.Errorf("flattened header = %q/%q/%q", f.Name, f.Type, f.Description)
+	}
+	if f.IsComposed() {
+		t.Error("flattened formula should not be composed")
+	}
+	if !reflect.DeepEqual(f.Included, []string{"audit"}) {
+		t.Errorf("Included = %v, want [audit]", f.Included)
+	}
+
+	want := map[string][]string{
+		"design":     nil,
+		"implement":  {"design"},
+		"submit":     {"implement", "sec.report"},
+		"sec.scan":   {"implement"},
+		"sec.report": {"sec.scan"},
+		"docs":       {"implement"},
+	}
+	if len(f.Steps) != len(want) {
+		t.Fatalf("steps = %v, want %d", f.GetAllIDs(), len(want))
+	}
+	for id, needs := range want {
+		step := f.GetStep(id)
+		if step == nil {
+			t.Errorf("missing step %s", id)
+			continue
+		}
+		if len(step.Needs) != 0 || len(needs) != 0 {
+			if !reflect.DeepEqual(step.Needs, needs) {
+				t.Errorf("%s.Needs = %v, want %v", id, step.Needs, needs)
+			}
+		}
+	}
+	if got := f.GetStep("implement").Title; got != "Implement carefully" {
+		t.Errorf("override title = %q", got)
+	}
+	if f.Vars["feature"].Required != true || f.Vars["severity"].Default != "high" {
+		t.Errorf("vars not inherited: %v", f.Vars)
+	}
+
+	order, err := f.TopologicalSort()
+	if err != nil {
+		t.Fatalf("TopologicalSort: %v", err)
+	}
+	if order[len(order)-1] != "submit" && order[len(order)-1] != "docs" {
+		t.Errorf("order = %v", order)
+	}
+}
+
+func TestFlatten_ExtendsArray(t *testing.T) {
+	dir := writeFormulas(t, map[string]string{
+		"base": composeBase,
+		"secure": `
+formula = "secure"
+extends = ["base"]
+type = "workflow"
+
+[compose]
+aspects = ["security-audit"]
+`,
+	})
+
+	f, err := ParseFile(filepath.Join(dir, "secure.formula.toml"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	if len(f.Steps) != 3 {
+		t.Errorf("steps = %v, want base's 3", f.GetAllIDs())
+	}
+}
+
+func TestFlatten_EncodeRoundTrip(t *testing.T) {
+	dir := writeFormulas(t, map[string]string{
+		"base":  composeBase,
+		"audit": composeAudit,
+		"child": `
+formula = "child"
+extends = "base"
+
+[[include]]
+formula = "audit"
+prefix = "sec"
+after = "implement"
+before = "submit"
+`,
+	})
+
+	f, err := ParseFile(filepath.Join(dir, "child.formula.toml"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	var buf strings.Builder
+	if err := f.Encode(&buf); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	g, err := Parse([]byte(buf.String()))
+	if err != nil {
+		t.Fatalf("Parse(encoded): %v\n%s", err, buf.String())
+	}
+	if g.IsComposed() {
+		t.Errorf("encoded formula still composed:\n%s", buf.String())
+	}
+	if g.Name != "child" || g.Type != TypeWorkflow {
+		t.Errorf("encoded header = %q/%q", g.Name, g.Type)
+	}
+	if !reflect.DeepEqual(g.GetAllIDs(), f.GetAllIDs()) {
+		t.Errorf("encoded steps = %v, want %v", g.GetAllIDs(), f.GetAllIDs())
+	}
+	if got := g.GetStep("submit").Needs; !reflect.DeepEqual(got, []string{"implement", "sec.report"}) {
+		t.Errorf("submit.Needs = %v", got)
+	}
+	if g.Vars["severity"].Default != "high" {
+		t.Errorf("vars not encoded: %v", g.Vars)
+	}
+}
+
+func TestFlatten_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a": "formula = \"a\"\nextends = \"b\"\n",
+				"b": "formula = \"b\"\nextends = \"a\"\n",
+			},
+			wantErr: "formula composition cycle: a -> b -> a",
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"a": "formula = \"a\"\n[[include]]\nformula = \"a\"\n",
+			},
+			wantErr: "cycle",
+		},
+		{
+			name:    "missing parent",
+			files:   map[string]string{"a": "formula = \"a\"\nextends = \"nope\"\n"},
+			wantErr: "not found",
+		},
+		{
+			name: "include collision",
+			files: map[string]string{
+				"base": composeBase,
+				"a":    "formula = \"a\"\nextends = \"base\"\n[[include]]\nformula = \"base\"\n",
+			},
+			wantErr: "duplicate step id: design",
+		},
+		{
+			name: "unknown insertion point",
+			files: map[string]string{
+				"audit": composeAudit,
+				"a":     "formula = \"a\"\ntype = \"workflow\"\n[[include]]\nformula = \"audit\"\nafter = \"nope\"\n",
+			},
+			wantErr: "after references unknown step: nope",
+		},
+		{
+			name: "override creates cycle",
+			files: map[string]string{
+				"base": composeBase,
+				"a":    "formula = \"a\"\nextends = \"base\"\n[[steps]]\nid = \"design\"\nneeds = [\"submit\"]\n",
+			},
+			wantErr: "cycle detected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFormulas(t, tt.files)
+			_, err := ParseFile(filepath.Join(dir, "a.formula.toml"))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFlatten_EmbeddedShiny(t *testing.T) {
+	for _, name := range []string{"shiny-secure", "shiny-enterprise"} {
+		f, err := ParseFile(filepath.Join("formulas", name+".formula.toml"))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(f.Steps) != 5 || f.Name != name {
+			t.Errorf("%s: flattened to %s with steps %v", name, f.Name, f.GetAllIDs())
+		}
+	}
+}
+
+func TestFlatten_OverrideParallel(t *testing.T) {
+	dir := writeFormulas(t, map[string]string{
+		"fan": `
+formula = "fan"
+type = "workflow"
+
+[[steps]]
+id = "design"
+title = "Design"
+
+[[steps]]
+id = "backend"
+title = "Backend"
+needs = ["design"]
+parallel = true
+
+[[steps]]
+id = "frontend"
+title = "Frontend"
+needs = ["design"]
+parallel = true
+
+[[steps]]
+id = "docs"
+title = "Docs"
+needs = ["design"]
+`,
+		"child": `
+formula = "child"
+extends = "fan"
+
+[[steps]]
+id = "backend"
+parallel = false
+
+[[steps]]
+id = "docs"
+parallel = true
+
+[[steps]]
+id = "frontend"
+title = "Frontend, carefully"
+`,
+	})
+
+	f, err := ParseFile(filepath.Join(dir, "child.formula.toml"))
+	if err != nil {
+		t.Fatalf("ParseFile: %v", err)
+	}
+	for id, want := range map[string]bool{"design": false, "backend": false, "frontend": true, "docs": true} {
+		if got := f.GetStep(id).IsParallel(); got != want {
+			t.Errorf("%s parallel = %v, want %v", id, got, want)
+		}
+	}
+	if got := f.FanOut([]string{"backend", "frontend", "docs"}, false); !reflect.DeepEqual(got, []string{"frontend", "docs"}) {
+		t.Errorf("FanOut = %v, want [frontend docs]", got)
+	}
+}
@@ -114,6 +114,10 @@
 //	ready := f.ReadySteps(completed)
 //	// Returns: ["build"] (test is done, build can run)
 //
+// A convoy's synthesis becomes ready, as "synthesis", once its legs are
+// completed. Simulate walks a formula with ReadySteps to estimate its
+// wall-clock time on a limited number of polecats.
+//
 // # Embedded Formulas
 //
 // The package includes embedded formula files that can be provisioned
@@ -0,0 +1,260 @@
+package formula
+
+import "sort"
+
+// Node kinds in a formula graph.
+const (
+	NodeStep      = "step"
+	NodeTemplate  = "template"
+	NodeLeg       = "leg"
+	NodeAspect    = "aspect"
+	NodeSynthesis = "synthesis"
+)
+
+// SynthesisID is the ID a convoy's synthesis goes by in graphs and
+// ReadySteps.
+const SynthesisID = "synthesis"
+
+// Graph is a formula's dependency graph. Edges point from a dependency to
+// the node that needs it.
+type Graph struct {
+	Name  string
+	Nodes []GraphNode
+	Edges []GraphEdge
+}
+
+// GraphNode is a step, template, leg, aspect or synthesis.
+type GraphNode struct {
+	ID    string
+	Title string
+	Kind  string
+}
+
+// GraphEdge says To needs From.
+type GraphEdge struct {
+	From string
+	To   string
+}
+
+// GraphAnalysis summarizes the shape of a formula graph.
+type GraphAnalysis struct {
+	// Width is the maximum parallelism: the most nodes that can be in
+	// flight at once, none depending (even indirectly) on another.
+	Width int
+
+	// Levels groups nodes by the length of the longest chain before them;
+	// every node in a level can start once the previous levels are done.
+	Levels [][]string
+
+	// CriticalPath is the longest dependency chain. No schedule finishes
+	// in fewer rounds than its length.
+	CriticalPath []string
+
+	// RedundantEdges are dependencies already implied by a longer path.
+	RedundantEdges []GraphEdge
+
+	// Unreachable nodes can never become ready: they are in or behind a
+	// cycle, or need a node that doesn't exist.
+	Unreachable []string
+}
+
+// Graph builds the formula's dependency graph. Convoy synthesis depends on
+// its depends_on legs, or on every leg if none are listed.
+func (f *Formula) Graph() *Graph {
+	g := &Graph{Name: f.Name}
+	switch f.Type {
+	case TypeWorkflow:
+		for _, step := range f.Steps {
+			g.Nodes = append(g.Nodes, GraphNode{ID: step.ID, Title: step.Title, Kind: NodeStep})
+			for _, need := range step.Needs {
+				g.Edges = append(g.Edges, GraphEdge{From: need, To: step.ID})
+			}
+		}
+	case TypeExpansion:
+		for _, tmpl := range f.Template {
+			g.Nodes = append(g.Nodes, GraphNode{ID: tmpl.ID, Title: tmpl.Title, Kind: NodeTemplate})
+			for _, need := range tmpl.Needs {
+				g.Edges = append(g.Edges, GraphEdge{From: need, To: tmpl.ID})
+			}
+		}
+	case TypeConvoy:
+		for _, leg := range f.Legs {
+			g.Nodes = append(g.Nodes, GraphNode{ID: leg.ID, Title: leg.Title, Kind: NodeLeg})
+		}
+		if f.Synthesis != nil {
+			g.Nodes = append(g.Nodes, GraphNode{ID: SynthesisID, Title: f.Synthesis.Title, Kind: NodeSynthesis})
+			deps := f.Synthesis.DependsOn
+			if len(deps) == 0 {
+				for _, leg := range f.Legs {
+					deps = append(deps, leg.ID)
+				}
+			}
+			for _, dep := range deps {
+				g.Edges = append(g.Edges, GraphEdge{From: dep, To: SynthesisID})
+			}
+		}
+	case TypeAspect:
+		for _, aspect := range f.Aspects {
+			g.Nodes = append(g.Nodes, GraphNode{ID: aspect.ID, Title: aspect.Title, Kind: NodeAspect})
+		}
+	}
+	return g
+}
+
+// Node returns the node with the given ID, or nil.
+func (g *Graph) Node(id string) *GraphNode {
+	for i := range g.Nodes {
+		if g.Nodes[i].ID == id {
+			return &g.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// Analyze computes parallelism width, levels, the critical path, redundant
+// edges and unreachable nodes.
+func (g *Graph) Analyze() *GraphAnalysis {
+	a := &GraphAnalysis{}
+
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+	}
+	n := len(g.Nodes)
+	succ := make([][]int, n)
+	pred := make([][]int, n)
+	broken := make([]bool, n) // needs a node that doesn't exist
+	for _, e := range g.Edges {
+		to, ok := index[e.To]
+		if !ok {
+			continue
+		}
+		from, ok := index[e.From]
+		if !ok {
+			broken[to] = true
+			continue
+		}
+		succ[from] = append(succ[from], to)
+		pred[to] = append(pred[to], from)
+	}
+
+	// Kahn's algorithm, tracking the longest chain into each node
+	inDegree := make([]int, n)
+	for i := range g.Nodes {
+		inDegree[i] = len(pred[i])
+	}
+	depth := make([]int, n)
+	prev := make([]int, n)
+	var queue, order []int
+	for i := range g.Nodes {
+		prev[i] = -1
+		if inDegree[i] == 0 && !broken[i] {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		order = append(order, u)
+		for _, v := range succ[u] {
+			if depth[u]+1 > depth[v] {
+				depth[v] = depth[u] + 1
+				prev[v] = u
+			}
+			inDegree[v]--
+			if inDegree[v] == 0 && !broken[v] {
+				queue = append(queue, v)
+			}
+		}
+	}
+
+	scheduled := make([]bool, n)
+	for _, u := range order {
+		scheduled[u] = true
+	}
+	for i, node := range g.Nodes {
+		if !scheduled[i] {
+			a.Unreachable = append(a.Unreachable, node.ID)
+		}
+	}
+	if len(order) == 0 {
+		return a
+	}
+
+	// Levels and critical path
+	last := order[0]
+	for _, u := range order {
+		for len(a.Levels) <= depth[u] {
+			a.Levels = append(a.Levels, nil)
+		}
+		a.Levels[depth[u]] = append(a.Levels[depth[u]], g.Nodes[u].ID)
+		if depth[u] > depth[last] {
+			last = u
+		}
+	}
+	for u := last; u != -1; u = prev[u] {
+		a.CriticalPath = append([]string{g.Nodes[u].ID}, a.CriticalPath...)
+	}
+
+	// Reachability over scheduled nodes, in reverse topological order
+	reach := make([]map[int]bool, n)
+	for i := len(order) - 1; i >= 0; i-- {
+		u := order[i]
+		reach[u] = make(map[int]bool)
+		for _, v := range succ[u] {
+			reach[u][v] = true
+			for w := range reach[v] {
+				reach[u][w] = true
+			}
+		}
+	}
+
+	// An edge u->v is redundant if another successor of u reaches v
+	for _, u := range order {
+		for _, v := range succ[u] {
+			for _, w := range succ[u] {
+				if w != v && reach[w][v] {
+					a.RedundantEdges = append(a.RedundantEdges, GraphEdge{From: g.Nodes[u].ID, To: g.Nodes[v].ID})
+					break
+				}
+			}
+		}
+	}
+
+	a.Width = len(order) - maxChainMatching(order, reach)
+	return a
+}
+
+// maxChainMatching returns the size of a maximum matching in the bipartite
+// graph u->v for every v reachable from u. By Dilworth's theorem, the
+// largest antichain (maximum parallelism) is the node count minus this.
+func maxChainMatching(order []int, reach []map[int]bool) int {
+	matchedTo := make(map[int]int) // right node -> left node
+	var try func(u int, seen map[int]bool) bool
+	try = func(u int, seen map[int]bool) bool {
+		targets := make([]int, 0, len(reach[u]))
+		for v := range reach[u] {
+			targets = append(targets, v)
+		}
+		sort.Ints(targets)
+		for _, v := range targets {
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+			if w, ok := matchedTo[v]; !ok || try(w, seen) {
+				matchedTo[v] = u
+				return true
+			}
+		}
+		return false
+	}
+
+	matching := 0
+	for _, u := range order {
+		if try(u, make(map[int]bool)) {
+			matching++
+		}
+	}
+	return matching
+}
@@ -0,0 +1,121 @@
+package formula
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// diamond: a -> (b, c) -> d, plus a redundant a -> d and a side chain c -> e.
+func diamondFormula() *Formula {
+	return &Formula{
+		Name: "diamond",
+		Type: TypeWorkflow,
+		Steps: []Step{
+			{ID: "a", Title: "Start"},
+			{ID: "b", Needs: []string{"a"}},
+			{ID: "c", Needs: []string{"a"}},
+			{ID: "d", Title: `Say "done"`, Needs: []string{"b", "c", "a"}},
+			{ID: "e", Needs: []string{"c"}},
+		},
+	}
+}
+
+func TestGraphAnalyze(t *testing.T) {
+	a := diamondFormula().Graph().Analyze()
+
+	if a.Width != 2 {
+		t.Errorf("Width = %d, want 2", a.Width)
+	}
+	if want := [][]string{{"a"}, {"b", "c"}, {"d", "e"}}; !reflect.DeepEqual(a.Levels, want) {
+		t.Errorf("Levels = %v, want %v", a.Levels, want)
+	}
+	if len(a.CriticalPath) != 3 || a.CriticalPath[0] != "a" {
+		t.Errorf("CriticalPath = %v, want a length-3 chain from a", a.CriticalPath)
+	}
+	if want := []GraphEdge{{From: "a", To: "d"}}; !reflect.DeepEqual(a.RedundantEdges, want) {
+		t.Errorf("RedundantEdges = %v, want %v", a.RedundantEdges, want)
+	}
+	if len(a.Unreachable) != 0 {
+		t.Errorf("Unreachable = %v, want none", a.Unreachable)
+	}
+}
+
+func TestGraphAnalyze_WidthIsAntichain(t *testing.T) {
+	// Two chains a1->a2->a3 and b1->b2->b3 with a3 also needing b1: every
+	// level has two nodes and no three nodes are mutually independent.
+	f := &Formula{Name: "chains", Type: TypeWorkflow, Steps: []Step{
+		{ID: "a1"}, {ID: "a2", Needs: []string{"a1"}}, {ID: "a3", Needs: []string{"a2", "b1"}},
+		{ID: "b1"}, {ID: "b2", Needs: []string{"b1"}}, {ID: "b3", Needs: []string{"b2"}},
+	}}
+	if w := f.Graph().Analyze().Width; w != 2 {
+		t.Errorf("Width = %d, want 2", w)
+	}
+}
+
+func TestGraphAnalyze_Unreachable(t *testing.T) {
+	g := &Graph{
+		Nodes: []GraphNode{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}},
+		Edges: []GraphEdge{{From: "b", To: "c"}, {From: "c", To: "b"}, {From: "missing", To: "d"}},
+	}
+	a := g.Analyze()
+	if want := []string{"b", "c", "d"}; !reflect.DeepEqual(a.Unreachable, want) {
+		t.Errorf("Unreachable = %v, want %v", a.Unreachable, want)
+	}
+}
+
+func TestGraph_Convoy(t *testing.T) {
+	f := &Formula{
+		Name:      "review",
+		Type:      TypeConvoy,
+		Legs:      []Leg{{ID: "sec"}, {ID: "perf"}},
+		Synthesis: &Synthesis{Title: "Combine"},
+	}
+	g := f.Graph()
+	if len(g.Nodes) != 3 || len(g.Edges) != 2 {
+		t.Fatalf("graph = %+v, want 2 legs feeding synthesis", g)
+	}
+	a := g.Analyze()
+	if a.Width != 2 || len(a.CriticalPath) != 2 {
+		t.Errorf("analysis = %+v", a)
+	}
+}
+
+func TestGraphRender(t *testing.T) {
+	g := diamondFormula().Graph()
+	a := g.Analyze()
+
+	dot, err := g.Render(FormatDOT, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`digraph "diamond" {`, `"a" -> "d" [style=dashed];`, `label="d\nSay \"done\""`, "// max parallelism: 2"} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("dot missing %q:\n%s", want, dot)
+		}
+	}
+
+	mermaid, err := g.Render(FormatMermaid, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"flowchart TD", `n3["d<br/>Say #quot;done#quot;"]`, "n0 -.-> n3", "class n0,", "%% critical path: 3"} {
+		if !strings.Contains(mermaid, want) {
+			t.Errorf("mermaid missing %q:\n%s", want, mermaid)
+		}
+	}
+
+	ascii, err := g.Render(FormatASCII, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1  * a: Start", "<- b, c, a", "redundant edges: a -> d"} {
+		if !strings.Contains(ascii, want) {
+			t.Errorf("ascii missing %q:\n%s", want, ascii)
+		}
+	}
+
+	if _, err := g.Render("svg", a); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
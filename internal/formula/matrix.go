@@ -0,0 +1,296 @@
+package formula
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MatrixCommandTimeout bounds a matrix command.
+const MatrixCommandTimeout = time.Minute
+
+// Matrix stamps an expansion formula's templates out once per item: each
+// item gets its own chain of steps, with {{item}} and {{index}} (1-based)
+// substituted in ids, titles and descriptions. Template ids without
+// {{item}} are prefixed with the item. The items come from exactly one of
+// Items, Var or Command.
+//
+//	[matrix]
+//	var = "services"
+//
+//	[matrix.fan_in]
+//	id = "report"
+//	title = "Summarize the upgrade across {{items}}"
+type Matrix struct {
+	Items   []string `toml:"items"`   // Literal items
+	Var     string   `toml:"var"`     // Variable holding items, comma or newline separated
+	Command string   `toml:"command"` // Command printing one item per line (sh -c)
+
+	// FanIn is an optional step that needs the last step of every chain.
+	// {{items}} in its text is the comma-separated item list.
+	FanIn *Template `toml:"fan_in"`
+}
+
+// matrixRefPattern matches the placeholders a matrix fills in.
+var matrixRefPattern = regexp.MustCompile(`\{\{\s*(item|index|items)\s*\}\}`)
+
+// validate checks a matrix against its formula's templates and vars.
+func (m *Matrix) validate(f *Formula) error {
+	sources := 0
+	for _, set := range []bool{len(m.Items) > 0, m.Var != "", m.Command != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return fmt.Errorf("matrix needs exactly one of items, var or command")
+	}
+	if m.Var != "" {
+		if _, ok := f.Vars[m.Var]; !ok {
+			return fmt.Errorf("matrix var %q is not declared in [vars]", m.Var)
+		}
+	}
+	if m.FanIn != nil {
+		if m.FanIn.ID == "" {
+			return fmt.Errorf("matrix fan_in missing required id field")
+		}
+		for _, tmpl := range f.Template {
+			if tmpl.ID == m.FanIn.ID {
+				return fmt.Errorf("matrix fan_in id %q is also a template id", m.FanIn.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// MatrixItems resolves the matrix items: the literal list, the matrix
+// variable's value (from vars, else its default), or the non-empty lines
+// the matrix command prints when run in dir. Items must be distinct.
+func (f *Formula) MatrixItems(ctx context.Context, dir string, vars map[string]string) ([]string, error) {
+	m := f.Matrix
+	if m == nil {
+		return nil, fmt.Errorf("formula %s has no matrix", f.Name)
+	}
+
+	var raw []string
+	switch {
+	case len(m.Items) > 0:
+		raw = m.Items
+	case m.Var != "":
+		value, ok := vars[m.Var]
+		if !ok {
+			value = f.Vars[m.Var].Default
+		}
+		raw = strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' })
+	case m.Command != "":
+		out, err := runMatrixCommand(ctx, dir, m.Command)
+		if err != nil {
+			return nil, err
+		}
+		raw = strings.Split(out, "\n")
+	}
+
+	var items []string
+	seen := make(map[string]bool)
+	for _, item := range raw {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		slug := matrixSlug(item)
+		if seen[slug] {
+			return nil, fmt.Errorf("matrix item %q is listed twice", item)
+		}
+		seen[slug] = true
+		items = append(items, item)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("matrix of formula %s has no items", f.Name)
+	}
+	return items, nil
+}
+
+func runMatrixCommand(ctx context.Context, dir, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, MatrixCommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("matrix command timed out after %s: %s", MatrixCommandTimeout, command)
+		}
+		return "", fmt.Errorf("matrix command %q: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// ExpandMatrix stamps the templates out over items into a workflow formula:
+// one chain of steps per item, plus the fan-in step if there is one.
+func (f *Formula) ExpandMatrix(items []string) (*Formula, error) {
+	if f.Matrix == nil {
+		return nil, fmt.Errorf("formula %s has no matrix", f.Name)
+	}
+
+	out := &Formula{
+		Name:        f.Name,
+		Description: f.Description,
+		Type:        TypeWorkflow,
+		Version:     f.Version,
+		Vars:        f.Vars,
+	}
+	var leaves []string
+	for i, item := range items {
+		chain := f.matrixChain(i, item)
+		out.Steps = append(out.Steps, chain...)
+		leaves = append(leaves, chainLeaves(chain)...)
+	}
+	if fan := f.Matrix.FanIn; fan != nil {
+		fill := matrixReplacer(items, 0, "")
+		out.Steps = append(out.Steps, Step{
+			ID:          fan.ID,
+			Title:       fill(fan.Title),
+			Description: fill(fan.Description),
+			Needs:       leaves,
+		})
+	}
+	if err := out.Validate(); err != nil {
+		return nil, fmt.Errorf("expanding matrix of %s: %w", f.Name, err)
+	}
+	return out, nil
+}
+
+// MatrixConvoy stamps the templates out over items as a convoy formula: a
+// leg per item whose description walks through its chain of steps, and the
+// fan-in step as the synthesis.
+func (f *Formula) MatrixConvoy(items []string) (*Formula, error) {
+	flat, err := f.ExpandMatrix(items)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Formula{
+		Name:        f.Name,
+		Description: f.Description,
+		Type:        TypeConvoy,
+		Version:     f.Version,
+		Vars:        f.Vars,
+		Prompts:     f.Prompts,
+	}
+	for i, item := range items {
+		chain := f.matrixChain(i, item)
+		var desc strings.Builder
+		for n, step := range chain {
+			fmt.Fprintf(&desc, "%d. %s\n", n+1, step.Title)
+			if step.Description != "" {
+				fmt.Fprintf(&desc, "   %s\n", strings.ReplaceAll(strings.TrimSpace(step.Description), "\n", "\n   "))
+			}
+		}
+		out.Legs = append(out.Legs, Leg{
+			ID:          matrixSlug(item),
+			Title:       fmt.Sprintf("%s: %s", f.Name, item),
+			Focus:       item,
+			Description: strings.TrimSpace(desc.String()),
+		})
+	}
+	if fan := f.Matrix.FanIn; fan != nil {
+		step := flat.GetStep(fan.ID)
+		out.Synthesis = &Synthesis{Title: step.Title, Description: step.Description}
+		for _, leg := range out.Legs {
+			out.Synthesis.DependsOn = append(out.Synthesis.DependsOn, leg.ID)
+		}
+	}
+	if err := out.Validate(); err != nil {
+		return nil, fmt.Errorf("expanding matrix of %s: %w", f.Name, err)
+	}
+	return out, nil
+}
+
+// matrixChain returns the steps for one matrix item.
+func (f *Formula) matrixChain(index int, item string) []Step {
+	fill := matrixReplacer(nil, index, item)
+	slug := matrixSlug(item)
+	id := func(tmplID string) string {
+		if matrixRefPattern.MatchString(tmplID) {
+			return matrixRefPattern.ReplaceAllStringFunc(tmplID, func(ref string) string {
+				if strings.Contains(ref, "item") {
+					return slug
+				}
+				return fill(ref)
+			})
+		}
+		return slug + "." + tmplID
+	}
+
+	chain := make([]Step, len(f.Template))
+	for i, tmpl := range f.Template {
+		step := Step{
+			ID:          id(tmpl.ID),
+			Title:       fill(tmpl.Title),
+			Description: fill(tmpl.Description),
+		}
+		for _, need := range tmpl.Needs {
+			step.Needs = append(step.Needs, id(need))
+		}
+		chain[i] = step
+	}
+	return chain
+}
+
+// matrixReplacer fills in {{item}}, {{index}} and {{items}}.
+func matrixReplacer(items []string, index int, item string) func(string) string {
+	return func(s string) string {
+		return matrixRefPattern.ReplaceAllStringFunc(s, func(ref string) string {
+			switch matrixRefPattern.FindStringSubmatch(ref)[1] {
+			case "item":
+				return item
+			case "index":
+				return strconv.Itoa(index + 1)
+			default:
+				return strings.Join(items, ", ")
+			}
+		})
+	}
+}
+
+// chainLeaves returns the steps of a chain that no other step needs.
+func chainLeaves(chain []Step) []string {
+	needed := make(map[string]bool)
+	for _, step := range chain {
+		for _, need := range step.Needs {
+			needed[need] = true
+		}
+	}
+	var leaves []string
+	for _, step := range chain {
+		if !needed[step.ID] {
+			leaves = append(leaves, step.ID)
+		}
+	}
+	return leaves
+}
+
+// matrixSlug turns an item into an id fragment: lower case, with anything
+// but letters, digits, '-' and '_' replaced by '-'.
+func matrixSlug(item string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, item)
+	return strings.Trim(slug, "-")
+}
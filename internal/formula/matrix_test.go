@@ -0,0 +1,162 @@
+package formula
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const matrixFormula = `
+formula = "upgrade"
+type = "expansion"
+
+[vars.services]
+description = "Services to upgrade"
+default = "api, Web UI"
+
+[vars.version]
+required = true
+
+[[template]]
+id = "bump"
+title = "Bump {{item}} to {{version}}"
+
+[[template]]
+id = "test"
+title = "Test {{item}} ({{index}})"
+needs = ["bump"]
+
+[matrix]
+var = "services"
+
+[matrix.fan_in]
+id = "report"
+title = "Report on {{items}}"
+`
+
+func TestExpandMatrix(t *testing.T) {
+	f, err := Parse([]byte(matrixFormula))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	items, err := f.MatrixItems(context.Background(), t.TempDir(), nil)
+	if err != nil {
+		t.Fatalf("MatrixItems: %v", err)
+	}
+	if want := []string{"api", "Web UI"}; !reflect.DeepEqual(items, want) {
+		t.Fatalf("items = %v, want %v", items, want)
+	}
+
+	flat, err := f.ExpandMatrix(items)
+	if err != nil {
+		t.Fatalf("ExpandMatrix: %v", err)
+	}
+	if flat.Type != TypeWorkflow {
+		t.Errorf("Type = %s, want workflow", flat.Type)
+	}
+	if want := []string{"api.bump", "api.test", "web-ui.bump", "web-ui.test", "report"}; !reflect.DeepEqual(flat.GetAllIDs(), want) {
+		t.Errorf("ids = %v, want %v", flat.GetAllIDs(), want)
+	}
+	test := flat.GetStep("web-ui.test")
+	if test.Title != "Test Web UI (2)" || !reflect.DeepEqual(test.Needs, []string{"web-ui.bump"}) {
+		t.Errorf("web-ui.test = %q needs %v", test.Title, test.Needs)
+	}
+	if got := flat.GetStep("api.bump").Title; got != "Bump api to {{version}}" {
+		t.Errorf("api.bump title = %q, want other vars left alone", got)
+	}
+	report := flat.GetStep("report")
+	if report.Title != "Report on api, Web UI" || !reflect.DeepEqual(report.Needs, []string{"api.test", "web-ui.test"}) {
+		t.Errorf("report = %q needs %v", report.Title, report.Needs)
+	}
+
+	convoy, err := f.MatrixConvoy(items)
+	if err != nil {
+		t.Fatalf("MatrixConvoy: %v", err)
+	}
+	if len(convoy.Legs) != 2 || convoy.Legs[1].ID != "web-ui" || !strings.Contains(convoy.Legs[1].Description, "2. Test Web UI (2)") {
+		t.Errorf("legs = %+v", convoy.Legs)
+	}
+	if convoy.Synthesis == nil || !reflect.DeepEqual(convoy.Synthesis.DependsOn, []string{"api", "web-ui"}) {
+		t.Errorf("synthesis = %+v", convoy.Synthesis)
+	}
+
+	// {{item}}, {{index}} and {{items}} aren't vars; the matrix var is used
+	if errs, warnings := f.Lint(); len(errs) != 0 || len(warnings) != 0 {
+		t.Errorf("Lint = %v, %v; want clean", errs, warnings)
+	}
+}
+
+func TestMatrixItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		matrix  Matrix
+		vars    map[string]string
+		want    []string
+		wantErr string
+	}{
+		{name: "items", matrix: Matrix{Items: []string{"a", "b"}}, want: []string{"a", "b"}},
+		{name: "var", matrix: Matrix{Var: "list"}, vars: map[string]string{"list": "x\ny,z"}, want: []string{"x", "y", "z"}},
+		{name: "command", matrix: Matrix{Command: "printf 'one\\n\\ntwo\\n'"}, want: []string{"one", "two"}},
+		{name: "command fails", matrix: Matrix{Command: "exit 3"}, wantErr: "exit status 3"},
+		{name: "duplicate", matrix: Matrix{Items: []string{"a b", "A-B"}}, wantErr: "listed twice"},
+		{name: "empty", matrix: Matrix{Var: "list"}, vars: map[string]string{"list": " , "}, wantErr: "no items"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Formula{Name: "m", Matrix: &tt.matrix, Vars: map[string]Var{"list": {}}}
+			got, err := f.MatrixItems(context.Background(), t.TempDir(), tt.vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MatrixItems: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("items = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatrixValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		toml    string
+		wantErr string
+	}{
+		{
+			name:    "two sources",
+			toml:    "[matrix]\nitems = [\"a\"]\ncommand = \"ls\"",
+			wantErr: "exactly one of items, var or command",
+		},
+		{
+			name:    "undeclared var",
+			toml:    "[matrix]\nvar = \"services\"",
+			wantErr: `matrix var "services" is not declared`,
+		},
+		{
+			name:    "fan-in clashes",
+			toml:    "[matrix]\nitems = [\"a\"]\n[matrix.fan_in]\nid = \"t\"",
+			wantErr: `fan_in id "t" is also a template id`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "formula = \"m\"\ntype = \"expansion\"\n[[template]]\nid = \"t\"\n" + tt.toml
+			_, err := Parse([]byte(data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	_, err := Parse([]byte("formula = \"w\"\ntype = \"workflow\"\n[[steps]]\nid = \"s\"\n[matrix]\nitems = [\"a\"]"))
+	if err == nil || !strings.Contains(err.Error(), "only allowed on expansion formulas") {
+		t.Errorf("workflow with matrix: err = %v", err)
+	}
+}
@@ -0,0 +1,307 @@
+package formula
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// Formula packs are git repositories of formulas shared across towns. Each
+// installed pack is checked out under <town>/.beads/formula-packs/<pack>,
+// and its commit is pinned in <town>/.beads/formula-packs.lock.json so every
+// checkout of the town runs the same formulas. Pack formulas are named
+// pack/name; a bare name finds a pack formula only when no local formula and
+// no other pack has it.
+const (
+	PacksDirName  = "formula-packs"
+	PackLockFile  = "formula-packs.lock.json"
+	packLockPerms = 0644
+)
+
+// PackLock records the installed packs. Stored in .beads/formula-packs.lock.json
+type PackLock struct {
+	Packs map[string]PackPin `json:"packs"` // pack name -> pin
+}
+
+// PackPin pins a pack to a commit of its repository.
+type PackPin struct {
+	URL    string `json:"url"`
+	Ref    string `json:"ref,omitempty"` // branch, tag or commit to follow on update (default: remote HEAD)
+	Commit string `json:"commit"`
+}
+
+// packNamePattern is what a pack name may look like.
+var packNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Packs manages the formula packs of a beads directory (normally the town's).
+type Packs struct {
+	beadsDir string
+}
+
+// NewPacks returns the packs installed under beadsDir.
+func NewPacks(beadsDir string) *Packs {
+	return &Packs{beadsDir: beadsDir}
+}
+
+// ParsePackSpec splits url[@ref] into its URL and ref. The user part of
+// scp-style URLs (git@host:repo) is not taken for a ref.
+func ParsePackSpec(spec string) (url, ref string) {
+	at := strings.LastIndex(spec, "@")
+	if at > strings.LastIndexAny(spec, "/:") {
+		return spec[:at], spec[at+1:]
+	}
+	return spec, ""
+}
+
+// PackNameFromURL derives a pack name from its repository URL: the last
+// path element without .git, lower-cased.
+func PackNameFromURL(url string) string {
+	name := strings.TrimRight(url, "/")
+	if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.ToLower(strings.TrimSuffix(name, ".git"))
+}
+
+// ValidatePackName checks that a pack name can be used as a namespace.
+func ValidatePackName(name string) error {
+	if !packNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid pack name %q (use lower-case letters, digits, '.', '_' and '-')", name)
+	}
+	return nil
+}
+
+// Path returns the checkout directory of a pack.
+func (p *Packs) Path(name string) string {
+	return filepath.Join(p.beadsDir, PacksDirName, name)
+}
+
+// FormulaDir returns the directory a pack keeps its formulas in: formulas/
+// or .beads/formulas/ if the repository has one, else its root.
+func (p *Packs) FormulaDir(name string) string {
+	root := p.Path(name)
+	for _, sub := range []string{"formulas", filepath.Join(".beads", "formulas")} {
+		if info, err := os.Stat(filepath.Join(root, sub)); err == nil && info.IsDir() {
+			return filepath.Join(root, sub)
+		}
+	}
+	return root
+}
+
+// LoadLock reads the lockfile. A missing lockfile is an empty lock.
+func (p *Packs) LoadLock() (*PackLock, error) {
+	data, err := os.ReadFile(filepath.Join(p.beadsDir, PackLockFile))
+	if os.IsNotExist(err) {
+		return &PackLock{Packs: make(map[string]PackPin)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", PackLockFile, err)
+	}
+
+	var lock PackLock
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", PackLockFile, err)
+	}
+	if lock.Packs == nil {
+		lock.Packs = make(map[string]PackPin)
+	}
+	return &lock, nil
+}
+
+func (p *Packs) saveLock(lock *PackLock) error {
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(p.beadsDir, PackLockFile), append(data, '\n'), packLockPerms)
+}
+
+// Install fetches a pack from url, checks out ref (default: the remote's
+// HEAD) and pins the commit in the lockfile. Installing a pack again under
+// the same name moves it to the new URL and ref.
+func (p *Packs) Install(name, url, ref string) (PackPin, error) {
+	if err := ValidatePackName(name); err != nil {
+		return PackPin{}, err
+	}
+	lock, err := p.LoadLock()
+	if err != nil {
+		return PackPin{}, err
+	}
+	if old, ok := lock.Packs[name]; ok && old.URL != url {
+		// A different repository under the same name: start over
+		if err := os.RemoveAll(p.Path(name)); err != nil {
+			return PackPin{}, fmt.Errorf("removing old checkout of %s: %w", name, err)
+		}
+	}
+
+	g, err := p.fetch(name, url)
+	if err != nil {
+		return PackPin{}, err
+	}
+	commit, err := resolvePackRef(g, ref)
+	if err != nil {
+		return PackPin{}, fmt.Errorf("pack %s: %w", name, err)
+	}
+	if err := g.Checkout(commit); err != nil {
+		return PackPin{}, fmt.Errorf("pack %s: checking out %s: %w", name, commit, err)
+	}
+
+	pin := PackPin{URL: url, Ref: ref, Commit: commit}
+	lock.Packs[name] = pin
+	return pin, p.saveLock(lock)
+}
+
+// Sync checks out every pack at its pinned commit, cloning packs that are
+// in the lockfile but not on disk (a fresh checkout of the town). Returns
+// the packs it had to fetch.
+func (p *Packs) Sync() ([]string, error) {
+	lock, err := p.LoadLock()
+	if err != nil {
+		return nil, err
+	}
+
+	var synced []string
+	for _, name := range sortedKeys(lock.Packs) {
+		pin := lock.Packs[name]
+		g := git.NewGit(p.Path(name))
+		if head, err := g.Rev("HEAD"); err == nil && head == pin.Commit {
+			continue
+		}
+		if g, err = p.fetch(name, pin.URL); err != nil {
+			return synced, err
+		}
+		if err := g.Checkout(pin.Commit); err != nil {
+			return synced, fmt.Errorf("pack %s: checking out pinned %s: %w", name, pin.Commit, err)
+		}
+		synced = append(synced, name)
+	}
+	return synced, nil
+}
+
+// Update fetches a pack, moves it to the newest commit of its ref and
+// re-pins it. Returns the old and new pins.
+func (p *Packs) Update(name string) (old, updated PackPin, err error) {
+	lock, err := p.LoadLock()
+	if err != nil {
+		return old, updated, err
+	}
+	old, ok := lock.Packs[name]
+	if !ok {
+		return old, updated, fmt.Errorf("pack %s is not installed", name)
+	}
+	updated, err = p.Install(name, old.URL, old.Ref)
+	return old, updated, err
+}
+
+// Installed returns the names of the installed packs, sorted.
+func (p *Packs) Installed() ([]string, error) {
+	lock, err := p.LoadLock()
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(lock.Packs), nil
+}
+
+// Formulas returns the formula names a pack provides, sorted.
+func (p *Packs) Formulas(name string) ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(p.FormulaDir(name), "*.formula.toml"))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		names[i] = strings.TrimSuffix(filepath.Base(path), ".formula.toml")
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Find returns the file of a pack formula. pack/name looks in that pack; a
+// bare name must be provided by exactly one pack.
+func (p *Packs) Find(name string) (string, error) {
+	if pack, formula, ok := strings.Cut(name, "/"); ok {
+		if err := ValidatePackName(pack); err != nil {
+			return "", err
+		}
+		path := filepath.Join(p.FormulaDir(pack), formula+".formula.toml")
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("formula %q %w in pack %s", formula, ErrNotFound, pack)
+		}
+		return path, nil
+	}
+
+	packs, err := p.Installed()
+	if err != nil {
+		return "", err
+	}
+	var found []string
+	for _, pack := range packs {
+		if _, err := os.Stat(filepath.Join(p.FormulaDir(pack), name+".formula.toml")); err == nil {
+			found = append(found, pack)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("formula %q %w in any pack", name, ErrNotFound)
+	case 1:
+		return filepath.Join(p.FormulaDir(found[0]), name+".formula.toml"), nil
+	default:
+		return "", fmt.Errorf("formula %q is in several packs (%s); use <pack>/%s", name, strings.Join(found, ", "), name)
+	}
+}
+
+// Load implements Loader, so composed formulas can extend or include pack
+// formulas.
+func (p *Packs) Load(name string) (*Formula, error) {
+	path, err := p.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	return parseFile(path)
+}
+
+// fetch clones a pack, or fetches it if it is already checked out.
+func (p *Packs) fetch(name, url string) (*git.Git, error) {
+	dest := p.Path(name)
+	g := git.NewGit(dest)
+	// Stat .git rather than asking git: the town itself is usually a repo
+	if _, err := os.Stat(filepath.Join(dest, ".git")); err == nil {
+		if err := g.Fetch("origin"); err != nil {
+			return nil, fmt.Errorf("fetching pack %s: %w", name, err)
+		}
+		return g, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", PacksDirName, err)
+	}
+	if err := git.NewGit(filepath.Dir(dest)).Clone(url, dest); err != nil {
+		_ = os.RemoveAll(dest)
+		return nil, fmt.Errorf("cloning pack %s from %s: %w", name, url, err)
+	}
+	return g, nil
+}
+
+// resolvePackRef returns the commit a ref names: a remote branch, a tag or
+// a commit. The empty ref is the remote's default branch.
+func resolvePackRef(g *git.Git, ref string) (string, error) {
+	candidates := []string{"origin/HEAD"}
+	if ref != "" {
+		candidates = []string{"origin/" + ref, ref}
+	}
+	for _, c := range candidates {
+		if commit, err := g.Rev(c + "^{commit}"); err == nil {
+			return commit, nil
+		}
+	}
+	if ref == "" {
+		return "", fmt.Errorf("remote has no default branch; give a ref with <url>@<ref>")
+	}
+	return "", fmt.Errorf("unknown ref %q", ref)
+}
@@ -0,0 +1,193 @@
+package formula
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// packRepo makes a git repository with the given formula files under
+// formulas/ and returns its file:// URL, and a func that commits more files
+// and optionally tags the result.
+func packRepo(t *testing.T, files map[string]string) (string, func(files map[string]string, tag string)) {
+	t.Helper()
+	dir := t.TempDir()
+	gitRun := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	commit := func(files map[string]string, tag string) {
+		t.Helper()
+		for name, content := range files {
+			path := filepath.Join(dir, "formulas", name+".formula.toml")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(files) > 0 {
+			gitRun("add", ".")
+			gitRun("commit", "-q", "-m", "formulas")
+		}
+		if tag != "" {
+			gitRun("tag", tag)
+		}
+	}
+
+	gitRun("init", "-q", "-b", "main")
+	gitRun("config", "user.email", "test@test.com")
+	gitRun("config", "user.name", "Test User")
+	commit(files, "")
+	return "file://" + dir, commit
+}
+
+func workflowTOML(name, title string) string {
+	return "formula = \"" + name + "\"\ntype = \"workflow\"\n[[steps]]\nid = \"a\"\ntitle = \"" + title + "\"\n"
+}
+
+func TestParsePackSpec(t *testing.T) {
+	tests := []struct {
+		spec, url, ref string
+	}{
+		{"https://example.com/acme/formulas.git", "https://example.com/acme/formulas.git", ""},
+		{"https://example.com/acme/formulas.git@v2", "https://example.com/acme/formulas.git", "v2"},
+		{"git@github.com:acme/formulas.git", "git@github.com:acme/formulas.git", ""},
+		{"git@github.com:acme/formulas.git@main", "git@github.com:acme/formulas.git", "main"},
+		{"file:///srv/team-formulas@abc123", "file:///srv/team-formulas", "abc123"},
+	}
+	for _, tt := range tests {
+		url, ref := ParsePackSpec(tt.spec)
+		if url != tt.url || ref != tt.ref {
+			t.Errorf("ParsePackSpec(%q) = %q, %q; want %q, %q", tt.spec, url, ref, tt.url, tt.ref)
+		}
+	}
+	if got := PackNameFromURL("git@github.com:acme/Team-Formulas.git"); got != "team-formulas" {
+		t.Errorf("PackNameFromURL = %q, want team-formulas", got)
+	}
+}
+
+func TestPacksInstallUpdateSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	url, commit := packRepo(t, map[string]string{"release": workflowTOML("release", "v1")})
+	commit(nil, "v1")
+	packs := NewPacks(t.TempDir())
+
+	pin, err := packs.Install("acme", url, "")
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if len(pin.Commit) != 40 {
+		t.Errorf("pinned commit = %q", pin.Commit)
+	}
+	lock, err := packs.LoadLock()
+	if err != nil || lock.Packs["acme"] != pin {
+		t.Fatalf("lock = %+v, %v; want acme pinned to %+v", lock, err, pin)
+	}
+
+	path, err := packs.Find("acme/release")
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if f, err := ParseFile(path); err != nil || f.Steps[0].Title != "v1" {
+		t.Fatalf("ParseFile(%s) = %v", path, err)
+	}
+
+	// A new commit upstream changes nothing until update
+	commit(map[string]string{"release": workflowTOML("release", "v2")}, "")
+	f, _ := packs.Load("release")
+	if f.Steps[0].Title != "v1" {
+		t.Errorf("before update: title = %q, want v1", f.Steps[0].Title)
+	}
+	old, updated, err := packs.Update("acme")
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if old.Commit != pin.Commit || updated.Commit == pin.Commit {
+		t.Errorf("Update: %s -> %s, want a move from %s", old.Commit, updated.Commit, pin.Commit)
+	}
+	if f, _ := packs.Load("release"); f.Steps[0].Title != "v2" {
+		t.Errorf("after update: title = %q, want v2", f.Steps[0].Title)
+	}
+
+	// A pack pinned to a tag stays there on update
+	if _, err := packs.Install("acme", url, "v1"); err != nil {
+		t.Fatalf("Install @v1: %v", err)
+	}
+	if _, _, err := packs.Update("acme"); err != nil {
+		t.Fatalf("Update @v1: %v", err)
+	}
+	if f, _ := packs.Load("acme/release"); f.Steps[0].Title != "v1" {
+		t.Errorf("pinned to v1: title = %q", f.Steps[0].Title)
+	}
+
+	// Sync restores a missing checkout at the pinned commit
+	if err := os.RemoveAll(packs.Path("acme")); err != nil {
+		t.Fatal(err)
+	}
+	synced, err := packs.Sync()
+	if err != nil || len(synced) != 1 {
+		t.Fatalf("Sync = %v, %v", synced, err)
+	}
+	if f, _ := packs.Load("acme/release"); f == nil || f.Steps[0].Title != "v1" {
+		t.Errorf("after sync: %+v", f)
+	}
+	if synced, _ := packs.Sync(); len(synced) != 0 {
+		t.Errorf("second Sync = %v, want nothing to do", synced)
+	}
+}
+
+func TestPacksNamespaces(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	urlA, _ := packRepo(t, map[string]string{"release": workflowTOML("release", "a"), "lint": workflowTOML("lint", "a")})
+	urlB, _ := packRepo(t, map[string]string{"release": workflowTOML("release", "b")})
+	packs := NewPacks(t.TempDir())
+	for name, url := range map[string]string{"a": urlA, "b": urlB} {
+		if _, err := packs.Install(name, url, ""); err != nil {
+			t.Fatalf("Install %s: %v", name, err)
+		}
+	}
+
+	if _, err := packs.Find("release"); err == nil || !strings.Contains(err.Error(), "use <pack>/release") {
+		t.Errorf("ambiguous Find: err = %v", err)
+	}
+	if f, err := packs.Load("b/release"); err != nil || f.Steps[0].Title != "b" {
+		t.Errorf("Load b/release = %v", err)
+	}
+	if _, err := packs.Find("lint"); err != nil {
+		t.Errorf("unique bare name: %v", err)
+	}
+	if _, err := packs.Find("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("missing: err = %v, want ErrNotFound", err)
+	}
+	if _, err := packs.Find("../x"); err == nil {
+		t.Error("Find(../x) should reject the pack name")
+	}
+
+	// Composition reaches pack formulas through Loaders
+	child, err := Parse([]byte("formula = \"child\"\nextends = \"b/release\"\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	flat, err := child.Flatten(Loaders{DirLoader{t.TempDir()}, packs})
+	if err != nil {
+		t.Fatalf("Flatten: %v", err)
+	}
+	if len(flat.Steps) != 1 || flat.Steps[0].Title != "b" {
+		t.Errorf("flattened steps = %+v", flat.Steps)
+	}
+}
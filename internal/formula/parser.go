@@ -1,22 +1,99 @@
 package formula
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 )
 
-// ParseFile reads and parses a formula.toml file.
+// ParseError is a formula that failed to parse or validate, with the file
+// and, for TOML errors, the line and column it failed at.
+type ParseError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %v", e.Path, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseFile reads and parses a formula.toml file. Composed formulas are
+// flattened, resolving the formulas they extend or include from the same
+// directory.
 func ParseFile(path string) (*Formula, error) {
+	return ParseFileWith(path, DirLoader{filepath.Dir(path)})
+}
+
+// ParseFileWith is ParseFile resolving composition through l.
+func ParseFileWith(path string, l Loader) (*Formula, error) {
+	f, err := parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	flat, err := f.Flatten(l)
+	if err != nil {
+		var pe *ParseError
+		if errors.As(err, &pe) {
+			return nil, err
+		}
+		return nil, &ParseError{Path: path, Err: err}
+	}
+	return flat, nil
+}
+
+// tomlLinePattern matches TOML decode errors, which report a line and the
+// key being decoded but aren't toml.ParseError.
+var tomlLinePattern = regexp.MustCompile(`toml: line (\d+) \(last key "([^"]*)"\): (.*)$`)
+
+// parseFile reads and parses one formula file without flattening it.
+// Errors are *ParseError.
+func parseFile(path string) (*Formula, error) {
 	data, err := os.ReadFile(path) //nolint:gosec // G304: path is from trusted formula directory
 	if err != nil {
 		return nil, fmt.Errorf("reading formula file: %w", err)
 	}
-	return Parse(data)
+	f, err := Parse(data)
+	if err != nil {
+		pe := &ParseError{Path: path, Err: err}
+		var te toml.ParseError
+		if errors.As(err, &te) {
+			pe.Line, pe.Column = te.Position.Line, te.Position.Col
+			if te.Message != "" {
+				pe.Err = errors.New(te.Message)
+			}
+		} else if m := tomlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+			// Decode (type) errors only carry the line in their text
+			pe.Line, _ = strconv.Atoi(m[1])
+			pe.Err = fmt.Errorf("%s: %s", m[2], m[3])
+		}
+		return nil, pe
+	}
+	return f, nil
 }
 
-// Parse parses formula.toml content from bytes.
+// Parse parses formula.toml content from bytes. A composed formula (one
+// with extends or include) can only be fully validated once flattened, so
+// Parse checks just its name; call Flatten before using it.
 func Parse(data []byte) (*Formula, error) {
 	var f Formula
 	if _, err := toml.Decode(string(data), &f); err != nil {
@@ -26,6 +103,13 @@ func Parse(data []byte) (*Formula, error) {
 	// Infer type from content if not explicitly set
 	f.inferType()
 
+	if f.IsComposed() {
+		if f.Name == "" {
+			return nil, fmt.Errorf("formula field is required")
+		}
+		return &f, nil
+	}
+
 	if err := f.Validate(); err != nil {
 		return nil, err
 	}
@@ -33,6 +117,15 @@ func Parse(data []byte) (*Formula, error) {
 	return &f, nil
 }
 
+// Encode writes the formula as formula.toml content. A flattened formula
+// encodes as a standalone formula that Parse reads back without composition.
+func (f *Formula) Encode(w io.Writer) error {
+	if err := toml.NewEncoder(w).Encode(f); err != nil {
+		return fmt.Errorf("encoding formula %s: %w", f.Name, err)
+	}
+	return nil
+}
+
 // inferType sets the formula type based on content when not explicitly set.
 func (f *Formula) inferType() {
 	if f.Type != "" {
@@ -62,6 +155,13 @@ func (f *Formula) Validate() error {
 		return fmt.Errorf("invalid formula type %q (must be convoy, workflow, expansion, or aspect)", f.Type)
 	}
 
+	if err := f.validateVars(); err != nil {
+		return err
+	}
+	if f.Matrix != nil && f.Type != TypeExpansion {
+		return fmt.Errorf("matrix is only allowed on expansion formulas")
+	}
+
 	// Type-specific validation
 	switch f.Type {
 	case TypeConvoy:
@@ -92,6 +192,9 @@ func (f *Formula) validateConvoy() error {
 			return fmt.Errorf("duplicate leg id: %s", leg.ID)
 		}
 		seen[leg.ID] = true
+		if leg.Agent != "" && leg.RoleAgent != "" {
+			return fmt.Errorf("leg %q sets both agent and role_agent", leg.ID)
+		}
 	}
 
 	// Validate synthesis depends_on references valid legs
@@ -121,6 +224,9 @@ func (f *Formula) validateWorkflow() error {
 			return fmt.Errorf("duplicate step id: %s", step.ID)
 		}
 		seen[step.ID] = true
+		if step.Agent != "" && step.RoleAgent != "" {
+			return fmt.Errorf("step %q sets both agent and role_agent", step.ID)
+		}
 	}
 
 	// Validate step needs references
@@ -132,6 +238,16 @@ func (f *Formula) validateWorkflow() error {
 		}
 	}
 
+	// Validate exit criteria
+	for _, step := range f.Steps {
+		if step.Verify == nil {
+			continue
+		}
+		if err := step.Verify.Validate(); err != nil {
+			return fmt.Errorf("step %q verify: %w", step.ID, err)
+		}
+	}
+
 	// Check for cycles
 	if err := f.checkCycles(); err != nil {
 		return err
@@ -166,12 +282,29 @@ func (f *Formula) validateExpansion() error {
 		}
 	}
 
+	if f.Matrix != nil {
+		return f.Matrix.validate(f)
+	}
 	return nil
 }
 
 func (f *Formula) validateAspect() error {
-	if len(f.Aspects) == 0 {
-		return fmt.Errorf("aspect formula requires at least one aspect")
+	if len(f.Aspects) == 0 && len(f.Advice) == 0 {
+		return fmt.Errorf("aspect formula requires at least one aspect or advice")
+	}
+
+	for i, adv := range f.Advice {
+		if adv.Target == "" {
+			return fmt.Errorf("advice %d missing required target field", i+1)
+		}
+		if adv.Around == nil {
+			continue
+		}
+		for _, tmpl := range slices.Concat(adv.Around.Before, adv.Around.After) {
+			if tmpl.ID == "" {
+				return fmt.Errorf("advice on %q has a step missing required id field", adv.Target)
+			}
+		}
 	}
 
 	// Check aspect IDs are unique
@@ -323,7 +456,8 @@ func (f *Formula) TopologicalSort() ([]string, error) {
 }
 
 // ReadySteps returns steps that have no unmet dependencies.
-// completed is a set of step IDs that have been completed.
+// completed is a set of step IDs that have been completed. A convoy's
+// synthesis (ID "synthesis", as in Graph) is ready once its legs are.
 func (f *Formula) ReadySteps(completed map[string]bool) []string {
 	var ready []string
 
@@ -367,6 +501,22 @@ func (f *Formula) ReadySteps(completed map[string]bool) []string {
 				ready = append(ready, leg.ID)
 			}
 		}
+		if f.Synthesis != nil && !completed[SynthesisID] {
+			deps := f.Synthesis.DependsOn
+			if len(deps) == 0 {
+				deps = f.GetAllIDs()
+			}
+			allMet := true
+			for _, dep := range deps {
+				if !completed[dep] {
+					allMet = false
+					break
+				}
+			}
+			if allMet {
+				ready = append(ready, SynthesisID)
+			}
+		}
 	case TypeAspect:
 		// All aspects are ready unless already completed
 		for _, aspect := range f.Aspects {
@@ -379,6 +529,24 @@ func (f *Formula) ReadySteps(completed map[string]bool) []string {
 	return ready
 }
 
+// FanOut picks, from a set of ready step IDs, the steps to hand to other
+// agents: the ones marked parallel, or every ready step when all is set.
+// A lone ready step is never fanned out; its agent just works it.
+func (f *Formula) FanOut(ready []string, all bool) []string {
+	if len(ready) < 2 {
+		return nil
+	}
+	var out []string
+	for _, id := range ready {
+		if all {
+			out = append(out, id)
+		} else if step := f.GetStep(id); step != nil && step.IsParallel() {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
 // GetStep returns a step by ID, or nil if not found.
 func (f *Formula) GetStep(id string) *Step {
 	for i := range f.Steps {
@@ -389,6 +557,100 @@ func (f *Formula) GetStep(id string) *Step {
 	return nil
 }
 
+// StepForTitle returns the step an instantiated step bead was created from,
+// matched by title, or nil if no step or more than one matches. Molecules
+// record each step's formula step ID (see MatchSteps); titles are only for
+// molecules made before that.
+func (f *Formula) StepForTitle(title string) *Step {
+	if steps := f.StepsForTitle(title); len(steps) == 1 {
+		return steps[0]
+	}
+	return nil
+}
+
+// StepsForTitle returns the steps whose title is title or, failing that,
+// whose title matches it with {{var}} placeholders standing for any text.
+func (f *Formula) StepsForTitle(title string) []*Step {
+	var exact, matched []*Step
+	for i := range f.Steps {
+		switch {
+		case f.Steps[i].Title == title:
+			exact = append(exact, &f.Steps[i])
+		case titlePattern(f.Steps[i].Title).MatchString(title):
+			matched = append(matched, &f.Steps[i])
+		}
+	}
+	if len(exact) > 0 {
+		return exact
+	}
+	return matched
+}
+
+// MatchSteps maps a molecule's step beads, given by title in creation
+// order, to the IDs of the formula steps they were made from. Titles are
+// compared with vars (then declared defaults) substituted; repeated titles
+// pair up in order. A bead that matches no step, or several equally, maps
+// to "".
+func (f *Formula) MatchSteps(titles []string, vars map[string]string) []string {
+	ids := make([]string, len(titles))
+	used := make(map[string]bool)
+	for i := range f.Steps {
+		want := varPattern.ReplaceAllStringFunc(f.Steps[i].Title, func(ref string) string {
+			name := strings.TrimSpace(ref[2 : len(ref)-2])
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			if v, ok := f.Vars[name]; ok && v.Default != "" {
+				return v.Default
+			}
+			return ref
+		})
+		for j, title := range titles {
+			if ids[j] == "" && title == want {
+				ids[j] = f.Steps[i].ID
+				used[f.Steps[i].ID] = true
+				break
+			}
+		}
+	}
+
+	// Titles with placeholders no value was known for
+	for j, title := range titles {
+		if ids[j] != "" {
+			continue
+		}
+		var candidate string
+		for i := range f.Steps {
+			if used[f.Steps[i].ID] || !titlePattern(f.Steps[i].Title).MatchString(title) {
+				continue
+			}
+			if candidate != "" {
+				candidate = ""
+				break
+			}
+			candidate = f.Steps[i].ID
+		}
+		if candidate != "" {
+			ids[j] = candidate
+			used[candidate] = true
+		}
+	}
+	return ids
+}
+
+// titlePattern matches the titles a step title can be instantiated as:
+// {{var}} placeholders match any text.
+func titlePattern(title string) *regexp.Regexp {
+	parts := varPattern.Split(title, -1)
+	for j, part := range parts {
+		parts[j] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// varPattern matches a {{var}} placeholder.
+var varPattern = regexp.MustCompile(`\{\{[^}]*\}\}`)
+
 // GetLeg returns a leg by ID, or nil if not found.
 func (f *Formula) GetLeg(id string) *Leg {
 	for i := range f.Legs {
@@ -1,6 +1,10 @@
 package formula
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -353,3 +357,132 @@ title = "Leg 3"
 		t.Errorf("ReadySteps({leg1}) = %v, want 2 legs", ready)
 	}
 }
+
+func TestParse_AspectAdvice(t *testing.T) {
+	data := []byte(`
+formula = "audit"
+type = "aspect"
+
+[[advice]]
+target = "implement"
+[advice.around]
+[[advice.around.before]]
+id = "{step.id}-prescan"
+title = "Prescan {step.id}"
+
+[[pointcuts]]
+glob = "implement"
+`)
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(f.Advice) != 1 || f.Advice[0].Around == nil || len(f.Advice[0].Around.Before) != 1 {
+		t.Errorf("Advice = %+v, want one before step", f.Advice)
+	}
+	if len(f.Pointcuts) != 1 || f.Pointcuts[0].Glob != "implement" {
+		t.Errorf("Pointcuts = %+v", f.Pointcuts)
+	}
+
+	if _, err := Parse([]byte("formula = \"audit\"\ntype = \"aspect\"\n[[advice]]\n")); err == nil {
+		t.Error("expected error for advice without target")
+	}
+}
+
+func TestParseFile_ErrorPosition(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"syntax", "formula = \"x\"\n[[steps]\nid = \"a\"\n", "end of table array name"},
+		{"type", "formula = \"x\"\n\n[[steps]]\nid = \"a\"\nneeds = \"b\"\n", ":5: steps.needs: incompatible types"},
+		{"invalid", "formula = \"x\"\n[[steps]]\nid = \"a\"\nneeds = [\"b\"]\n", `.formula.toml: step "a" needs unknown step: b`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".formula.toml")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := ParseFile(path)
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Path != path {
+				t.Fatalf("ParseFile error = %v, want *ParseError for %s", err, path)
+			}
+			if tt.name != "invalid" && pe.Line == 0 {
+				t.Errorf("error %q has no line", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFanOut(t *testing.T) {
+	parallel := true
+	f := &Formula{
+		Name: "fan",
+		Type: TypeWorkflow,
+		Steps: []Step{
+			{ID: "design"},
+			{ID: "backend", Needs: []string{"design"}, Parallel: &parallel},
+			{ID: "frontend", Needs: []string{"design"}, Parallel: &parallel},
+			{ID: "docs", Needs: []string{"design"}},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		ready []string
+		all   bool
+		want  []string
+	}{
+		{"parallel only", []string{"backend", "frontend", "docs"}, false, []string{"backend", "frontend"}},
+		{"all enabled", []string{"backend", "docs"}, true, []string{"backend", "docs"}},
+		{"lone ready step", []string{"backend"}, true, nil},
+		{"nothing parallel", []string{"docs", "design"}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.FanOut(tt.ready, tt.all); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("FanOut(%v, %v) = %v, want %v", tt.ready, tt.all, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Agents(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "cheap"
+type = "workflow"
+[[steps]]
+id = "load"
+agent = "claude-haiku"
+[[steps]]
+id = "implement"
+role_agent = "crew"
+needs = ["load"]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if f.Steps[0].Agent != "claude-haiku" || f.Steps[1].RoleAgent != "crew" {
+		t.Errorf("Steps = %+v", f.Steps)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"step", "formula = \"x\"\n[[steps]]\nid = \"a\"\nagent = \"codex\"\nrole_agent = \"crew\"\n"},
+		{"leg", "formula = \"x\"\ntype = \"convoy\"\n[[legs]]\nid = \"a\"\nagent = \"codex\"\nrole_agent = \"crew\"\n"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt.content)); err == nil || !strings.Contains(err.Error(), "both agent and role_agent") {
+			t.Errorf("%s: err = %v, want both agent and role_agent error", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package formula
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Graph output formats.
+const (
+	FormatASCII   = "ascii"
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// Render draws the graph in the given format, with the analysis included:
+// as a summary for ascii, as comments for dot and mermaid so the output
+// stays valid input for their tools. The critical path is highlighted and
+// redundant edges are dashed.
+func (g *Graph) Render(format string, a *GraphAnalysis) (string, error) {
+	switch format {
+	case FormatASCII, "":
+		return g.renderASCII(a), nil
+	case FormatDOT:
+		return g.renderDOT(a), nil
+	case FormatMermaid:
+		return g.renderMermaid(a), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q (must be ascii, dot or mermaid)", format)
+	}
+}
+
+// Summary returns the analysis as lines of text.
+func (a *GraphAnalysis) Summary() []string {
+	lines := []string{
+		fmt.Sprintf("max parallelism: %d", a.Width),
+		fmt.Sprintf("critical path: %d (%s)", len(a.CriticalPath), strings.Join(a.CriticalPath, " -> ")),
+	}
+	if len(a.RedundantEdges) > 0 {
+		edges := make([]string, len(a.RedundantEdges))
+		for i, e := range a.RedundantEdges {
+			edges[i] = e.From + " -> " + e.To
+		}
+		lines = append(lines, "redundant edges: "+strings.Join(edges, ", "))
+	}
+	if len(a.Unreachable) > 0 {
+		lines = append(lines, "unreachable: "+strings.Join(a.Unreachable, ", "))
+	}
+	return lines
+}
+
+func (g *Graph) renderASCII(a *GraphAnalysis) string {
+	critical := a.criticalSet()
+	needs := make(map[string][]string)
+	for _, e := range g.Edges {
+		needs[e.To] = append(needs[e.To], e.From)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", g.Name)
+	levels := a.Levels
+	if len(a.Unreachable) > 0 {
+		levels = append(levels, a.Unreachable)
+	}
+	for i, level := range levels {
+		label := fmt.Sprintf("%d", i+1)
+		if i == len(a.Levels) {
+			label = "!"
+		}
+		for j, id := range level {
+			prefix := "   "
+			if j == 0 {
+				prefix = fmt.Sprintf("%-3s", label)
+			}
+			mark := " "
+			if critical[id] {
+				mark = "*"
+			}
+			line := fmt.Sprintf("%s%s %s", prefix, mark, id)
+			if node := g.Node(id); node != nil && node.Title != "" {
+				line += ": " + node.Title
+			}
+			if deps := needs[id]; len(deps) > 0 {
+				line += "  <- " + strings.Join(deps, ", ")
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if len(a.Unreachable) > 0 {
+		b.WriteString("\n(* = critical path, ! = unreachable)\n")
+	} else {
+		b.WriteString("\n(* = critical path)\n")
+	}
+	for _, line := range a.Summary() {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+func (g *Graph) renderDOT(a *GraphAnalysis) string {
+	critical := a.criticalEdges()
+	redundant := a.redundantSet()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(g.Name))
+	for _, line := range a.Summary() {
+		fmt.Fprintf(&b, "  // %s\n", line)
+	}
+	b.WriteString("  rankdir=TB;\n  node [shape=box];\n")
+	onPath := a.criticalSet()
+	for _, n := range g.Nodes {
+		label := n.ID
+		if n.Title != "" {
+			label += "\n" + n.Title
+		}
+		attrs := "label=" + dotQuote(label)
+		if onPath[n.ID] {
+			attrs += ", penwidth=2, color=red"
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.ID), attrs)
+	}
+	for _, e := range g.Edges {
+		var attrs []string
+		if critical[e] {
+			attrs = append(attrs, "penwidth=2", "color=red")
+		}
+		if redundant[e] {
+			attrs = append(attrs, "style=dashed")
+		}
+		line := fmt.Sprintf("  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if len(attrs) > 0 {
+			line += " [" + strings.Join(attrs, ", ") + "]"
+		}
+		b.WriteString(line + ";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func (g *Graph) renderMermaid(a *GraphAnalysis) string {
+	critical := a.criticalEdges()
+	redundant := a.redundantSet()
+
+	// Mermaid IDs must be simple; map formula IDs to n0, n1, ...
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+	for _, line := range a.Summary() {
+		fmt.Fprintf(&b, "  %%%% %s\n", line)
+	}
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+		label := n.ID
+		if n.Title != "" {
+			label += "<br/>" + n.Title
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[n.ID], mermaidEscape(label))
+	}
+
+	var criticalLinks []string
+	link := 0
+	for _, e := range g.Edges {
+		from, to := ids[e.From], ids[e.To]
+		if from == "" || to == "" {
+			continue
+		}
+		arrow := "-->"
+		if redundant[e] {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", from, arrow, to)
+		if critical[e] {
+			criticalLinks = append(criticalLinks, fmt.Sprintf("%d", link))
+		}
+		link++
+	}
+
+	if len(a.CriticalPath) > 0 {
+		nodes := make([]string, len(a.CriticalPath))
+		for i, id := range a.CriticalPath {
+			nodes[i] = ids[id]
+		}
+		b.WriteString("  classDef critical stroke:#d33,stroke-width:3px\n")
+		fmt.Fprintf(&b, "  class %s critical\n", strings.Join(nodes, ","))
+	}
+	if len(criticalLinks) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#d33,stroke-width:3px\n", strings.Join(criticalLinks, ","))
+	}
+	return b.String()
+}
+
+func (a *GraphAnalysis) criticalSet() map[string]bool {
+	set := make(map[string]bool, len(a.CriticalPath))
+	for _, id := range a.CriticalPath {
+		set[id] = true
+	}
+	return set
+}
+
+func (a *GraphAnalysis) criticalEdges() map[GraphEdge]bool {
+	set := make(map[GraphEdge]bool)
+	for i := 1; i < len(a.CriticalPath); i++ {
+		set[GraphEdge{From: a.CriticalPath[i-1], To: a.CriticalPath[i]}] = true
+	}
+	return set
+}
+
+func (a *GraphAnalysis) redundantSet() map[GraphEdge]bool {
+	set := make(map[GraphEdge]bool, len(a.RedundantEdges))
+	for _, e := range a.RedundantEdges {
+		set[e] = true
+	}
+	return set
+}
+
+// dotQuote quotes a DOT identifier or label.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// mermaidEscape makes text safe inside a quoted mermaid label.
+func mermaidEscape(s string) string {
+	return strings.ReplaceAll(s, `"`, "#quot;")
+}
@@ -0,0 +1,366 @@
+package formula
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Simulation defaults.
+const (
+	DefaultSimDuration    = 30 * time.Minute
+	DefaultSimMaxAttempts = 3
+	DefaultSimRuns        = 1000
+)
+
+// SimOptions configures Simulate.
+type SimOptions struct {
+	// Concurrency is the number of polecats; 0 means one per ready step.
+	Concurrency int
+
+	// Durations maps step IDs to how long they take; others take
+	// DefaultDuration (DefaultSimDuration if zero).
+	Durations       map[string]time.Duration
+	DefaultDuration time.Duration
+
+	// FailRates maps step IDs to the chance an attempt fails; others fail
+	// at DefaultFailRate. A failed attempt takes the step's full duration
+	// and is retried, up to MaxAttempts (DefaultSimMaxAttempts if zero)
+	// before the run fails.
+	FailRates       map[string]float64
+	DefaultFailRate float64
+	MaxAttempts     int
+
+	// Runs is how many randomized runs to make when anything can fail
+	// (DefaultSimRuns if zero). Seed makes them reproducible.
+	Runs int
+	Seed uint64
+}
+
+// SimResult is what Simulate reports.
+type SimResult struct {
+	// Ideal is the wall-clock time when nothing fails, and Schedule the
+	// steps' slots in that run, in start order.
+	Ideal    time.Duration
+	Schedule []SimSlot
+
+	// Work is the sum of all step durations: the wall-clock time with a
+	// single polecat.
+	Work time.Duration
+
+	// Peak is the most polecats busy at once in the ideal run.
+	Peak int
+
+	// Runs, Succeeded and the wall-clock percentiles over the succeeded
+	// runs. With no failure rates there is one run, the ideal one.
+	Runs      int
+	Succeeded int
+	Mean      time.Duration
+	P50       time.Duration
+	P90       time.Duration
+
+	// Attempts is the mean number of attempts per step over all runs.
+	Attempts map[string]float64
+
+	// Blockers ranks steps by the work waiting on them, most first.
+	Blockers []SimBlocker
+}
+
+// SimSlot is one step's place in a simulated run.
+type SimSlot struct {
+	ID      string
+	Polecat int // 1-based
+	Start   time.Duration
+	End     time.Duration
+}
+
+// SimBlocker is a step and the downstream work it holds up.
+type SimBlocker struct {
+	ID             string
+	Downstream     int           // steps that need it, directly or not
+	DownstreamWork time.Duration // their total duration
+	Critical       bool          // on the longest chain by duration
+}
+
+// Simulate walks the formula's dependency graph with ReadySteps, running
+// ready steps on a limited number of polecats with the given durations and
+// failure rates, and reports the expected wall-clock time and the steps
+// that hold up the most work.
+func (f *Formula) Simulate(opts SimOptions) (*SimResult, error) {
+	if opts.DefaultDuration <= 0 {
+		opts.DefaultDuration = DefaultSimDuration
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultSimMaxAttempts
+	}
+	if opts.Runs <= 0 {
+		opts.Runs = DefaultSimRuns
+	}
+
+	g := f.Graph()
+	if len(g.Nodes) == 0 {
+		return nil, fmt.Errorf("formula %s has nothing to simulate", f.Name)
+	}
+	if a := g.Analyze(); len(a.Unreachable) > 0 {
+		return nil, fmt.Errorf("formula %s has steps that never become ready: %v", f.Name, a.Unreachable)
+	}
+	for id, rate := range opts.FailRates {
+		if g.Node(id) == nil {
+			return nil, fmt.Errorf("failure rate for unknown step %s", id)
+		}
+		if rate < 0 || rate >= 1 {
+			return nil, fmt.Errorf("failure rate for %s must be in [0, 1), got %g", id, rate)
+		}
+	}
+	for id, d := range opts.Durations {
+		if g.Node(id) == nil {
+			return nil, fmt.Errorf("duration for unknown step %s", id)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("duration for %s must be positive, got %s", id, d)
+		}
+	}
+	if opts.DefaultFailRate < 0 || opts.DefaultFailRate >= 1 {
+		return nil, fmt.Errorf("default failure rate must be in [0, 1), got %g", opts.DefaultFailRate)
+	}
+
+	sim := &simulator{f: f, opts: opts}
+	res := &SimResult{Attempts: make(map[string]float64)}
+	for _, n := range g.Nodes {
+		res.Work += sim.duration(n.ID)
+	}
+
+	ideal := sim.run(nil)
+	res.Ideal, res.Schedule, res.Peak = ideal.end, ideal.slots, ideal.peak
+
+	runs := 1
+	if sim.canFail() {
+		runs = opts.Runs
+	}
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))
+	var ends []time.Duration
+	attempts := make(map[string]int)
+	for range runs {
+		r := ideal
+		if sim.canFail() {
+			r = sim.run(rng)
+		}
+		for id, n := range r.attempts {
+			attempts[id] += n
+		}
+		if r.ok {
+			ends = append(ends, r.end)
+		}
+	}
+	for id, n := range attempts {
+		res.Attempts[id] = float64(n) / float64(runs)
+	}
+	res.Runs, res.Succeeded = runs, len(ends)
+	if len(ends) > 0 {
+		slices.Sort(ends)
+		var sum time.Duration
+		for _, d := range ends {
+			sum += d
+		}
+		res.Mean = sum / time.Duration(len(ends))
+		res.P50 = percentile(ends, 50)
+		res.P90 = percentile(ends, 90)
+	}
+
+	res.Blockers = sim.blockers(g)
+	return res, nil
+}
+
+type simulator struct {
+	f    *Formula
+	opts SimOptions
+}
+
+type simRun struct {
+	end      time.Duration
+	slots    []SimSlot
+	peak     int
+	attempts map[string]int
+	ok       bool
+}
+
+func (s *simulator) duration(id string) time.Duration {
+	if d, ok := s.opts.Durations[id]; ok {
+		return d
+	}
+	return s.opts.DefaultDuration
+}
+
+func (s *simulator) failRate(id string) float64 {
+	if r, ok := s.opts.FailRates[id]; ok {
+		return r
+	}
+	return s.opts.DefaultFailRate
+}
+
+func (s *simulator) canFail() bool {
+	if s.opts.DefaultFailRate > 0 {
+		return true
+	}
+	for _, r := range s.opts.FailRates {
+		if r > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// run simulates one execution. With a nil rng nothing fails.
+func (s *simulator) run(rng *rand.Rand) simRun {
+	r := simRun{attempts: make(map[string]int), ok: true}
+	completed := make(map[string]bool)
+	running := make(map[string]SimSlot)
+	var free []int // idle polecats, lowest first
+	polecats := 0
+	now := time.Duration(0)
+
+	for {
+		// Start ready steps in formula order while polecats are free
+		for _, id := range s.f.ReadySteps(completed) {
+			if _, busy := running[id]; busy {
+				continue
+			}
+			if s.opts.Concurrency > 0 && len(running) >= s.opts.Concurrency {
+				break
+			}
+			var p int
+			if len(free) > 0 {
+				p, free = free[0], free[1:]
+			} else {
+				polecats++
+				p = polecats
+			}
+			r.attempts[id]++
+			running[id] = SimSlot{ID: id, Polecat: p, Start: now, End: now + s.duration(id)}
+		}
+		if len(running) > r.peak {
+			r.peak = len(running)
+		}
+		if len(running) == 0 {
+			break
+		}
+
+		// Advance to the next finish; ties in ID order keep runs reproducible
+		var next []SimSlot
+		for _, slot := range running {
+			if len(next) == 0 || slot.End < next[0].End {
+				next = []SimSlot{slot}
+			} else if slot.End == next[0].End {
+				next = append(next, slot)
+			}
+		}
+		sort.Slice(next, func(i, j int) bool { return next[i].ID < next[j].ID })
+		now = next[0].End
+		for _, slot := range next {
+			delete(running, slot.ID)
+			free = append(free, slot.Polecat)
+			if rng != nil && rng.Float64() < s.failRate(slot.ID) {
+				if r.attempts[slot.ID] >= s.opts.MaxAttempts {
+					// Out of attempts: everything after it is stuck
+					r.ok = false
+					r.end = now
+					return r
+				}
+				continue
+			}
+			completed[slot.ID] = true
+			r.slots = append(r.slots, slot)
+		}
+		slices.Sort(free)
+	}
+
+	r.end = now
+	sort.Slice(r.slots, func(i, j int) bool {
+		if r.slots[i].Start != r.slots[j].Start {
+			return r.slots[i].Start < r.slots[j].Start
+		}
+		return r.slots[i].Polecat < r.slots[j].Polecat
+	})
+	return r
+}
+
+// blockers ranks the graph's nodes by downstream work and marks the longest
+// chain by duration.
+func (s *simulator) blockers(g *Graph) []SimBlocker {
+	succ := make(map[string][]string)
+	pred := make(map[string][]string)
+	for _, e := range g.Edges {
+		succ[e.From] = append(succ[e.From], e.To)
+		pred[e.To] = append(pred[e.To], e.From)
+	}
+
+	var out []SimBlocker
+	for _, n := range g.Nodes {
+		seen := map[string]bool{}
+		stack := slices.Clone(succ[n.ID])
+		b := SimBlocker{ID: n.ID}
+		for len(stack) > 0 {
+			id := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+			b.Downstream++
+			b.DownstreamWork += s.duration(id)
+			stack = append(stack, succ[id]...)
+		}
+		out = append(out, b)
+	}
+
+	// Longest chain by duration: finish[id] is the earliest a node can end
+	// with unlimited polecats
+	finish := make(map[string]time.Duration)
+	via := make(map[string]string)
+	var end func(id string) time.Duration
+	end = func(id string) time.Duration {
+		if d, ok := finish[id]; ok {
+			return d
+		}
+		var start time.Duration
+		for _, p := range pred[id] {
+			if e := end(p); e > start {
+				start, via[id] = e, p
+			}
+		}
+		finish[id] = start + s.duration(id)
+		return finish[id]
+	}
+	last := ""
+	for _, n := range g.Nodes {
+		if last == "" || end(n.ID) > end(last) {
+			last = n.ID
+		}
+	}
+	critical := map[string]bool{}
+	for id := last; id != ""; id = via[id] {
+		critical[id] = true
+	}
+	for i := range out {
+		out[i].Critical = critical[out[i].ID]
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].DownstreamWork != out[j].DownstreamWork {
+			return out[i].DownstreamWork > out[j].DownstreamWork
+		}
+		return out[i].Critical && !out[j].Critical
+	})
+	return out
+}
+
+// percentile returns the p-th percentile of sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p + 99) / 100
+	if i > 0 {
+		i--
+	}
+	return sorted[i]
+}
@@ -0,0 +1,135 @@
+package formula
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func diamondDurations() map[string]time.Duration {
+	return map[string]time.Duration{
+		"a": time.Hour,
+		"b": 2 * time.Hour,
+		"c": 30 * time.Minute,
+		"d": time.Hour,
+		"e": 10 * time.Minute,
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	f := diamondFormula()
+
+	res, err := f.Simulate(SimOptions{Durations: diamondDurations()})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if res.Ideal != 4*time.Hour {
+		t.Errorf("Ideal = %s, want 4h (a, b, d)", res.Ideal)
+	}
+	if res.Work != 4*time.Hour+40*time.Minute {
+		t.Errorf("Work = %s, want 4h40m", res.Work)
+	}
+	if res.Peak != 2 || res.Runs != 1 || res.Succeeded != 1 || res.Mean != res.Ideal {
+		t.Errorf("Peak %d, Runs %d, Succeeded %d, Mean %s", res.Peak, res.Runs, res.Succeeded, res.Mean)
+	}
+	var order []string
+	for _, slot := range res.Schedule {
+		order = append(order, slot.ID)
+	}
+	if want := []string{"a", "b", "c", "e", "d"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("schedule = %v, want %v", order, want)
+	}
+
+	top := res.Blockers[0]
+	if top.ID != "a" || top.Downstream != 4 || top.DownstreamWork != 3*time.Hour+40*time.Minute || !top.Critical {
+		t.Errorf("top blocker = %+v", top)
+	}
+	for _, b := range res.Blockers {
+		if want := b.ID == "a" || b.ID == "b" || b.ID == "d"; b.Critical != want {
+			t.Errorf("%s critical = %v, want %v", b.ID, b.Critical, want)
+		}
+	}
+
+	// One polecat does everything in turn
+	res, err = f.Simulate(SimOptions{Durations: diamondDurations(), Concurrency: 1})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if res.Ideal != res.Work || res.Peak != 1 {
+		t.Errorf("Concurrency 1: Ideal %s, Peak %d; want %s, 1", res.Ideal, res.Peak, res.Work)
+	}
+}
+
+func TestSimulateFailures(t *testing.T) {
+	opts := SimOptions{
+		Durations: diamondDurations(),
+		FailRates: map[string]float64{"b": 0.5},
+		Runs:      500,
+		Seed:      7,
+	}
+	res, err := diamondFormula().Simulate(opts)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if res.Runs != 500 || res.Succeeded == 0 || res.Succeeded == res.Runs {
+		t.Errorf("Runs %d, Succeeded %d; want some runs to exhaust b's attempts", res.Runs, res.Succeeded)
+	}
+	if a := res.Attempts["b"]; a <= 1 || a >= 3 {
+		t.Errorf("Attempts[b] = %g, want between 1 and 3", a)
+	}
+	if res.Attempts["a"] != 1 {
+		t.Errorf("Attempts[a] = %g, want 1", res.Attempts["a"])
+	}
+	if res.P50 < res.Ideal || res.P90 < res.P50 || res.Mean <= res.Ideal {
+		t.Errorf("Ideal %s, Mean %s, P50 %s, P90 %s", res.Ideal, res.Mean, res.P50, res.P90)
+	}
+
+	again, _ := diamondFormula().Simulate(opts)
+	if again.Mean != res.Mean || again.Succeeded != res.Succeeded {
+		t.Error("the same seed should give the same result")
+	}
+
+	if _, err := diamondFormula().Simulate(SimOptions{FailRates: map[string]float64{"zz": 0.1}}); err == nil {
+		t.Error("failure rate for an unknown step should be an error")
+	}
+	if _, err := diamondFormula().Simulate(SimOptions{DefaultFailRate: 1}); err == nil {
+		t.Error("a failure rate of 1 should be an error")
+	}
+}
+
+func TestSimulateConvoy(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "review"
+type = "convoy"
+[[legs]]
+id = "a"
+[[legs]]
+id = "b"
+[[legs]]
+id = "c"
+[synthesis]
+title = "Combine"
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if ready := f.ReadySteps(map[string]bool{"a": true, "b": true}); !reflect.DeepEqual(ready, []string{"c"}) {
+		t.Errorf("ReadySteps = %v, want [c]", ready)
+	}
+	if ready := f.ReadySteps(map[string]bool{"a": true, "b": true, "c": true}); !reflect.DeepEqual(ready, []string{SynthesisID}) {
+		t.Errorf("ReadySteps = %v, want [synthesis]", ready)
+	}
+
+	res, err := f.Simulate(SimOptions{Concurrency: 2, DefaultDuration: time.Hour})
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	// Two legs, then the third, then the synthesis
+	if res.Ideal != 3*time.Hour || res.Peak != 2 {
+		t.Errorf("Ideal %s, Peak %d; want 3h, 2", res.Ideal, res.Peak)
+	}
+	if last := res.Schedule[len(res.Schedule)-1]; last.ID != SynthesisID || last.Start != 2*time.Hour {
+		t.Errorf("last slot = %+v, want synthesis at 2h", last)
+	}
+}
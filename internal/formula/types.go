@@ -30,6 +30,15 @@ type Formula struct {
 	Type        FormulaType `toml:"type"`
 	Version     int         `toml:"version"`
 
+	// Composition, resolved by Flatten
+	Extends FormulaRefs `toml:"extends"`
+	Include []Include   `toml:"include"`
+
+	// Included lists the formulas [[include]] pulled in, directly or through
+	// a parent. Set by Flatten; bd ignores include, so these formulas are
+	// cooked from their encoded flattened form.
+	Included []string `toml:"-"`
+
 	// Convoy-specific
 	Inputs    map[string]Input `toml:"inputs"`
 	Prompts   map[string]string `toml:"prompts"`
@@ -41,11 +50,38 @@ type Formula struct {
 	Steps []Step           `toml:"steps"`
 	Vars  map[string]Var   `toml:"vars"`
 
+	// Placeholders are {{names}} in step text that the agent fills in while
+	// working (report fields, computed values), not vars set at cook time.
+	Placeholders []string `toml:"placeholders"`
+
 	// Expansion-specific
 	Template []Template `toml:"template"`
+	Matrix   *Matrix    `toml:"matrix"`
 
 	// Aspect-specific (similar to convoy but for analysis)
 	Aspects []Aspect `toml:"aspects"`
+
+	// Aspect advice, woven into other formulas' steps by bd
+	Advice    []Advice   `toml:"advice"`
+	Pointcuts []Pointcut `toml:"pointcuts"`
+}
+
+// Advice adds steps around a target step of the formula an aspect is
+// applied to. {step.id} in the added steps names the target.
+type Advice struct {
+	Target string        `toml:"target"`
+	Around *AdviceAround `toml:"around"`
+}
+
+// AdviceAround holds the steps inserted before and after the target.
+type AdviceAround struct {
+	Before []Template `toml:"before"`
+	After  []Template `toml:"after"`
+}
+
+// Pointcut selects the steps an aspect applies to.
+type Pointcut struct {
+	Glob string `toml:"glob"`
 }
 
 // Aspect represents a parallel analysis aspect in an aspect formula.
@@ -63,6 +99,8 @@ type Input struct {
 	Required       bool     `toml:"required"`
 	RequiredUnless []string `toml:"required_unless"`
 	Default        string   `toml:"default"`
+	Enum           []string `toml:"enum"`
+	Pattern        string   `toml:"pattern"`
 }
 
 // Output configures where formula outputs are written.
@@ -78,6 +116,11 @@ type Leg struct {
 	Title       string `toml:"title"`
 	Focus       string `toml:"focus"`
 	Description string `toml:"description"`
+
+	// Agent or RoleAgent picks the runtime the leg's polecat starts with;
+	// see Step.
+	Agent     string `toml:"agent"`
+	RoleAgent string `toml:"role_agent"`
 }
 
 // Synthesis represents the synthesis step that combines leg outputs.
@@ -93,6 +136,24 @@ type Step struct {
 	Title       string   `toml:"title"`
 	Description string   `toml:"description"`
 	Needs       []string `toml:"needs"`
+	Verify      *Verify  `toml:"verify"`
+
+	// Parallel steps are handed to their own polecat when they become
+	// ready alongside other steps, instead of being worked in turn. A
+	// pointer so an overriding step can turn an inherited parallel off.
+	Parallel *bool `toml:"parallel"`
+
+	// Agent names the runtime (a town or rig agent, or a built-in preset
+	// like claude or gemini) a polecat working this step on its own starts
+	// with. RoleAgent instead takes whatever role_agents assigns to a role,
+	// e.g. "refinery". At most one may be set.
+	Agent     string `toml:"agent"`
+	RoleAgent string `toml:"role_agent"`
+}
+
+// IsParallel reports whether the step is marked parallel.
+func (s *Step) IsParallel() bool {
+	return s.Parallel != nil && *s.Parallel
 }
 
 // Template represents a template step in an expansion formula.
@@ -104,10 +165,15 @@ type Template struct {
 }
 
 // Var represents a variable definition for formulas.
+// Type is string (default), int, bool, enum (with Enum), bead-id or path;
+// Pattern constrains string values with a regular expression.
 type Var struct {
-	Description string `toml:"description"`
-	Required    bool   `toml:"required"`
-	Default     string `toml:"default"`
+	Description string   `toml:"description"`
+	Required    bool     `toml:"required"`
+	Default     string   `toml:"default"`
+	Type        string   `toml:"type"`
+	Enum        []string `toml:"enum"`
+	Pattern     string   `toml:"pattern"`
 }
 
 // IsValid returns true if the formula type is recognized.
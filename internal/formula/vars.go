@@ -0,0 +1,293 @@
+package formula
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Variable types. A var or input without a type is a string.
+const (
+	VarString = "string"
+	VarInt    = "int"
+	VarBool   = "bool"
+	VarEnum   = "enum"    // One of Enum
+	VarBeadID = "bead-id" // A bead ID such as gt-abc12 or hq-cv-x1y2
+	VarPath   = "path"    // A relative or absolute file system path
+)
+
+// varTypeAliases maps accepted spellings to their canonical type.
+var varTypeAliases = map[string]string{
+	"":        VarString,
+	"string":  VarString,
+	"int":     VarInt,
+	"integer": VarInt,
+	"number":  VarInt,
+	"bool":    VarBool,
+	"boolean": VarBool,
+	"enum":    VarEnum,
+	"bead-id": VarBeadID,
+	"bead":    VarBeadID,
+	"path":    VarPath,
+}
+
+// beadIDPattern matches bead IDs: a prefix, then dash-separated segments,
+// optionally followed by .N step suffixes.
+var beadIDPattern = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-zA-Z0-9]+)+(\.[0-9]+)*$`)
+
+// varRefPattern matches a {{name}} or {{name.field}} reference in step
+// text. Helpers such as {{#each}} and Go template actions ({{.x}}) don't match.
+var varRefPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)(?:\.[A-Za-z0-9_.]+)?\s*\}\}`)
+
+// templateKeywords are {{...}} words that are template syntax, not vars.
+var templateKeywords = map[string]bool{"else": true, "end": true, "this": true}
+
+// varSpec is what Var and Input have in common for checking values.
+type varSpec struct {
+	Type    string
+	Enum    []string
+	Pattern string
+}
+
+// validate checks the spec itself and that def (if set) satisfies it.
+func (s varSpec) validate(def string) error {
+	typ, ok := varTypeAliases[s.Type]
+	if !ok {
+		return fmt.Errorf("unknown type %q (must be string, int, bool, enum, bead-id or path)", s.Type)
+	}
+	if typ == VarEnum && len(s.Enum) == 0 {
+		return fmt.Errorf("enum type requires enum values")
+	}
+	if typ != VarEnum && len(s.Enum) > 0 {
+		return fmt.Errorf("enum values given for %s type", typ)
+	}
+	if s.Pattern != "" {
+		if typ != VarString {
+			return fmt.Errorf("pattern only applies to string type")
+		}
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if def != "" {
+		if err := s.check(def); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+	}
+	return nil
+}
+
+// check validates a value against the spec.
+func (s varSpec) check(value string) error {
+	switch varTypeAliases[s.Type] {
+	case VarInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+	case VarBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not a boolean (true/false)", value)
+		}
+	case VarEnum:
+		for _, e := range s.Enum {
+			if value == e {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %s", value, strings.Join(s.Enum, ", "))
+	case VarBeadID:
+		if !beadIDPattern.MatchString(value) {
+			return fmt.Errorf("%q is not a bead ID", value)
+		}
+	case VarPath:
+		if value == "" || strings.ContainsRune(value, 0) {
+			return fmt.Errorf("%q is not a path", value)
+		}
+	case VarString:
+		if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(value) {
+			return fmt.Errorf("%q does not match %s", value, s.Pattern)
+		}
+	}
+	return nil
+}
+
+// Check validates a value for the variable.
+func (v Var) Check(value string) error {
+	return varSpec{v.Type, v.Enum, v.Pattern}.check(value)
+}
+
+// Check validates a value for the input.
+func (in Input) Check(value string) error {
+	return varSpec{in.Type, in.Enum, in.Pattern}.check(value)
+}
+
+// validateVars checks var and input declarations.
+func (f *Formula) validateVars() error {
+	for _, name := range sortedKeys(f.Vars) {
+		v := f.Vars[name]
+		if err := (varSpec{v.Type, v.Enum, v.Pattern}).validate(v.Default); err != nil {
+			return fmt.Errorf("var %q: %w", name, err)
+		}
+	}
+	for _, name := range sortedKeys(f.Inputs) {
+		in := f.Inputs[name]
+		if err := (varSpec{in.Type, in.Enum, in.Pattern}).validate(in.Default); err != nil {
+			return fmt.Errorf("input %q: %w", name, err)
+		}
+		for _, other := range in.RequiredUnless {
+			if _, ok := f.Inputs[other]; !ok {
+				return fmt.Errorf("input %q: required_unless references unknown input: %s", name, other)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckVars validates values supplied for a run (gt sling --var, gt formula
+// run --var) against the formula's vars and inputs: each value must fit its
+// declared type, required ones must be present, and names must be declared
+// or at least referenced in step text, which catches typos. All problems are
+// reported together.
+func (f *Formula) CheckVars(values map[string]string) error {
+	var errs []error
+	known := f.referencedVars()
+
+	for _, name := range sortedKeys(values) {
+		value := values[name]
+		if v, ok := f.Vars[name]; ok {
+			if err := v.Check(value); err != nil {
+				errs = append(errs, fmt.Errorf("var %s: %w", name, err))
+			}
+			continue
+		}
+		if in, ok := f.Inputs[name]; ok {
+			if err := in.Check(value); err != nil {
+				errs = append(errs, fmt.Errorf("input %s: %w", name, err))
+			}
+			continue
+		}
+		if !known[name] {
+			errs = append(errs, fmt.Errorf("unknown variable %s%s", name, f.declaredHint()))
+		}
+	}
+
+	for _, name := range sortedKeys(f.Vars) {
+		v := f.Vars[name]
+		if _, ok := values[name]; !ok && v.Required && v.Default == "" {
+			errs = append(errs, fmt.Errorf("var %s is required", name))
+		}
+	}
+	for _, name := range sortedKeys(f.Inputs) {
+		in := f.Inputs[name]
+		if _, ok := values[name]; ok || in.Default != "" {
+			continue
+		}
+		if in.Required {
+			errs = append(errs, fmt.Errorf("input %s is required", name))
+			continue
+		}
+		if len(in.RequiredUnless) > 0 {
+			satisfied := false
+			for _, other := range in.RequiredUnless {
+				if _, ok := values[other]; ok {
+					satisfied = true
+				}
+			}
+			if !satisfied {
+				group := append([]string{name}, in.RequiredUnless...)
+				sort.Strings(group)
+				errs = append(errs, fmt.Errorf("one of %s is required", strings.Join(group, ", ")))
+			}
+		}
+	}
+
+	return dedupeErrors(errs)
+}
+
+// declaredHint lists the declared names for an unknown-variable error.
+func (f *Formula) declaredHint() string {
+	names := append(sortedKeys(f.Vars), sortedKeys(f.Inputs)...)
+	if len(names) == 0 {
+		return " (formula declares no variables)"
+	}
+	return " (declared: " + strings.Join(names, ", ") + ")"
+}
+
+// Lint reports variable problems that Validate lets through: {{var}}
+// references in step text to undeclared variables (errors), and declared
+// variables that no step references (warnings). Names listed in
+// placeholders are the agent's to fill in and not checked.
+func (f *Formula) Lint() (errs, warnings []string) {
+	refs := f.referencedVars()
+	for _, name := range sortedKeys(refs) {
+		if _, ok := f.Vars[name]; !ok {
+			errs = append(errs, fmt.Sprintf("{{%s}} is used but not declared in [vars]", name))
+		}
+	}
+	for _, name := range sortedKeys(f.Vars) {
+		if !refs[name] {
+			warnings = append(warnings, fmt.Sprintf("var %s is declared but never used", name))
+		}
+	}
+	return errs, warnings
+}
+
+// referencedVars returns the variable names referenced in step and
+// template titles and descriptions, leaving out placeholders.
+func (f *Formula) referencedVars() map[string]bool {
+	var texts []string
+	for _, step := range f.Steps {
+		texts = append(texts, step.Title, step.Description)
+	}
+	for _, tmpl := range f.Template {
+		texts = append(texts, tmpl.Title, tmpl.Description)
+	}
+	if f.Matrix != nil && f.Matrix.FanIn != nil {
+		texts = append(texts, f.Matrix.FanIn.Title, f.Matrix.FanIn.Description)
+	}
+
+	refs := make(map[string]bool)
+	for _, text := range texts {
+		for _, m := range varRefPattern.FindAllStringSubmatch(text, -1) {
+			if templateKeywords[m[1]] || slices.Contains(f.Placeholders, m[1]) {
+				continue
+			}
+			// The matrix fills in its own placeholders
+			if f.Matrix != nil && matrixRefPattern.MatchString("{{"+m[1]+"}}") {
+				continue
+			}
+			refs[m[1]] = true
+		}
+	}
+	if f.Matrix != nil && f.Matrix.Var != "" {
+		refs[f.Matrix.Var] = true
+	}
+	return refs
+}
+
+// dedupeErrors joins errs, dropping repeated messages.
+func dedupeErrors(errs []error) error {
+	seen := make(map[string]bool)
+	var out []error
+	for _, err := range errs {
+		if !seen[err.Error()] {
+			seen[err.Error()] = true
+			out = append(out, err)
+		}
+	}
+	return errors.Join(out...)
+}
+
+// sortedKeys returns a map's keys in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
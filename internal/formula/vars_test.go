@@ -0,0 +1,193 @@
+package formula
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const typedVarsFormula = `
+formula = "typed"
+type = "workflow"
+
+[vars.disks]
+type = "int"
+default = "3"
+
+[vars.dry_run]
+type = "bool"
+
+[vars.mode]
+type = "enum"
+enum = ["fast", "thorough"]
+required = true
+
+[vars.issue]
+type = "bead-id"
+
+[vars.branch]
+pattern = "^[a-z0-9/-]+$"
+
+[vars.output]
+type = "path"
+
+[vars.unused]
+description = "Never referenced"
+
+[[steps]]
+id = "work"
+title = "Move {{disks}} disks on {{branch}}"
+description = "Mode {{mode}}, issue {{issue}}, dry run {{dry_run}}, write to {{output}}. Report {{summary}}. {{#each items}}{{else}}{{end}}"
+`
+
+func TestCheckVars(t *testing.T) {
+	f, err := Parse([]byte(typedVarsFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	ok := map[string]string{
+		"disks":   "7",
+		"dry_run": "true",
+		"mode":    "fast",
+		"issue":   "gt-abc12",
+		"branch":  "polecat/toast",
+		"output":  "docs/out.md",
+		"summary": "referenced in steps, so accepted",
+	}
+	if err := f.CheckVars(ok); err != nil {
+		t.Errorf("CheckVars(valid) = %v", err)
+	}
+
+	bad := map[string]string{
+		"disks":   "three",
+		"dry_run": "maybe",
+		"issue":   "not a bead",
+		"branch":  "Has Spaces",
+		"diskz":   "7",
+	}
+	err = f.CheckVars(bad)
+	if err == nil {
+		t.Fatal("CheckVars(invalid) = nil")
+	}
+	for _, want := range []string{
+		`var disks: "three" is not an integer`,
+		`var dry_run: "maybe" is not a boolean`,
+		`var issue: "not a bead" is not a bead ID`,
+		`var branch: "Has Spaces" does not match`,
+		"unknown variable diskz (declared: branch, disks",
+		"var mode is required",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error missing %q:\n%v", want, err)
+		}
+	}
+
+	if err := f.CheckVars(map[string]string{"mode": "slow"}); err == nil || !strings.Contains(err.Error(), "not one of fast, thorough") {
+		t.Errorf("enum check = %v", err)
+	}
+}
+
+func TestCheckVars_Inputs(t *testing.T) {
+	f := &Formula{
+		Name: "review",
+		Type: TypeConvoy,
+		Legs: []Leg{{ID: "a"}},
+		Inputs: map[string]Input{
+			"pr":     {Type: "number", RequiredUnless: []string{"branch"}},
+			"branch": {Type: "string", RequiredUnless: []string{"pr"}},
+		},
+	}
+	if err := f.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if err := f.CheckVars(map[string]string{"pr": "12"}); err != nil {
+		t.Errorf("pr only: %v", err)
+	}
+	if err := f.CheckVars(map[string]string{"pr": "twelve"}); err == nil {
+		t.Error("expected number check to fail")
+	}
+	err := f.CheckVars(nil)
+	if err == nil || strings.Count(err.Error(), "one of branch, pr is required") != 1 {
+		t.Errorf("CheckVars(nil) = %v, want one required_unless error", err)
+	}
+}
+
+func TestValidateVars(t *testing.T) {
+	tests := []struct {
+		name    string
+		vars    string
+		wantErr string
+	}{
+		{"unknown type", "[vars.x]\ntype = \"float\"", `unknown type "float"`},
+		{"enum without values", "[vars.x]\ntype = \"enum\"", "requires enum values"},
+		{"bad pattern", "[vars.x]\npattern = \"(\"", "invalid pattern"},
+		{"pattern on int", "[vars.x]\ntype = \"int\"\npattern = \"1\"", "only applies to string"},
+		{"bad default", "[vars.x]\ntype = \"int\"\ndefault = \"many\"", `default: "many" is not an integer`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "formula = \"v\"\n[[steps]]\nid = \"s\"\ntitle = \"{{x}}\"\n" + tt.vars
+			_, err := Parse([]byte(data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLintEmbeddedFormulas(t *testing.T) {
+	entries, err := formulasFS.ReadDir("formulas")
+	if err != nil {
+		t.Fatalf("reading embedded formulas: %v", err)
+	}
+	for _, entry := range entries {
+		f, err := ParseFile(filepath.Join("formulas", entry.Name()))
+		if err != nil {
+			t.Errorf("%s: %v", entry.Name(), err)
+			continue
+		}
+		if errs, _ := f.Lint(); len(errs) > 0 {
+			t.Errorf("%s: %s", entry.Name(), strings.Join(errs, "; "))
+		}
+	}
+}
+
+func TestLintPlaceholders(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "report"
+type = "workflow"
+placeholders = ["total"]
+
+[[steps]]
+id = "report"
+title = "Report on {{target}}"
+description = "Found {{total}} items"
+
+[vars.target]
+required = true
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if errs, warnings := f.Lint(); len(errs) != 0 || len(warnings) != 0 {
+		t.Errorf("Lint() = %v, %v; want clean", errs, warnings)
+	}
+	if err := f.CheckVars(map[string]string{"target": "x", "total": "3"}); err == nil || !strings.Contains(err.Error(), "total") {
+		t.Errorf("CheckVars accepted a placeholder as a var: %v", err)
+	}
+}
+
+func TestLint(t *testing.T) {
+	f, err := Parse([]byte(typedVarsFormula))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	errs, warnings := f.Lint()
+	if len(errs) != 1 || !strings.Contains(errs[0], "{{summary}}") {
+		t.Errorf("errs = %v, want only {{summary}}", errs)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "var unused") {
+		t.Errorf("warnings = %v, want only unused", warnings)
+	}
+}
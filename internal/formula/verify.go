@@ -0,0 +1,208 @@
+package formula
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultVerifyTimeout bounds a verify block that sets no timeout.
+const DefaultVerifyTimeout = 5 * time.Minute
+
+// verifyOutputLines is how much of a failing command's output is reported.
+const verifyOutputLines = 40
+
+// Verify holds machine-checked exit criteria for a workflow step. A step
+// with a verify block cannot be completed until every check passes.
+//
+//	[steps.verify]
+//	command = "go test ./..."
+//	exit_code = 0
+//	file_exists = ["docs/design.md"]
+//	grep = [{ file = "CHANGELOG.md", pattern = "^## Unreleased" }]
+//	timeout = "10m"
+//
+// Paths are relative to the agent's worktree and may be glob patterns.
+type Verify struct {
+	Command    string      `toml:"command"`     // Run with sh -c in the worktree
+	ExitCode   int         `toml:"exit_code"`   // Expected exit code of command (default 0)
+	FileExists []string    `toml:"file_exists"` // Each must match at least one file
+	Grep       []GrepCheck `toml:"grep"`        // Each pattern must match a line of its file
+	Timeout    string      `toml:"timeout"`     // Bound on the whole block (default 5m)
+}
+
+// GrepCheck asserts that a file contains a line matching a regular expression.
+type GrepCheck struct {
+	File    string `toml:"file"`
+	Pattern string `toml:"pattern"`
+}
+
+// VerifyError reports the check that stopped a step from completing.
+type VerifyError struct {
+	Check  string // The failing check, e.g. "command `make test`"
+	Reason string // Why it failed
+	Output string // Tail of the command's output, if any
+}
+
+func (e *VerifyError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Check, e.Reason)
+}
+
+// Validate checks that the block asserts something and is well formed.
+func (v *Verify) Validate() error {
+	if v.Command == "" && len(v.FileExists) == 0 && len(v.Grep) == 0 {
+		return fmt.Errorf("needs a command, file_exists or grep check")
+	}
+	if v.Command == "" && v.ExitCode != 0 {
+		return fmt.Errorf("exit_code set without a command")
+	}
+	if v.ExitCode < 0 || v.ExitCode > 255 {
+		return fmt.Errorf("exit_code %d out of range", v.ExitCode)
+	}
+	if v.Timeout != "" {
+		d, err := time.ParseDuration(v.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", v.Timeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("timeout must be positive")
+		}
+	}
+	for _, path := range v.FileExists {
+		if err := checkVerifyPath(path); err != nil {
+			return fmt.Errorf("file_exists: %w", err)
+		}
+	}
+	for _, g := range v.Grep {
+		if err := checkVerifyPath(g.File); err != nil {
+			return fmt.Errorf("grep: %w", err)
+		}
+		if g.Pattern == "" {
+			return fmt.Errorf("grep %s: pattern is required", g.File)
+		}
+		if _, err := regexp.Compile(g.Pattern); err != nil {
+			return fmt.Errorf("grep %s: invalid pattern: %w", g.File, err)
+		}
+	}
+	return nil
+}
+
+// checkVerifyPath rejects paths that would look outside the worktree.
+func checkVerifyPath(path string) error {
+	switch {
+	case path == "":
+		return fmt.Errorf("path is required")
+	case filepath.IsAbs(path):
+		return fmt.Errorf("%s: must be relative to the worktree", path)
+	case path == ".." || strings.HasPrefix(filepath.Clean(path), ".."+string(filepath.Separator)):
+		return fmt.Errorf("%s: must stay inside the worktree", path)
+	}
+	if _, err := filepath.Match(path, ""); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// TimeoutDuration returns the block's timeout, or DefaultVerifyTimeout.
+func (v *Verify) TimeoutDuration() time.Duration {
+	if d, err := time.ParseDuration(v.Timeout); err == nil && d > 0 {
+		return d
+	}
+	return DefaultVerifyTimeout
+}
+
+// Run executes the checks in dir: file assertions first, then the command.
+// Returns a *VerifyError for the first check that fails.
+func (v *Verify) Run(ctx context.Context, dir string) error {
+	ctx, cancel := context.WithTimeout(ctx, v.TimeoutDuration())
+	defer cancel()
+
+	for _, pattern := range v.FileExists {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		if len(matches) == 0 {
+			return &VerifyError{Check: "file_exists " + pattern, Reason: "no such file"}
+		}
+	}
+
+	for _, g := range v.Grep {
+		check := fmt.Sprintf("grep %q %s", g.Pattern, g.File)
+		ok, err := grepFiles(dir, g)
+		if err != nil {
+			return &VerifyError{Check: check, Reason: err.Error()}
+		}
+		if !ok {
+			return &VerifyError{Check: check, Reason: "no matching line"}
+		}
+	}
+
+	if v.Command == "" {
+		return nil
+	}
+
+	check := fmt.Sprintf("command `%s`", v.Command)
+	cmd := exec.CommandContext(ctx, "sh", "-c", v.Command) //nolint:gosec // G204: command comes from the formula author
+	cmd.Dir = dir
+	// Don't wait on children of the shell that outlive it on timeout
+	cmd.WaitDelay = time.Second
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return &VerifyError{Check: check, Reason: fmt.Sprintf("timed out after %s", v.TimeoutDuration()), Output: tailLines(out.String(), verifyOutputLines)}
+	}
+	code := 0
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return &VerifyError{Check: check, Reason: err.Error()}
+		}
+		code = exitErr.ExitCode()
+	}
+	if code != v.ExitCode {
+		return &VerifyError{Check: check, Reason: fmt.Sprintf("exit code %d, want %d", code, v.ExitCode), Output: tailLines(out.String(), verifyOutputLines)}
+	}
+	return nil
+}
+
+// grepFiles reports whether any file matching g.File has a line matching
+// g.Pattern.
+func grepFiles(dir string, g GrepCheck) (bool, error) {
+	re, err := regexp.Compile(g.Pattern)
+	if err != nil {
+		return false, fmt.Errorf("invalid pattern: %w", err)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, g.File))
+	if len(matches) == 0 {
+		return false, fmt.Errorf("no such file")
+	}
+	for _, path := range matches {
+		data, err := os.ReadFile(path) //nolint:gosec // G304: path is inside the agent's worktree
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if re.MatchString(line) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = append([]string{fmt.Sprintf("... (%d lines omitted)", len(lines)-n)}, lines[len(lines)-n:]...)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,184 @@
+package formula
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_StepVerify(t *testing.T) {
+	data := []byte(`
+formula = "verified"
+type = "workflow"
+
+[[steps]]
+id = "implement"
+title = "Implement {{feature}}"
+
+[steps.verify]
+command = "make test"
+exit_code = 2
+file_exists = ["docs/*.md"]
+grep = [{ file = "CHANGELOG.md", pattern = "^## Unreleased" }]
+timeout = "90s"
+
+[[steps]]
+id = "submit"
+title = "Submit"
+needs = ["implement"]
+`)
+
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	v := f.Steps[0].Verify
+	if v == nil {
+		t.Fatal("implement.Verify = nil")
+	}
+	if v.Command != "make test" || v.ExitCode != 2 || len(v.FileExists) != 1 || len(v.Grep) != 1 {
+		t.Errorf("Verify = %+v", v)
+	}
+	if v.TimeoutDuration().String() != "1m30s" {
+		t.Errorf("TimeoutDuration = %s, want 1m30s", v.TimeoutDuration())
+	}
+	if f.Steps[1].Verify != nil {
+		t.Error("submit.Verify should be nil")
+	}
+
+	if got := f.StepForTitle("Implement rate limiting"); got == nil || got.ID != "implement" {
+		t.Errorf("StepForTitle(substituted) = %v, want implement", got)
+	}
+	if got := f.StepForTitle("Submit"); got == nil || got.ID != "submit" {
+		t.Errorf("StepForTitle(Submit) = %v, want submit", got)
+	}
+	if got := f.StepForTitle("Review"); got != nil {
+		t.Errorf("StepForTitle(Review) = %v, want nil", got)
+	}
+}
+
+func TestVerifyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		verify Verify
+	}{
+		{"empty", Verify{Timeout: "1m"}},
+		{"exit code without command", Verify{ExitCode: 1, FileExists: []string{"a"}}},
+		{"bad timeout", Verify{Command: "true", Timeout: "soon"}},
+		{"absolute path", Verify{FileExists: []string{"/etc/passwd"}}},
+		{"escaping path", Verify{FileExists: []string{"../other/file"}}},
+		{"bad pattern", Verify{Grep: []GrepCheck{{File: "a", Pattern: "("}}}},
+		{"missing pattern", Verify{Grep: []GrepCheck{{File: "a"}}}},
+	}
+	for _, tt := range tests {
+		if err := tt.verify.Validate(); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+
+	ok := Verify{Command: "true", FileExists: []string{"docs/*.md"}, Grep: []GrepCheck{{File: "go.mod", Pattern: "^module "}}}
+	if err := ok.Validate(); err != nil {
+		t.Errorf("valid block: %v", err)
+	}
+}
+
+func TestVerifyRun(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "NOTES.md"), []byte("# Notes\nstatus: done\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	pass := Verify{
+		Command:    "test -f NOTES.md",
+		FileExists: []string{"*.md"},
+		Grep:       []GrepCheck{{File: "NOTES.md", Pattern: "^status: done$"}},
+	}
+	if err := pass.Run(ctx, dir); err != nil {
+		t.Errorf("passing block: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		verify Verify
+		check  string
+		output string
+	}{
+		{"missing file", Verify{FileExists: []string{"REPORT.md"}}, "file_exists REPORT.md", ""},
+		{"grep miss", Verify{Grep: []GrepCheck{{File: "NOTES.md", Pattern: "^status: blocked"}}}, "grep", ""},
+		{"exit code", Verify{Command: "echo broken; exit 3"}, "command `echo broken; exit 3`", "broken"},
+		{"timeout", Verify{Command: "sleep 5", Timeout: "100ms"}, "command `sleep 5`", ""},
+	}
+	for _, tt := range tests {
+		err := tt.verify.Run(ctx, dir)
+		var verr *VerifyError
+		if !errors.As(err, &verr) {
+			t.Errorf("%s: err = %v, want *VerifyError", tt.name, err)
+			continue
+		}
+		if !strings.HasPrefix(verr.Check, tt.check) {
+			t.Errorf("%s: Check = %q, want prefix %q", tt.name, verr.Check, tt.check)
+		}
+		if verr.Output != tt.output {
+			t.Errorf("%s: Output = %q, want %q", tt.name, verr.Output, tt.output)
+		}
+	}
+
+	expectFail := Verify{Command: "exit 1", ExitCode: 1}
+	if err := expectFail.Run(ctx, dir); err != nil {
+		t.Errorf("expected exit code 1: %v", err)
+	}
+}
+
+func TestMatchSteps(t *testing.T) {
+	f, err := Parse([]byte(`
+formula = "twice"
+[vars.feature]
+required = true
+[vars.target]
+default = "main"
+
+[[steps]]
+id = "test-unit"
+title = "Run tests"
+[[steps]]
+id = "implement"
+title = "Implement {{feature}}"
+needs = ["test-unit"]
+[[steps]]
+id = "test-final"
+title = "Run tests"
+needs = ["implement"]
+[[steps]]
+id = "merge"
+title = "Merge into {{target}}"
+needs = ["test-final"]
+[[steps]]
+id = "notify"
+title = "Tell {{who}}"
+needs = ["merge"]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// Same titles pair up in order; vars and defaults are substituted
+	titles := []string{"Run tests", "Implement {{feature}}", "Run tests", "Merge into main", "Tell mayor", "Stray"}
+	titles[1] = "Implement Run tests" // a value that looks like another step's title
+	got := f.MatchSteps(titles, map[string]string{"feature": "Run tests"})
+	want := []string{"test-unit", "implement", "test-final", "merge", "notify", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MatchSteps = %v, want %v", got, want)
+	}
+
+	if got := f.StepForTitle("Run tests"); got != nil {
+		t.Errorf("StepForTitle(ambiguous) = %v, want nil", got.ID)
+	}
+	if got := f.StepsForTitle("Run tests"); len(got) != 2 {
+		t.Errorf("StepsForTitle(Run tests) = %d steps, want 2", len(got))
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
@@ -159,21 +160,55 @@ func (g *Git) CloneBare(url, dest string) error {
 	if err := cmd.Run(); err != nil {
 		return g.wrapError(err, stdout.String(), stderr.String(), []string{"clone", "--bare", url})
 	}
+	// Worktrees share the bare repo's config, so this covers every polecat
+	if err := configureHooksPath(dest); err != nil {
+		return err
+	}
 	// Configure refspec so worktrees can fetch and see origin/* refs
 	return configureRefspec(dest)
 }
 
-// configureHooksPath sets core.hooksPath to use the repo's .githooks directory
-// if it exists. This ensures Gas Town agents use the pre-push hook that blocks
-// pushes to non-main branches (internal PRs are not allowed).
+// configureHooksPath installs Gas Town's prepare-commit-msg hook in the
+// repo's git common dir and sets core.hooksPath to it. The hook stamps
+// provenance trailers (Gt-Bead, Gt-Agent, ...) on agent commits, then chains
+// to the hooks path the repo already had (husky, lefthook, pre-commit, ...),
+// recorded in gt.chainedHooksPath, or else to the repo's .githooks/ (e.g. the
+// pre-push hook that blocks pushes to non-main branches) or .git/hooks/.
+// Other hooks are only installed, as plain chains, for the hooks that path
+// provides.
 func configureHooksPath(repoPath string) error {
-	hooksDir := filepath.Join(repoPath, ".githooks")
-	if _, err := os.Stat(hooksDir); os.IsNotExist(err) {
-		// No .githooks directory, nothing to configure
-		return nil
+	dir, err := hooksDir(repoPath)
+	if err != nil {
+		return err
+	}
+	out, _ := exec.Command("git", "-C", repoPath, "config", "--get", "core.hooksPath").Output()
+	if current := strings.TrimSpace(string(out)); current != "" && current != dir {
+		cmd := exec.Command("git", "-C", repoPath, "config", chainedHooksPathKey, current)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("recording hooks path %s: %s", current, strings.TrimSpace(stderr.String()))
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating hooks dir: %w", err)
+	}
+	names := managedHookNames(repoPath)
+	for _, name := range clientHooks {
+		path := filepath.Join(dir, name)
+		if !slices.Contains(names, name) {
+			// The repo no longer provides it
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("removing %s hook: %w", name, err)
+			}
+			continue
+		}
+		if err := os.WriteFile(path, []byte(managedHookScript), 0755); err != nil { //nolint:gosec // G306: hooks must be executable
+			return fmt.Errorf("installing %s hook: %w", name, err)
+		}
 	}
 
-	cmd := exec.Command("git", "-C", repoPath, "config", "core.hooksPath", ".githooks")
+	cmd := exec.Command("git", "-C", repoPath, "config", "core.hooksPath", dir)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	if err := cmd.Run(); err != nil {
@@ -229,6 +264,13 @@ func (g *Git) Checkout(ref string) error {
 	return err
 }
 
+// CheckoutNewBranch creates (or resets) branch at startPoint and checks it out.
+// Untracked and ignored files are left in place.
+func (g *Git) CheckoutNewBranch(branch, startPoint string) error {
+	_, err := g.run("checkout", "-B", branch, startPoint)
+	return err
+}
+
 // Fetch fetches from the remote.
 func (g *Git) Fetch(remote string) error {
 	_, err := g.run("fetch", remote)
@@ -418,6 +460,13 @@ func (g *Git) Rebase(onto string) error {
 	return err
 }
 
+// RebaseOnto replays the commits of branch after upstream onto the given ref
+// (git rebase --onto). branch is left checked out.
+func (g *Git) RebaseOnto(onto, upstream, branch string) error {
+	_, err := g.run("rebase", "--onto", onto, upstream, branch)
+	return err
+}
+
 // AbortMerge aborts a merge in progress.
 func (g *Git) AbortMerge() error {
 	_, err := g.run("merge", "--abort")
@@ -590,6 +639,110 @@ func (g *Git) Rev(ref string) (string, error) {
 	return g.run("rev-parse", ref)
 }
 
+// CommitParents returns the parent SHAs of a commit.
+func (g *Git) CommitParents(commit string) ([]string, error) {
+	out, err := g.run("rev-list", "--parents", "-n", "1", commit)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("commit %s not found", commit)
+	}
+	return fields[1:], nil
+}
+
+// Revert creates a commit that reverts commit. For merge commits, mainline
+// is the parent number to revert to (1 = the branch merged into); pass 0
+// for ordinary commits.
+func (g *Git) Revert(commit string, mainline int, message string) error {
+	args := []string{"revert", "--no-edit"}
+	if mainline > 0 {
+		args = append(args, "-m", fmt.Sprintf("%d", mainline))
+	}
+	if _, err := g.run(append(args, commit)...); err != nil {
+		_, _ = g.run("revert", "--abort")
+		return err
+	}
+	if message != "" {
+		_, err := g.run("commit", "--amend", "-m", message)
+		return err
+	}
+	return nil
+}
+
+// ChangedFiles returns the files added, copied, modified or renamed on head
+// since it diverged from base (git diff base...head). Deleted files are not
+// listed.
+func (g *Git) ChangedFiles(base, head string) ([]string, error) {
+	out, err := g.run("diff", "--name-only", "-z", "--diff-filter=ACMR", base+"..."+head)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// BlobSize returns the size in bytes of path at rev.
+func (g *Git) BlobSize(rev, path string) (int64, error) {
+	out, err := g.run("cat-file", "-s", rev+":"+path)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	if _, err := fmt.Sscanf(out, "%d", &size); err != nil {
+		return 0, fmt.Errorf("parsing size of %s: %w", path, err)
+	}
+	return size, nil
+}
+
+// ShowFile returns the contents of path at rev.
+func (g *Git) ShowFile(rev, path string) ([]byte, error) {
+	args := []string{"cat-file", "blob", rev + ":" + path}
+	if g.gitDir != "" {
+		args = append([]string{"--git-dir=" + g.gitDir}, args...)
+	}
+	cmd := exec.Command("git", args...)
+	if g.workDir != "" {
+		cmd.Dir = g.workDir
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, g.wrapError(err, stdout.String(), stderr.String(), args)
+	}
+	return stdout.Bytes(), nil
+}
+
+// FirstParentHistory returns the commits on the first-parent chain of ref,
+// newest first. If since is non-empty, only commits after since are listed.
+// max limits the number of commits (0 for no limit).
+func (g *Git) FirstParentHistory(ref, since string, max int) ([]string, error) {
+	args := []string{"rev-list", "--first-parent"}
+	if max > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", max))
+	}
+	if since != "" {
+		args = append(args, since+".."+ref)
+	} else {
+		args = append(args, ref)
+	}
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	if out == "" {
+		return nil, nil
+	}
+	return strings.Split(out, "\n"), nil
+}
+
 // IsAncestor checks if ancestor is an ancestor of descendant.
 func (g *Git) IsAncestor(ancestor, descendant string) (bool, error) {
 	_, err := g.run("merge-base", "--is-ancestor", ancestor, descendant)
@@ -792,6 +945,12 @@ func (g *Git) WorktreeRemove(path string, force bool) error {
 	return err
 }
 
+// WorktreeMove moves a worktree to a new path, updating git's administrative files.
+func (g *Git) WorktreeMove(from, to string) error {
+	_, err := g.run("worktree", "move", from, to)
+	return err
+}
+
 // WorktreePrune removes worktree entries for deleted paths.
 func (g *Git) WorktreePrune() error {
 	_, err := g.run("worktree", "prune")
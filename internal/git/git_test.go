@@ -488,3 +488,104 @@ func stringContains(s, substr string) bool {
 	}
 	return false
 }
+
+func TestRevertMergeCommit(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	mainBranch, _ := g.CurrentBranch()
+
+	if err := g.CreateBranch("feature"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("feature"); err != nil {
+		t.Fatalf("Checkout feature: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "feature.txt"), []byte("feature\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add("feature.txt"); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit("add feature"); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+	if err := g.Checkout(mainBranch); err != nil {
+		t.Fatalf("Checkout main: %v", err)
+	}
+	if err := g.MergeNoFF("feature", "Merge feature"); err != nil {
+		t.Fatalf("MergeNoFF: %v", err)
+	}
+
+	merge, _ := g.Rev("HEAD")
+	parents, err := g.CommitParents(merge)
+	if err != nil {
+		t.Fatalf("CommitParents: %v", err)
+	}
+	if len(parents) != 2 {
+		t.Fatalf("merge commit has %d parents, want 2", len(parents))
+	}
+
+	if err := g.Revert(merge, 1, "Revert feature"); err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "feature.txt")); !os.IsNotExist(err) {
+		t.Error("feature.txt still present after revert")
+	}
+	subject, _ := g.run("log", "-1", "--format=%s")
+	if subject != "Revert feature" {
+		t.Errorf("subject = %q, want %q", subject, "Revert feature")
+	}
+}
+
+func TestRebaseOnto(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	mainBranch, _ := g.CurrentBranch()
+
+	commitFile := func(name string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if err := g.Add(name); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+		if err := g.Commit("add " + name); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+
+	// B is stacked on A; A is then rewritten before it merges
+	if err := g.CheckoutNewBranch("a", mainBranch); err != nil {
+		t.Fatalf("CheckoutNewBranch a: %v", err)
+	}
+	commitFile("a.txt")
+	base, _ := g.Rev("HEAD")
+	if err := g.CheckoutNewBranch("b", "a"); err != nil {
+		t.Fatalf("CheckoutNewBranch b: %v", err)
+	}
+	commitFile("b.txt")
+	if err := g.CheckoutNewBranch("a", mainBranch); err != nil {
+		t.Fatalf("resetting a: %v", err)
+	}
+	commitFile("a2.txt")
+	if err := g.Checkout(mainBranch); err != nil {
+		t.Fatalf("Checkout main: %v", err)
+	}
+	if err := g.MergeNoFF("a", "Merge a"); err != nil {
+		t.Fatalf("MergeNoFF: %v", err)
+	}
+
+	if err := g.RebaseOnto(mainBranch, base, "b"); err != nil {
+		t.Fatalf("RebaseOnto: %v", err)
+	}
+	if branch, _ := g.CurrentBranch(); branch != "b" {
+		t.Errorf("current branch = %q, want b", branch)
+	}
+	for name, want := range map[string]bool{"a.txt": false, "a2.txt": true, "b.txt": true} {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if got := err == nil; got != want {
+			t.Errorf("%s present = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,445 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Provenance trailers link commits to the Gas Town work that produced them.
+// Agent commits get them from the managed prepare-commit-msg hook, which
+// also stamps Gt-MR when the refinery patrol rebases an MR with GT_MR set;
+// the Engineer's merge commits get them directly.
+const (
+	TrailerBead   = "Gt-Bead"
+	TrailerAgent  = "Gt-Agent"
+	TrailerConvoy = "Gt-Convoy"
+	TrailerMR     = "Gt-MR"
+)
+
+// Provenance is the set of Gas Town trailers on a commit.
+type Provenance struct {
+	Bead   string `json:"bead,omitempty"`
+	Agent  string `json:"agent,omitempty"`
+	Convoy string `json:"convoy,omitempty"`
+	MR     string `json:"mr,omitempty"`
+}
+
+// IsZero returns true if no provenance is set.
+func (p Provenance) IsZero() bool {
+	return p == Provenance{}
+}
+
+// Trailers returns p as "Key: value" trailer lines, skipping empty values.
+func (p Provenance) Trailers() []string {
+	var lines []string
+	for _, t := range []struct{ key, value string }{
+		{TrailerBead, p.Bead},
+		{TrailerAgent, p.Agent},
+		{TrailerConvoy, p.Convoy},
+		{TrailerMR, p.MR},
+	} {
+		if t.value != "" {
+			lines = append(lines, t.key+": "+t.value)
+		}
+	}
+	return lines
+}
+
+// AppendTrailers returns message with p's trailers appended. Trailers whose
+// key already appears in message are left alone, matching the hook's
+// --if-exists doNothing behavior.
+func AppendTrailers(message string, p Provenance) string {
+	existing := ParseProvenance(message)
+	if existing.Bead != "" {
+		p.Bead = ""
+	}
+	if existing.Agent != "" {
+		p.Agent = ""
+	}
+	if existing.Convoy != "" {
+		p.Convoy = ""
+	}
+	if existing.MR != "" {
+		p.MR = ""
+	}
+	lines := p.Trailers()
+	if len(lines) == 0 {
+		return message
+	}
+
+	message = strings.TrimRight(message, "\n")
+	if existing.IsZero() {
+		message += "\n"
+	}
+	return message + "\n" + strings.Join(lines, "\n")
+}
+
+// ParseProvenance extracts Gas Town trailers from a commit message or a
+// trailer block. Keys are matched case-insensitively, as git does.
+func ParseProvenance(message string) Provenance {
+	var p Provenance
+	for _, line := range strings.Split(message, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "gt-bead":
+			p.Bead = value
+		case "gt-agent":
+			p.Agent = value
+		case "gt-convoy":
+			p.Convoy = value
+		case "gt-mr":
+			p.MR = value
+		}
+	}
+	return p
+}
+
+// provenanceFile holds the bead/convoy/MR an agent is working on. It lives
+// in the worktree's own git dir, so each agent's worktree has its own.
+const provenanceFile = "gt-provenance"
+
+// WriteProvenance records the work an agent in workDir is on, for the
+// prepare-commit-msg hook to stamp on its commits. Agent is ignored: the
+// hook takes it from BD_ACTOR.
+func WriteProvenance(workDir string, p Provenance) error {
+	gitDir, err := NewGit(workDir).run("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for _, kv := range []struct{ key, value string }{
+		{"bead", p.Bead},
+		{"convoy", p.Convoy},
+		{"mr", p.MR},
+	} {
+		if kv.value != "" {
+			fmt.Fprintf(&sb, "%s=%s\n", kv.key, kv.value)
+		}
+	}
+	return os.WriteFile(filepath.Join(gitDir, provenanceFile), []byte(sb.String()), 0644) //nolint:gosec // G306: not sensitive
+}
+
+// ReadProvenance returns the work recorded by WriteProvenance for workDir.
+func ReadProvenance(workDir string) (Provenance, error) {
+	var p Provenance
+	gitDir, err := NewGit(workDir).run("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return p, err
+	}
+	data, err := os.ReadFile(filepath.Join(gitDir, provenanceFile)) //nolint:gosec // G304: path is inside the git dir
+	if err != nil {
+		if os.IsNotExist(err) {
+			return p, nil
+		}
+		return p, err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, _ := strings.Cut(line, "=")
+		switch key {
+		case "bead":
+			p.Bead = value
+		case "convoy":
+			p.Convoy = value
+		case "mr":
+			p.MR = value
+		}
+	}
+	return p, nil
+}
+
+// ClearProvenance removes the work recorded by WriteProvenance for workDir.
+func ClearProvenance(workDir string) error {
+	gitDir, err := NewGit(workDir).run("rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(gitDir, provenanceFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// managedHooksDir is the hooks directory Gas Town installs inside the git
+// common dir and points core.hooksPath at.
+const managedHooksDir = "gt-hooks"
+
+// chainedHooksPathKey records the core.hooksPath a repo had before the
+// managed hooks took it over; the managed hooks run that path's hooks.
+const chainedHooksPathKey = "gt.chainedHooksPath"
+
+// clientHooks are the client-side hooks git runs from core.hooksPath.
+var clientHooks = []string{
+	"applypatch-msg",
+	"pre-applypatch",
+	"post-applypatch",
+	"pre-commit",
+	"pre-merge-commit",
+	"prepare-commit-msg",
+	"commit-msg",
+	"post-commit",
+	"pre-rebase",
+	"post-checkout",
+	"post-merge",
+	"pre-push",
+	"post-rewrite",
+}
+
+// managedHookScript is installed as prepare-commit-msg, and under the name
+// of each hook the repo itself provides so those keep running.
+//
+// As prepare-commit-msg it stamps provenance trailers on the commit message:
+//   - Gt-Agent from BD_ACTOR (set for every agent session)
+//   - Gt-Bead, Gt-Convoy, Gt-MR from GT_BEAD/GT_CONVOY/GT_MR, then from the
+//     worktree's gt-provenance file (written by gt sling)
+//   - Gt-Bead falls back to the polecat/<name>/<bead>@<ts> branch name
+//
+// Trailers already in the message are kept. Messages with no content yet
+// (editor templates) are left alone so an empty commit still aborts.
+const managedHookScript = `#!/bin/sh
+# Managed by Gas Town (gt): regenerated on clone and by 'gt doctor --fix'.
+# Stamps provenance trailers on agent commits, then chains to the repo's
+# own hook: in the hooks path it had before (gt.chainedHooksPath), else in
+# .githooks/ or .git/hooks/.
+
+hook=$(basename "$0")
+
+gt_trailer() {
+	[ -n "$2" ] || return 0
+	git interpret-trailers --in-place --if-exists doNothing --trailer "$1: $2" "$3"
+}
+
+if [ "$hook" = "prepare-commit-msg" ] && [ -n "$1" ] && grep -v '^#' "$1" | grep -q '[^[:space:]]'; then
+	bead="$GT_BEAD"
+	convoy="$GT_CONVOY"
+	mr="$GT_MR"
+	gitdir=$(git rev-parse --absolute-git-dir 2>/dev/null)
+	if [ -n "$gitdir" ] && [ -f "$gitdir/` + provenanceFile + `" ]; then
+		while IFS='=' read -r key value; do
+			case "$key" in
+			bead) [ -n "$bead" ] || bead="$value" ;;
+			convoy) [ -n "$convoy" ] || convoy="$value" ;;
+			mr) [ -n "$mr" ] || mr="$value" ;;
+			esac
+		done < "$gitdir/` + provenanceFile + `"
+	fi
+	if [ -z "$bead" ]; then
+		branch=$(git symbolic-ref --quiet --short HEAD 2>/dev/null)
+		case "$branch" in
+		polecat/*/*@*)
+			bead=${branch#polecat/*/}
+			bead=${bead%@*}
+			;;
+		esac
+	fi
+	gt_trailer "` + TrailerBead + `" "$bead" "$1"
+	gt_trailer "` + TrailerAgent + `" "$BD_ACTOR" "$1"
+	gt_trailer "` + TrailerConvoy + `" "$convoy" "$1"
+	gt_trailer "` + TrailerMR + `" "$mr" "$1"
+fi
+
+chained=$(git config --get ` + chainedHooksPathKey + ` 2>/dev/null)
+if [ -n "$chained" ]; then
+	# Relative to where git runs hooks (the worktree top), like core.hooksPath
+	case "$chained" in
+	"~/"*) chained="$HOME/${chained#"~/"}" ;;
+	esac
+	if [ -x "$chained/$hook" ]; then
+		exec "$chained/$hook" "$@"
+	fi
+	exit 0
+fi
+top=$(git rev-parse --show-toplevel 2>/dev/null)
+if [ -n "$top" ] && [ -x "$top/.githooks/$hook" ]; then
+	exec "$top/.githooks/$hook" "$@"
+fi
+common=$(git rev-parse --path-format=absolute --git-common-dir 2>/dev/null)
+if [ -n "$common" ] && [ -x "$common/hooks/$hook" ]; then
+	exec "$common/hooks/$hook" "$@"
+fi
+exit 0
+`
+
+// hooksDir returns the managed hooks directory for repoPath (a clone,
+// worktree, or bare repo).
+func hooksDir(repoPath string) (string, error) {
+	cmd := exec.Command("git", "-C", repoPath, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("finding git dir for %s: %w", repoPath, err)
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), managedHooksDir), nil
+}
+
+// ConfigureHooksPath installs Gas Town's managed hooks for repoPath and
+// points core.hooksPath at them. Safe to run repeatedly.
+func ConfigureHooksPath(repoPath string) error {
+	return configureHooksPath(repoPath)
+}
+
+// HooksPathConfigured returns true if repoPath uses the managed hooks and
+// they cover every hook the repo itself provides.
+func HooksPathConfigured(repoPath string) bool {
+	dir, err := hooksDir(repoPath)
+	if err != nil {
+		return false
+	}
+	out, err := exec.Command("git", "-C", repoPath, "config", "--get", "core.hooksPath").Output()
+	if err != nil || strings.TrimSpace(string(out)) != dir {
+		return false
+	}
+	for _, name := range managedHookNames(repoPath) {
+		data, err := os.ReadFile(filepath.Join(dir, name)) //nolint:gosec // G304: path is inside the git dir
+		if err != nil || string(data) != managedHookScript {
+			return false
+		}
+	}
+	return true
+}
+
+// managedHookNames returns the hooks to install for repoPath:
+// prepare-commit-msg, plus each hook the managed hooks chain to.
+func managedHookNames(repoPath string) []string {
+	provided := make(map[string]bool)
+	chained, _ := exec.Command("git", "-C", repoPath, "config", "--get", chainedHooksPathKey).Output()
+	if dir := strings.TrimSpace(string(chained)); dir != "" {
+		for _, name := range listHooks(repoPath, dir) {
+			provided[name] = true
+		}
+	} else {
+		for _, name := range listHooks(repoPath, ".githooks") {
+			provided[name] = true
+		}
+		if out, err := exec.Command("git", "-C", repoPath, "rev-parse", "--path-format=absolute", "--git-common-dir").Output(); err == nil {
+			for _, name := range listHooks(repoPath, filepath.Join(strings.TrimSpace(string(out)), "hooks")) {
+				provided[name] = true
+			}
+		}
+	}
+
+	names := []string{"prepare-commit-msg"}
+	for _, name := range clientHooks {
+		if provided[name] && name != "prepare-commit-msg" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// listHooks returns the client hook names present in a hooks directory.
+// A relative dir is resolved against the worktree like core.hooksPath; in a
+// bare repo it is read from HEAD's tree, which its worktrees check out.
+func listHooks(repoPath, dir string) []string {
+	if strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, dir[2:])
+		}
+	}
+	var entries []string
+	if filepath.IsAbs(dir) {
+		des, _ := os.ReadDir(dir)
+		for _, de := range des {
+			entries = append(entries, de.Name())
+		}
+	} else if top, err := exec.Command("git", "-C", repoPath, "rev-parse", "--show-toplevel").Output(); err == nil {
+		des, _ := os.ReadDir(filepath.Join(strings.TrimSpace(string(top)), dir))
+		for _, de := range des {
+			entries = append(entries, de.Name())
+		}
+	} else if out, err := exec.Command("git", "-C", repoPath, "ls-tree", "--name-only", "HEAD:"+filepath.ToSlash(dir)).Output(); err == nil {
+		entries = strings.Fields(string(out))
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if slices.Contains(clientHooks, entry) {
+			names = append(names, entry)
+		}
+	}
+	return names
+}
+
+// ProvenanceCommit is a commit with its Gas Town trailers.
+type ProvenanceCommit struct {
+	Hash    string    `json:"hash"`
+	Date    time.Time `json:"date"`
+	Author  string    `json:"author"`
+	Subject string    `json:"subject"`
+	Provenance
+}
+
+// LogByProvenance lists commits on all refs whose trailers match want,
+// newest first. Bead, Convoy and MR must match exactly; Agent matches as a
+// substring, so "joe" finds "gastown/crew/joe". With a zero want, every
+// commit carrying any Gas Town trailer is listed. max limits the number of
+// commits (0 for no limit).
+func (g *Git) LogByProvenance(want Provenance, since time.Time, max int) ([]ProvenanceCommit, error) {
+	args := []string{"log", "--all", "-z", "--extended-regexp",
+		"--format=%H%x1f%aI%x1f%an%x1f%s%x1f%(trailers:only,unfold)"}
+
+	var greps []string
+	if want.Bead != "" {
+		greps = append(greps, "^"+TrailerBead+": "+regexp.QuoteMeta(want.Bead)+"$")
+	}
+	if want.Agent != "" {
+		greps = append(greps, "^"+TrailerAgent+": .*"+regexp.QuoteMeta(want.Agent))
+	}
+	if want.Convoy != "" {
+		greps = append(greps, "^"+TrailerConvoy+": "+regexp.QuoteMeta(want.Convoy)+"$")
+	}
+	if want.MR != "" {
+		greps = append(greps, "^"+TrailerMR+": "+regexp.QuoteMeta(want.MR)+"$")
+	}
+	if len(greps) == 0 {
+		greps = append(greps, "^Gt-(Bead|Agent|Convoy|MR): ")
+	}
+	for _, re := range greps {
+		args = append(args, "--grep="+re)
+	}
+	if len(greps) > 1 {
+		args = append(args, "--all-match")
+	}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	if max > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", max))
+	}
+
+	out, err := g.run(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseProvenanceLog(out), nil
+}
+
+// parseProvenanceLog parses NUL-separated records of
+// hash, date, author, subject and trailer block (0x1f-separated).
+func parseProvenanceLog(out string) []ProvenanceCommit {
+	var commits []ProvenanceCommit
+	for _, record := range strings.Split(out, "\x00") {
+		record = strings.TrimLeft(record, "\n")
+		parts := strings.SplitN(record, "\x1f", 5)
+		if len(parts) < 5 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, parts[1])
+		commits = append(commits, ProvenanceCommit{
+			Hash:       parts[0],
+			Date:       date,
+			Author:     parts[2],
+			Subject:    parts[3],
+			Provenance: ParseProvenance(parts[4]),
+		})
+	}
+	return commits
+}
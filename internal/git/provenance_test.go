@@ -0,0 +1,260 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendTrailers(t *testing.T) {
+	p := Provenance{Bead: "gt-abc", Agent: "gastown/refinery", MR: "gt-mr-1"}
+
+	got := AppendTrailers("Merge polecat/nux into main", p)
+	want := "Merge polecat/nux into main\n\nGt-Bead: gt-abc\nGt-Agent: gastown/refinery\nGt-MR: gt-mr-1"
+	if got != want {
+		t.Errorf("AppendTrailers =\n%s\nwant\n%s", got, want)
+	}
+
+	// Existing trailers are kept, the rest join the same block
+	got = AppendTrailers("Fix\n\nGt-Bead: gt-xyz\n", p)
+	want = "Fix\n\nGt-Bead: gt-xyz\nGt-Agent: gastown/refinery\nGt-MR: gt-mr-1"
+	if got != want {
+		t.Errorf("AppendTrailers with existing =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := AppendTrailers("Fix", Provenance{}); got != "Fix" {
+		t.Errorf("AppendTrailers(zero) = %q, want unchanged", got)
+	}
+}
+
+func TestParseProvenance(t *testing.T) {
+	msg := "Add feature\n\nBody text: not a trailer\n\nGt-Bead: gt-abc\ngt-agent: gastown/polecats/nux\nGt-Convoy: hq-cv-1\nSigned-off-by: someone"
+	got := ParseProvenance(msg)
+	want := Provenance{Bead: "gt-abc", Agent: "gastown/polecats/nux", Convoy: "hq-cv-1"}
+	if got != want {
+		t.Errorf("ParseProvenance = %+v, want %+v", got, want)
+	}
+}
+
+func commitFile(t *testing.T, g *Git, dir, name, msg string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(name+"\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := g.Add(name); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := g.Commit(msg); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+}
+
+func TestProvenanceHook(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	// The repo's own hooks must keep running behind the managed ones
+	if err := os.MkdirAll(filepath.Join(dir, ".githooks"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	script := "#!/bin/sh\ntouch " + marker + "\n"
+	if err := os.WriteFile(filepath.Join(dir, ".githooks", "post-commit"), []byte(script), 0755); err != nil { //nolint:gosec // test hook
+		t.Fatal(err)
+	}
+
+	if err := ConfigureHooksPath(dir); err != nil {
+		t.Fatalf("ConfigureHooksPath: %v", err)
+	}
+	if !HooksPathConfigured(dir) {
+		t.Fatal("HooksPathConfigured = false after ConfigureHooksPath")
+	}
+
+	t.Setenv("BD_ACTOR", "gastown/crew/joe")
+	t.Setenv("GT_BEAD", "")
+	t.Setenv("GT_CONVOY", "")
+	t.Setenv("GT_MR", "")
+	if err := WriteProvenance(dir, Provenance{Bead: "gt-abc", Convoy: "hq-cv-1"}); err != nil {
+		t.Fatalf("WriteProvenance: %v", err)
+	}
+	commitFile(t, g, dir, "a.txt", "add a")
+
+	msg, _ := g.run("log", "-1", "--format=%B")
+	got := ParseProvenance(msg)
+	want := Provenance{Bead: "gt-abc", Agent: "gastown/crew/joe", Convoy: "hq-cv-1"}
+	if got != want {
+		t.Errorf("trailers = %+v, want %+v\nmessage:\n%s", got, want, msg)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("repo's .githooks/post-commit did not run")
+	}
+
+	// Without recorded work, polecat branches still name the bead
+	if err := ClearProvenance(dir); err != nil {
+		t.Fatalf("ClearProvenance: %v", err)
+	}
+	if err := g.CreateBranch("polecat/nux/gt-xyz@m1abc"); err != nil {
+		t.Fatalf("CreateBranch: %v", err)
+	}
+	if err := g.Checkout("polecat/nux/gt-xyz@m1abc"); err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	commitFile(t, g, dir, "b.txt", "add b")
+	msg, _ = g.run("log", "-1", "--format=%B")
+	if p := ParseProvenance(msg); p.Bead != "gt-xyz" || p.Convoy != "" {
+		t.Errorf("branch fallback trailers = %+v, want bead gt-xyz only", p)
+	}
+
+	commits, err := g.LogByProvenance(Provenance{Bead: "gt-abc"}, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("LogByProvenance: %v", err)
+	}
+	if len(commits) != 1 || commits[0].Subject != "add a" || commits[0].Convoy != "hq-cv-1" {
+		t.Errorf("LogByProvenance(bead) = %+v, want the one commit for gt-abc", commits)
+	}
+
+	commits, err = g.LogByProvenance(Provenance{Agent: "joe"}, time.Time{}, 0)
+	if err != nil {
+		t.Fatalf("LogByProvenance: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Errorf("LogByProvenance(agent) returned %d commits, want 2", len(commits))
+	}
+	for _, c := range commits {
+		if !strings.HasSuffix(c.Agent, "/joe") {
+			t.Errorf("commit %s agent = %q", c.Hash, c.Agent)
+		}
+	}
+}
+
+func TestProvenanceHookChainsExistingHooksPath(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+
+	// A repo managed by husky/lefthook/etc. already has a hooks path
+	if err := os.MkdirAll(filepath.Join(dir, ".husky"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	marker := filepath.Join(t.TempDir(), "ran")
+	script := "#!/bin/sh\ntouch " + marker + "\n"
+	if err := os.WriteFile(filepath.Join(dir, ".husky", "pre-commit"), []byte(script), 0755); err != nil { //nolint:gosec // test hook
+		t.Fatal(err)
+	}
+	if _, err := g.run("config", "core.hooksPath", ".husky"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Installing twice must not record the managed dir over the original
+	for range 2 {
+		if err := ConfigureHooksPath(dir); err != nil {
+			t.Fatalf("ConfigureHooksPath: %v", err)
+		}
+	}
+	if got, _ := g.run("config", "--get", chainedHooksPathKey); strings.TrimSpace(got) != ".husky" {
+		t.Errorf("%s = %q, want .husky", chainedHooksPathKey, got)
+	}
+
+	t.Setenv("BD_ACTOR", "gastown/crew/joe")
+	commitFile(t, g, dir, "a.txt", "add a")
+	if _, err := os.Stat(marker); err != nil {
+		t.Error("the previous hooks path's pre-commit did not run")
+	}
+	msg, _ := g.run("log", "-1", "--format=%B")
+	if p := ParseProvenance(msg); p.Agent != "gastown/crew/joe" {
+		t.Errorf("trailers = %+v, want the agent stamped", p)
+	}
+}
+
+func TestManagedHooksOnlyCoverRepoHooks(t *testing.T) {
+	dir := initTestRepo(t)
+	hooks, err := hooksDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	installed := func() []string {
+		t.Helper()
+		entries, err := os.ReadDir(hooks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, e := range entries {
+			names = append(names, e.Name())
+		}
+		return names
+	}
+
+	// A stale hook from an earlier install goes away
+	if err := os.MkdirAll(hooks, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(hooks, "pre-rebase"), []byte(managedHookScript), 0755); err != nil { //nolint:gosec // test hook
+		t.Fatal(err)
+	}
+	if err := ConfigureHooksPath(dir); err != nil {
+		t.Fatalf("ConfigureHooksPath: %v", err)
+	}
+	if got := installed(); !reflect.DeepEqual(got, []string{"prepare-commit-msg"}) {
+		t.Errorf("installed hooks = %v, want only prepare-commit-msg", got)
+	}
+
+	// A hook the repo adds later is chained once re-installed
+	if err := os.MkdirAll(filepath.Join(dir, ".githooks"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".githooks", "pre-push"), []byte("#!/bin/sh\n"), 0755); err != nil { //nolint:gosec // test hook
+		t.Fatal(err)
+	}
+	if HooksPathConfigured(dir) {
+		t.Error("HooksPathConfigured = true while .githooks/pre-push isn't chained")
+	}
+	if err := ConfigureHooksPath(dir); err != nil {
+		t.Fatalf("ConfigureHooksPath: %v", err)
+	}
+	if got := installed(); !reflect.DeepEqual(got, []string{"pre-push", "prepare-commit-msg"}) {
+		t.Errorf("installed hooks = %v, want pre-push and prepare-commit-msg", got)
+	}
+	if !HooksPathConfigured(dir) {
+		t.Error("HooksPathConfigured = false after re-install")
+	}
+}
+
+func TestProvenanceHookStampsMROnRebase(t *testing.T) {
+	dir := initTestRepo(t)
+	g := NewGit(dir)
+	if err := ConfigureHooksPath(dir); err != nil {
+		t.Fatalf("ConfigureHooksPath: %v", err)
+	}
+	target, err := g.CurrentBranch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("BD_ACTOR", "gastown/polecats/nux")
+	t.Setenv("GT_BEAD", "gt-abc")
+	t.Setenv("GT_CONVOY", "")
+	t.Setenv("GT_MR", "")
+	if err := g.CreateBranch("polecat/nux"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Checkout("polecat/nux"); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, g, dir, "a.txt", "add a")
+
+	// The refinery patrol rebases the MR with GT_MR set, as its own agent
+	t.Setenv("BD_ACTOR", "gastown/refinery")
+	t.Setenv("GT_BEAD", "")
+	t.Setenv("GT_MR", "gt-mr-1")
+	if _, err := g.run("rebase", "--force-rebase", target); err != nil {
+		t.Fatalf("rebase: %v", err)
+	}
+	msg, _ := g.run("log", "-1", "--format=%B")
+	want := Provenance{Bead: "gt-abc", Agent: "gastown/polecats/nux", MR: "gt-mr-1"}
+	if got := ParseProvenance(msg); got != want {
+		t.Errorf("rebased trailers = %+v, want %+v\nmessage:\n%s", got, want, msg)
+	}
+}
@@ -0,0 +1,512 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFormat is an on-disk mail interchange format.
+type ExportFormat string
+
+const (
+	// FormatMaildir writes one RFC 5322 file per message under cur/ and new/.
+	FormatMaildir ExportFormat = "maildir"
+
+	// FormatMbox writes all messages to a single mboxrd file.
+	FormatMbox ExportFormat = "mbox"
+
+	// FormatJSONL writes one Message per line, same as the mailbox archive.
+	FormatJSONL ExportFormat = "jsonl"
+)
+
+// messageIDDomain is the domain part of generated Message-ID headers.
+const messageIDDomain = "gastown"
+
+// Gas Town metadata that has no standard header is carried in X-Gt-* headers.
+const (
+	headerThread   = "X-Gt-Thread-Id"
+	headerType     = "X-Gt-Type"
+	headerPriority = "X-Gt-Priority"
+	headerRead     = "X-Gt-Read"
+	headerPinned   = "X-Gt-Pinned"
+	headerWisp     = "X-Gt-Wisp"
+	headerQueue    = "X-Gt-Queue"
+	headerChannel  = "X-Gt-Channel"
+	headerClaimed  = "X-Gt-Claimed-By"
+	headerDelivery = "X-Gt-Delivery"
+)
+
+// ParseExportFormat validates a format name.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch f := ExportFormat(strings.ToLower(s)); f {
+	case FormatMaildir, FormatMbox, FormatJSONL:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want maildir, mbox, jsonl)", s)
+}
+
+// DetectImportFormat guesses the format of an import source:
+// directories are Maildir, *.jsonl files are JSONL, anything else is mbox.
+func DetectImportFormat(path string) (ExportFormat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return FormatMaildir, nil
+	}
+	if strings.HasSuffix(path, ".jsonl") || strings.HasSuffix(path, ".json") {
+		return FormatJSONL, nil
+	}
+	return FormatMbox, nil
+}
+
+// ParseExportTime parses a --since/--until value using the same date syntax
+// as mail search (2006-01-02, RFC3339, today, 7d, ...).
+func ParseExportTime(s string) (time.Time, error) {
+	return parseSearchDate(s, timeNow())
+}
+
+// ExportFilter selects which messages to export.
+type ExportFilter struct {
+	ThreadID string    // Only this thread (empty = all)
+	Since    time.Time // Sent at or after (zero = unbounded)
+	Until    time.Time // Sent before (zero = unbounded)
+}
+
+func (f ExportFilter) matches(msg *Message) bool {
+	if f.ThreadID != "" && msg.ThreadID != f.ThreadID {
+		return false
+	}
+	if !f.Since.IsZero() && msg.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !msg.Timestamp.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// CollectForExport gathers inbox and archived messages matching the filter,
+// oldest first. For beads mailboxes the archive is shared by the whole town,
+// so only archived messages addressed to (or CC'd to) this identity are kept.
+func (m *Mailbox) CollectForExport(filter ExportFilter) ([]*Message, error) {
+	inbox, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	archived, err := m.ListArchived()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var messages []*Message
+	for _, msg := range inbox {
+		if filter.matches(msg) && !seen[msg.ID] {
+			seen[msg.ID] = true
+			messages = append(messages, msg)
+		}
+	}
+	for _, msg := range archived {
+		if !m.legacy && !m.isRecipient(msg) {
+			continue
+		}
+		if filter.matches(msg) && !seen[msg.ID] {
+			seen[msg.ID] = true
+			messages = append(messages, msg)
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+	return messages, nil
+}
+
+// isRecipient reports whether msg was addressed or CC'd to this mailbox.
+func (m *Mailbox) isRecipient(msg *Message) bool {
+	if AddressToIdentity(msg.To) == m.identity {
+		return true
+	}
+	for _, cc := range msg.CC {
+		if AddressToIdentity(cc) == m.identity {
+			return true
+		}
+	}
+	return false
+}
+
+// ImportArchive appends messages to the mailbox archive, skipping any whose
+// ID is already archived. Returns the number of messages imported.
+func (m *Mailbox) ImportArchive(messages []*Message) (int, error) {
+	existing, err := m.ListArchived()
+	if err != nil {
+		return 0, err
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, msg := range existing {
+		seen[msg.ID] = true
+	}
+
+	imported := 0
+	for _, msg := range messages {
+		if msg.ID == "" || seen[msg.ID] {
+			continue
+		}
+		if err := m.appendToArchive(msg); err != nil {
+			return imported, err
+		}
+		seen[msg.ID] = true
+		imported++
+	}
+	return imported, nil
+}
+
+// WriteJSONL writes messages one JSON object per line.
+func WriteJSONL(w io.Writer, messages []*Message) error {
+	enc := json.NewEncoder(w)
+	for _, msg := range messages {
+		if err := enc.Encode(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadJSONL reads messages written by WriteJSONL or the mailbox archive.
+// Malformed lines are skipped.
+func ReadJSONL(r io.Reader) ([]*Message, error) {
+	var messages []*Message
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var msg Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			continue // Skip malformed lines
+		}
+		messages = append(messages, &msg)
+	}
+	return messages, scanner.Err()
+}
+
+// WriteMbox writes messages in mboxrd format: each message is preceded by a
+// "From " separator line and body lines matching ^>*From are quoted with ">".
+func WriteMbox(w io.Writer, messages []*Message) error {
+	bw := bufio.NewWriter(w)
+	for _, msg := range messages {
+		sender := AddressToIdentity(msg.From)
+		if sender == "" {
+			sender = "MAILER-DAEMON"
+		}
+		fmt.Fprintf(bw, "From %s %s\n", strings.ReplaceAll(sender, " ", "_"), msg.Timestamp.UTC().Format(time.ANSIC))
+
+		var buf bytes.Buffer
+		writeRFC5322(&buf, msg)
+		for _, line := range strings.SplitAfter(buf.String(), "\n") {
+			if isMboxFromLine(line) {
+				bw.WriteString(">")
+			}
+			bw.WriteString(line)
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// ReadMbox reads messages from an mboxrd (or mboxo) file.
+func ReadMbox(r io.Reader) ([]*Message, error) {
+	var messages []*Message
+	var cur bytes.Buffer
+	inMessage := false
+
+	flush := func() error {
+		if !inMessage {
+			return nil
+		}
+		// The separator's trailing blank line is not part of the message.
+		data := bytes.TrimSuffix(cur.Bytes(), []byte("\n"))
+		msg, err := parseRFC5322(data)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, msg)
+		cur.Reset()
+		return nil
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			switch {
+			case strings.HasPrefix(line, "From "):
+				if ferr := flush(); ferr != nil {
+					return nil, ferr
+				}
+				inMessage = true
+			case inMessage:
+				if isMboxFromLine(strings.TrimPrefix(line, ">")) && strings.HasPrefix(line, ">") {
+					line = line[1:]
+				}
+				cur.WriteString(line)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// isMboxFromLine reports whether a line must be quoted in mboxrd.
+func isMboxFromLine(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, ">"), "From ")
+}
+
+// WriteMaildir writes messages into a Maildir at dir, creating cur/, new/ and
+// tmp/ as needed. Read messages go to cur/ with the Seen flag; unread to new/.
+func WriteMaildir(dir string, messages []*Message) error {
+	for _, sub := range []string{"cur", "new", "tmp"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return err
+		}
+	}
+
+	for _, msg := range messages {
+		base := fmt.Sprintf("%d.%s.%s", msg.Timestamp.Unix(), maildirSafe(msg.ID), messageIDDomain)
+		var path string
+		if msg.Read {
+			path = filepath.Join(dir, "cur", base+":2,S")
+		} else {
+			path = filepath.Join(dir, "new", base)
+		}
+
+		var buf bytes.Buffer
+		writeRFC5322(&buf, msg)
+
+		// Deliver via tmp/ so readers never see a partial file.
+		tmp := filepath.Join(dir, "tmp", base)
+		if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil { //nolint:gosec // G306: exported mail is non-sensitive operational data
+			return err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			_ = os.Remove(tmp)
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadMaildir reads all messages from a Maildir's cur/ and new/ directories.
+func ReadMaildir(dir string) ([]*Message, error) {
+	var messages []*Message
+	for _, sub := range []string{"cur", "new"} {
+		entries, err := os.ReadDir(filepath.Join(dir, sub))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, sub, entry.Name())) //nolint:gosec // G304: caller-chosen import path
+			if err != nil {
+				return nil, err
+			}
+			msg, err := parseRFC5322(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+			}
+			// Maildir flags are authoritative for read state.
+			if _, flags, ok := strings.Cut(entry.Name(), ":2,"); ok {
+				msg.Read = strings.Contains(flags, "S")
+			} else if sub == "new" {
+				msg.Read = false
+			}
+			messages = append(messages, msg)
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+	return messages, nil
+}
+
+// maildirSafe replaces characters that are not allowed in Maildir file names.
+func maildirSafe(s string) string {
+	return strings.NewReplacer("/", "_", ":", "_").Replace(s)
+}
+
+// writeRFC5322 renders a message as an RFC 5322 email. Thread structure is
+// carried in Message-ID/In-Reply-To/References so mail clients thread it.
+func writeRFC5322(w io.Writer, msg *Message) {
+	header := func(k, v string) {
+		if v != "" {
+			fmt.Fprintf(w, "%s: %s\n", k, v)
+		}
+	}
+
+	header("From", formatAddress(msg.From))
+	header("To", formatAddress(msg.To))
+	if len(msg.CC) > 0 {
+		cc := make([]string, 0, len(msg.CC))
+		for _, addr := range msg.CC {
+			cc = append(cc, formatAddress(addr))
+		}
+		header("Cc", strings.Join(cc, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", msg.Timestamp.Format(time.RFC1123Z))
+	header("Message-ID", formatMessageID(msg.ID))
+	if msg.ReplyTo != "" {
+		header("In-Reply-To", formatMessageID(msg.ReplyTo))
+		header("References", formatMessageID(msg.ReplyTo))
+	}
+	header(headerThread, msg.ThreadID)
+	header(headerType, string(msg.Type))
+	header(headerPriority, string(msg.Priority))
+	header(headerRead, strconv.FormatBool(msg.Read))
+	if msg.Pinned {
+		header(headerPinned, "true")
+	}
+	if msg.Wisp {
+		header(headerWisp, "true")
+	}
+	header(headerDelivery, string(msg.Delivery))
+	header(headerQueue, msg.Queue)
+	header(headerChannel, msg.Channel)
+	header(headerClaimed, msg.ClaimedBy)
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	fmt.Fprint(w, "\n")
+
+	body := msg.Body
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	fmt.Fprint(w, body)
+}
+
+// parseRFC5322 converts an email produced by writeRFC5322 (or a similar
+// client-edited copy) back into a Message.
+func parseRFC5322(data []byte) (*Message, error) {
+	em, err := netmail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parsing message: %w", err)
+	}
+	h := em.Header
+
+	body, err := io.ReadAll(em.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := new(mime.WordDecoder)
+	subject, err := dec.DecodeHeader(h.Get("Subject"))
+	if err != nil {
+		subject = h.Get("Subject")
+	}
+
+	msg := &Message{
+		ID:        parseMessageID(h.Get("Message-ID")),
+		From:      parseAddress(h.Get("From")),
+		To:        parseAddress(h.Get("To")),
+		Subject:   subject,
+		Body:      strings.TrimSuffix(string(body), "\n"),
+		ReplyTo:   parseMessageID(h.Get("In-Reply-To")),
+		ThreadID:  h.Get(headerThread),
+		Type:      ParseMessageType(h.Get(headerType)),
+		Priority:  ParsePriority(h.Get(headerPriority)),
+		Read:      h.Get(headerRead) == "true",
+		Pinned:    h.Get(headerPinned) == "true",
+		Wisp:      h.Get(headerWisp) == "true",
+		Delivery:  Delivery(h.Get(headerDelivery)),
+		Queue:     h.Get(headerQueue),
+		Channel:   h.Get(headerChannel),
+		ClaimedBy: h.Get(headerClaimed),
+	}
+	if cc := h.Get("Cc"); cc != "" {
+		for _, addr := range strings.Split(cc, ",") {
+			if a := parseAddress(addr); a != "" {
+				msg.CC = append(msg.CC, a)
+			}
+		}
+	}
+	if ts, err := h.Date(); err == nil {
+		msg.Timestamp = ts
+	}
+	if msg.ID == "" {
+		msg.ID = generateID()
+	}
+	if msg.ThreadID == "" {
+		msg.ThreadID = generateThreadID()
+	}
+	return msg, nil
+}
+
+// formatAddress renders a Gas Town address as an RFC 5322 address, using the
+// address itself as the display name: "gastown/Toast" <gastown_Toast@gastown>.
+func formatAddress(addr string) string {
+	if addr == "" {
+		return ""
+	}
+	local := strings.NewReplacer("/", "_", " ", "_").Replace(strings.TrimSuffix(addr, "/"))
+	return fmt.Sprintf("%q <%s@%s>", addr, local, messageIDDomain)
+}
+
+// parseAddress recovers a Gas Town address from an RFC 5322 address. The
+// display name is preferred; otherwise the local part is used as-is.
+func parseAddress(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	a, err := netmail.ParseAddress(s)
+	if err != nil {
+		return s
+	}
+	if a.Name != "" {
+		return a.Name
+	}
+	local, _, _ := strings.Cut(a.Address, "@")
+	return local
+}
+
+func formatMessageID(id string) string {
+	return "<" + id + "@" + messageIDDomain + ">"
+}
+
+func parseMessageID(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "<")
+	s = strings.TrimSuffix(s, ">")
+	s = strings.TrimSuffix(s, "@"+messageIDDomain)
+	return s
+}
@@ -0,0 +1,136 @@
+package mail
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func exportFixture() []*Message {
+	ts := time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC)
+	return []*Message{
+		{
+			ID: "msg-a1", From: "mayor/", To: "gastown/Toast", CC: []string{"gastown/Nux", "deacon/"},
+			Subject: "Überprüfung needed", Body: "Please review.\nFrom the top:\n>From quoted",
+			Timestamp: ts, Priority: PriorityHigh, Type: TypeTask, ThreadID: "thread-1", Read: true,
+		},
+		{
+			ID: "msg-a2", From: "gastown/Toast", To: "mayor/",
+			Subject: "Re: Überprüfung needed", Body: "Done.",
+			Timestamp: ts.Add(time.Hour), Priority: PriorityNormal, Type: TypeReply,
+			ThreadID: "thread-1", ReplyTo: "msg-a1",
+		},
+	}
+}
+
+func assertRoundTrip(t *testing.T, got []*Message) {
+	t.Helper()
+	want := exportFixture()
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.ID != w.ID || g.From != w.From || g.To != w.To || g.Subject != w.Subject || g.Body != w.Body {
+			t.Errorf("message %d = %+v, want %+v", i, g, w)
+		}
+		if g.ThreadID != w.ThreadID || g.ReplyTo != w.ReplyTo || g.Type != w.Type || g.Priority != w.Priority || g.Read != w.Read {
+			t.Errorf("message %d metadata = %+v, want %+v", i, g, w)
+		}
+		if !g.Timestamp.Equal(w.Timestamp) {
+			t.Errorf("message %d timestamp = %v, want %v", i, g.Timestamp, w.Timestamp)
+		}
+		if len(g.CC) != len(w.CC) {
+			t.Errorf("message %d CC = %v, want %v", i, g.CC, w.CC)
+			continue
+		}
+		for j := range w.CC {
+			if g.CC[j] != w.CC[j] {
+				t.Errorf("message %d CC = %v, want %v", i, g.CC, w.CC)
+			}
+		}
+	}
+}
+
+func TestMboxRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteMbox(&buf, exportFixture()); err != nil {
+		t.Fatalf("WriteMbox error: %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("\n>From the top:")) || !bytes.Contains(buf.Bytes(), []byte("\n>>From quoted")) {
+		t.Errorf("mbox body From lines not quoted:\n%s", buf.String())
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("In-Reply-To: <msg-a1@gastown>")) {
+		t.Errorf("mbox missing In-Reply-To header:\n%s", buf.String())
+	}
+
+	got, err := ReadMbox(&buf)
+	if err != nil {
+		t.Fatalf("ReadMbox error: %v", err)
+	}
+	assertRoundTrip(t, got)
+}
+
+func TestMaildirRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "Maildir")
+	if err := WriteMaildir(dir, exportFixture()); err != nil {
+		t.Fatalf("WriteMaildir error: %v", err)
+	}
+
+	// Read mail goes to cur/ with the Seen flag, unread to new/.
+	if matches, _ := filepath.Glob(filepath.Join(dir, "cur", "*:2,S")); len(matches) != 1 {
+		t.Errorf("cur/ has %d seen messages, want 1", len(matches))
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "new", "*")); len(matches) != 1 {
+		t.Errorf("new/ has %d messages, want 1", len(matches))
+	}
+
+	got, err := ReadMaildir(dir)
+	if err != nil {
+		t.Fatalf("ReadMaildir error: %v", err)
+	}
+	assertRoundTrip(t, got)
+}
+
+func TestJSONLRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSONL(&buf, exportFixture()); err != nil {
+		t.Fatalf("WriteJSONL error: %v", err)
+	}
+	got, err := ReadJSONL(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSONL error: %v", err)
+	}
+	assertRoundTrip(t, got)
+}
+
+func TestExportImportMailbox(t *testing.T) {
+	src := NewMailbox(t.TempDir())
+	for _, msg := range exportFixture() {
+		if err := src.Append(msg); err != nil {
+			t.Fatalf("Append error: %v", err)
+		}
+	}
+
+	msgs, err := src.CollectForExport(ExportFilter{Since: time.Date(2026, 2, 1, 10, 30, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatalf("CollectForExport error: %v", err)
+	}
+	if len(msgs) != 1 || msgs[0].ID != "msg-a2" {
+		t.Errorf("CollectForExport(since) = %v, want [msg-a2]", msgs)
+	}
+
+	dst := NewMailbox(t.TempDir())
+	n, err := dst.ImportArchive(exportFixture())
+	if err != nil || n != 2 {
+		t.Fatalf("ImportArchive = %d, %v; want 2, nil", n, err)
+	}
+	// Re-importing skips already-archived IDs.
+	if n, err := dst.ImportArchive(exportFixture()); err != nil || n != 0 {
+		t.Errorf("second ImportArchive = %d, %v; want 0, nil", n, err)
+	}
+	if ids := searchIDs(t, dst, "needed is:archived"); len(ids) != 2 {
+		t.Errorf("imported mail not searchable: %v", ids)
+	}
+}
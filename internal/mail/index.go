@@ -0,0 +1,703 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// searchIndexVersion is bumped whenever the on-disk index layout changes.
+// Indexes with a different version are discarded and rebuilt.
+const searchIndexVersion = 2
+
+// subjectWeight is how much more a subject hit counts than a body hit.
+const subjectWeight = 3.0
+
+// compactMinRecords is how many records the index log may hold before dead
+// records (removed or superseded messages) are compacted away.
+var compactMinRecords = 1000
+
+// searchIndex is an incremental inverted index over a mailbox and its archive.
+//
+// On disk it is an append-only JSONL log: a version header, then one record
+// per indexed message carrying its metadata and term counts, plus records
+// for removals, read-state changes and the archive position. Searches append
+// only what changed since the last one and write nothing otherwise; the log
+// is compacted once dead records outnumber live ones. Message bodies are not
+// stored: inbox hits come from the current inbox, archive hits are read back
+// from the archive by byte offset.
+type searchIndex struct {
+	docs     map[string]*indexDoc
+	postings map[string]map[string][2]int // term -> doc key -> [subject tf, body tf]
+	archive  archivePosition
+
+	records int           // Records in the on-disk log
+	pending []indexRecord // Records not yet appended
+	rewrite bool          // Log must be rewritten from scratch
+}
+
+// archivePosition records how far the archive has been indexed.
+type archivePosition struct {
+	Offset int64  `json:"offset"`
+	Head   string `json:"head,omitempty"` // hash of first archive line, detects rewrites
+}
+
+// indexRecord is one line of the index log. A record with a Doc adds or
+// replaces a message, one with Read updates its read state, and one with
+// only a Key removes it.
+type indexRecord struct {
+	Version int               `json:"version,omitempty"` // Header line only
+	Key     string            `json:"k,omitempty"`
+	Doc     *indexDoc         `json:"d,omitempty"`
+	Terms   map[string][2]int `json:"t,omitempty"`
+	Read    *bool             `json:"r,omitempty"`
+	Archive *archivePosition  `json:"a,omitempty"`
+}
+
+// indexDoc holds the filterable metadata for one indexed message.
+type indexDoc struct {
+	ID        string      `json:"id"`
+	From      string      `json:"from"`
+	To        string      `json:"to,omitempty"`
+	CC        []string    `json:"cc,omitempty"`
+	Timestamp time.Time   `json:"ts"`
+	Type      MessageType `json:"type,omitempty"`
+	ThreadID  string      `json:"thread,omitempty"`
+	Priority  Priority    `json:"priority,omitempty"`
+	Read      bool        `json:"read,omitempty"`
+	Archived  bool        `json:"archived,omitempty"`
+	Length    int         `json:"len"`    // Number of terms (for length normalization)
+	Offset    int64       `json:"offset"` // Byte offset in the archive (archived docs only)
+
+	terms []string // Distinct terms, for removal
+}
+
+// SearchResult is a message matched by Search with its relevance score.
+type SearchResult struct {
+	Message *Message `json:"message"`
+	Score   float64  `json:"score"`
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:     make(map[string]*indexDoc),
+		postings: make(map[string]map[string][2]int),
+		rewrite:  true,
+	}
+}
+
+// IndexPath returns the path to the on-disk search index for this mailbox.
+func (m *Mailbox) IndexPath() string {
+	if m.legacy {
+		return m.path + ".idx"
+	}
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(strings.TrimSuffix(m.identity, "/"))
+	if name == "" {
+		name = "_"
+	}
+	return filepath.Join(m.beadsDir, "mail-index", name+".json")
+}
+
+// RebuildIndex discards the on-disk search index so the next search rebuilds it.
+func (m *Mailbox) RebuildIndex() error {
+	if err := os.Remove(m.IndexPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// loadSearchIndex replays the index log, returning a fresh index if it is
+// missing, unreadable, or from a different version. A torn line from an
+// interrupted append is skipped and its message re-indexed; the log is then
+// rewritten so later appends don't land on the torn line.
+func loadSearchIndex(path string) *searchIndex {
+	file, err := os.Open(path) //nolint:gosec // G304: path is derived from mailbox location
+	if err != nil {
+		return newSearchIndex()
+	}
+	defer func() { _ = file.Close() }()
+
+	idx := newSearchIndex()
+	torn := false
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec indexRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			torn = true
+			continue
+		}
+		if idx.records == 0 && rec.Version != searchIndexVersion {
+			return newSearchIndex()
+		}
+		idx.apply(&rec)
+		idx.records++
+	}
+	if scanner.Err() != nil || idx.records == 0 {
+		return newSearchIndex()
+	}
+	idx.rewrite = torn
+	return idx
+}
+
+// apply updates the in-memory index with one log record.
+func (idx *searchIndex) apply(rec *indexRecord) {
+	switch {
+	case rec.Archive != nil:
+		idx.archive = *rec.Archive
+	case rec.Key == "":
+		// Header
+	case rec.Doc != nil:
+		idx.drop(rec.Key)
+		doc := rec.Doc
+		doc.terms = make([]string, 0, len(rec.Terms))
+		for term, c := range rec.Terms {
+			postings := idx.postings[term]
+			if postings == nil {
+				postings = make(map[string][2]int)
+				idx.postings[term] = postings
+			}
+			postings[rec.Key] = c
+			doc.terms = append(doc.terms, term)
+		}
+		idx.docs[rec.Key] = doc
+	case rec.Read != nil:
+		if doc := idx.docs[rec.Key]; doc != nil {
+			doc.Read = *rec.Read
+		}
+	default:
+		idx.drop(rec.Key)
+	}
+}
+
+// record applies a change and queues it for the on-disk log.
+func (idx *searchIndex) record(rec indexRecord) {
+	idx.apply(&rec)
+	idx.pending = append(idx.pending, rec)
+}
+
+// save appends pending records to the log, or rewrites the log when it is
+// new, stale, or mostly dead records. Nothing is written if nothing changed.
+// Concurrent writers may race; a lost update is re-indexed by the next search.
+func (idx *searchIndex) save(path string) error {
+	if len(idx.pending) == 0 && !idx.rewrite {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	live := len(idx.docs) + 2 // header and archive position
+	if idx.rewrite || (idx.records+len(idx.pending) > compactMinRecords && idx.records+len(idx.pending) > 2*live) {
+		return idx.compact(path)
+	}
+
+	var buf bytes.Buffer
+	for i := range idx.pending {
+		data, err := json.Marshal(&idx.pending[i])
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644) //nolint:gosec // G304: path is derived from mailbox location
+	if err != nil {
+		return err
+	}
+	// One write per search keeps concurrent appends from interleaving.
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	idx.records += len(idx.pending)
+	idx.pending = nil
+	return nil
+}
+
+// compact writes the live index to a new log and atomically replaces the old one.
+func (idx *searchIndex) compact(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	records := 0
+	write := func(rec *indexRecord) {
+		if err == nil {
+			err = enc.Encode(rec)
+			records++
+		}
+	}
+
+	write(&indexRecord{Version: searchIndexVersion})
+	archive := idx.archive
+	write(&indexRecord{Archive: &archive})
+	keys := make([]string, 0, len(idx.docs))
+	for key := range idx.docs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		doc := idx.docs[key]
+		terms := make(map[string][2]int, len(doc.terms))
+		for _, term := range doc.terms {
+			terms[term] = idx.postings[term][key]
+		}
+		write(&indexRecord{Key: key, Doc: doc, Terms: terms})
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	idx.records = records
+	idx.pending = nil
+	idx.rewrite = false
+	return nil
+}
+
+// add indexes a message under the given doc key.
+func (idx *searchIndex) add(key string, msg *Message, archived bool, offset int64) {
+	doc := &indexDoc{
+		ID:        msg.ID,
+		From:      msg.From,
+		To:        msg.To,
+		CC:        msg.CC,
+		Timestamp: msg.Timestamp,
+		Type:      msg.Type,
+		ThreadID:  msg.ThreadID,
+		Priority:  msg.Priority,
+		Read:      msg.Read || archived,
+		Archived:  archived,
+		Offset:    offset,
+	}
+
+	counts := make(map[string][2]int)
+	for _, t := range tokenize(msg.Subject) {
+		c := counts[t]
+		c[0]++
+		counts[t] = c
+		doc.Length++
+	}
+	for _, t := range tokenize(msg.Body) {
+		c := counts[t]
+		c[1]++
+		counts[t] = c
+		doc.Length++
+	}
+
+	idx.record(indexRecord{Key: key, Doc: doc, Terms: counts})
+}
+
+// drop removes a doc and its postings from memory.
+func (idx *searchIndex) drop(key string) {
+	doc, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		if postings := idx.postings[term]; postings != nil {
+			delete(postings, key)
+			if len(postings) == 0 {
+				delete(idx.postings, term)
+			}
+		}
+	}
+	delete(idx.docs, key)
+}
+
+func inboxDocKey(id string) string      { return "i:" + id }
+func archiveDocKey(offset int64) string { return "a:" + strconv.FormatInt(offset, 10) }
+
+// syncInbox brings inbox docs in line with the current inbox contents.
+func (idx *searchIndex) syncInbox(inbox []*Message) {
+	current := make(map[string]*Message, len(inbox))
+	for _, msg := range inbox {
+		current[inboxDocKey(msg.ID)] = msg
+	}
+
+	for key, doc := range idx.docs {
+		if doc.Archived {
+			continue
+		}
+		msg, ok := current[key]
+		if !ok {
+			idx.record(indexRecord{Key: key})
+			continue
+		}
+		// Read state changes without the content changing.
+		if doc.Read != msg.Read {
+			read := msg.Read
+			idx.record(indexRecord{Key: key, Read: &read})
+		}
+	}
+
+	for key, msg := range current {
+		if _, ok := idx.docs[key]; !ok {
+			idx.add(key, msg, false, -1)
+		}
+	}
+}
+
+// syncArchive indexes archive lines appended since the last sync. If the
+// archive was truncated or rewritten (e.g. by PurgeArchive), archived docs are
+// dropped and the archive is indexed from the start.
+func (idx *searchIndex) syncArchive(archivePath string) error {
+	file, err := os.Open(archivePath) //nolint:gosec // G304: path is derived from mailbox location
+	if err != nil {
+		if os.IsNotExist(err) {
+			if idx.archive.Offset > 0 {
+				idx.resetArchive()
+			}
+			return nil
+		}
+		return err
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := bufio.NewReader(file)
+	first, err := reader.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	head := ""
+	if len(first) > 0 && first[len(first)-1] == '\n' {
+		sum := sha256.Sum256(first)
+		head = hex.EncodeToString(sum[:8])
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < idx.archive.Offset || (idx.archive.Offset > 0 && head != idx.archive.Head) {
+		idx.resetArchive()
+	}
+
+	if info.Size() == idx.archive.Offset {
+		return nil
+	}
+	if _, err := file.Seek(idx.archive.Offset, io.SeekStart); err != nil {
+		return err
+	}
+	reader.Reset(file)
+
+	offset := idx.archive.Offset
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			// EOF or a partially written line: stop and pick it up next time.
+			break
+		}
+		lineOffset := offset
+		offset += int64(len(line))
+
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			var msg Message
+			if jsonErr := json.Unmarshal(trimmed, &msg); jsonErr == nil {
+				idx.add(archiveDocKey(lineOffset), &msg, true, lineOffset)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	if offset != idx.archive.Offset || head != idx.archive.Head {
+		idx.record(indexRecord{Archive: &archivePosition{Offset: offset, Head: head}})
+	}
+	return nil
+}
+
+// resetArchive drops all archived docs so the archive is re-indexed. The
+// log is rewritten rather than filled with a removal per archived message.
+func (idx *searchIndex) resetArchive() {
+	for key, doc := range idx.docs {
+		if doc.Archived {
+			idx.drop(key)
+		}
+	}
+	idx.archive = archivePosition{}
+	idx.rewrite = true
+}
+
+// openIndex loads the index and syncs it with the archive and, unless the
+// query is limited to archived mail, the inbox. It returns the current inbox
+// by doc key, which inbox hits are read from.
+func (m *Mailbox) openIndex(archivedOnly bool) (*searchIndex, map[string]*Message, error) {
+	idx := loadSearchIndex(m.IndexPath())
+
+	inbox := make(map[string]*Message)
+	if !archivedOnly {
+		messages, err := m.List()
+		if err != nil {
+			return nil, nil, err
+		}
+		idx.syncInbox(messages)
+		for _, msg := range messages {
+			inbox[inboxDocKey(msg.ID)] = msg
+		}
+	}
+
+	if err := idx.syncArchive(m.ArchivePath()); err != nil {
+		return nil, nil, err
+	}
+
+	// Saving is best-effort: a read-only mailbox can still be searched.
+	_ = idx.save(m.IndexPath())
+
+	return idx, inbox, nil
+}
+
+// termPostings returns the postings for a term. A term matches every indexed
+// word containing it, so "deploy" finds "deployment" and part of an ID finds
+// the whole ID; a trailing "*" matches only words starting with it. Counts
+// from all matching words are summed per doc.
+func (idx *searchIndex) termPostings(term string) (map[string][2]int, int) {
+	match := strings.Contains
+	if strings.HasSuffix(term, "*") {
+		term = strings.TrimSuffix(term, "*")
+		match = strings.HasPrefix
+	}
+	if term == "" {
+		return nil, 0
+	}
+
+	merged := make(map[string][2]int)
+	for t, postings := range idx.postings {
+		if !match(t, term) {
+			continue
+		}
+		for key, c := range postings {
+			prev := merged[key]
+			merged[key] = [2]int{prev[0] + c[0], prev[1] + c[1]}
+		}
+	}
+	return merged, len(merged)
+}
+
+// search runs a parsed query against the index and returns ranked doc keys.
+func (idx *searchIndex) search(q *SearchQuery, subjectOnly, bodyOnly bool) []scoredDoc {
+	// Phrase words narrow candidates the same way plain terms do.
+	terms := append([]string{}, q.Terms...)
+	for _, phrase := range q.Phrases {
+		terms = append(terms, tokenize(phrase)...)
+	}
+
+	var candidates map[string]float64
+	if len(terms) == 0 {
+		candidates = make(map[string]float64, len(idx.docs))
+		for key := range idx.docs {
+			candidates[key] = 0
+		}
+	} else {
+		avgLen := idx.averageLength()
+		n := float64(len(idx.docs))
+		for i, term := range terms {
+			postings, df := idx.termPostings(term)
+			idf := math.Log(1 + (n-float64(df)+0.5)/(float64(df)+0.5))
+
+			next := make(map[string]float64)
+			for key, c := range postings {
+				if i > 0 {
+					if _, ok := candidates[key]; !ok {
+						continue
+					}
+				}
+				subj, body := float64(c[0]), float64(c[1])
+				if subjectOnly {
+					body = 0
+				} else if bodyOnly {
+					subj = 0
+				}
+				tf := subjectWeight*subj + body
+				if tf == 0 {
+					continue
+				}
+				norm := 0.25 + 0.75*float64(idx.docs[key].Length)/avgLen
+				next[key] = candidates[key] + idf*tf/(tf+1.2*norm)
+			}
+			candidates = next
+			if len(candidates) == 0 {
+				break
+			}
+		}
+	}
+
+	results := make([]scoredDoc, 0, len(candidates))
+	for key, score := range candidates {
+		doc := idx.docs[key]
+		if doc == nil || !q.matchesMeta(doc) {
+			continue
+		}
+		results = append(results, scoredDoc{key: key, doc: doc, score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].score != results[j].score {
+			return results[i].score > results[j].score
+		}
+		return results[i].doc.Timestamp.After(results[j].doc.Timestamp)
+	})
+
+	return results
+}
+
+func (idx *searchIndex) averageLength() float64 {
+	if len(idx.docs) == 0 {
+		return 1
+	}
+	total := 0
+	for _, doc := range idx.docs {
+		total += doc.Length
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(total) / float64(len(idx.docs))
+}
+
+type scoredDoc struct {
+	key   string
+	doc   *indexDoc
+	score float64
+}
+
+// archiveReader loads archived messages by byte offset.
+type archiveReader struct {
+	path string
+	file *os.File
+}
+
+func (r *archiveReader) read(offset int64) (*Message, error) {
+	if r.file == nil {
+		f, err := os.Open(r.path) //nolint:gosec // G304: path is derived from mailbox location
+		if err != nil {
+			return nil, err
+		}
+		r.file = f
+	}
+	if _, err := r.file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	line, err := bufio.NewReader(r.file).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	var msg Message
+	if err := json.Unmarshal(bytes.TrimSpace(line), &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (r *archiveReader) close() {
+	if r.file != nil {
+		_ = r.file.Close()
+	}
+}
+
+// matchesPhrases checks that every phrase appears literally in the searched fields.
+func matchesPhrases(msg *Message, phrases []string, subjectOnly, bodyOnly bool) bool {
+	subject := strings.ToLower(msg.Subject)
+	body := strings.ToLower(msg.Body)
+	for _, phrase := range phrases {
+		switch {
+		case subjectOnly:
+			if !strings.Contains(subject, phrase) {
+				return false
+			}
+		case bodyOnly:
+			if !strings.Contains(body, phrase) {
+				return false
+			}
+		default:
+			if !strings.Contains(subject, phrase) && !strings.Contains(body, phrase) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// searchIndexed runs a search against the on-disk index.
+func (m *Mailbox) searchIndexed(opts SearchOptions) ([]SearchResult, error) {
+	q, err := ParseSearchQuery(opts.Query, timeNow())
+	if err != nil {
+		return nil, fmt.Errorf("invalid search query: %w", err)
+	}
+	if opts.FromFilter != "" {
+		q.From = append(q.From, strings.ToLower(opts.FromFilter))
+	}
+	if opts.ArchivedOnly {
+		q.Location = "archived"
+	}
+
+	if opts.Rebuild {
+		if err := m.RebuildIndex(); err != nil {
+			return nil, err
+		}
+	}
+
+	idx, inbox, err := m.openIndex(q.Location == "archived")
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &archiveReader{path: m.ArchivePath()}
+	defer archive.close()
+
+	seen := make(map[string]bool)
+	var results []SearchResult
+	for _, sd := range idx.search(q, opts.SubjectOnly, opts.BodyOnly) {
+		if seen[sd.doc.ID] {
+			continue
+		}
+
+		msg := inbox[sd.key]
+		if sd.doc.Archived {
+			msg, err = archive.read(sd.doc.Offset)
+			if err != nil {
+				continue // Archive changed underneath us; next search re-syncs
+			}
+		}
+		if msg == nil {
+			continue
+		}
+		if len(q.Phrases) > 0 && !matchesPhrases(msg, q.Phrases, opts.SubjectOnly, opts.BodyOnly) {
+			continue
+		}
+
+		seen[sd.doc.ID] = true
+		results = append(results, SearchResult{Message: msg, Score: sd.score})
+		if opts.Limit > 0 && len(results) >= opts.Limit {
+			break
+		}
+	}
+
+	return results, nil
+}
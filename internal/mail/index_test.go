@@ -0,0 +1,276 @@
+package mail
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func searchIDs(t *testing.T, m *Mailbox, query string) []string {
+	t.Helper()
+	msgs, err := m.Search(SearchOptions{Query: query})
+	if err != nil {
+		t.Fatalf("Search(%q) error: %v", query, err)
+	}
+	var ids []string
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+func newSearchMailbox(t *testing.T) *Mailbox {
+	t.Helper()
+	m := NewMailbox(t.TempDir())
+	base := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	msgs := []*Message{
+		{ID: "msg-1", From: "mayor/", To: "gastown/Toast", Subject: "Deploy failed", Body: "The deploy to staging failed on tests.", Timestamp: base, Type: TypeTask, Priority: PriorityHigh, ThreadID: "thread-a"},
+		{ID: "msg-2", From: "gastown/witness", To: "gastown/Toast", Subject: "Status check", Body: "Are you stuck? Mention deploy status.", Timestamp: base.Add(24 * time.Hour), Type: TypeNotification, Priority: PriorityNormal, ThreadID: "thread-b"},
+		{ID: "msg-3", From: "mayor/", To: "gastown/Toast", CC: []string{"gastown/Nux"}, Subject: "Handoff notes", Body: "Context for the refinery handoff.", Timestamp: base.Add(48 * time.Hour), Type: TypeReply, Priority: PriorityLow, ThreadID: "thread-a"},
+	}
+	for _, msg := range msgs {
+		if err := m.Append(msg); err != nil {
+			t.Fatalf("Append error: %v", err)
+		}
+	}
+	return m
+}
+
+func TestSearchRanksSubjectHitsFirst(t *testing.T) {
+	m := newSearchMailbox(t)
+
+	ids := searchIDs(t, m, "deploy")
+	if len(ids) != 2 || ids[0] != "msg-1" || ids[1] != "msg-2" {
+		t.Errorf("Search(deploy) = %v, want [msg-1 msg-2]", ids)
+	}
+
+	if _, err := os.Stat(m.IndexPath()); err != nil {
+		t.Errorf("index file not written: %v", err)
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	m := newSearchMailbox(t)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"from:mayor", []string{"msg-3", "msg-1"}},
+		{"to:nux", []string{"msg-3"}},
+		{"type:task", []string{"msg-1"}},
+		{"thread:thread-a", []string{"msg-3", "msg-1"}},
+		{"priority:high", []string{"msg-1"}},
+		{"date:2026-03-11", []string{"msg-2"}},
+		{"after:2026-03-11 before:2026-03-12", []string{"msg-2"}},
+		{"date:2026-03-10..2026-03-11", []string{"msg-2", "msg-1"}},
+		{"hand*", []string{"msg-3"}},
+		{`"deploy status"`, []string{"msg-2"}},
+		{`"status deploy"`, nil},
+		{"deploy from:witness", []string{"msg-2"}},
+		{"nonexistent", nil},
+	}
+
+	for _, tt := range tests {
+		got := searchIDs(t, m, tt.query)
+		if len(got) != len(tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestSearchSubstring(t *testing.T) {
+	m := NewMailbox(t.TempDir())
+	base := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+	for i, msg := range []*Message{
+		{ID: "msg-1", From: "mayor/", Subject: "Deployment plan", Body: "Roll out gt-abc123 first."},
+		{ID: "msg-2", From: "mayor/", Subject: "Redeploy", Body: "Retry gt-abd9 after lunch."},
+		{ID: "msg-3", From: "mayor/", Subject: "Lunch", Body: "Deploy nothing today."},
+	} {
+		msg.Timestamp = base.Add(time.Duration(i) * time.Hour)
+		if err := m.Append(msg); err != nil {
+			t.Fatalf("Append error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"deploy", 3},     // deployment, redeploy and deploy
+		{"DEPLOY", 3},     // case-insensitive
+		{"deploy*", 2},    // prefix only: deployment, deploy
+		{"gt-abc", 1},     // part of an ID, matched literally
+		{"abc12", 1},      // part of a word
+		{"gt-ab", 2},      // both IDs
+		{"abc-gt", 0},     // punctuation must match literally
+		{"ploy lunch", 2}, // terms combine with AND
+	}
+	for _, tt := range tests {
+		if got := searchIDs(t, m, tt.query); len(got) != tt.want {
+			t.Errorf("Search(%q) = %v, want %d results", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchIndexIncremental(t *testing.T) {
+	m := newSearchMailbox(t)
+
+	if err := m.Archive("msg-1"); err != nil {
+		t.Fatalf("Archive error: %v", err)
+	}
+	if ids := searchIDs(t, m, "deploy is:archived"); len(ids) != 1 || ids[0] != "msg-1" {
+		t.Errorf("after archive: %v, want [msg-1]", ids)
+	}
+	if ids := searchIDs(t, m, "deploy"); len(ids) != 2 {
+		t.Errorf("default search = %v, want inbox and archived matches", ids)
+	}
+	msgs, err := m.Search(SearchOptions{Query: "deploy", ArchivedOnly: true})
+	if err != nil || len(msgs) != 1 || msgs[0].ID != "msg-1" {
+		t.Errorf("ArchivedOnly search = %v, %v; want [msg-1]", msgs, err)
+	}
+
+	// New inbox mail is picked up without a rebuild.
+	if err := m.Append(&Message{ID: "msg-4", From: "mayor/", To: "gastown/Toast", Subject: "Redeploy", Body: "deploy again", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+	if ids := searchIDs(t, m, "deploy is:inbox"); len(ids) != 2 {
+		t.Errorf("after append: %v, want 2 inbox results", ids)
+	}
+
+	// Rewriting the archive invalidates archived docs.
+	if _, err := m.PurgeArchive(0); err != nil {
+		t.Fatalf("PurgeArchive error: %v", err)
+	}
+	if ids := searchIDs(t, m, "is:archived"); len(ids) != 0 {
+		t.Errorf("after purge: %v, want none", ids)
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	now := time.Date(2026, 3, 15, 9, 30, 0, 0, time.UTC)
+
+	q, err := ParseSearchQuery(`from:Mayor "Exact Phrase" build* priority:1 since:7d is:unread`, now)
+	if err != nil {
+		t.Fatalf("ParseSearchQuery error: %v", err)
+	}
+	if len(q.From) != 1 || q.From[0] != "mayor" {
+		t.Errorf("From = %v, want [mayor]", q.From)
+	}
+	if len(q.Phrases) != 1 || q.Phrases[0] != "exact phrase" {
+		t.Errorf("Phrases = %v, want [exact phrase]", q.Phrases)
+	}
+	if len(q.Terms) != 1 || q.Terms[0] != "build*" {
+		t.Errorf("Terms = %v, want [build*]", q.Terms)
+	}
+	if len(q.Priorities) != 1 || q.Priorities[0] != PriorityHigh {
+		t.Errorf("Priorities = %v, want [high]", q.Priorities)
+	}
+	if want := now.Add(-7 * 24 * time.Hour); !q.After.Equal(want) {
+		t.Errorf("After = %v, want %v", q.After, want)
+	}
+	if q.Read != "unread" {
+		t.Errorf("Read = %q, want unread", q.Read)
+	}
+
+	for _, bad := range []string{"type:bogus", "priority:9", "after:someday", "is:maybe"} {
+		if _, err := ParseSearchQuery(bad, now); err == nil {
+			t.Errorf("ParseSearchQuery(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSearchIndexAppendOnly(t *testing.T) {
+	m := newSearchMailbox(t)
+	searchIDs(t, m, "deploy")
+
+	before, err := os.ReadFile(m.IndexPath())
+	if err != nil {
+		t.Fatalf("reading index: %v", err)
+	}
+	if strings.Contains(string(before), "The deploy to staging failed on tests.") {
+		t.Error("index stores message bodies")
+	}
+
+	// Nothing changed: the index is not rewritten.
+	info, _ := os.Stat(m.IndexPath())
+	old := info.ModTime().Add(-time.Hour)
+	if err := os.Chtimes(m.IndexPath(), old, old); err != nil {
+		t.Fatal(err)
+	}
+	searchIDs(t, m, "handoff")
+	if info, _ := os.Stat(m.IndexPath()); !info.ModTime().Equal(old) {
+		t.Error("index written by a search that changed nothing")
+	}
+
+	// New mail and read-state changes are appended, not rewritten.
+	if err := m.Append(&Message{ID: "msg-4", From: "mayor/", Subject: "Redeploy", Body: "deploy again", Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Append error: %v", err)
+	}
+	if err := m.MarkRead("msg-2"); err != nil {
+		t.Fatalf("MarkRead error: %v", err)
+	}
+	if ids := searchIDs(t, m, "deploy is:unread"); len(ids) != 2 {
+		t.Errorf("after append: %v, want msg-4 and msg-1 unread", ids)
+	}
+	after, err := os.ReadFile(m.IndexPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) <= len(before) || string(after[:len(before)]) != string(before) {
+		t.Error("index log was rewritten instead of appended to")
+	}
+
+	// A torn last line from an interrupted append is ignored.
+	f, err := os.OpenFile(m.IndexPath(), os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"k":"i:msg-9","d":{"id":`)
+	_ = f.Close()
+	if ids := searchIDs(t, m, "deploy"); len(ids) != 3 {
+		t.Errorf("after torn write: %v, want 3 results", ids)
+	}
+	if data, _ := os.ReadFile(m.IndexPath()); strings.Contains(string(data), "msg-9") {
+		t.Error("torn line kept after the next search")
+	}
+}
+
+func TestSearchIndexCompacts(t *testing.T) {
+	defer func(n int) { compactMinRecords = n }(compactMinRecords)
+	compactMinRecords = 5
+
+	m := newSearchMailbox(t)
+	searchIDs(t, m, "deploy")
+	for i := 0; i < 4; i++ {
+		if err := m.MarkRead("msg-1"); err != nil {
+			t.Fatal(err)
+		}
+		searchIDs(t, m, "deploy")
+		if err := m.MarkUnread("msg-1"); err != nil {
+			t.Fatal(err)
+		}
+		searchIDs(t, m, "deploy")
+	}
+
+	data, err := os.ReadFile(m.IndexPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Header, archive position and the three messages, plus a few appends
+	// since the last compaction.
+	if lines := strings.Count(string(data), "\n"); lines > 2*(3+2) {
+		t.Errorf("index log has %d lines, want it compacted", lines)
+	}
+	if ids := searchIDs(t, m, "deploy is:unread"); len(ids) != 2 {
+		t.Errorf("after compaction: %v, want msg-1 and msg-2", ids)
+	}
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"time"
 
@@ -658,75 +657,36 @@ func (m *Mailbox) rewriteArchive(messages []*Message) error {
 
 // SearchOptions specifies search parameters.
 type SearchOptions struct {
-	Query       string // Regex pattern to search for
-	FromFilter  string // Optional: only match messages from this sender
-	SubjectOnly bool   // Only search subject
-	BodyOnly    bool   // Only search body
-}
-
-// Search finds messages matching the given criteria.
-// Returns messages from both inbox and archive.
-// Query and FromFilter are treated as literal strings (not regex) to prevent ReDoS.
+	Query        string // Search query (see SearchQuery for syntax)
+	FromFilter   string // Optional: only match messages from this sender
+	SubjectOnly  bool   // Only search subject
+	BodyOnly     bool   // Only search body
+	ArchivedOnly bool   // Only match archived messages (like is:archived)
+	Limit        int    // Maximum results (0 = unlimited)
+	Rebuild      bool   // Discard the on-disk index and rebuild it first
+}
+
+// Search finds messages matching the given criteria, best matches first.
+// Returns messages from both inbox and archive unless the query or
+// ArchivedOnly narrows it.
+// Queries are tokenized and matched against an on-disk index, so user input
+// is never compiled as a regex.
 func (m *Mailbox) Search(opts SearchOptions) ([]*Message, error) {
-	// Use QuoteMeta to escape special regex chars - prevents ReDoS attacks
-	// and provides intuitive literal string matching for users
-	re, err := regexp.Compile("(?i)" + regexp.QuoteMeta(opts.Query))
-	if err != nil {
-		return nil, fmt.Errorf("invalid search pattern: %w", err)
-	}
-
-	var fromRe *regexp.Regexp
-	if opts.FromFilter != "" {
-		fromRe, err = regexp.Compile("(?i)" + regexp.QuoteMeta(opts.FromFilter))
-		if err != nil {
-			return nil, fmt.Errorf("invalid from pattern: %w", err)
-		}
-	}
-
-	// Get inbox messages
-	inbox, err := m.List()
+	results, err := m.SearchRanked(opts)
 	if err != nil {
 		return nil, err
 	}
-
-	// Get archived messages
-	archived, err := m.ListArchived()
-	if err != nil && !os.IsNotExist(err) {
-		return nil, err
+	messages := make([]*Message, 0, len(results))
+	for _, r := range results {
+		messages = append(messages, r.Message)
 	}
+	return messages, nil
+}
 
-	// Combine and search
-	all := append(inbox, archived...)
-	var matches []*Message
-
-	for _, msg := range all {
-		// Apply from filter
-		if fromRe != nil && !fromRe.MatchString(msg.From) {
-			continue
-		}
-
-		// Search in specified fields
-		matched := false
-		if opts.SubjectOnly {
-			matched = re.MatchString(msg.Subject)
-		} else if opts.BodyOnly {
-			matched = re.MatchString(msg.Body)
-		} else {
-			// Search in both subject and body
-			matched = re.MatchString(msg.Subject) || re.MatchString(msg.Body)
-		}
-
-		if matched {
-			matches = append(matches, msg)
-		}
-	}
-
-	// Sort by timestamp (newest first)
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].Timestamp.After(matches[j].Timestamp)
-	})
-
-	return matches, nil
+// SearchRanked is like Search but also returns each result's relevance score.
+// Filter-only queries (no text terms) score zero and are ordered newest first.
+func (m *Mailbox) SearchRanked(opts SearchOptions) ([]SearchResult, error) {
+	return m.searchIndexed(opts)
 }
 
 // Count returns the total and unread message counts.
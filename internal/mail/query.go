@@ -0,0 +1,389 @@
+package mail
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SearchQuery is a parsed mail search query.
+//
+// Query syntax (all parts optional, combined with AND):
+//
+//	word              subject or body contains "word" (case-insensitive)
+//	prefix*           any word starting with "prefix"
+//	"exact phrase"    literal phrase (case-insensitive)
+//	from:<addr>       sender contains <addr>
+//	to:<addr>         recipient or CC contains <addr>
+//	type:<type>       task, scavenge, notification, reply
+//	thread:<id>       exact thread ID
+//	priority:<p>      urgent, high, normal, low (or 0-4)
+//	after:<date>      sent at or after <date>
+//	before:<date>     sent before <date>
+//	date:<date>       sent on <date>, or within <date>..<date>
+//	is:<state>        read, unread, inbox, archived
+//
+// Dates accept 2006-01-02, 2006-01-02T15:04, RFC3339, "today",
+// "yesterday", or a relative age such as 24h, 7d or 2w.
+type SearchQuery struct {
+	Terms      []string      // Free-text terms (lowercase); trailing "*" marks a prefix
+	Phrases    []string      // Quoted phrases and punctuated words (lowercase), matched literally
+	From       []string      // Sender substrings (lowercase)
+	To         []string      // Recipient/CC substrings (lowercase)
+	Types      []MessageType // Allowed message types
+	Threads    []string      // Allowed thread IDs
+	Priorities []Priority    // Allowed priorities
+	After      time.Time     // Inclusive lower bound (zero = unbounded)
+	Before     time.Time     // Exclusive upper bound (zero = unbounded)
+	Read       string        // "read", "unread", or "" for either
+	Location   string        // "inbox", "archived", or "" for either
+}
+
+// ParseSearchQuery parses a query string into a SearchQuery.
+// Relative dates are resolved against now.
+func ParseSearchQuery(query string, now time.Time) (*SearchQuery, error) {
+	q := &SearchQuery{}
+
+	for _, tok := range splitQuery(query) {
+		if tok.quoted {
+			if phrase := strings.ToLower(strings.TrimSpace(tok.text)); phrase != "" {
+				q.Phrases = append(q.Phrases, phrase)
+			}
+			continue
+		}
+
+		key, value, hasKey := strings.Cut(tok.text, ":")
+		if !hasKey || value == "" || !isSearchKey(key) {
+			q.addTerms(tok.text)
+			continue
+		}
+
+		raw := value
+		value = strings.ToLower(value)
+		switch strings.ToLower(key) {
+		case "from":
+			q.From = append(q.From, value)
+		case "to":
+			q.To = append(q.To, value)
+		case "type":
+			t := MessageType(value)
+			switch t {
+			case TypeTask, TypeScavenge, TypeNotification, TypeReply:
+				q.Types = append(q.Types, t)
+			default:
+				return nil, fmt.Errorf("invalid type %q (want task, scavenge, notification, reply)", value)
+			}
+		case "thread":
+			q.Threads = append(q.Threads, raw)
+		case "priority":
+			p, err := parseSearchPriority(value)
+			if err != nil {
+				return nil, err
+			}
+			q.Priorities = append(q.Priorities, p)
+		case "after", "since":
+			t, err := parseSearchDate(raw, now)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s date: %w", key, err)
+			}
+			if q.After.IsZero() || t.After(q.After) {
+				q.After = t
+			}
+		case "before", "until":
+			t, err := parseSearchDate(raw, now)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s date: %w", key, err)
+			}
+			if q.Before.IsZero() || t.Before(q.Before) {
+				q.Before = t
+			}
+		case "date":
+			start, end, err := parseSearchDateRange(raw, now)
+			if err != nil {
+				return nil, fmt.Errorf("invalid date: %w", err)
+			}
+			if q.After.IsZero() || start.After(q.After) {
+				q.After = start
+			}
+			if q.Before.IsZero() || end.Before(q.Before) {
+				q.Before = end
+			}
+		case "is":
+			switch value {
+			case "read", "unread":
+				q.Read = value
+			case "inbox", "archived":
+				q.Location = value
+			default:
+				return nil, fmt.Errorf("invalid is: value %q (want read, unread, inbox, archived)", value)
+			}
+		}
+	}
+
+	return q, nil
+}
+
+// searchKeys are the recognized key:value filters.
+var searchKeys = map[string]bool{
+	"from": true, "to": true, "type": true, "thread": true, "priority": true,
+	"after": true, "since": true, "before": true, "until": true, "date": true, "is": true,
+}
+
+func isSearchKey(key string) bool {
+	return searchKeys[strings.ToLower(key)]
+}
+
+// addTerms tokenizes free text into terms, preserving a trailing "*" prefix
+// marker. A word with punctuation (a bead ID like gt-abc1) must appear
+// literally, so it is matched like a phrase.
+func (q *SearchQuery) addTerms(text string) {
+	prefix := strings.HasSuffix(text, "*")
+	tokens := tokenize(text)
+	if !prefix && (len(tokens) != 1 || tokens[0] != strings.ToLower(text)) {
+		if len(tokens) > 0 {
+			q.Phrases = append(q.Phrases, strings.ToLower(text))
+		}
+		return
+	}
+	for i, t := range tokens {
+		if prefix && i == len(tokens)-1 {
+			t += "*"
+		}
+		q.Terms = append(q.Terms, t)
+	}
+}
+
+// IsEmpty reports whether the query has no text terms (filters only).
+func (q *SearchQuery) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
+
+// matchesMeta reports whether a document passes the query's metadata filters.
+func (q *SearchQuery) matchesMeta(d *indexDoc) bool {
+	if len(q.From) > 0 {
+		from := strings.ToLower(d.From)
+		for _, f := range q.From {
+			if !strings.Contains(from, f) {
+				return false
+			}
+		}
+	}
+	for _, want := range q.To {
+		found := strings.Contains(strings.ToLower(d.To), want)
+		for _, cc := range d.CC {
+			if strings.Contains(strings.ToLower(cc), want) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(q.Types) > 0 && !containsType(q.Types, d.Type) {
+		return false
+	}
+	if len(q.Threads) > 0 && !stringInList(q.Threads, d.ThreadID) {
+		return false
+	}
+	if len(q.Priorities) > 0 && !containsPriority(q.Priorities, d.Priority) {
+		return false
+	}
+	if !q.After.IsZero() && d.Timestamp.Before(q.After) {
+		return false
+	}
+	if !q.Before.IsZero() && !d.Timestamp.Before(q.Before) {
+		return false
+	}
+	switch q.Read {
+	case "read":
+		if !d.Read {
+			return false
+		}
+	case "unread":
+		if d.Read {
+			return false
+		}
+	}
+	switch q.Location {
+	case "inbox":
+		if d.Archived {
+			return false
+		}
+	case "archived":
+		if !d.Archived {
+			return false
+		}
+	}
+	return true
+}
+
+func containsType(types []MessageType, t MessageType) bool {
+	if t == "" {
+		t = TypeNotification
+	}
+	for _, want := range types {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPriority(priorities []Priority, p Priority) bool {
+	if p == "" {
+		p = PriorityNormal
+	}
+	for _, want := range priorities {
+		if want == p {
+			return true
+		}
+	}
+	return false
+}
+
+func stringInList(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func parseSearchPriority(value string) (Priority, error) {
+	switch Priority(value) {
+	case PriorityUrgent, PriorityHigh, PriorityNormal, PriorityLow:
+		return Priority(value), nil
+	}
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 && n <= 4 {
+		return PriorityFromInt(n), nil
+	}
+	return "", fmt.Errorf("invalid priority %q (want urgent, high, normal, low or 0-4)", value)
+}
+
+// parseSearchDate parses an absolute or relative date for search filters.
+func parseSearchDate(value string, now time.Time) (time.Time, error) {
+	switch strings.ToLower(value) {
+	case "today":
+		return startOfDay(now), nil
+	case "yesterday":
+		return startOfDay(now).AddDate(0, 0, -1), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if age, ok := parseRelativeAge(value); ok {
+		return now.Add(-age), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+// parseSearchDateRange parses "D" (the whole day) or "A..B" (A through end of B).
+func parseSearchDateRange(value string, now time.Time) (time.Time, time.Time, error) {
+	startStr, endStr, isRange := strings.Cut(value, "..")
+	if !isRange {
+		endStr = startStr
+	}
+
+	var start, end time.Time
+	if startStr != "" {
+		t, err := parseSearchDate(startStr, now)
+		if err != nil {
+			return start, end, err
+		}
+		start = t
+	}
+	if endStr != "" {
+		t, err := parseSearchDate(endStr, now)
+		if err != nil {
+			return start, end, err
+		}
+		// A bare day includes the whole day.
+		if t.Equal(startOfDay(t)) {
+			t = t.AddDate(0, 0, 1)
+		}
+		end = t
+	}
+	return start, end, nil
+}
+
+// parseRelativeAge parses ages like "90m", "24h", "7d", "2w".
+func parseRelativeAge(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	switch value[len(value)-1] {
+	case 'm':
+		return time.Duration(n) * time.Minute, true
+	case 'h':
+		return time.Duration(n) * time.Hour, true
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, true
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// queryToken is a whitespace-separated piece of a query.
+type queryToken struct {
+	text   string
+	quoted bool
+}
+
+// splitQuery splits a query on whitespace, keeping "quoted phrases" together.
+// A quoted value after a key (from:"mayor/") is unquoted into the key's value.
+func splitQuery(query string) []queryToken {
+	var tokens []queryToken
+	var cur strings.Builder
+	inQuote := false
+	quotedWhole := false
+
+	flush := func() {
+		if cur.Len() > 0 || quotedWhole {
+			tokens = append(tokens, queryToken{text: cur.String(), quoted: quotedWhole})
+		}
+		cur.Reset()
+		quotedWhole = false
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if !inQuote && cur.Len() == 0 {
+				quotedWhole = true
+			}
+			inQuote = !inQuote
+		case unicode.IsSpace(r) && !inQuote:
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// tokenize lowercases text and splits it into alphanumeric terms.
+func tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
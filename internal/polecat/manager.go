@@ -221,7 +221,8 @@ func (m *Manager) exists(name string) bool {
 
 // AddOptions configures polecat creation.
 type AddOptions struct {
-	HookBead string // Bead ID to set as hook_bead at spawn time (atomic assignment)
+	HookBead   string // Bead ID to set as hook_bead at spawn time (atomic assignment)
+	StartPoint string // Ref to branch from instead of origin/<default-branch> (stacked work)
 }
 
 // Add creates a new polecat as a git worktree from the repo base.
@@ -249,18 +250,7 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 	polecatDir := m.polecatDir(name)
 	clonePath := filepath.Join(polecatDir, m.rig.Name)
 
-	// Branch naming: include issue ID when available for better traceability.
-	// Format: polecat/<worker>/<issue>@<timestamp> when HookBead is set
-	// The @timestamp suffix ensures uniqueness if the same issue is re-slung.
-	// parseBranchName strips the @suffix to extract the issue ID.
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 36)
-	var branchName string
-	if opts.HookBead != "" {
-		branchName = fmt.Sprintf("polecat/%s/%s@%s", name, opts.HookBead, timestamp)
-	} else {
-		// Fallback to timestamp format when no issue is known at spawn time
-		branchName = fmt.Sprintf("polecat/%s-%s", name, timestamp)
-	}
+	branchName := polecatBranchName(name, opts.HookBead)
 
 	// Create polecat directory (polecats/<name>/)
 	if err := os.MkdirAll(polecatDir, 0755); err != nil {
@@ -281,11 +271,7 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 
 	// Determine the start point for the new worktree
 	// Use origin/<default-branch> to ensure we start from the rig's configured branch
-	defaultBranch := "main"
-	if rigCfg, err := rig.LoadRigConfig(m.rig.Path); err == nil && rigCfg.DefaultBranch != "" {
-		defaultBranch = rigCfg.DefaultBranch
-	}
-	startPoint := fmt.Sprintf("origin/%s", defaultBranch)
+	startPoint := m.startPoint(opts)
 
 	// Always create fresh branch - unique name guarantees no collision
 	// git worktree add -b polecat/<name>-<timestamp> <path> <startpoint>
@@ -294,6 +280,45 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		return nil, fmt.Errorf("creating worktree from %s: %w", startPoint, err)
 	}
 
+	m.provisionWorktree(clonePath)
+
+	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by gt install.
+	// All agents inherit them via Claude's directory traversal - no per-workspace copies needed.
+
+	return m.registerPolecat(name, clonePath, branchName, opts), nil
+}
+
+// startPoint returns the ref a new polecat branch starts from: the
+// requested start point for stacked work, else origin/<default-branch>.
+func (m *Manager) startPoint(opts AddOptions) string {
+	if opts.StartPoint != "" {
+		return opts.StartPoint
+	}
+	defaultBranch := "main"
+	if rigCfg, err := rig.LoadRigConfig(m.rig.Path); err == nil && rigCfg.DefaultBranch != "" {
+		defaultBranch = rigCfg.DefaultBranch
+	}
+	return fmt.Sprintf("origin/%s", defaultBranch)
+}
+
+// polecatBranchName returns a unique branch name for a polecat run.
+// Branch naming: include issue ID when available for better traceability.
+// Format: polecat/<worker>/<issue>@<timestamp> when hookBead is set
+// The @timestamp suffix ensures uniqueness if the same issue is re-slung.
+// parseBranchName strips the @suffix to extract the issue ID.
+func polecatBranchName(name, hookBead string) string {
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 36)
+	if hookBead != "" {
+		return fmt.Sprintf("polecat/%s/%s@%s", name, hookBead, timestamp)
+	}
+	// Fallback to timestamp format when no issue is known at spawn time
+	return fmt.Sprintf("polecat/%s-%s", name, timestamp)
+}
+
+// provisionWorktree prepares a fresh polecat worktree: AGENTS.md fallback,
+// shared beads redirect, PRIME.md, overlay files and setup hooks.
+// All steps are non-fatal.
+func (m *Manager) provisionWorktree(clonePath string) {
 	// Ensure AGENTS.md exists - critical for polecats to "land the plane"
 	// Fall back to copy from mayor/rig if not in git (e.g., stale fetch, local-only file)
 	agentsMDPath := filepath.Join(clonePath, "AGENTS.md")
@@ -340,10 +365,11 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		// Non-fatal - log warning but continue
 		fmt.Printf("Warning: could not run setup hooks: %v\n", err)
 	}
+}
 
-	// NOTE: Slash commands (.claude/commands/) are provisioned at town level by gt install.
-	// All agents inherit them via Claude's directory traversal - no per-workspace copies needed.
-
+// registerPolecat creates or reopens the polecat's agent bead and returns
+// the polecat in working state.
+func (m *Manager) registerPolecat(name, clonePath, branchName string, opts AddOptions) *Polecat {
 	// Determine worktree base path for lifecycle tracking
 	// This is either .repo.git (bare repo) or mayor/rig (legacy)
 	bareRepoPath := filepath.Join(m.rig.Path, ".repo.git")
@@ -360,7 +386,7 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 	// P1 (oc-hyor): Extended with worker lifecycle tracking for crash recovery.
 	agentID := m.agentBeadID(name)
 	sessionID := fmt.Sprintf("polecat:%s:%s", m.rig.Name, name)
-	_, err = m.beads.CreateOrReopenAgentBead(agentID, agentID, &beads.AgentFields{
+	_, err := m.beads.CreateOrReopenAgentBead(agentID, agentID, &beads.AgentFields{
 		RoleType:   "polecat",
 		Rig:        m.rig.Name,
 		AgentState: "spawning",
@@ -393,7 +419,7 @@ func (m *Manager) AddWithOptions(name string, opts AddOptions) (*Polecat, error)
 		UpdatedAt: now,
 	}
 
-	return polecat, nil
+	return polecat
 }
 
 // Remove deletes a polecat worktree.
@@ -612,23 +638,12 @@ func (m *Manager) RepairWorktreeWithOptions(name string, force bool, opts AddOpt
 
 	// Determine the start point for the new worktree
 	// Use origin/<default-branch> to ensure we start from latest fetched commits
-	defaultBranch := "main"
-	if rigCfg, err := rig.LoadRigConfig(m.rig.Path); err == nil && rigCfg.DefaultBranch != "" {
-		defaultBranch = rigCfg.DefaultBranch
-	}
-	startPoint := fmt.Sprintf("origin/%s", defaultBranch)
+	startPoint := m.startPoint(opts)
 
 	// Create fresh worktree with unique branch name, starting from origin's default branch
 	// Old branches are left behind - they're ephemeral (never pushed to origin)
 	// and will be cleaned up by garbage collection
-	// Branch naming: include issue ID when available for better traceability.
-	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 36)
-	var branchName string
-	if opts.HookBead != "" {
-		branchName = fmt.Sprintf("polecat/%s/%s@%s", name, opts.HookBead, timestamp)
-	} else {
-		branchName = fmt.Sprintf("polecat/%s-%s", name, timestamp)
-	}
+	branchName := polecatBranchName(name, opts.HookBead)
 	if err := repoGit.WorktreeAddFromRef(newClonePath, branchName, startPoint); err != nil {
 		return nil, fmt.Errorf("creating fresh worktree from %s: %w", startPoint, err)
 	}
@@ -0,0 +1,253 @@
+package polecat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// ErrSandboxUnavailable is returned when sandboxing is enabled but cannot be used.
+var ErrSandboxUnavailable = errors.New("sandbox unavailable")
+
+// sandboxSpec describes what a sandboxed polecat may write to.
+type sandboxSpec struct {
+	// Required paths must exist and are bound read-write.
+	Required []string
+
+	// Optional paths are bound read-write only if they exist.
+	Optional []string
+
+	// Created directories are made if missing, then bound read-write.
+	Created []string
+
+	// Hidden paths are masked by an empty tmpfs.
+	Hidden []string
+
+	// TmuxSocketDir is bound back into the private /tmp so gt commands
+	// run by the polecat (nudge, mail notify, handoff) can reach tmux.
+	TmuxSocketDir string
+
+	// WorkDir is the directory the runtime starts in.
+	WorkDir string
+
+	// NoNetwork disables networking.
+	NoNetwork bool
+}
+
+// loadSandboxConfig returns the rig's sandbox settings, or nil if sandboxing
+// is off. A rig without settings has no sandbox; settings that can't be read
+// are an error, since they may be the ones asking for it.
+func (m *SessionManager) loadSandboxConfig() (*config.SandboxConfig, error) {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path))
+	if err != nil {
+		if errors.Is(err, config.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if settings.Sandbox == nil || !settings.Sandbox.Enabled {
+		return nil, nil
+	}
+	return settings.Sandbox, nil
+}
+
+// sandboxSpecFor builds the writable set for a polecat: its own directory,
+// the git object store its worktree shares, the rig and town beads
+// directories, the runtime config dir and the configured allowlist.
+func (m *SessionManager) sandboxSpecFor(cfg *config.SandboxConfig, polecat, workDir, runtimeConfigDir string) sandboxSpec {
+	townRoot := filepath.Dir(m.rig.Path)
+	spec := sandboxSpec{
+		Required:      []string{m.polecatDir(polecat)},
+		WorkDir:       workDir,
+		NoNetwork:     cfg.Network == "none",
+		TmuxSocketDir: tmuxSocketDir(),
+	}
+
+	if commonDir := gitCommonDir(workDir); commonDir != "" {
+		spec.Required = append(spec.Required, commonDir)
+	}
+
+	spec.Optional = append(spec.Optional,
+		beads.ResolveBeadsDir(m.rig.Path),
+		beads.ResolveBeadsDir(townRoot),
+		filepath.Join(townRoot, ".events.jsonl"),
+	)
+	if runtimeConfigDir != "" {
+		spec.Optional = append(spec.Optional, runtimeConfigDir)
+	}
+	if cache := rig.LoadCacheConfig(m.rig.Path); cache != nil {
+		spec.Optional = append(spec.Optional, rig.CacheRoot(m.rig.Path, cache))
+	}
+
+	writable := cfg.Writable
+	if writable == nil {
+		writable = config.DefaultSandboxWritable
+		// Claude keeps transcripts per working directory; only this
+		// worktree's are writable, not other agents'
+		if runtimeConfigDir == "" {
+			spec.Created = append(spec.Created, claudeProjectDir(workDir))
+		}
+	}
+	for _, p := range writable {
+		spec.Optional = append(spec.Optional, expandHome(p))
+	}
+	for _, p := range cfg.Hidden {
+		spec.Hidden = append(spec.Hidden, expandHome(p))
+	}
+	return spec
+}
+
+// sandboxCommand wraps a startup command so it runs inside a bubblewrap sandbox.
+func sandboxCommand(binary string, spec sandboxSpec, command string) (string, error) {
+	if goruntime.GOOS != "linux" {
+		return "", fmt.Errorf("%w: requires Linux namespaces (running on %s)", ErrSandboxUnavailable, goruntime.GOOS)
+	}
+	if binary == "" {
+		binary = "bwrap"
+	}
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s not found (install bubblewrap)", ErrSandboxUnavailable, binary)
+	}
+	for _, p := range spec.Required {
+		if _, err := os.Stat(p); err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrSandboxUnavailable, p, err)
+		}
+	}
+	for _, p := range spec.Created {
+		if err := os.MkdirAll(p, 0755); err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrSandboxUnavailable, p, err)
+		}
+	}
+
+	args := append([]string{path}, bwrapArgs(spec)...)
+	args = append(args, "--", "sh", "-c", command)
+
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " "), nil
+}
+
+// bwrapArgs returns the bubblewrap arguments for a sandbox spec. The host
+// root is bound read-only; writable paths are rebound on top of it. /tmp is
+// private so other processes' scratch files stay out of reach; only the
+// tmux socket directory is bound back in.
+func bwrapArgs(spec sandboxSpec) []string {
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--unshare-user",
+		"--unshare-pid",
+		"--unshare-ipc",
+		"--unshare-uts",
+		"--die-with-parent",
+	}
+	if spec.NoNetwork {
+		args = append(args, "--unshare-net")
+	}
+	if spec.TmuxSocketDir != "" {
+		args = append(args, "--bind-try", spec.TmuxSocketDir, spec.TmuxSocketDir)
+	}
+
+	for _, p := range dedupePaths(append(spec.Required, spec.Created...)) {
+		args = append(args, "--bind", p, p)
+	}
+	for _, p := range dedupePaths(spec.Optional) {
+		args = append(args, "--bind-try", p, p)
+	}
+	for _, p := range dedupePaths(spec.Hidden) {
+		args = append(args, "--tmpfs", p)
+	}
+
+	if spec.WorkDir != "" {
+		args = append(args, "--chdir", spec.WorkDir)
+	}
+	return args
+}
+
+// dedupePaths cleans, sorts and removes duplicate paths so parents are bound
+// before children.
+func dedupePaths(paths []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, p := range paths {
+		if p == "" {
+			continue
+		}
+		p = filepath.Clean(p)
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// tmuxSocketDir returns the directory tmux keeps its server sockets in:
+// tmux-<uid> under $TMUX_TMPDIR, or under /tmp.
+func tmuxSocketDir() string {
+	base := os.Getenv("TMUX_TMPDIR")
+	if base == "" {
+		base = "/tmp"
+	}
+	return filepath.Join(base, fmt.Sprintf("tmux-%d", os.Getuid()))
+}
+
+// gitCommonDir returns the absolute git common dir for a worktree, or "".
+// Commits from a worktree write objects there, so it must stay writable.
+func gitCommonDir(workDir string) string {
+	out, err := exec.Command("git", "-C", workDir, "rev-parse", "--path-format=absolute", "--git-common-dir").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// claudeProjectDir returns the directory Claude keeps a working directory's
+// transcripts in: ~/.claude/projects/ plus the path with every character
+// other than letters and digits replaced by a dash.
+func claudeProjectDir(workDir string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, workDir)
+	return filepath.Join(expandHome("~/.claude/projects"), name)
+}
+
+// expandHome expands a leading ~ to the user's home directory.
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
+
+// shellQuote quotes s for a POSIX shell if needed.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:@+,", r))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
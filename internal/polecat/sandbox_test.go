@@ -0,0 +1,154 @@
+package polecat
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/tmux"
+)
+
+func TestBwrapArgs(t *testing.T) {
+	spec := sandboxSpec{
+		Required:      []string{"/gt/gastown/polecats/Toast", "/gt/gastown/.repo.git/"},
+		Optional:      []string{"/home/u/.cache/go-build", "/gt/.beads", "/home/u/.cache/go-build"},
+		Created:       []string{"/home/u/.claude/projects/-gt-gastown-polecats-Toast-gastown"},
+		Hidden:        []string{"/home/u/.ssh"},
+		WorkDir:       "/gt/gastown/polecats/Toast/gastown",
+		NoNetwork:     true,
+		TmuxSocketDir: "/tmp/tmux-1000",
+	}
+	got := strings.Join(bwrapArgs(spec), " ")
+
+	for _, want := range []string{
+		"--ro-bind / /",
+		"--tmpfs /tmp",
+		"--bind-try /tmp/tmux-1000 /tmp/tmux-1000",
+		"--unshare-user",
+		"--unshare-net",
+		"--bind /gt/gastown/.repo.git /gt/gastown/.repo.git",
+		"--bind /gt/gastown/polecats/Toast /gt/gastown/polecats/Toast",
+		"--bind /home/u/.claude/projects/-gt-gastown-polecats-Toast-gastown /home/u/.claude/projects/-gt-gastown-polecats-Toast-gastown",
+		"--bind-try /gt/.beads /gt/.beads --bind-try /home/u/.cache/go-build /home/u/.cache/go-build --tmpfs /home/u/.ssh",
+		"--chdir /gt/gastown/polecats/Toast/gastown",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("bwrap args missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "--bind-try /tmp/tmux-1000") < strings.Index(got, "--tmpfs /tmp") {
+		t.Errorf("tmux socket dir must be bound after the private /tmp: %s", got)
+	}
+	if strings.Count(got, "--bind-try /home/u/.cache/go-build") != 1 {
+		t.Errorf("duplicate paths not removed: %s", got)
+	}
+
+	spec.NoNetwork = false
+	if got := strings.Join(bwrapArgs(spec), " "); strings.Contains(got, "--unshare-net") {
+		t.Errorf("network should be shared by default: %s", got)
+	}
+}
+
+func TestSandboxSpecFor(t *testing.T) {
+	root := t.TempDir()
+	r := &rig.Rig{Name: "gastown", Path: filepath.Join(root, "gastown")}
+	m := NewSessionManager(tmux.NewTmux(), r)
+
+	cfg := &config.SandboxConfig{Enabled: true, Network: "none", Writable: []string{"~/go/pkg/mod"}, Hidden: []string{"~/.ssh"}}
+	spec := m.sandboxSpecFor(cfg, "Toast", filepath.Join(r.Path, "polecats", "Toast", "gastown"), "/accounts/work")
+
+	if spec.Required[0] != filepath.Join(r.Path, "polecats", "Toast") {
+		t.Errorf("Required = %v, want polecat dir first", spec.Required)
+	}
+	if !spec.NoNetwork {
+		t.Error("network: none should disable networking")
+	}
+	joined := strings.Join(spec.Optional, " ")
+	for _, want := range []string{filepath.Join(r.Path, ".beads"), filepath.Join(root, ".beads"), "/accounts/work", "go/pkg/mod"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Optional missing %q: %v", want, spec.Optional)
+		}
+	}
+	if strings.Contains(joined, ".claude.json") {
+		t.Errorf("explicit Writable should replace defaults: %v", spec.Optional)
+	}
+	t.Setenv("TMUX_TMPDIR", "/run/tmux")
+	if got := m.sandboxSpecFor(cfg, "Toast", filepath.Join(r.Path, "polecats", "Toast", "gastown"), "").TmuxSocketDir; got != fmt.Sprintf("/run/tmux/tmux-%d", os.Getuid()) {
+		t.Errorf("TmuxSocketDir = %q, want it under $TMUX_TMPDIR", got)
+	}
+	if len(spec.Hidden) != 1 || strings.HasPrefix(spec.Hidden[0], "~") {
+		t.Errorf("Hidden = %v, want expanded ~/.ssh", spec.Hidden)
+	}
+	if len(spec.Created) != 0 {
+		t.Errorf("Created = %v, want none with explicit Writable", spec.Created)
+	}
+
+	// Defaults: specific runtime files and this worktree's transcripts only
+	cfg.Writable = nil
+	workDir := filepath.Join(r.Path, "polecats", "Toast", "gastown")
+	spec = m.sandboxSpecFor(cfg, "Toast", workDir, "")
+	for _, p := range spec.Optional {
+		if strings.HasSuffix(p, "/.claude") || strings.HasSuffix(p, "/.cache") || p == "/tmp" {
+			t.Errorf("default Optional binds a whole shared directory: %s", p)
+		}
+	}
+	if len(spec.Created) != 1 || filepath.Base(spec.Created[0]) != strings.ReplaceAll(workDir, "/", "-") {
+		t.Errorf("Created = %v, want the worktree's Claude project dir", spec.Created)
+	}
+}
+
+func TestLoadSandboxConfig(t *testing.T) {
+	root := t.TempDir()
+	r := &rig.Rig{Name: "gastown", Path: filepath.Join(root, "gastown")}
+	m := NewSessionManager(tmux.NewTmux(), r)
+
+	if cfg, err := m.loadSandboxConfig(); cfg != nil || err != nil {
+		t.Errorf("no settings: got %v, %v; want no sandbox", cfg, err)
+	}
+
+	path := config.RigSettingsPath(r.Path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"type": "rig-settings", "sandbox": {"enabled": true,`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.loadSandboxConfig(); err == nil {
+		t.Error("malformed settings should be an error, not an unsandboxed polecat")
+	}
+}
+
+func TestSandboxCommand(t *testing.T) {
+	spec := sandboxSpec{Required: []string{t.TempDir()}}
+
+	if runtime.GOOS != "linux" {
+		if _, err := sandboxCommand("", spec, "claude"); !errors.Is(err, ErrSandboxUnavailable) {
+			t.Errorf("expected ErrSandboxUnavailable on %s, got %v", runtime.GOOS, err)
+		}
+		return
+	}
+
+	if _, err := sandboxCommand("gt-no-such-bwrap", spec, "claude"); !errors.Is(err, ErrSandboxUnavailable) {
+		t.Errorf("missing binary: got %v, want ErrSandboxUnavailable", err)
+	}
+
+	// Any binary on PATH stands in for bwrap; only quoting is checked here.
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+	cmd, err := sandboxCommand(sh, spec, "export GT_ROLE=polecat && claude 'gt prime'")
+	if err != nil {
+		t.Fatalf("sandboxCommand: %v", err)
+	}
+	if !strings.HasSuffix(cmd, `-- sh -c 'export GT_ROLE=polecat && claude '\''gt prime'\'''`) {
+		t.Errorf("command not quoted correctly: %s", cmd)
+	}
+}
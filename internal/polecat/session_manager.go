@@ -190,6 +190,31 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		command = config.PrependEnv(command, map[string]string{runtimeConfig.Session.ConfigDirEnv: opts.RuntimeConfigDir})
 	}
 
+	// Point build tools at the rig's shared caches so polecats reuse
+	// downloaded modules and build outputs instead of starting cold. A
+	// broken cache only costs speed, so start without it.
+	cacheEnv, err := rig.CacheEnv(m.rig.Path)
+	if err != nil {
+		fmt.Printf("Warning: starting without shared cache: %v\n", err)
+	}
+	if len(cacheEnv) > 0 {
+		command = config.PrependEnv(command, cacheEnv)
+	}
+
+	// Run inside the rig's sandbox if configured. Fail closed: a rig that
+	// asks for a sandbox never silently gets an unsandboxed polecat.
+	sandbox, err := m.loadSandboxConfig()
+	if err != nil {
+		return fmt.Errorf("sandboxing polecat %s: loading rig settings: %w", polecat, err)
+	}
+	if sandbox != nil {
+		spec := m.sandboxSpecFor(sandbox, polecat, workDir, opts.RuntimeConfigDir)
+		command, err = sandboxCommand(sandbox.Binary, spec, command)
+		if err != nil {
+			return fmt.Errorf("sandboxing polecat %s: %w", polecat, err)
+		}
+	}
+
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
 	if err := m.tmux.NewSessionWithCommand(sessionID, workDir, command); err != nil {
@@ -207,6 +232,9 @@ func (m *SessionManager) Start(polecat string, opts SessionStartOptions) error {
 		RuntimeConfigDir: opts.RuntimeConfigDir,
 		BeadsNoDaemon:    true,
 	})
+	for k, v := range cacheEnv {
+		envVars[k] = v
+	}
 	for k, v := range envVars {
 		debugSession("SetEnvironment "+k, m.tmux.SetEnvironment(sessionID, k, v))
 	}
@@ -0,0 +1,304 @@
+package polecat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// Warm pool layout: each idle worktree lives in polecats/.warm-<id>/<rigname>/,
+// the same depth as a real polecat (polecats/<name>/<rigname>/), so relative
+// paths written during setup (beads redirect, hook output) survive the move.
+// The dot prefix keeps warm slots out of List() and name pool reconciliation.
+const (
+	warmSlotPrefix = ".warm-"
+	warmReadyFile  = ".warm-ready"
+
+	// warmProvisionTimeout is how long an unfinished slot (no ready marker)
+	// may exist before it is assumed abandoned and removed.
+	warmProvisionTimeout = 2 * time.Hour
+)
+
+// ErrNoWarmWorktree is returned when the warm pool has no ready worktree.
+var ErrNoWarmWorktree = errors.New("no warm worktree available")
+
+// WarmSlot describes one pre-provisioned worktree.
+type WarmSlot struct {
+	ID        string    `json:"id"`
+	Path      string    `json:"path"`
+	Ready     bool      `json:"ready"`
+	CreatedAt time.Time `json:"created_at"`
+	Commit    string    `json:"commit,omitempty"`
+}
+
+// warmMarker is written to a slot once provisioning completes.
+type warmMarker struct {
+	CreatedAt time.Time `json:"created_at"`
+	Commit    string    `json:"commit,omitempty"`
+}
+
+// WarmPoolConfig returns the rig's warm pool settings, or nil if disabled.
+func (m *Manager) WarmPoolConfig() *config.WarmPoolConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(m.rig.Path))
+	if err != nil || settings.WarmPool == nil || settings.WarmPool.Size <= 0 {
+		return nil
+	}
+	return settings.WarmPool
+}
+
+// warmSlotDir returns the directory for a warm slot.
+func (m *Manager) warmSlotDir(id string) string {
+	return filepath.Join(m.rig.Path, "polecats", warmSlotPrefix+id)
+}
+
+// WarmSlots lists the rig's warm worktrees, oldest first.
+func (m *Manager) WarmSlots() ([]WarmSlot, error) {
+	polecatsDir := filepath.Join(m.rig.Path, "polecats")
+	entries, err := os.ReadDir(polecatsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading polecats dir: %w", err)
+	}
+
+	var slots []WarmSlot
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), warmSlotPrefix) {
+			continue
+		}
+		id := strings.TrimPrefix(entry.Name(), warmSlotPrefix)
+		dir := m.warmSlotDir(id)
+		slot := WarmSlot{ID: id, Path: filepath.Join(dir, m.rig.Name)}
+
+		data, err := os.ReadFile(filepath.Join(dir, warmReadyFile)) //nolint:gosec // G304: path is constructed internally
+		if err == nil {
+			var marker warmMarker
+			if json.Unmarshal(data, &marker) == nil {
+				slot.Ready = true
+				slot.CreatedAt = marker.CreatedAt
+				slot.Commit = marker.Commit
+			}
+		}
+		if slot.CreatedAt.IsZero() {
+			if info, err := entry.Info(); err == nil {
+				slot.CreatedAt = info.ModTime()
+			}
+		}
+		slots = append(slots, slot)
+	}
+
+	sort.Slice(slots, func(i, j int) bool {
+		return slots[i].CreatedAt.Before(slots[j].CreatedAt)
+	})
+	return slots, nil
+}
+
+// ProvisionWarm creates one idle worktree at origin/<default-branch> and runs
+// the full setup (overlay, setup hooks, shared beads). The slot only becomes
+// claimable once everything has finished.
+func (m *Manager) ProvisionWarm() (*WarmSlot, error) {
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return nil, fmt.Errorf("finding repo base: %w", err)
+	}
+
+	id := strconv.FormatInt(time.Now().UnixNano(), 36)
+	dir := m.warmSlotDir(id)
+	clonePath := filepath.Join(dir, m.rig.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating warm slot dir: %w", err)
+	}
+
+	if err := repoGit.Fetch("origin"); err != nil {
+		fmt.Printf("Warning: could not fetch origin: %v\n", err)
+	}
+	startPoint := "origin/" + m.rig.DefaultBranch()
+	if err := repoGit.WorktreeAddDetached(clonePath, startPoint); err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("creating warm worktree from %s: %w", startPoint, err)
+	}
+
+	m.provisionWorktree(clonePath)
+
+	commit, _ := git.NewGit(clonePath).Rev("HEAD")
+	marker := warmMarker{CreatedAt: time.Now(), Commit: commit}
+	if err := util.AtomicWriteJSON(filepath.Join(dir, warmReadyFile), marker); err != nil {
+		m.removeWarmSlot(repoGit, id)
+		return nil, fmt.Errorf("marking warm slot ready: %w", err)
+	}
+
+	return &WarmSlot{ID: id, Path: clonePath, Ready: true, CreatedAt: marker.CreatedAt, Commit: commit}, nil
+}
+
+// ClaimWarm turns a ready warm worktree into polecat name: the worktree is
+// moved into polecats/<name>/, reset onto a fresh branch at the latest
+// origin/<default-branch> (or opts.StartPoint), and registered like a newly
+// added polecat.
+// Returns ErrNoWarmWorktree if the pool is empty.
+func (m *Manager) ClaimWarm(name string, opts AddOptions) (*Polecat, error) {
+	if m.exists(name) {
+		return nil, ErrPolecatExists
+	}
+
+	slots, err := m.WarmSlots()
+	if err != nil {
+		return nil, err
+	}
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return nil, fmt.Errorf("finding repo base: %w", err)
+	}
+
+	polecatDir := m.polecatDir(name)
+	clonePath := filepath.Join(polecatDir, m.rig.Name)
+
+	for _, slot := range slots {
+		if !slot.Ready {
+			continue
+		}
+		if err := os.MkdirAll(polecatDir, 0755); err != nil {
+			return nil, fmt.Errorf("creating polecat dir: %w", err)
+		}
+		// The move is the claim: if another spawner got here first the
+		// source is gone and we try the next slot.
+		if err := repoGit.WorktreeMove(slot.Path, clonePath); err != nil {
+			continue
+		}
+		_ = os.RemoveAll(m.warmSlotDir(slot.ID))
+
+		if err := m.resetClaimedWorktree(repoGit, clonePath, name, opts); err != nil {
+			// Don't hand out a half-reset worktree; discard it
+			_ = repoGit.WorktreeRemove(clonePath, true)
+			_ = os.RemoveAll(polecatDir)
+			return nil, err
+		}
+
+		branchName, _ := git.NewGit(clonePath).CurrentBranch()
+		return m.registerPolecat(name, clonePath, branchName, opts), nil
+	}
+
+	_ = os.Remove(polecatDir) // only removes the dir if we created it empty
+	return nil, ErrNoWarmWorktree
+}
+
+// resetClaimedWorktree moves a claimed worktree onto a fresh polecat branch
+// at the latest default branch. Untracked and ignored files produced by setup
+// hooks (dependency installs) are kept; that is the point of the pool.
+func (m *Manager) resetClaimedWorktree(repoGit *git.Git, clonePath, name string, opts AddOptions) error {
+	if err := repoGit.Fetch("origin"); err != nil {
+		fmt.Printf("Warning: could not fetch origin: %v\n", err)
+	}
+	startPoint := "origin/" + m.rig.DefaultBranch()
+	if opts.StartPoint != "" {
+		startPoint = opts.StartPoint
+	}
+	branchName := polecatBranchName(name, opts.HookBead)
+	if err := git.NewGit(clonePath).CheckoutNewBranch(branchName, startPoint); err != nil {
+		return fmt.Errorf("resetting warm worktree to %s: %w", startPoint, err)
+	}
+
+	// Cheap steps are redone so they reflect current rig state.
+	if err := m.setupSharedBeads(clonePath); err != nil {
+		fmt.Printf("Warning: could not set up shared beads: %v\n", err)
+	}
+	if err := rig.CopyOverlay(m.rig.Path, clonePath); err != nil {
+		fmt.Printf("Warning: could not copy overlay files: %v\n", err)
+	}
+	return nil
+}
+
+// ReplenishWarmPool tops the pool up to the configured size, first removing
+// abandoned and expired slots. Returns the number of worktrees created.
+func (m *Manager) ReplenishWarmPool() (int, error) {
+	cfg := m.WarmPoolConfig()
+	if cfg == nil {
+		return 0, nil
+	}
+
+	var maxAge time.Duration
+	if cfg.MaxAge != "" {
+		d, err := time.ParseDuration(cfg.MaxAge)
+		if err != nil {
+			return 0, fmt.Errorf("invalid warm_pool.max_age %q: %w", cfg.MaxAge, err)
+		}
+		maxAge = d
+	}
+
+	if _, err := m.PruneWarmPool(maxAge); err != nil {
+		return 0, err
+	}
+	slots, err := m.WarmSlots()
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for n := len(slots); n < cfg.Size; n++ {
+		if _, err := m.ProvisionWarm(); err != nil {
+			return created, err
+		}
+		created++
+	}
+	return created, nil
+}
+
+// PruneWarmPool removes slots whose provisioning was abandoned and, if maxAge
+// is non-zero, ready slots older than maxAge. Returns the number removed.
+func (m *Manager) PruneWarmPool(maxAge time.Duration) (int, error) {
+	slots, err := m.WarmSlots()
+	if err != nil {
+		return 0, err
+	}
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return 0, fmt.Errorf("finding repo base: %w", err)
+	}
+
+	removed := 0
+	for _, slot := range slots {
+		age := time.Since(slot.CreatedAt)
+		abandoned := !slot.Ready && age > warmProvisionTimeout
+		expired := slot.Ready && maxAge > 0 && age > maxAge
+		if abandoned || expired {
+			m.removeWarmSlot(repoGit, slot.ID)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// DrainWarmPool removes every warm slot. Returns the number removed.
+func (m *Manager) DrainWarmPool() (int, error) {
+	slots, err := m.WarmSlots()
+	if err != nil {
+		return 0, err
+	}
+	repoGit, err := m.repoBase()
+	if err != nil {
+		return 0, fmt.Errorf("finding repo base: %w", err)
+	}
+	for _, slot := range slots {
+		m.removeWarmSlot(repoGit, slot.ID)
+	}
+	return len(slots), nil
+}
+
+// removeWarmSlot deletes a warm slot's worktree and directory (best-effort).
+func (m *Manager) removeWarmSlot(repoGit *git.Git, id string) {
+	dir := m.warmSlotDir(id)
+	_ = repoGit.WorktreeRemove(filepath.Join(dir, m.rig.Name), true)
+	_ = os.RemoveAll(dir)
+	_ = repoGit.WorktreePrune()
+}
@@ -0,0 +1,118 @@
+package polecat
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// makeWarmSlot creates a warm slot directory, marked ready if created is non-zero.
+func makeWarmSlot(t *testing.T, m *Manager, id string, created time.Time) {
+	t.Helper()
+	dir := m.warmSlotDir(id)
+	if err := os.MkdirAll(filepath.Join(dir, m.rig.Name), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if !created.IsZero() {
+		if err := util.AtomicWriteJSON(filepath.Join(dir, warmReadyFile), warmMarker{CreatedAt: created}); err != nil {
+			t.Fatalf("write marker: %v", err)
+		}
+	}
+}
+
+func TestWarmSlots(t *testing.T) {
+	root := t.TempDir()
+	r := &rig.Rig{Name: "test-rig", Path: root}
+	m := NewManager(r, git.NewGit(root), nil)
+
+	now := time.Now()
+	makeWarmSlot(t, m, "b", now.Add(-time.Hour))
+	makeWarmSlot(t, m, "a", now.Add(-2*time.Hour))
+	makeWarmSlot(t, m, "c", time.Time{}) // still provisioning
+	if err := os.MkdirAll(filepath.Join(root, "polecats", "Toast"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	slots, err := m.WarmSlots()
+	if err != nil {
+		t.Fatalf("WarmSlots: %v", err)
+	}
+	if len(slots) != 3 {
+		t.Fatalf("slots = %d, want 3", len(slots))
+	}
+	if slots[0].ID != "a" || slots[1].ID != "b" {
+		t.Errorf("order = %s,%s, want oldest first (a,b)", slots[0].ID, slots[1].ID)
+	}
+	if !slots[0].Ready || slots[2].Ready {
+		t.Errorf("ready flags = %v,%v,%v, want true,true,false", slots[0].Ready, slots[1].Ready, slots[2].Ready)
+	}
+	if want := filepath.Join(root, "polecats", ".warm-a", "test-rig"); slots[0].Path != want {
+		t.Errorf("path = %s, want %s", slots[0].Path, want)
+	}
+
+	// Warm slots are not polecats
+	polecats, err := m.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(polecats) != 1 || polecats[0].Name != "Toast" {
+		t.Errorf("List() = %v, want only Toast", polecats)
+	}
+}
+
+func TestPruneWarmPool(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "mayor", "rig"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	r := &rig.Rig{Name: "test-rig", Path: root}
+	m := NewManager(r, git.NewGit(root), nil)
+
+	now := time.Now()
+	makeWarmSlot(t, m, "fresh", now.Add(-time.Minute))
+	makeWarmSlot(t, m, "expired", now.Add(-48*time.Hour))
+	makeWarmSlot(t, m, "abandoned", time.Time{})
+	old := now.Add(-3 * warmProvisionTimeout)
+	if err := os.Chtimes(m.warmSlotDir("abandoned"), old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	removed, err := m.PruneWarmPool(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("PruneWarmPool: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("removed = %d, want 2", removed)
+	}
+
+	slots, _ := m.WarmSlots()
+	if len(slots) != 1 || slots[0].ID != "fresh" {
+		t.Errorf("remaining slots = %v, want only fresh", slots)
+	}
+}
+
+func TestClaimWarmEmptyPool(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "mayor", "rig"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	r := &rig.Rig{Name: "test-rig", Path: root}
+	m := NewManager(r, git.NewGit(root), nil)
+
+	// An unfinished slot must never be claimed
+	makeWarmSlot(t, m, "pending", time.Time{})
+
+	_, err := m.ClaimWarm("Toast", AddOptions{})
+	if !errors.Is(err, ErrNoWarmWorktree) {
+		t.Fatalf("ClaimWarm error = %v, want ErrNoWarmWorktree", err)
+	}
+	if m.exists("Toast") {
+		t.Error("failed claim left a polecat directory behind")
+	}
+}
@@ -0,0 +1,347 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Check names reported in violations.
+const (
+	CheckCommand = "command"
+	CheckPath    = "path"
+	CheckBranch  = "branch"
+	CheckGit     = "git"
+)
+
+// writeTools are the tools whose target path is checked by WorktreeOnly rules.
+var writeTools = map[string]bool{
+	"Edit":         true,
+	"Write":        true,
+	"MultiEdit":    true,
+	"NotebookEdit": true,
+}
+
+// Context describes the agent making the tool call.
+type Context struct {
+	// Role is the agent role (polecat, crew, witness, refinery, mayor, deacon).
+	Role string
+
+	// Worktree is the agent's own directory. Empty disables path checks.
+	Worktree string
+
+	// Branch is the current git branch, used for pushes without a refspec.
+	Branch string
+}
+
+// Violation is one rule check that matched a tool call.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Action  Action `json:"action"`
+	Check   string `json:"check"`
+	Detail  string `json:"detail"`
+	Message string `json:"message"`
+}
+
+// Decision is the outcome of evaluating a tool call.
+type Decision struct {
+	Violations []Violation `json:"violations,omitempty"`
+}
+
+// Blocked reports whether any violation blocks the call.
+func (d Decision) Blocked() bool {
+	for _, v := range d.Violations {
+		if v.Action == ActionBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// Evaluate checks a tool call against every rule that applies to ctx.Role.
+func (p *Policy) Evaluate(payload *Payload, ctx Context) Decision {
+	var d Decision
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if !r.AppliesTo(ctx.Role) {
+			continue
+		}
+		for _, v := range r.check(payload, ctx) {
+			v.Rule = r.Name
+			v.Action = r.Action
+			if r.Message != "" {
+				v.Message = r.Message
+			}
+			d.Violations = append(d.Violations, v)
+		}
+	}
+	return d
+}
+
+// check runs a rule's checks against one tool call.
+func (r *Rule) check(payload *Payload, ctx Context) []Violation {
+	var out []Violation
+
+	if payload.ToolName == "Bash" && payload.ToolInput.Command != "" {
+		for _, segment := range splitCommand(payload.ToolInput.Command) {
+			for _, re := range r.commands {
+				if re.MatchString(segment) {
+					out = append(out, Violation{
+						Check:   CheckCommand,
+						Detail:  segment,
+						Message: fmt.Sprintf("command matches forbidden pattern %q", re.String()),
+					})
+					break
+				}
+			}
+
+			sub, args, ok := gitCommand(segment)
+			if !ok {
+				continue
+			}
+			if r.DestructiveGit {
+				if reason := destructiveGit(sub, args); reason != "" {
+					out = append(out, Violation{
+						Check:   CheckGit,
+						Detail:  segment,
+						Message: "destructive git operation: " + reason,
+					})
+				}
+			}
+			if len(r.ProtectedBranches) > 0 {
+				for _, branch := range touchedBranches(sub, args, ctx.Branch) {
+					if r.protects(branch) {
+						out = append(out, Violation{
+							Check:   CheckBranch,
+							Detail:  branch,
+							Message: fmt.Sprintf("branch %q is protected", branch),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	if r.WorktreeOnly && writeTools[payload.ToolName] && ctx.Worktree != "" {
+		target := payload.ToolInput.FilePath
+		if target == "" {
+			target = payload.ToolInput.NotebookPath
+		}
+		if target != "" {
+			if !filepath.IsAbs(target) && payload.Cwd != "" {
+				target = filepath.Join(payload.Cwd, target)
+			}
+			target = filepath.Clean(target)
+			if !r.pathAllowed(target, ctx.Worktree) {
+				out = append(out, Violation{
+					Check:   CheckPath,
+					Detail:  target,
+					Message: fmt.Sprintf("path is outside the agent worktree %s", ctx.Worktree),
+				})
+			}
+		}
+	}
+
+	return out
+}
+
+// protects reports whether branch matches one of the rule's protected globs.
+func (r *Rule) protects(branch string) bool {
+	for _, glob := range r.ProtectedBranches {
+		if ok, _ := path.Match(glob, branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// pathAllowed reports whether target is inside the worktree or an allowed path.
+func (r *Rule) pathAllowed(target, worktree string) bool {
+	if within(target, worktree) {
+		return true
+	}
+	for _, allowed := range r.AllowPaths {
+		if strings.HasPrefix(allowed, "~") {
+			if home, err := os.UserHomeDir(); err == nil {
+				allowed = filepath.Join(home, strings.TrimPrefix(allowed, "~"))
+			}
+		}
+		if within(target, filepath.Clean(allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether target is dir or below it.
+func within(target, dir string) bool {
+	rel, err := filepath.Rel(dir, target)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// splitCommand splits a shell command into simple commands on &&, ||, ;,
+// | and newlines. Quoting is not interpreted; the split is deliberately
+// conservative so a separator inside quotes only yields extra segments.
+func splitCommand(command string) []string {
+	replacer := strings.NewReplacer("&&", "\n", "||", "\n", ";", "\n", "|", "\n")
+	var segments []string
+	for _, s := range strings.Split(replacer.Replace(command), "\n") {
+		if s = strings.TrimSpace(s); s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// gitCommand parses a simple command as a git invocation, skipping leading
+// environment assignments, wrappers (sudo, env, command, exec) and git's
+// global options. It returns the subcommand and its arguments.
+func gitCommand(segment string) (string, []string, bool) {
+	fields := strings.Fields(segment)
+	i := 0
+	for i < len(fields) {
+		f := fields[i]
+		if f == "sudo" || f == "env" || f == "command" || f == "exec" ||
+			(strings.Contains(f, "=") && !strings.HasPrefix(f, "-")) {
+			i++
+			continue
+		}
+		break
+	}
+	if i >= len(fields) || (fields[i] != "git" && !strings.HasSuffix(fields[i], "/git")) {
+		return "", nil, false
+	}
+	i++
+
+	for i < len(fields) && strings.HasPrefix(fields[i], "-") {
+		switch fields[i] {
+		case "-C", "-c", "--git-dir", "--work-tree", "--namespace":
+			i += 2
+		default:
+			i++
+		}
+	}
+	if i >= len(fields) {
+		return "", nil, false
+	}
+	return fields[i], fields[i+1:], true
+}
+
+// destructiveGit returns why a git invocation is destructive, or "".
+func destructiveGit(sub string, args []string) string {
+	has := func(flags ...string) bool {
+		for _, a := range args {
+			for _, f := range flags {
+				if a == f || (strings.HasPrefix(f, "--") && strings.HasPrefix(a, f+"=")) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	shortFlag := func(letter byte) bool {
+		for _, a := range args {
+			if len(a) > 1 && a[0] == '-' && a[1] != '-' && strings.IndexByte(a[1:], letter) >= 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch sub {
+	case "push":
+		if has("--force", "--force-with-lease", "--mirror") || shortFlag('f') {
+			return "force push"
+		}
+		for _, a := range args {
+			if strings.HasPrefix(a, "+") {
+				return "force push"
+			}
+		}
+	case "reset":
+		if has("--hard", "--merge", "--keep") {
+			return "reset discards uncommitted work"
+		}
+	case "clean":
+		if has("--force") || shortFlag('f') {
+			return "clean deletes untracked files"
+		}
+	case "branch":
+		if shortFlag('D') || (has("--delete") && has("--force")) {
+			return "force branch deletion"
+		}
+	case "checkout", "restore":
+		// Checking out or restoring the whole tree throws away local edits.
+		if sub == "checkout" && (has("--force") || shortFlag('f')) {
+			return "checkout discards working tree changes"
+		}
+		if n := len(args); n > 0 && (args[n-1] == "." || args[n-1] == ":/") {
+			return sub + " discards working tree changes"
+		}
+	case "stash":
+		if len(args) > 0 && (args[0] == "drop" || args[0] == "clear") {
+			return "stash " + args[0] + " discards stashed work"
+		}
+	case "filter-branch", "filter-repo":
+		return "history rewrite"
+	case "reflog":
+		if len(args) > 0 && (args[0] == "expire" || args[0] == "delete") {
+			return "reflog " + args[0]
+		}
+	case "update-ref":
+		if has("-d") {
+			return "ref deletion"
+		}
+	}
+	return ""
+}
+
+// touchedBranches returns the branches a git invocation pushes to or deletes.
+func touchedBranches(sub string, args []string, current string) []string {
+	var positional []string
+	deleting := false
+	for _, a := range args {
+		switch {
+		case a == "-d" || a == "-D" || a == "--delete":
+			deleting = true
+		case strings.HasPrefix(a, "-"):
+		default:
+			positional = append(positional, a)
+		}
+	}
+
+	var branches []string
+	switch sub {
+	case "push":
+		if len(positional) <= 1 {
+			// git push [remote]: pushes the current branch
+			if current != "" && current != "HEAD" {
+				branches = append(branches, current)
+			}
+			break
+		}
+		for _, spec := range positional[1:] {
+			spec = strings.TrimPrefix(spec, "+")
+			dst := spec
+			if i := strings.LastIndex(spec, ":"); i >= 0 {
+				dst = spec[i+1:]
+			}
+			if dst == "HEAD" || dst == "" && !strings.Contains(spec, ":") {
+				dst = current
+			}
+			if dst = strings.TrimPrefix(dst, "refs/heads/"); dst != "" {
+				branches = append(branches, dst)
+			}
+		}
+	case "branch":
+		if deleting {
+			branches = append(branches, positional...)
+		}
+	}
+	return branches
+}
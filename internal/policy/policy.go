@@ -0,0 +1,243 @@
+// Package policy implements the declarative guard policy evaluated by
+// `gt tap guard policy` on Claude Code PreToolUse payloads.
+//
+// Policies live in settings/policy.json at town and rig level. Each rule
+// applies to a set of roles and can forbid command patterns, writes outside
+// the agent's worktree, pushes to protected branches, and destructive git
+// operations. Rig rules override town rules of the same name.
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+)
+
+// CurrentVersion is the current policy file schema version.
+const CurrentVersion = 1
+
+// Action is what happens when a rule matches.
+type Action string
+
+const (
+	// ActionBlock blocks the tool call (hook exit code 2).
+	ActionBlock Action = "block"
+
+	// ActionWarn allows the tool call but records an audit event.
+	ActionWarn Action = "warn"
+)
+
+// Policy is a set of guard rules (settings/policy.json).
+type Policy struct {
+	Type    string `json:"type"`    // "policy"
+	Version int    `json:"version"` // schema version
+	Rules   []Rule `json:"rules"`
+}
+
+// Rule declares one guard. A rule may combine several checks; any check
+// that matches produces a violation.
+type Rule struct {
+	// Name identifies the rule. Rig rules replace town rules with the same name.
+	Name string `json:"name"`
+
+	// Description is shown by `gt tap guard test`.
+	Description string `json:"description,omitempty"`
+
+	// Roles limits the rule to agent roles (polecat, crew, witness, refinery,
+	// mayor, deacon). Empty or "*" applies to every agent role.
+	Roles []string `json:"roles,omitempty"`
+
+	// Action is "block" (default) or "warn".
+	Action Action `json:"action,omitempty"`
+
+	// Disabled turns the rule off. Use it in a rig policy to drop a town rule.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Commands are regular expressions matched against each segment of a
+	// Bash command (split on &&, ||, ;, | and newlines).
+	Commands []string `json:"commands,omitempty"`
+
+	// WorktreeOnly blocks file writes (Edit, Write, MultiEdit, NotebookEdit)
+	// outside the agent's own directory.
+	WorktreeOnly bool `json:"worktree_only,omitempty"`
+
+	// AllowPaths are extra directories writable when WorktreeOnly is set.
+	// A leading ~ is expanded to the home directory.
+	AllowPaths []string `json:"allow_paths,omitempty"`
+
+	// ProtectedBranches are branch globs (e.g. "main", "release/*") that
+	// may not be pushed to or deleted.
+	ProtectedBranches []string `json:"protected_branches,omitempty"`
+
+	// DestructiveGit blocks history- and work-destroying git operations:
+	// force pushes, reset --hard, clean -f, branch -D, checkout/restore of
+	// the whole tree, stash drop/clear, filter-branch and reflog expiry.
+	DestructiveGit bool `json:"destructive_git,omitempty"`
+
+	// Message is shown to the agent when the rule blocks a call.
+	Message string `json:"message,omitempty"`
+
+	commands []*regexp.Regexp
+}
+
+// Path returns the policy file path under a town or rig root.
+func Path(root string) string {
+	return filepath.Join(root, "settings", "policy.json")
+}
+
+// LoadFile reads and validates a single policy file.
+// Returns nil without error if the file does not exist.
+func LoadFile(path string) (*Policy, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is a town or rig settings file
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing policy %s: %w", path, err)
+	}
+	if err := p.compile(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Load reads the town policy and overlays the rig policy (if rigPath is set).
+// Missing files are skipped; the result is never nil.
+func Load(townRoot, rigPath string) (*Policy, error) {
+	merged := &Policy{Type: "policy", Version: CurrentVersion}
+
+	roots := []string{townRoot}
+	if rigPath != "" {
+		roots = append(roots, rigPath)
+	}
+	for _, root := range roots {
+		p, err := LoadFile(Path(root))
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			merged.overlay(p)
+		}
+	}
+	return merged, nil
+}
+
+// overlay adds o's rules, replacing same-named rules and dropping disabled ones.
+func (p *Policy) overlay(o *Policy) {
+	for _, r := range o.Rules {
+		replaced := false
+		for i := range p.Rules {
+			if p.Rules[i].Name == r.Name {
+				p.Rules[i] = r
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			p.Rules = append(p.Rules, r)
+		}
+	}
+
+	kept := p.Rules[:0]
+	for _, r := range p.Rules {
+		if !r.Disabled {
+			kept = append(kept, r)
+		}
+	}
+	p.Rules = kept
+}
+
+// compile validates the policy and compiles command patterns.
+func (p *Policy) compile() error {
+	if p.Type != "policy" && p.Type != "" {
+		return fmt.Errorf("expected type 'policy', got '%s'", p.Type)
+	}
+	if p.Version > CurrentVersion {
+		return fmt.Errorf("unsupported policy version %d (max %d)", p.Version, CurrentVersion)
+	}
+
+	seen := make(map[string]bool)
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Name == "" {
+			return fmt.Errorf("rule %d: name is required", i+1)
+		}
+		if seen[r.Name] {
+			return fmt.Errorf("rule %q: duplicate name", r.Name)
+		}
+		seen[r.Name] = true
+
+		switch r.Action {
+		case "":
+			r.Action = ActionBlock
+		case ActionBlock, ActionWarn:
+		default:
+			return fmt.Errorf("rule %q: invalid action %q (want block or warn)", r.Name, r.Action)
+		}
+
+		r.commands = nil
+		for _, pattern := range r.Commands {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("rule %q: invalid command pattern: %w", r.Name, err)
+			}
+			r.commands = append(r.commands, re)
+		}
+		for _, glob := range r.ProtectedBranches {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("rule %q: invalid branch pattern %q: %w", r.Name, glob, err)
+			}
+		}
+	}
+	return nil
+}
+
+// AppliesTo reports whether the rule covers an agent role.
+func (r *Rule) AppliesTo(role string) bool {
+	if len(r.Roles) == 0 {
+		return true
+	}
+	for _, want := range r.Roles {
+		if want == "*" || want == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload is the subset of a Claude Code PreToolUse hook payload the policy uses.
+type Payload struct {
+	SessionID     string    `json:"session_id,omitempty"`
+	Cwd           string    `json:"cwd,omitempty"`
+	HookEventName string    `json:"hook_event_name,omitempty"`
+	ToolName      string    `json:"tool_name"`
+	ToolInput     ToolInput `json:"tool_input"`
+}
+
+// ToolInput holds the tool arguments relevant to policy checks.
+type ToolInput struct {
+	Command      string `json:"command,omitempty"`
+	FilePath     string `json:"file_path,omitempty"`
+	NotebookPath string `json:"notebook_path,omitempty"`
+}
+
+// ParsePayload decodes a PreToolUse hook payload.
+func ParsePayload(data []byte) (*Payload, error) {
+	var p Payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("parsing hook payload: %w", err)
+	}
+	if p.ToolName == "" {
+		return nil, errors.New("hook payload has no tool_name")
+	}
+	return &p, nil
+}
@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePolicy(t *testing.T, root, content string) {
+	t.Helper()
+	dir := filepath.Join(root, "settings")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "policy.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadOverlay(t *testing.T) {
+	town := t.TempDir()
+	rig := filepath.Join(town, "gastown")
+
+	writePolicy(t, town, `{"type":"policy","version":1,"rules":[
+		{"name":"no-prs","commands":["^gh pr create"]},
+		{"name":"git-safety","destructive_git":true},
+		{"name":"polecat-branches","roles":["polecat"],"protected_branches":["main"]}
+	]}`)
+	writePolicy(t, rig, `{"rules":[
+		{"name":"git-safety","disabled":true},
+		{"name":"polecat-branches","roles":["polecat"],"protected_branches":["main","release/*"],"action":"warn"}
+	]}`)
+
+	p, err := Load(town, rig)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(p.Rules) != 2 {
+		t.Fatalf("got %d rules, want 2 (git-safety disabled by rig)", len(p.Rules))
+	}
+	if r := p.Rules[1]; r.Name != "polecat-branches" || r.Action != ActionWarn || len(r.ProtectedBranches) != 2 {
+		t.Errorf("rig override not applied: %+v", r)
+	}
+
+	// Missing files are fine
+	if p, err := Load(t.TempDir(), ""); err != nil || len(p.Rules) != 0 {
+		t.Errorf("Load(empty) = %v, %v", p, err)
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"bad regex":  `{"rules":[{"name":"x","commands":["("]}]}`,
+		"bad action": `{"rules":[{"name":"x","action":"explode"}]}`,
+		"no name":    `{"rules":[{"commands":["rm"]}]}`,
+		"duplicate":  `{"rules":[{"name":"x"},{"name":"x"}]}`,
+		"wrong type": `{"type":"escalation","rules":[]}`,
+	} {
+		root := t.TempDir()
+		writePolicy(t, root, content)
+		if _, err := LoadFile(Path(root)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p := &Policy{Rules: []Rule{
+		{Name: "no-prs", Commands: []string{`^gh pr create`}},
+		{Name: "polecat", Roles: []string{"polecat"}, WorktreeOnly: true, AllowPaths: []string{"/tmp"},
+			ProtectedBranches: []string{"main", "release/*"}, DestructiveGit: true},
+	}}
+	if err := p.compile(); err != nil {
+		t.Fatal(err)
+	}
+
+	polecat := Context{Role: "polecat", Worktree: "/gt/gastown/polecats/Toast", Branch: "polecat/Toast/gt-abc"}
+	crew := Context{Role: "crew", Worktree: "/gt/gastown/crew/max", Branch: "main"}
+
+	bash := func(cmd string) *Payload {
+		return &Payload{ToolName: "Bash", Cwd: "/gt/gastown/polecats/Toast/gastown", ToolInput: ToolInput{Command: cmd}}
+	}
+	write := func(path string) *Payload {
+		return &Payload{ToolName: "Write", Cwd: "/gt/gastown/polecats/Toast/gastown", ToolInput: ToolInput{FilePath: path}}
+	}
+
+	tests := []struct {
+		name    string
+		payload *Payload
+		ctx     Context
+		check   string // expected check, "" for allowed
+	}{
+		{"pr create", bash("git add . && gh pr create --fill"), crew, CheckCommand},
+		{"plain push own branch", bash("git push origin HEAD"), polecat, ""},
+		{"push to main", bash("git push origin HEAD:main"), polecat, CheckBranch},
+		{"push release glob", bash("cd x; git -C repo push origin release/1.2"), polecat, CheckBranch},
+		{"delete main", bash("git push origin --delete main"), polecat, CheckBranch},
+		{"crew may push main", bash("git push"), crew, ""},
+		{"force push", bash("GIT_TRACE=1 git push -f origin polecat/Toast/gt-abc"), polecat, CheckGit},
+		{"plus refspec", bash("git push origin +HEAD:polecat/Toast/gt-abc"), polecat, CheckGit},
+		{"reset hard", bash("git reset --hard HEAD~1"), polecat, CheckGit},
+		{"clean", bash("git clean -fdx"), polecat, CheckGit},
+		{"checkout tree", bash("git checkout -- ."), polecat, CheckGit},
+		{"soft reset ok", bash("git reset HEAD~1"), polecat, ""},
+		{"write inside", write("src/main.go"), polecat, ""},
+		{"write own CLAUDE.md", write("/gt/gastown/polecats/Toast/CLAUDE.md"), polecat, ""},
+		{"write tmp", write("/tmp/scratch.txt"), polecat, ""},
+		{"write other polecat", write("/gt/gastown/polecats/Nux/gastown/x.go"), polecat, CheckPath},
+		{"escape via dotdot", write("../../Nux/x.go"), polecat, CheckPath},
+		{"crew unrestricted", write("/etc/hosts"), crew, ""},
+	}
+	for _, tt := range tests {
+		d := p.Evaluate(tt.payload, tt.ctx)
+		switch {
+		case tt.check == "" && len(d.Violations) > 0:
+			t.Errorf("%s: unexpected violations %+v", tt.name, d.Violations)
+		case tt.check != "" && (len(d.Violations) == 0 || d.Violations[0].Check != tt.check):
+			t.Errorf("%s: violations %+v, want %s", tt.name, d.Violations, tt.check)
+		case tt.check != "" && !d.Blocked():
+			t.Errorf("%s: expected block", tt.name)
+		}
+	}
+}
+
+func TestParsePayload(t *testing.T) {
+	p, err := ParsePayload([]byte(`{"session_id":"s","cwd":"/w","hook_event_name":"PreToolUse","tool_name":"Edit","tool_input":{"file_path":"/w/a.go","old_string":"x"}}`))
+	if err != nil || p.ToolName != "Edit" || p.ToolInput.FilePath != "/w/a.go" {
+		t.Errorf("ParsePayload = %+v, %v", p, err)
+	}
+	if _, err := ParsePayload([]byte(`{"tool_input":{}}`)); err == nil {
+		t.Error("expected error for missing tool_name")
+	}
+}
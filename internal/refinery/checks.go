@@ -0,0 +1,398 @@
+package refinery
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// secretScanLimit caps how much of a file the secret scanner reads. Files
+// this large are left to the file-size check.
+const secretScanLimit = 1 << 20
+
+// allowSecretMarker on a line suppresses secret findings for that line.
+const allowSecretMarker = "gt:allow-secret"
+
+// CheckInput describes the MR a pre-merge check inspects.
+type CheckInput struct {
+	Branch  string   // Source branch being merged
+	Target  string   // Target branch
+	WorkDir string   // Refinery clone (target checked out)
+	Files   []string // Files added or changed on Branch since it left Target
+
+	git *git.Git
+}
+
+// Size returns the size of file on the MR branch.
+func (in *CheckInput) Size(file string) (int64, error) {
+	return in.git.BlobSize(in.Branch, file)
+}
+
+// Content returns file's contents on the MR branch.
+func (in *CheckInput) Content(file string) ([]byte, error) {
+	return in.git.ShowFile(in.Branch, file)
+}
+
+// CheckFinding is one problem found by a pre-merge check.
+type CheckFinding struct {
+	Check  string `json:"check"`          // Name of the check that found it
+	File   string `json:"file,omitempty"` // Offending file (empty for command output without a file)
+	Line   int    `json:"line,omitempty"` // 1-based line, 0 if not line-specific
+	Reason string `json:"reason"`
+}
+
+func (f CheckFinding) String() string {
+	loc := f.File
+	if f.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	if loc == "" {
+		return fmt.Sprintf("[%s] %s", f.Check, f.Reason)
+	}
+	return fmt.Sprintf("[%s] %s: %s", f.Check, loc, f.Reason)
+}
+
+// PreMergeCheck inspects an MR before it is merged. Checks run after the
+// conflict check and before tests; any finding fails the MR.
+type PreMergeCheck interface {
+	Name() string
+	Check(ctx context.Context, in *CheckInput) ([]CheckFinding, error)
+}
+
+// AddPreMergeCheck registers an extra check to run after the configured ones.
+func (e *Engineer) AddPreMergeCheck(c PreMergeCheck) {
+	e.extraChecks = append(e.extraChecks, c)
+}
+
+// preMergeChecks returns the checks enabled by the merge queue config.
+func (e *Engineer) preMergeChecks() []PreMergeCheck {
+	var checks []PreMergeCheck
+	if e.config.ScanSecrets {
+		checks = append(checks, &SecretScanner{Allowlist: e.config.CheckAllowlist})
+	}
+	if e.config.MaxFileSize > 0 {
+		checks = append(checks, &FileSizeCheck{Max: e.config.MaxFileSize, Allowlist: e.config.CheckAllowlist})
+	}
+	for _, command := range e.config.CheckCommands {
+		checks = append(checks, &CommandCheck{Command: command})
+	}
+	return append(checks, e.extraChecks...)
+}
+
+// runPreMergeChecks runs checks against branch and fails the MR with every
+// finding if any check reports one.
+func (e *Engineer) runPreMergeChecks(ctx context.Context, branch, target string, checks []PreMergeCheck) ProcessResult {
+	result, err := e.checkBranch(ctx, branch, target, checks)
+	if err != nil {
+		return ProcessResult{
+			Success: false,
+			Error:   err.Error(),
+		}
+	}
+	if result.Passed() {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Pre-merge checks passed (%d file(s))\n", result.Files)
+		return ProcessResult{Success: true}
+	}
+	return ProcessResult{
+		Success:     false,
+		CheckFailed: true,
+		Error:       result.Summary(),
+	}
+}
+
+// CheckResult is the outcome of running the pre-merge checks on an MR.
+type CheckResult struct {
+	MR       string         `json:"mr,omitempty"`
+	Branch   string         `json:"branch"`
+	Target   string         `json:"target"`
+	Checks   []string       `json:"checks"`
+	Files    int            `json:"files"`
+	Findings []CheckFinding `json:"findings,omitempty"`
+}
+
+// Passed reports whether no check found anything.
+func (r *CheckResult) Passed() bool {
+	return len(r.Findings) == 0
+}
+
+// Summary describes the findings, one per line.
+func (r *CheckResult) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "pre-merge checks failed (%d finding(s)):", len(r.Findings))
+	for _, f := range r.Findings {
+		sb.WriteString("\n  " + f.String())
+	}
+	return sb.String()
+}
+
+// CheckMR runs the configured pre-merge checks on an MR for a patrol that
+// merges by hand (gt refinery check). branch overrides the MR's branch, so
+// the rebased copy that will actually be merged can be checked.
+func (e *Engineer) CheckMR(ctx context.Context, mrID, branch string) (*CheckResult, error) {
+	mr, err := e.beads.Show(mrID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return nil, fmt.Errorf("%s has no MR fields", mrID)
+	}
+	if branch == "" {
+		branch = fields.Branch
+	}
+	target := fields.Target
+	if target == "" {
+		target = e.config.TargetBranch
+	}
+	if branch == "" {
+		return nil, fmt.Errorf("%s has no branch", mrID)
+	}
+
+	result, err := e.checkBranch(ctx, branch, target, e.preMergeChecks())
+	if err != nil {
+		return nil, err
+	}
+	result.MR = mrID
+	return result, nil
+}
+
+// checkBranch runs checks against the files branch changes since it left
+// target and collects every finding.
+func (e *Engineer) checkBranch(ctx context.Context, branch, target string, checks []PreMergeCheck) (*CheckResult, error) {
+	files, err := e.git.ChangedFiles(target, branch)
+	if err != nil {
+		return nil, fmt.Errorf("listing changed files: %w", err)
+	}
+	in := &CheckInput{Branch: branch, Target: target, WorkDir: e.workDir, Files: files, git: e.git}
+
+	result := &CheckResult{Branch: branch, Target: target, Checks: []string{}, Files: len(files)}
+	for _, c := range checks {
+		result.Checks = append(result.Checks, c.Name())
+		found, err := c.Check(ctx, in)
+		if err != nil {
+			return nil, fmt.Errorf("pre-merge check %s: %w", c.Name(), err)
+		}
+		result.Findings = append(result.Findings, found...)
+	}
+	return result, nil
+}
+
+// allowlisted returns true if file matches one of the path globs, either as
+// a whole path or by base name.
+func allowlisted(file string, globs []string) bool {
+	for _, g := range globs {
+		if ok, _ := path.Match(g, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(g, path.Base(file)); ok {
+			return true
+		}
+		if strings.HasSuffix(g, "/") && strings.HasPrefix(file, g) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretFileNames are files that hold secrets by convention, matched on the
+// base name.
+var secretFileNames = []struct {
+	glob   string
+	reason string
+}{
+	{".env", "dotenv file"},
+	{".env.*", "dotenv file"},
+	{"*.pem", "PEM key or certificate"},
+	{"*.key", "private key file"},
+	{"*.p12", "PKCS#12 keystore"},
+	{"*.pfx", "PKCS#12 keystore"},
+	{"id_rsa", "SSH private key"},
+	{"id_dsa", "SSH private key"},
+	{"id_ecdsa", "SSH private key"},
+	{"id_ed25519", "SSH private key"},
+	{".netrc", "netrc credentials"},
+	{".pgpass", "PostgreSQL password file"},
+}
+
+// secretFileExamples are templates that look like secret files but aren't.
+var secretFileExamples = []string{".example", ".sample", ".template", ".dist"}
+
+// secretPatterns are high-confidence token formats.
+var secretPatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY( BLOCK)?-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{"GitLab token", regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{"Anthropic API key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{32,}`)},
+	{"OpenAI API key", regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9]{32,}\b`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"Stripe live key", regexp.MustCompile(`\b[rs]k_live_[0-9A-Za-z]{24,}\b`)},
+}
+
+// SecretScanner flags secret files (.env, keys) and token-shaped strings in
+// the files an MR changes. Lines containing "gt:allow-secret" are skipped.
+type SecretScanner struct {
+	Allowlist []string // Path globs to skip
+}
+
+// Name returns the check name.
+func (s *SecretScanner) Name() string { return "secrets" }
+
+// Check scans each changed file.
+func (s *SecretScanner) Check(ctx context.Context, in *CheckInput) ([]CheckFinding, error) {
+	var findings []CheckFinding
+	for _, file := range in.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if allowlisted(file, s.Allowlist) {
+			continue
+		}
+		if reason := secretFileReason(file); reason != "" {
+			findings = append(findings, CheckFinding{Check: s.Name(), File: file, Reason: reason})
+			continue
+		}
+
+		size, err := in.Size(file)
+		if err != nil || size > secretScanLimit {
+			continue // Submodules and oversized files aren't scanned
+		}
+		content, err := in.Content(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", file, err)
+		}
+		findings = append(findings, scanSecrets(s.Name(), file, content)...)
+	}
+	return findings, nil
+}
+
+// secretFileReason returns why file's name marks it as a secret file, or "".
+func secretFileReason(file string) string {
+	base := path.Base(file)
+	for _, suffix := range secretFileExamples {
+		if strings.HasSuffix(base, suffix) {
+			return ""
+		}
+	}
+	for _, f := range secretFileNames {
+		if ok, _ := path.Match(f.glob, base); ok {
+			return f.reason
+		}
+	}
+	return ""
+}
+
+// scanSecrets reports lines of content matching a secret pattern. The
+// secret itself is never included in the finding.
+func scanSecrets(check, file string, content []byte) []CheckFinding {
+	if bytes.IndexByte(content, 0) >= 0 {
+		return nil // Binary
+	}
+	var findings []CheckFinding
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.Contains(line, allowSecretMarker) {
+			continue
+		}
+		for _, p := range secretPatterns {
+			if p.re.MatchString(line) {
+				findings = append(findings, CheckFinding{
+					Check:  check,
+					File:   file,
+					Line:   i + 1,
+					Reason: "possible " + p.name,
+				})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// FileSizeCheck flags files larger than Max bytes.
+type FileSizeCheck struct {
+	Max       int64
+	Allowlist []string // Path globs to skip
+}
+
+// Name returns the check name.
+func (c *FileSizeCheck) Name() string { return "file-size" }
+
+// Check compares each changed file's size to the limit.
+func (c *FileSizeCheck) Check(ctx context.Context, in *CheckInput) ([]CheckFinding, error) {
+	var findings []CheckFinding
+	for _, file := range in.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if allowlisted(file, c.Allowlist) {
+			continue
+		}
+		size, err := in.Size(file)
+		if err != nil {
+			continue // Submodule entries have no blob
+		}
+		if size > c.Max {
+			findings = append(findings, CheckFinding{
+				Check:  c.Name(),
+				File:   file,
+				Reason: fmt.Sprintf("%s exceeds max_file_size %s", util.FormatSize(size), util.FormatSize(c.Max)),
+			})
+		}
+	}
+	return findings, nil
+}
+
+// CommandCheck runs a rig-defined command from the refinery clone. The
+// changed files are passed on stdin, one per line, and the branches in
+// GT_MR_BRANCH and GT_MR_TARGET. A non-zero exit fails the MR; each line of
+// output becomes a finding, so commands should print offending files.
+type CommandCheck struct {
+	Command string
+}
+
+// Name returns the check name.
+func (c *CommandCheck) Name() string { return "command" }
+
+// Check runs the command.
+func (c *CommandCheck) Check(ctx context.Context, in *CheckInput) ([]CheckFinding, error) {
+	// Note: Command comes from rig's config.json (trusted infrastructure config),
+	// not from PR branches, same as test_command.
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.Command) //nolint:gosec // G204: Command is from trusted rig config
+	cmd.Dir = in.WorkDir
+	cmd.Env = append(os.Environ(), "GT_MR_BRANCH="+in.Branch, "GT_MR_TARGET="+in.Target)
+	cmd.Stdin = strings.NewReader(strings.Join(in.Files, "\n"))
+	out, err := cmd.CombinedOutput()
+	if err == nil {
+		return nil, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if _, ok := err.(*exec.ExitError); !ok {
+		return nil, fmt.Errorf("running %q: %w", c.Command, err)
+	}
+
+	var findings []CheckFinding
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			findings = append(findings, CheckFinding{Check: c.Command, Reason: line})
+		}
+	}
+	if len(findings) == 0 {
+		findings = append(findings, CheckFinding{Check: c.Command, Reason: err.Error()})
+	}
+	return findings, nil
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/steveyegge/gastown/internal/mail"
 	"github.com/steveyegge/gastown/internal/protocol"
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/util"
 )
 
 // MergeQueueConfig holds configuration for the merge queue processor.
@@ -51,6 +52,39 @@ type MergeQueueConfig struct {
 
 	// MaxConcurrent is the maximum number of MRs to process concurrently.
 	MaxConcurrent int `json:"max_concurrent"`
+
+	// VerifyAfterMerge enables post-merge verification: the test command is
+	// periodically run on the target head, and failures are bisected across
+	// recent MR merge commits to find the culprit.
+	VerifyAfterMerge bool `json:"verify_after_merge"`
+
+	// VerifyInterval is the minimum time between post-merge verifications.
+	VerifyInterval time.Duration `json:"verify_interval"`
+
+	// BisectLookback is the maximum number of recent merges to bisect.
+	BisectLookback int `json:"bisect_lookback"`
+
+	// ScanSecrets enables the built-in secret scanner in pre-merge checks.
+	// Off by default; rigs opt in with merge_queue.scan_secrets.
+	ScanSecrets bool `json:"scan_secrets"`
+
+	// MaxFileSize is the largest file (in bytes) an MR may add or change.
+	// Zero, the default, disables the check; rigs opt in with
+	// merge_queue.max_file_size.
+	MaxFileSize int64 `json:"max_file_size"`
+
+	// CheckCommands are rig-defined pre-merge check commands.
+	CheckCommands []string `json:"check_commands"`
+
+	// CheckAllowlist holds path globs exempt from the built-in checks.
+	CheckAllowlist []string `json:"check_allowlist"`
+
+	// RequiredReviews is the number of approvals an MR needs before it can
+	// merge. Zero disables the review gate.
+	RequiredReviews int `json:"required_reviews"`
+
+	// ReviewFormula is slung on each review bead to dispatch a reviewer.
+	ReviewFormula string `json:"review_formula"`
 }
 
 // DefaultMergeQueueConfig returns sensible defaults for merge queue configuration.
@@ -66,6 +100,13 @@ func DefaultMergeQueueConfig() *MergeQueueConfig {
 		RetryFlakyTests:      1,
 		PollInterval:         30 * time.Second,
 		MaxConcurrent:        1,
+		VerifyAfterMerge:     false,
+		VerifyInterval:       30 * time.Minute,
+		BisectLookback:       20,
+		ScanSecrets:          false,
+		MaxFileSize:          0,
+		RequiredReviews:      0,
+		ReviewFormula:        DefaultReviewFormula,
 	}
 }
 
@@ -86,6 +127,10 @@ type MRInfo struct {
 	ConvoyCreatedAt *time.Time // Convoy creation time
 	CreatedAt       time.Time  // MR creation time
 	BlockedBy       string     // Task ID blocking this MR
+	MergeCommit     string     // Merge commit SHA (merged MRs only)
+	StackedOn       string     // MR this one is stacked on (merges first)
+	StackBase       string     // Commit the branch started from when stacked
+	StackParentHead string     // Parent branch head the MR was last stacked on
 }
 
 // Engineer is the merge queue processor that polls for ready merge-requests
@@ -99,6 +144,12 @@ type Engineer struct {
 	output  io.Writer    // Output destination for user-facing messages
 	router  *mail.Router // Mail router for sending protocol messages
 
+	// progress publishes the current step and output for the merge queue TUI
+	progress *progressRecorder
+
+	// extraChecks are pre-merge checks registered with AddPreMergeCheck
+	extraChecks []PreMergeCheck
+
 	// stopCh is used for graceful shutdown
 	stopCh chan struct{}
 }
@@ -126,6 +177,8 @@ func NewEngineer(r *rig.Rig) *Engineer {
 		output:  os.Stdout,
 		router:  mail.NewRouter(r.Path),
 		stopCh:  make(chan struct{}),
+
+		progress: newProgressRecorder(r.Path),
 	}
 }
 
@@ -163,16 +216,25 @@ func (e *Engineer) LoadConfig() error {
 	// Parse merge_queue section into our config struct
 	// We need special handling for poll_interval (string -> Duration)
 	var mqRaw struct {
-		Enabled              *bool   `json:"enabled"`
-		TargetBranch         *string `json:"target_branch"`
-		IntegrationBranches  *bool   `json:"integration_branches"`
-		OnConflict           *string `json:"on_conflict"`
-		RunTests             *bool   `json:"run_tests"`
-		TestCommand          *string `json:"test_command"`
-		DeleteMergedBranches *bool   `json:"delete_merged_branches"`
-		RetryFlakyTests      *int    `json:"retry_flaky_tests"`
-		PollInterval         *string `json:"poll_interval"`
-		MaxConcurrent        *int    `json:"max_concurrent"`
+		Enabled              *bool    `json:"enabled"`
+		TargetBranch         *string  `json:"target_branch"`
+		IntegrationBranches  *bool    `json:"integration_branches"`
+		OnConflict           *string  `json:"on_conflict"`
+		RunTests             *bool    `json:"run_tests"`
+		TestCommand          *string  `json:"test_command"`
+		DeleteMergedBranches *bool    `json:"delete_merged_branches"`
+		RetryFlakyTests      *int     `json:"retry_flaky_tests"`
+		PollInterval         *string  `json:"poll_interval"`
+		MaxConcurrent        *int     `json:"max_concurrent"`
+		VerifyAfterMerge     *bool    `json:"verify_after_merge"`
+		VerifyInterval       *string  `json:"verify_interval"`
+		BisectLookback       *int     `json:"bisect_lookback"`
+		ScanSecrets          *bool    `json:"scan_secrets"`
+		MaxFileSize          *string  `json:"max_file_size"`
+		CheckCommands        []string `json:"check_commands"`
+		CheckAllowlist       []string `json:"check_allowlist"`
+		RequiredReviews      *int     `json:"required_reviews"`
+		ReviewFormula        *string  `json:"review_formula"`
 	}
 
 	if err := json.Unmarshal(rawConfig.MergeQueue, &mqRaw); err != nil {
@@ -214,6 +276,44 @@ func (e *Engineer) LoadConfig() error {
 		}
 		e.config.PollInterval = dur
 	}
+	if mqRaw.VerifyAfterMerge != nil {
+		e.config.VerifyAfterMerge = *mqRaw.VerifyAfterMerge
+	}
+	if mqRaw.VerifyInterval != nil {
+		dur, err := time.ParseDuration(*mqRaw.VerifyInterval)
+		if err != nil {
+			return fmt.Errorf("invalid verify_interval %q: %w", *mqRaw.VerifyInterval, err)
+		}
+		e.config.VerifyInterval = dur
+	}
+	if mqRaw.BisectLookback != nil {
+		e.config.BisectLookback = *mqRaw.BisectLookback
+	}
+	if mqRaw.ScanSecrets != nil {
+		e.config.ScanSecrets = *mqRaw.ScanSecrets
+	}
+	if mqRaw.MaxFileSize != nil {
+		size, err := util.ParseSize(*mqRaw.MaxFileSize)
+		if err != nil {
+			return fmt.Errorf("invalid max_file_size: %w", err)
+		}
+		e.config.MaxFileSize = size
+	}
+	if mqRaw.CheckCommands != nil {
+		e.config.CheckCommands = mqRaw.CheckCommands
+	}
+	if mqRaw.CheckAllowlist != nil {
+		e.config.CheckAllowlist = mqRaw.CheckAllowlist
+	}
+	if mqRaw.RequiredReviews != nil {
+		if *mqRaw.RequiredReviews < 0 {
+			return fmt.Errorf("invalid required_reviews %d: must be >= 0", *mqRaw.RequiredReviews)
+		}
+		e.config.RequiredReviews = *mqRaw.RequiredReviews
+	}
+	if mqRaw.ReviewFormula != nil && *mqRaw.ReviewFormula != "" {
+		e.config.ReviewFormula = *mqRaw.ReviewFormula
+	}
 
 	return nil
 }
@@ -230,6 +330,15 @@ type ProcessResult struct {
 	Error       string
 	Conflict    bool
 	TestsFailed bool
+	CheckFailed bool // A pre-merge check (secrets, file size, rig command) failed
+
+	// ReviewPending means the MR lacks required approvals; it was not
+	// attempted and stays queued.
+	ReviewPending bool
+
+	// StackPending means the MR is stacked on one that has not merged yet;
+	// it was not attempted and stays queued.
+	StackPending bool
 }
 
 // ProcessMR processes a single merge request from a beads issue.
@@ -243,18 +352,58 @@ func (e *Engineer) ProcessMR(ctx context.Context, mr *beads.Issue) ProcessResult
 		}
 	}
 
+	if result, ok := e.checkReviewGate(mr.ID); !ok {
+		return result
+	}
+	stacked := &MRInfo{ID: mr.ID, Branch: mrFields.Branch, Target: mrFields.Target,
+		StackedOn: mrFields.StackedOn, StackBase: mrFields.StackBase, StackParentHead: mrFields.StackParentHead}
+	if result, ok := e.checkStack(stacked); !ok {
+		return result
+	}
+
+	done := e.trackProgress(mr.ID, mrFields.Branch, mrFields.Target)
+
 	// Log what we're processing
 	_, _ = fmt.Fprintln(e.output, "[Engineer] Processing MR:")
 	_, _ = fmt.Fprintf(e.output, "  Branch: %s\n", mrFields.Branch)
 	_, _ = fmt.Fprintf(e.output, "  Target: %s\n", mrFields.Target)
 	_, _ = fmt.Fprintf(e.output, "  Worker: %s\n", mrFields.Worker)
 
-	return e.doMerge(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue)
+	result := e.doMerge(ctx, mrFields.Branch, mrFields.Target, mrFields.SourceIssue,
+		e.mergeProvenance(mr.ID, mrFields.SourceIssue, mrFields.ConvoyID))
+	done(result)
+	return result
+}
+
+// trackProgress starts publishing progress for an MR and tees the Engineer's
+// output into it. The returned func records the result and restores output.
+func (e *Engineer) trackProgress(mrID, branch, target string) func(ProcessResult) {
+	if e.progress == nil {
+		return func(ProcessResult) {}
+	}
+	e.progress.begin(mrID, branch, target)
+	orig := e.output
+	e.output = io.MultiWriter(orig, e.progress.outputWriter())
+	return func(result ProcessResult) {
+		e.output = orig
+		e.progress.finish(result)
+	}
+}
+
+// mergeProvenance returns the trailers stamped on an MR's merge commit.
+func (e *Engineer) mergeProvenance(mrID, sourceIssue, convoyID string) git.Provenance {
+	return git.Provenance{
+		Bead:   sourceIssue,
+		Agent:  e.rig.Name + "/refinery",
+		Convoy: convoyID,
+		MR:     mrID,
+	}
 }
 
 // doMerge performs the actual git merge operation.
 // This is the core merge logic shared by ProcessMR and ProcessMRFromQueue.
-func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string) ProcessResult {
+// The merge commit carries prov as provenance trailers.
+func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue string, prov git.Provenance) ProcessResult {
 	// Step 1: Verify source branch exists locally (shared .repo.git with polecats)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking local branch %s...\n", branch)
 	exists, err := e.git.BranchExists(branch)
@@ -287,6 +436,7 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 	}
 
 	// Step 3: Check for merge conflicts (using local branch)
+	e.progress.step(StepConflictCheck)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Checking for conflicts...\n")
 	conflicts, err := e.git.CheckConflicts(branch, target)
 	if err != nil {
@@ -304,8 +454,18 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 	}
 
-	// Step 4: Run tests if configured
+	// Step 4: Pre-merge checks (secrets, large files, rig-defined commands)
+	if checks := e.preMergeChecks(); len(checks) > 0 {
+		e.progress.step(StepChecks)
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Running %d pre-merge check(s)...\n", len(checks))
+		if result := e.runPreMergeChecks(ctx, branch, target, checks); !result.Success {
+			return result
+		}
+	}
+
+	// Step 5: Run tests if configured
 	if e.config.RunTests && e.config.TestCommand != "" {
+		e.progress.step(StepTests)
 		_, _ = fmt.Fprintf(e.output, "[Engineer] Running tests: %s\n", e.config.TestCommand)
 		result := e.runTests(ctx)
 		if !result.Success {
@@ -318,13 +478,14 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		_, _ = fmt.Fprintln(e.output, "[Engineer] Tests passed")
 	}
 
-	// Step 5: Perform the actual merge
+	// Step 6: Perform the actual merge
+	e.progress.step(StepMerge)
 	mergeMsg := fmt.Sprintf("Merge %s into %s", branch, target)
 	if sourceIssue != "" {
 		mergeMsg = fmt.Sprintf("Merge %s into %s (%s)", branch, target, sourceIssue)
 	}
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Merging with message: %s\n", mergeMsg)
-	if err := e.git.MergeNoFF(branch, mergeMsg); err != nil {
+	if err := e.git.MergeNoFF(branch, git.AppendTrailers(mergeMsg, prov)); err != nil {
 		// ZFC: Use git's porcelain output to detect conflicts instead of parsing stderr.
 		// GetConflictingFiles() uses `git diff --diff-filter=U` which is proper.
 		conflicts, conflictErr := e.git.GetConflictingFiles()
@@ -342,7 +503,7 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 	}
 
-	// Step 6: Get the merge commit SHA
+	// Step 7: Get the merge commit SHA
 	mergeCommit, err := e.git.Rev("HEAD")
 	if err != nil {
 		return ProcessResult{
@@ -351,7 +512,8 @@ func (e *Engineer) doMerge(ctx context.Context, branch, target, sourceIssue stri
 		}
 	}
 
-	// Step 7: Push to origin
+	// Step 8: Push to origin
+	e.progress.step(StepPush)
 	_, _ = fmt.Fprintf(e.output, "[Engineer] Pushing to origin/%s...\n", target)
 	if err := e.git.Push("origin", target, false); err != nil {
 		return ProcessResult{
@@ -390,8 +552,9 @@ func (e *Engineer) runTests(ctx context.Context) ProcessResult {
 		cmd := exec.CommandContext(ctx, "sh", "-c", e.config.TestCommand) //nolint:gosec // G204: TestCommand is from trusted rig config
 		cmd.Dir = e.workDir
 		var stdout, stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
+		testLog := e.progress.testWriter()
+		cmd.Stdout = io.MultiWriter(&stdout, testLog)
+		cmd.Stderr = io.MultiWriter(&stderr, testLog)
 
 		err := cmd.Run()
 		if err == nil {
@@ -477,7 +640,10 @@ func (e *Engineer) handleSuccess(mr *beads.Issue, result ProcessResult) {
 		}
 	}
 
-	// 5. Log success
+	// 5. Move MRs stacked on this one onto the target
+	e.restackChildren(mr.ID)
+
+	// 6. Log success
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
 }
 
@@ -496,6 +662,15 @@ func (e *Engineer) handleFailure(mr *beads.Issue, result ProcessResult) {
 
 // ProcessMRInfo processes a merge request from MRInfo.
 func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult {
+	if result, ok := e.checkReviewGate(mr.ID); !ok {
+		return result
+	}
+	if result, ok := e.checkStack(mr); !ok {
+		return result
+	}
+
+	done := e.trackProgress(mr.ID, mr.Branch, mr.Target)
+
 	// MR fields are directly on the struct
 	_, _ = fmt.Fprintln(e.output, "[Engineer] Processing MR:")
 	_, _ = fmt.Fprintf(e.output, "  Branch: %s\n", mr.Branch)
@@ -504,7 +679,10 @@ func (e *Engineer) ProcessMRInfo(ctx context.Context, mr *MRInfo) ProcessResult
 	_, _ = fmt.Fprintf(e.output, "  Source: %s\n", mr.SourceIssue)
 
 	// Use the shared merge logic
-	return e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue)
+	result := e.doMerge(ctx, mr.Branch, mr.Target, mr.SourceIssue,
+		e.mergeProvenance(mr.ID, mr.SourceIssue, mr.ConvoyID))
+	done(result)
+	return result
 }
 
 // HandleMRInfoSuccess handles a successful merge from MRInfo.
@@ -586,7 +764,12 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 		}
 	}
 
-	// 3. Log success
+	// 3. Move MRs stacked on this one onto the target
+	if mr.ID != "" {
+		e.restackChildren(mr.ID)
+	}
+
+	// 4. Log success
 	_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ Merged: %s (commit: %s)\n", mr.ID, result.MergeCommit)
 }
 
@@ -594,6 +777,12 @@ func (e *Engineer) HandleMRInfoSuccess(mr *MRInfo, result ProcessResult) {
 // For conflicts, creates a resolution task and blocks the MR until resolved.
 // This enables non-blocking delegation: the queue continues to the next MR.
 func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
+	// Awaiting review or a stacked-on MR is not a failure: nothing was attempted
+	if result.ReviewPending || result.StackPending {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] MR %s %s - queue continues to next MR\n", mr.ID, result.Error)
+		return
+	}
+
 	// Notify Witness of the failure so polecat can be alerted
 	// Determine failure type from result
 	failureType := "build"
@@ -601,6 +790,8 @@ func (e *Engineer) HandleMRInfoFailure(mr *MRInfo, result ProcessResult) {
 		failureType = "conflict"
 	} else if result.TestsFailed {
 		failureType = "tests"
+	} else if result.CheckFailed {
+		failureType = string(FailurePreMergeCheck)
 	}
 	msg := protocol.NewMergeFailedMessage(e.rig.Name, mr.Worker, mr.Branch, mr.SourceIssue, mr.Target, failureType, result.Error)
 	if err := e.router.Send(msg); err != nil {
@@ -776,6 +967,14 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 		return nil, fmt.Errorf("querying beads for merge-requests: %w", err)
 	}
 
+	// Reviews are listed once for the whole queue when the gate is on
+	var reviews map[string][]*Review
+	if e.config.RequiredReviews > 0 {
+		if reviews, err = listReviews(e.beads); err != nil {
+			return nil, err
+		}
+	}
+
 	// Convert beads issues to MRInfo
 	var mrs []*MRInfo
 	for _, issue := range issues {
@@ -784,11 +983,23 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 			continue
 		}
 
+		// Skip MRs still waiting on required approvals
+		if state, _ := e.reviewGate(issue.ID, reviews); state != nil && !state.Satisfied() {
+			continue
+		}
+
 		fields := beads.ParseMRFields(issue)
 		if fields == nil {
 			continue // Skip issues without MR fields
 		}
 
+		// Skip stacked MRs until the MR they build on has closed
+		if fields.StackedOn != "" {
+			if open, _ := e.IsBeadOpen(fields.StackedOn); open {
+				continue
+			}
+		}
+
 		// Skip if already assigned (claimed by another worker)
 		if issue.Assignee != "" {
 			// TODO: Add stale claim detection based on updated_at
@@ -825,6 +1036,9 @@ func (e *Engineer) ListReadyMRs() ([]*MRInfo, error) {
 			ConvoyID:        fields.ConvoyID,
 			ConvoyCreatedAt: convoyCreatedAt,
 			CreatedAt:       createdAt,
+			StackedOn:       fields.StackedOn,
+			StackBase:       fields.StackBase,
+			StackParentHead: fields.StackParentHead,
 		}
 		mrs = append(mrs, mr)
 	}
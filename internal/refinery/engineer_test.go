@@ -28,6 +28,9 @@ func TestDefaultMergeQueueConfig(t *testing.T) {
 	if cfg.OnConflict != "assign_back" {
 		t.Errorf("expected OnConflict to be 'assign_back', got %q", cfg.OnConflict)
 	}
+	if cfg.ScanSecrets || cfg.MaxFileSize != 0 {
+		t.Errorf("expected secret scan and file-size checks off by default, got %v/%d", cfg.ScanSecrets, cfg.MaxFileSize)
+	}
 }
 
 func TestEngineer_LoadConfig_NoFile(t *testing.T) {
@@ -70,12 +73,13 @@ func TestEngineer_LoadConfig_WithMergeQueue(t *testing.T) {
 		"version": 1,
 		"name":    "test-rig",
 		"merge_queue": map[string]interface{}{
-			"enabled":        true,
-			"target_branch":  "develop",
-			"poll_interval":  "10s",
-			"max_concurrent": 2,
-			"run_tests":      false,
-			"test_command":   "make test",
+			"enabled":          true,
+			"target_branch":    "develop",
+			"poll_interval":    "10s",
+			"max_concurrent":   2,
+			"run_tests":        false,
+			"test_command":     "make test",
+			"required_reviews": 2,
 		},
 	}
 
@@ -111,11 +115,17 @@ func TestEngineer_LoadConfig_WithMergeQueue(t *testing.T) {
 	if e.config.TestCommand != "make test" {
 		t.Errorf("expected TestCommand 'make test', got %q", e.config.TestCommand)
 	}
+	if e.config.RequiredReviews != 2 {
+		t.Errorf("expected RequiredReviews 2, got %d", e.config.RequiredReviews)
+	}
 
 	// Check that defaults are preserved for unspecified fields
 	if e.config.OnConflict != "assign_back" {
 		t.Errorf("expected OnConflict default 'assign_back', got %q", e.config.OnConflict)
 	}
+	if e.config.ReviewFormula != DefaultReviewFormula {
+		t.Errorf("expected ReviewFormula default %q, got %q", DefaultReviewFormula, e.config.ReviewFormula)
+	}
 }
 
 func TestEngineer_LoadConfig_NoMergeQueueSection(t *testing.T) {
@@ -186,6 +186,15 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 		command = config.BuildAgentStartupCommand("refinery", m.rig.Name, townRoot, m.rig.Path, "gt prime")
 	}
 
+	// Share the rig's build caches with polecats so test runs start warm.
+	cacheEnv, err := rig.CacheEnv(m.rig.Path)
+	if err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: starting without shared cache: %v\n", err)
+	}
+	if len(cacheEnv) > 0 {
+		command = config.PrependEnv(command, cacheEnv)
+	}
+
 	// Create session with command directly to avoid send-keys race condition.
 	// See: https://github.com/anthropics/gastown/issues/280
 	if err := t.NewSessionWithCommand(sessionID, refineryRigDir, command); err != nil {
@@ -203,6 +212,9 @@ func (m *Manager) Start(foreground bool, agentOverride string) error {
 
 	// Add refinery-specific flag
 	envVars["GT_REFINERY"] = "1"
+	for k, v := range cacheEnv {
+		envVars[k] = v
+	}
 
 	// Set all env vars in tmux session (for debugging) and they'll also be exported to Claude
 	for k, v := range envVars {
@@ -337,6 +349,8 @@ func (m *Manager) Queue() ([]QueueItem, error) {
 				Position: pos,
 				MR:       mr,
 				Age:      formatAge(mr.CreatedAt),
+				Priority: s.issue.Priority,
+				Score:    s.score,
 			})
 			pos++
 		}
@@ -0,0 +1,279 @@
+package refinery
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// MergeStep identifies where the Engineer is in processing a merge request.
+type MergeStep string
+
+const (
+	StepCheckout      MergeStep = "checkout"
+	StepConflictCheck MergeStep = "conflict_check"
+	StepChecks        MergeStep = "checks"
+	StepTests         MergeStep = "tests"
+	StepMerge         MergeStep = "merge"
+	StepPush          MergeStep = "push"
+	StepDone          MergeStep = "done"
+	StepFailed        MergeStep = "failed"
+)
+
+// MergeSteps lists the active steps in the order the Engineer runs them.
+var MergeSteps = []MergeStep{StepCheckout, StepConflictCheck, StepChecks, StepTests, StepMerge, StepPush}
+
+// progressTailLines caps how many output and test log lines are kept.
+const progressTailLines = 200
+
+// progressFlushInterval throttles progress writes caused by output alone.
+// Step changes are always written immediately.
+const progressFlushInterval = 250 * time.Millisecond
+
+// Progress is a snapshot of the refinery's work on one merge request. The
+// Engineer writes it as it merges, and the patrol reports its steps with
+// gt refinery progress; either way it lands in the rig's runtime directory
+// so other processes (the merge queue TUI) can follow along.
+type Progress struct {
+	MRID          string    `json:"mr_id"`
+	Branch        string    `json:"branch"`
+	Target        string    `json:"target"`
+	Step          MergeStep `json:"step"`
+	StartedAt     time.Time `json:"started_at"`
+	StepStartedAt time.Time `json:"step_started_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+	Error         string    `json:"error,omitempty"`
+	MergeCommit   string    `json:"merge_commit,omitempty"`
+
+	// Output holds the most recent Engineer output lines.
+	Output []string `json:"output,omitempty"`
+
+	// TestLog holds the most recent lines of test command output.
+	TestLog []string `json:"test_log,omitempty"`
+}
+
+// Active reports whether the Engineer is still working on the MR.
+func (p *Progress) Active() bool {
+	return p.Step != StepDone && p.Step != StepFailed
+}
+
+// ProgressFile returns the path of the Engineer progress file for a rig.
+func ProgressFile(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "refinery-progress.json")
+}
+
+// LoadProgress reads the Engineer's latest progress for a rig.
+// Returns nil without error if the Engineer has not processed anything yet.
+func LoadProgress(rigPath string) (*Progress, error) {
+	data, err := os.ReadFile(ProgressFile(rigPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var p Progress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ParseMergeStep parses a step name as reported by gt refinery progress.
+func ParseMergeStep(name string) (MergeStep, error) {
+	for _, s := range append(MergeSteps, StepDone, StepFailed) {
+		if string(s) == name {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown merge step %q", name)
+}
+
+// ProgressUpdate is a step reported from outside the Engineer, by a patrol
+// that merges by hand.
+type ProgressUpdate struct {
+	MRID        string
+	Branch      string
+	Target      string
+	Step        MergeStep
+	Error       string // Why the MR failed (StepFailed)
+	MergeCommit string // Merged commit (StepDone)
+}
+
+// RecordProgress publishes u to a rig's progress file. StepCheckout, or a
+// step for a different MR than the file holds, starts tracking a new MR;
+// other steps continue the current one.
+func RecordProgress(rigPath string, u ProgressUpdate) error {
+	now := time.Now()
+	p, err := LoadProgress(rigPath)
+	if err != nil || p == nil || p.MRID != u.MRID || u.Step == StepCheckout {
+		// A missing or corrupt file starts over too
+		p = &Progress{MRID: u.MRID, Step: StepCheckout, StartedAt: now, StepStartedAt: now}
+	}
+	if u.Branch != "" {
+		p.Branch = u.Branch
+	}
+	if u.Target != "" {
+		p.Target = u.Target
+	}
+	if p.Step != u.Step {
+		p.Step = u.Step
+		p.StepStartedAt = now
+		if u.Step == StepTests {
+			p.TestLog = nil
+		}
+	}
+	p.Error = u.Error
+	p.MergeCommit = u.MergeCommit
+	p.UpdatedAt = now
+	return writeProgress(ProgressFile(rigPath), p)
+}
+
+// writeProgress atomically replaces the progress file with p.
+func writeProgress(path string, p *Progress) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(path, p)
+}
+
+// progressRecorder tracks the current MR and persists it to the progress file.
+// All methods are safe to call on a nil recorder.
+type progressRecorder struct {
+	mu        sync.Mutex
+	path      string
+	p         *Progress
+	lastFlush time.Time
+}
+
+func newProgressRecorder(rigPath string) *progressRecorder {
+	return &progressRecorder{path: ProgressFile(rigPath)}
+}
+
+// begin starts tracking a new merge request.
+func (r *progressRecorder) begin(mrID, branch, target string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.p = &Progress{
+		MRID:          mrID,
+		Branch:        branch,
+		Target:        target,
+		Step:          StepCheckout,
+		StartedAt:     now,
+		StepStartedAt: now,
+	}
+	r.flushLocked(true)
+}
+
+// step records the Engineer moving to a new step.
+func (r *progressRecorder) step(s MergeStep) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.p == nil {
+		return
+	}
+	r.p.Step = s
+	r.p.StepStartedAt = time.Now()
+	if s == StepTests {
+		r.p.TestLog = nil
+	}
+	r.flushLocked(true)
+}
+
+// finish records the outcome of the merge request.
+func (r *progressRecorder) finish(result ProcessResult) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.p == nil {
+		return
+	}
+	r.p.Step = StepDone
+	if !result.Success {
+		r.p.Step = StepFailed
+		r.p.Error = result.Error
+	}
+	r.p.MergeCommit = result.MergeCommit
+	r.p.StepStartedAt = time.Now()
+	r.flushLocked(true)
+}
+
+// outputWriter returns a writer whose lines are appended to Progress.Output.
+func (r *progressRecorder) outputWriter() io.Writer {
+	if r == nil {
+		return io.Discard
+	}
+	return &progressLineWriter{r: r, log: func(p *Progress) *[]string { return &p.Output }}
+}
+
+// testWriter returns a writer whose lines are appended to Progress.TestLog.
+func (r *progressRecorder) testWriter() io.Writer {
+	if r == nil {
+		return io.Discard
+	}
+	return &progressLineWriter{r: r, log: func(p *Progress) *[]string { return &p.TestLog }}
+}
+
+// appendLines adds complete lines to one of the progress logs.
+func (r *progressRecorder) appendLines(log func(*Progress) *[]string, lines []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.p == nil {
+		return
+	}
+	tail := log(r.p)
+	*tail = append(*tail, lines...)
+	if len(*tail) > progressTailLines {
+		*tail = (*tail)[len(*tail)-progressTailLines:]
+	}
+	r.flushLocked(false)
+}
+
+// flushLocked writes the progress file. Caller must hold r.mu.
+func (r *progressRecorder) flushLocked(force bool) {
+	now := time.Now()
+	if !force && now.Sub(r.lastFlush) < progressFlushInterval {
+		return
+	}
+	r.p.UpdatedAt = now
+	r.lastFlush = now
+	_ = writeProgress(r.path, r.p) // non-fatal: progress is informational
+}
+
+// progressLineWriter splits written bytes into lines for a progress log.
+type progressLineWriter struct {
+	r       *progressRecorder
+	log     func(*Progress) *[]string
+	partial string
+}
+
+func (w *progressLineWriter) Write(b []byte) (int, error) {
+	text := w.partial + string(b)
+	lines := strings.Split(text, "\n")
+	w.partial = lines[len(lines)-1]
+	if complete := lines[:len(lines)-1]; len(complete) > 0 {
+		w.r.appendLines(w.log, complete)
+	}
+	return len(b), nil
+}
@@ -0,0 +1,95 @@
+package refinery
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestProgressRecorder(t *testing.T) {
+	rigPath := t.TempDir()
+
+	if p, err := LoadProgress(rigPath); p != nil || err != nil {
+		t.Fatalf("LoadProgress before any merge = %v, %v; want nil, nil", p, err)
+	}
+
+	rec := newProgressRecorder(rigPath)
+	rec.begin("gt-mr-1", "polecat/Nux/gt-abc", "main")
+	rec.step(StepTests)
+
+	w := rec.testWriter()
+	_, _ = fmt.Fprint(w, "=== RUN TestA\n--- PASS: TestA\npartial")
+	rec.finish(ProcessResult{Success: false, Error: "tests failed"})
+
+	p, err := LoadProgress(rigPath)
+	if err != nil {
+		t.Fatalf("LoadProgress: %v", err)
+	}
+	if p.MRID != "gt-mr-1" || p.Step != StepFailed || p.Error != "tests failed" || p.Active() {
+		t.Errorf("progress = %+v, want failed gt-mr-1", p)
+	}
+	if len(p.TestLog) != 2 || p.TestLog[1] != "--- PASS: TestA" {
+		t.Errorf("TestLog = %q, want the two complete lines", p.TestLog)
+	}
+}
+
+func TestProgressRecorderNil(t *testing.T) {
+	var rec *progressRecorder
+	rec.begin("gt-mr-1", "b", "main")
+	rec.step(StepMerge)
+	rec.finish(ProcessResult{Success: true})
+	if _, err := rec.outputWriter().Write([]byte("x\n")); err != nil {
+		t.Errorf("nil recorder writer: %v", err)
+	}
+}
+
+func TestRecordProgress(t *testing.T) {
+	rigPath := t.TempDir()
+
+	steps := []ProgressUpdate{
+		{MRID: "gt-mr-1", Branch: "polecat/Nux/gt-abc", Target: "main", Step: StepCheckout},
+		{MRID: "gt-mr-1", Step: StepChecks},
+		{MRID: "gt-mr-1", Step: StepTests},
+	}
+	for _, u := range steps {
+		if err := RecordProgress(rigPath, u); err != nil {
+			t.Fatalf("RecordProgress(%s): %v", u.Step, err)
+		}
+	}
+	p, err := LoadProgress(rigPath)
+	if err != nil {
+		t.Fatalf("LoadProgress: %v", err)
+	}
+	if p.MRID != "gt-mr-1" || p.Branch != "polecat/Nux/gt-abc" || p.Target != "main" || p.Step != StepTests || !p.Active() {
+		t.Errorf("progress = %+v, want gt-mr-1 running tests", p)
+	}
+	started := p.StartedAt
+
+	if err := RecordProgress(rigPath, ProgressUpdate{MRID: "gt-mr-1", Step: StepDone, MergeCommit: "abc123"}); err != nil {
+		t.Fatalf("RecordProgress(done): %v", err)
+	}
+	p, _ = LoadProgress(rigPath)
+	if p.Step != StepDone || p.MergeCommit != "abc123" || !p.StartedAt.Equal(started) {
+		t.Errorf("progress = %+v, want gt-mr-1 done at abc123", p)
+	}
+
+	// Another MR starts over
+	if err := RecordProgress(rigPath, ProgressUpdate{MRID: "gt-mr-2", Step: StepFailed, Error: "checks failed"}); err != nil {
+		t.Fatalf("RecordProgress(gt-mr-2): %v", err)
+	}
+	p, _ = LoadProgress(rigPath)
+	if p.MRID != "gt-mr-2" || p.Branch != "" || p.Step != StepFailed || p.Error != "checks failed" {
+		t.Errorf("progress = %+v, want failed gt-mr-2 without gt-mr-1's branch", p)
+	}
+}
+
+func TestParseMergeStep(t *testing.T) {
+	if s, err := ParseMergeStep("tests"); err != nil || s != StepTests {
+		t.Errorf("ParseMergeStep(tests) = %q, %v", s, err)
+	}
+	if s, err := ParseMergeStep("done"); err != nil || s != StepDone {
+		t.Errorf("ParseMergeStep(done) = %q, %v", s, err)
+	}
+	if _, err := ParseMergeStep("bogus"); err == nil {
+		t.Error("ParseMergeStep(bogus) should fail")
+	}
+}
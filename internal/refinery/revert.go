@@ -0,0 +1,184 @@
+package refinery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+// Errors for reverting merged MRs.
+var (
+	ErrMRNotMerged     = errors.New("merge request has not been merged")
+	ErrAlreadyReverted = errors.New("merge request already reverted")
+)
+
+// RevertBranchPrefix prefixes branches created by RevertMR.
+const RevertBranchPrefix = "revert/"
+
+// RevertResult describes a revert submitted by RevertMR.
+type RevertResult struct {
+	MRID        string `json:"mr_id"`        // the merged MR being reverted
+	RevertMRID  string `json:"revert_mr_id"` // the new MR carrying the revert
+	Branch      string `json:"branch"`       // revert branch
+	Target      string `json:"target"`
+	MergeCommit string `json:"merge_commit"` // commit that was reverted
+	Commit      string `json:"commit"`       // the revert commit
+	SourceIssue string `json:"source_issue,omitempty"`
+	Reopened    bool   `json:"reopened"` // source issue was reopened
+}
+
+// RevertMR reverts a merged MR: it creates a revert commit for the MR's
+// recorded merge commit on a revert/<mr-id> branch, submits that branch as a
+// P0 merge request, reopens the MR's source issue, and links the two MR beads
+// (reverted_by on the original, reverts on the new one).
+//
+// The revert MR deliberately has no source_issue: merging it must not close
+// the issue that was just reopened for rework.
+func (m *Manager) RevertMR(mrID, reason string) (*RevertResult, error) {
+	b := beads.New(m.rig.BeadsPath())
+	issue, err := b.Show(mrID)
+	if err != nil {
+		if errors.Is(err, beads.ErrNotFound) {
+			return nil, ErrMRNotFound
+		}
+		return nil, fmt.Errorf("fetching MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		return nil, fmt.Errorf("%s is not a merge request", mrID)
+	}
+	if issue.Status != "closed" || fields.MergeCommit == "" {
+		return nil, fmt.Errorf("%w: %s (status %s, no merge commit recorded)", ErrMRNotMerged, mrID, issue.Status)
+	}
+	if fields.RevertedBy != "" {
+		return nil, fmt.Errorf("%w by %s", ErrAlreadyReverted, fields.RevertedBy)
+	}
+
+	target := fields.Target
+	if target == "" {
+		target = m.rig.DefaultBranch()
+	}
+	result := &RevertResult{
+		MRID:        mrID,
+		Branch:      RevertBranchPrefix + mrID,
+		Target:      target,
+		MergeCommit: fields.MergeCommit,
+		SourceIssue: fields.SourceIssue,
+	}
+
+	// Build the revert in a scratch worktree so the refinery's checkout is untouched
+	repoGit := git.NewGit(m.gitDir())
+	if err := repoGit.Fetch("origin"); err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: fetch origin: %v (continuing)\n", err)
+	}
+	if exists, _ := repoGit.BranchExists(result.Branch); exists {
+		return nil, fmt.Errorf("branch %s already exists", result.Branch)
+	}
+	if onTarget, err := repoGit.IsAncestor(fields.MergeCommit, "origin/"+target); err != nil || !onTarget {
+		return nil, fmt.Errorf("merge commit %s is not on origin/%s", shortSHA(fields.MergeCommit), target)
+	}
+
+	scratch := filepath.Join(m.rig.Path, ".runtime", "revert-"+mrID)
+	_ = os.RemoveAll(scratch)
+	if err := os.MkdirAll(filepath.Dir(scratch), 0755); err != nil {
+		return nil, fmt.Errorf("creating runtime dir: %w", err)
+	}
+	if err := repoGit.WorktreeAddDetached(scratch, "origin/"+target); err != nil {
+		return nil, fmt.Errorf("creating revert worktree: %w", err)
+	}
+	defer func() {
+		_ = repoGit.WorktreeRemove(scratch, true)
+		_ = os.RemoveAll(scratch)
+	}()
+
+	wt := git.NewGit(scratch)
+	parents, err := wt.CommitParents(fields.MergeCommit)
+	if err != nil {
+		return nil, fmt.Errorf("reading merge commit: %w", err)
+	}
+	mainline := 0
+	if len(parents) > 1 {
+		mainline = 1
+	}
+	msg := fmt.Sprintf("Revert %s (%s)\n\nThis reverts merge commit %s of %s.", mrID, fields.Branch, fields.MergeCommit, fields.Branch)
+	if reason != "" {
+		msg += "\n\nReason: " + reason
+	}
+	if err := wt.Revert(fields.MergeCommit, mainline, msg); err != nil {
+		return nil, fmt.Errorf("reverting %s (conflicts with later changes? revert by hand): %w", shortSHA(fields.MergeCommit), err)
+	}
+	if result.Commit, err = wt.Rev("HEAD"); err != nil {
+		return nil, fmt.Errorf("reading revert commit: %w", err)
+	}
+
+	if err := wt.CreateBranchFrom(result.Branch, result.Commit); err != nil {
+		return nil, fmt.Errorf("creating branch %s: %w", result.Branch, err)
+	}
+	if err := wt.Push("origin", result.Branch, false); err != nil {
+		_ = repoGit.DeleteBranch(result.Branch, true)
+		return nil, fmt.Errorf("pushing %s: %w", result.Branch, err)
+	}
+
+	// Submit the revert as a high-priority MR
+	revertFields := &beads.MRFields{
+		Branch:  result.Branch,
+		Target:  target,
+		Worker:  fields.Worker,
+		Rig:     m.rig.Name,
+		Reverts: mrID,
+	}
+	description := beads.FormatMRFields(revertFields)
+	if reason != "" {
+		description += "\n\nReason: " + reason
+	}
+	revertMR, err := b.Create(beads.CreateOptions{
+		Title:       fmt.Sprintf("Revert: %s", issue.Title),
+		Type:        "merge-request",
+		Priority:    0,
+		Description: description,
+		Ephemeral:   true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating revert MR bead: %w", err)
+	}
+	result.RevertMRID = revertMR.ID
+
+	// Link the original MR to its revert
+	fields.RevertedBy = revertMR.ID
+	newDesc := beads.SetMRFields(issue, fields)
+	if err := b.Update(mrID, beads.UpdateOptions{Description: &newDesc}); err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: failed to record reverted_by on %s: %v\n", mrID, err)
+	}
+
+	// The work is no longer on the target; put the source issue back in play
+	if fields.SourceIssue != "" {
+		note := fmt.Sprintf("Reverted in %s", revertMR.ID)
+		if reason != "" {
+			note += ": " + reason
+		}
+		if err := b.Reopen(fields.SourceIssue, note); err != nil {
+			_, _ = fmt.Fprintf(m.output, "Warning: failed to reopen %s: %v\n", fields.SourceIssue, err)
+		} else {
+			result.Reopened = true
+		}
+	}
+
+	actor := m.rig.Name + "/refinery"
+	_ = events.LogFeed(events.TypeMergeReverted, actor, events.MergePayload(mrID, fields.Worker, fields.Branch, reason))
+
+	return result, nil
+}
+
+// gitDir returns the refinery's working clone, falling back to mayor/rig.
+func (m *Manager) gitDir() string {
+	dir := filepath.Join(m.rig.Path, "refinery", "rig")
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		dir = filepath.Join(m.rig.Path, "mayor", "rig")
+	}
+	return dir
+}
@@ -0,0 +1,129 @@
+package refinery
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// revertBDStub answers bd show with the MR in $BD_SHOW and bd create with
+// gt-rev1, logging every call to $BD_LOG.
+const revertBDStub = `#!/bin/sh
+echo "$*" >> "$BD_LOG"
+while [ "$1" = "--no-daemon" ] || [ "$1" = "--allow-stale" ]; do shift; done
+case "$1" in
+  show) cat "$BD_SHOW" ;;
+  create) echo '{"id":"gt-rev1"}' ;;
+esac
+`
+
+// setupRevert creates a rig whose refinery clone has feature merged into
+// main, and a bd stub serving mrDescription for gt-mr1. It returns the
+// manager, the clone's git helper, the merge commit and the bd log path.
+func setupRevert(t *testing.T, mrDescription func(mergeCommit string) string) (*Manager, func(...string) string, string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("bd stub is a shell script")
+	}
+
+	rigPath := filepath.Join(t.TempDir(), "testrig")
+	work := filepath.Join(rigPath, "refinery", "rig")
+	run := newTestClone(t, work)
+	run("checkout", "-b", "polecat/Toast/gt-xyz")
+	commitFile(t, run, work, "feature.txt", "feature\n")
+	run("checkout", "main")
+	run("merge", "--no-ff", "-m", "Merge polecat/Toast/gt-xyz", "polecat/Toast/gt-xyz")
+	run("push", "origin", "main")
+	mergeCommit := run("rev-parse", "HEAD")
+
+	binDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "bd"), []byte(revertBDStub), 0755); err != nil {
+		t.Fatalf("write bd stub: %v", err)
+	}
+	show := filepath.Join(binDir, "show.json")
+	mr := fmt.Sprintf(`[{"id":"gt-mr1","title":"Add feature","status":"closed","description":%q}]`, mrDescription(mergeCommit))
+	if err := os.WriteFile(show, []byte(mr), 0644); err != nil {
+		t.Fatalf("write show.json: %v", err)
+	}
+	logPath := filepath.Join(binDir, "bd.log")
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("BD_SHOW", show)
+	t.Setenv("BD_LOG", logPath)
+
+	m := NewManager(&rig.Rig{Name: "testrig", Path: rigPath})
+	m.SetOutput(io.Discard)
+	return m, run, mergeCommit, logPath
+}
+
+func TestRevertMR(t *testing.T) {
+	m, run, mergeCommit, logPath := setupRevert(t, func(mergeCommit string) string {
+		return "branch: polecat/Toast/gt-xyz\ntarget: main\nsource_issue: gt-xyz\nworker: Toast\nmerge_commit: " + mergeCommit
+	})
+
+	result, err := m.RevertMR("gt-mr1", "broke the build")
+	if err != nil {
+		t.Fatalf("RevertMR: %v", err)
+	}
+	if result.RevertMRID != "gt-rev1" || result.Branch != "revert/gt-mr1" || !result.Reopened {
+		t.Errorf("result = %+v", result)
+	}
+
+	// The revert branch is pushed, sits on the merge and undoes it
+	run("fetch", "origin")
+	if got := run("rev-parse", "origin/revert/gt-mr1"); got != result.Commit {
+		t.Errorf("origin/revert/gt-mr1 = %s, want revert commit %s", got, result.Commit)
+	}
+	if got := run("rev-parse", "origin/revert/gt-mr1~1"); got != mergeCommit {
+		t.Errorf("revert parent = %s, want merge commit %s", got, mergeCommit)
+	}
+	if files := run("ls-tree", "--name-only", "origin/revert/gt-mr1"); strings.Contains(files, "feature.txt") {
+		t.Errorf("feature.txt still present after revert: %s", files)
+	}
+	if msg := run("log", "-1", "--format=%B", "origin/revert/gt-mr1"); !strings.Contains(msg, "Reason: broke the build") {
+		t.Errorf("revert message = %q", msg)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("reading bd log: %v", err)
+	}
+	calls := string(data)
+	for _, want := range []string{"--priority=0", "reverts: gt-mr1", "update gt-mr1", "reverted_by: gt-rev1", "reopen gt-xyz"} {
+		if !strings.Contains(calls, want) {
+			t.Errorf("bd calls missing %q:\n%s", want, calls)
+		}
+	}
+}
+
+func TestRevertMRRefuses(t *testing.T) {
+	tests := []struct {
+		name string
+		desc func(mergeCommit string) string
+		want error
+	}{
+		{"already reverted", func(mc string) string {
+			return "branch: polecat/Toast/gt-xyz\ntarget: main\nmerge_commit: " + mc + "\nreverted_by: gt-rev0"
+		}, ErrAlreadyReverted},
+		{"no merge commit", func(string) string {
+			return "branch: polecat/Toast/gt-xyz\ntarget: main"
+		}, ErrMRNotMerged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, run, _, _ := setupRevert(t, tt.desc)
+			if _, err := m.RevertMR("gt-mr1", ""); !errors.Is(err, tt.want) {
+				t.Fatalf("RevertMR error = %v, want %v", err, tt.want)
+			}
+			if branches := run("ls-remote", "--heads", "origin"); strings.Contains(branches, "revert/") {
+				t.Errorf("revert branch pushed despite refusal: %s", branches)
+			}
+		})
+	}
+}
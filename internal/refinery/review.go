@@ -0,0 +1,441 @@
+package refinery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/mail"
+	"github.com/steveyegge/gastown/internal/protocol"
+)
+
+// Review verdicts recorded on review beads.
+const (
+	ReviewPending          = "pending"
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+)
+
+// DefaultReviewFormula is slung on each review bead to dispatch a reviewer.
+const DefaultReviewFormula = "mol-polecat-review-mr"
+
+// ReviewLabel marks review beads.
+const ReviewLabel = "gt:review"
+
+// Errors for the review gate.
+var (
+	ErrNoPendingReview = errors.New("no pending review")
+	ErrSelfReview      = errors.New("authors cannot review their own merge request")
+)
+
+// Review is a review bead gating a merge request.
+type Review struct {
+	ID       string `json:"id"`
+	MR       string `json:"mr"`
+	Status   string `json:"status"`  // Bead status (open, hooked, closed, ...)
+	Verdict  string `json:"verdict"` // pending, approved, changes_requested
+	Reviewer string `json:"reviewer,omitempty"`
+	Assignee string `json:"assignee,omitempty"`
+	Author   string `json:"author,omitempty"`
+}
+
+// Open returns true if the review is still awaiting a verdict.
+func (r *Review) Open() bool {
+	return r.Status != "closed" && (r.Verdict == "" || r.Verdict == ReviewPending)
+}
+
+// ReviewState summarizes an MR's reviews against the rig's requirement.
+type ReviewState struct {
+	Required         int       `json:"required"`
+	Approved         int       `json:"approved"`
+	Pending          int       `json:"pending"`
+	ChangesRequested int       `json:"changes_requested"`
+	Reviews          []*Review `json:"reviews,omitempty"`
+}
+
+// Satisfied returns true if the MR has enough approvals to merge.
+func (s *ReviewState) Satisfied() bool {
+	return s.Approved >= s.Required
+}
+
+// String renders the state as e.g. "1/2 approved, 1 pending".
+func (s *ReviewState) String() string {
+	parts := []string{fmt.Sprintf("%d/%d approved", s.Approved, s.Required)}
+	if s.Pending > 0 {
+		parts = append(parts, fmt.Sprintf("%d pending", s.Pending))
+	}
+	if s.ChangesRequested > 0 {
+		parts = append(parts, fmt.Sprintf("%d changes requested", s.ChangesRequested))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// newReviewState tallies reviews against the required approval count.
+func newReviewState(reviews []*Review, required int) *ReviewState {
+	state := &ReviewState{Required: required, Reviews: reviews}
+	for _, r := range reviews {
+		switch {
+		case r.Verdict == ReviewApproved:
+			state.Approved++
+		case r.Verdict == ReviewChangesRequested:
+			state.ChangesRequested++
+		case r.Open():
+			state.Pending++
+		}
+	}
+	return state
+}
+
+// listReviews returns all review beads, grouped by the MR they review.
+func listReviews(b *beads.Beads) (map[string][]*Review, error) {
+	issues, err := b.List(beads.ListOptions{
+		Status:   "all",
+		Label:    ReviewLabel,
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing reviews: %w", err)
+	}
+
+	byMR := make(map[string][]*Review)
+	for _, issue := range issues {
+		if r := reviewFromIssue(issue); r != nil {
+			byMR[r.MR] = append(byMR[r.MR], r)
+		}
+	}
+	return byMR, nil
+}
+
+// reviewFromIssue converts a review bead, returning nil for other beads.
+func reviewFromIssue(issue *beads.Issue) *Review {
+	fields := beads.ParseReviewFields(issue)
+	if fields == nil {
+		return nil
+	}
+	verdict := fields.Verdict
+	if verdict == "" {
+		verdict = ReviewPending
+	}
+	return &Review{
+		ID:       issue.ID,
+		MR:       fields.ReviewOf,
+		Status:   issue.Status,
+		Verdict:  verdict,
+		Reviewer: fields.Reviewer,
+		Assignee: issue.Assignee,
+		Author:   fields.Author,
+	}
+}
+
+// isAuthor returns true if agent (a BD_ACTOR or mail address) is the
+// worker who authored an MR in rigName.
+func isAuthor(agent, rigName, worker string) bool {
+	if agent == "" || worker == "" {
+		return false
+	}
+	switch agent {
+	case worker, rigName + "/" + worker, rigName + "/polecats/" + worker, rigName + "/crew/" + worker:
+		return true
+	}
+	return false
+}
+
+// reviewConfig loads the rig's merge queue config for the review gate.
+func (m *Manager) reviewConfig() *MergeQueueConfig {
+	eng := NewEngineer(m.rig)
+	if err := eng.LoadConfig(); err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: loading merge queue config: %v\n", err)
+	}
+	return eng.Config()
+}
+
+// ReviewFormula returns the formula slung on review beads.
+func (m *Manager) ReviewFormula() string {
+	return m.reviewConfig().ReviewFormula
+}
+
+// ReviewState returns the reviews of an MR and whether they satisfy the
+// rig's required_reviews.
+func (m *Manager) ReviewState(mrID string) (*ReviewState, error) {
+	byMR, err := listReviews(beads.New(m.rig.BeadsPath()))
+	if err != nil {
+		return nil, err
+	}
+	return newReviewState(byMR[mrID], m.reviewConfig().RequiredReviews), nil
+}
+
+// RequestReviews makes sure an MR has enough review beads to reach the
+// rig's required_reviews, creating new ones as needed and blocking the MR
+// on each. Reviews that requested changes don't count toward the total.
+//
+// Returns the open reviews that have no reviewer assigned yet; the caller
+// dispatches them (see ReviewFormula). Returns nil when reviews are not
+// required.
+func (m *Manager) RequestReviews(mrID string) ([]*Review, error) {
+	cfg := m.reviewConfig()
+	if cfg.RequiredReviews <= 0 {
+		return nil, nil
+	}
+
+	b := beads.New(m.rig.BeadsPath())
+	mr, err := b.Show(mrID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(mr)
+	if fields == nil {
+		return nil, fmt.Errorf("%s is not a merge request", mrID)
+	}
+
+	byMR, err := listReviews(b)
+	if err != nil {
+		return nil, err
+	}
+	reviews := byMR[mrID]
+	state := newReviewState(reviews, cfg.RequiredReviews)
+
+	subject := fields.SourceIssue
+	if subject == "" {
+		subject = fields.Branch
+	}
+	for i := state.Approved + state.Pending; i < cfg.RequiredReviews; i++ {
+		reviewFields := &beads.ReviewFields{
+			ReviewOf: mrID,
+			Branch:   fields.Branch,
+			Target:   fields.Target,
+			Author:   fields.Worker,
+			Verdict:  ReviewPending,
+		}
+		description := beads.FormatReviewFields(reviewFields) +
+			fmt.Sprintf("\n\nReview merge request %s (%s → %s) and record a verdict with\n"+
+				"`gt mq review <this-bead> --approve` or `--request-changes -m <feedback>`.",
+				mrID, fields.Branch, fields.Target)
+
+		issue, err := b.Create(beads.CreateOptions{
+			Title:       fmt.Sprintf("Review %s: %s", mrID, subject),
+			Type:        "review",
+			Priority:    mr.Priority,
+			Description: description,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating review bead: %w", err)
+		}
+
+		// Block the MR until the review is approved (closed)
+		if err := b.AddDependency(mrID, issue.ID); err != nil {
+			return nil, fmt.Errorf("blocking %s on review %s: %w", mrID, issue.ID, err)
+		}
+		reviews = append(reviews, &Review{
+			ID:      issue.ID,
+			MR:      mrID,
+			Status:  "open",
+			Verdict: ReviewPending,
+			Author:  fields.Worker,
+		})
+	}
+
+	var undispatched []*Review
+	for _, r := range reviews {
+		if r.Open() && r.Assignee == "" {
+			undispatched = append(undispatched, r)
+		}
+	}
+	return undispatched, nil
+}
+
+// ReviewResult describes a verdict recorded by SubmitReview.
+type ReviewResult struct {
+	ReviewID    string       `json:"review_id"`
+	MRID        string       `json:"mr_id"`
+	Verdict     string       `json:"verdict"`
+	Reviewer    string       `json:"reviewer"`
+	State       *ReviewState `json:"state"`
+	SourceIssue string       `json:"source_issue,omitempty"`
+	Released    bool         `json:"released"` // source issue sent back for rework
+}
+
+// SubmitReview records a verdict on a review. id may be the review bead or
+// the MR; for an MR, the review assigned to reviewer is used, else the
+// first open one.
+//
+// Approving closes the review bead, which unblocks the MR once every
+// required review is approved. Requesting changes closes the MR (its
+// branch is kept), closes the MR's other pending reviews, and releases the
+// source issue with the feedback so the work goes back to its author.
+func (m *Manager) SubmitReview(id, reviewer, verdict, comment string) (*ReviewResult, error) {
+	if verdict != ReviewApproved && verdict != ReviewChangesRequested {
+		return nil, fmt.Errorf("invalid verdict %q", verdict)
+	}
+	if verdict == ReviewChangesRequested && strings.TrimSpace(comment) == "" {
+		return nil, fmt.Errorf("requesting changes needs feedback for the author")
+	}
+
+	b := beads.New(m.rig.BeadsPath())
+	review, err := m.resolveReview(b, id, reviewer)
+	if err != nil {
+		return nil, err
+	}
+	if !review.Open() {
+		return nil, fmt.Errorf("review %s already has verdict %s", review.ID, review.Verdict)
+	}
+	if isAuthor(reviewer, m.rig.Name, review.Author) {
+		return nil, ErrSelfReview
+	}
+
+	mr, err := b.Show(review.MR)
+	if err != nil {
+		return nil, fmt.Errorf("fetching MR %s: %w", review.MR, err)
+	}
+	if mr.Status == "closed" {
+		return nil, fmt.Errorf("MR %s is already closed", review.MR)
+	}
+	mrFields := beads.ParseMRFields(mr)
+	if mrFields == nil {
+		mrFields = &beads.MRFields{}
+	}
+
+	// Record the verdict on the review bead, then close it
+	issue, err := b.Show(review.ID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching review %s: %w", review.ID, err)
+	}
+	fields := beads.ParseReviewFields(issue)
+	fields.Reviewer = reviewer
+	fields.Verdict = verdict
+	desc := beads.SetReviewFields(issue, fields)
+	if err := b.Update(review.ID, beads.UpdateOptions{Description: &desc}); err != nil {
+		return nil, fmt.Errorf("recording verdict on %s: %w", review.ID, err)
+	}
+	reason := "approved"
+	if verdict == ReviewChangesRequested {
+		reason = "changes requested"
+	}
+	if comment != "" {
+		reason += ": " + comment
+	}
+	if err := b.CloseWithReason(reason, review.ID); err != nil {
+		return nil, fmt.Errorf("closing review %s: %w", review.ID, err)
+	}
+	review.Verdict = verdict
+	review.Reviewer = reviewer
+	review.Status = "closed"
+
+	result := &ReviewResult{
+		ReviewID:    review.ID,
+		MRID:        review.MR,
+		Verdict:     verdict,
+		Reviewer:    reviewer,
+		SourceIssue: mrFields.SourceIssue,
+	}
+
+	if verdict == ReviewChangesRequested {
+		m.requestChanges(b, review, mrFields, comment)
+		result.Released = mrFields.SourceIssue != ""
+	}
+
+	if byMR, err := listReviews(b); err == nil {
+		result.State = newReviewState(byMR[review.MR], m.reviewConfig().RequiredReviews)
+	}
+	return result, nil
+}
+
+// resolveReview finds the review bead for id, which is either a review
+// bead or an MR with an open review.
+func (m *Manager) resolveReview(b *beads.Beads, id, reviewer string) (*Review, error) {
+	issue, err := b.Show(id)
+	if err != nil {
+		if errors.Is(err, beads.ErrNotFound) {
+			return nil, fmt.Errorf("%s: %w", id, ErrMRNotFound)
+		}
+		return nil, fmt.Errorf("fetching %s: %w", id, err)
+	}
+	if r := reviewFromIssue(issue); r != nil {
+		return r, nil
+	}
+	if beads.ParseMRFields(issue) == nil {
+		return nil, fmt.Errorf("%s is neither a review nor a merge request", id)
+	}
+
+	byMR, err := listReviews(b)
+	if err != nil {
+		return nil, err
+	}
+	var first *Review
+	for _, r := range byMR[id] {
+		if !r.Open() {
+			continue
+		}
+		if reviewer != "" && r.Assignee == reviewer {
+			return r, nil
+		}
+		if first == nil {
+			first = r
+		}
+	}
+	if first == nil {
+		return nil, fmt.Errorf("%w for %s", ErrNoPendingReview, id)
+	}
+	return first, nil
+}
+
+// requestChanges sends an MR back to its author: the MR is closed, its
+// other pending reviews are closed as superseded, the source issue is
+// released for rework and the Witness is told so it can notify the worker.
+// Failures are reported but don't undo the recorded verdict.
+func (m *Manager) requestChanges(b *beads.Beads, review *Review, mrFields *beads.MRFields, comment string) {
+	if byMR, err := listReviews(b); err == nil {
+		for _, other := range byMR[review.MR] {
+			if other.ID != review.ID && other.Open() {
+				_ = b.CloseWithReason("superseded: changes requested in "+review.ID, other.ID)
+			}
+		}
+	}
+
+	if err := b.CloseWithReason(fmt.Sprintf("rejected: changes requested in %s: %s", review.ID, comment), review.MR); err != nil {
+		_, _ = fmt.Fprintf(m.output, "Warning: failed to close MR %s: %v\n", review.MR, err)
+	}
+
+	if mrFields.SourceIssue != "" {
+		note := fmt.Sprintf("Changes requested in %s by %s: %s", review.ID, review.Reviewer, comment)
+		if err := b.ReleaseWithReason(mrFields.SourceIssue, note); err != nil {
+			_, _ = fmt.Fprintf(m.output, "Warning: failed to release %s: %v\n", mrFields.SourceIssue, err)
+		}
+	}
+
+	if mrFields.Worker != "" {
+		msg := protocol.NewMergeFailedMessage(m.rig.Name, mrFields.Worker, mrFields.Branch,
+			mrFields.SourceIssue, mrFields.Target, string(FailureChangesRequested), comment)
+		if err := mail.NewRouter(m.workDir).Send(msg); err != nil {
+			_, _ = fmt.Fprintf(m.output, "Warning: failed to notify witness: %v\n", err)
+		}
+	}
+}
+
+// reviewGate reports whether an MR may merge under the review gate. It
+// returns nil when reviews are not required.
+func (e *Engineer) reviewGate(mrID string, byMR map[string][]*Review) (*ReviewState, error) {
+	if e.config.RequiredReviews <= 0 {
+		return nil, nil
+	}
+	if byMR == nil {
+		var err error
+		if byMR, err = listReviews(e.beads); err != nil {
+			return nil, err
+		}
+	}
+	return newReviewState(byMR[mrID], e.config.RequiredReviews), nil
+}
+
+// checkReviewGate returns a ReviewPending result and false if the MR may
+// not merge yet.
+func (e *Engineer) checkReviewGate(mrID string) (ProcessResult, bool) {
+	state, err := e.reviewGate(mrID, nil)
+	if err != nil {
+		return ProcessResult{ReviewPending: true, Error: fmt.Sprintf("review state unknown: %v", err)}, false
+	}
+	if state != nil && !state.Satisfied() {
+		return ProcessResult{ReviewPending: true, Error: "awaiting review: " + state.String()}, false
+	}
+	return ProcessResult{}, true
+}
@@ -0,0 +1,76 @@
+package refinery
+
+import (
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+func TestReviewState(t *testing.T) {
+	reviews := []*Review{
+		{ID: "r1", Status: "closed", Verdict: ReviewApproved},
+		{ID: "r2", Status: "hooked", Verdict: ReviewPending},
+		{ID: "r3", Status: "closed", Verdict: ReviewChangesRequested},
+		{ID: "r4", Status: "closed", Verdict: ReviewPending}, // closed without verdict
+	}
+
+	state := newReviewState(reviews, 2)
+	if state.Approved != 1 || state.Pending != 1 || state.ChangesRequested != 1 {
+		t.Errorf("state = %+v", state)
+	}
+	if state.Satisfied() {
+		t.Error("1/2 approvals should not satisfy the gate")
+	}
+	if got, want := state.String(), "1/2 approved, 1 pending, 1 changes requested"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	if !newReviewState(reviews, 1).Satisfied() {
+		t.Error("1/1 approvals should satisfy the gate")
+	}
+	if !newReviewState(nil, 0).Satisfied() {
+		t.Error("no required reviews should satisfy the gate")
+	}
+}
+
+func TestReviewFromIssue(t *testing.T) {
+	issue := &beads.Issue{
+		ID:          "gt-rev1",
+		Status:      "open",
+		Assignee:    "gastown/polecats/toast",
+		Description: "review_of: gt-mr1\nbranch: polecat/nux/gt-abc\nauthor: nux",
+	}
+	r := reviewFromIssue(issue)
+	if r == nil {
+		t.Fatal("reviewFromIssue() = nil")
+	}
+	if r.MR != "gt-mr1" || r.Verdict != ReviewPending || !r.Open() {
+		t.Errorf("review = %+v", r)
+	}
+
+	if reviewFromIssue(&beads.Issue{Description: "branch: x\ntarget: main"}) != nil {
+		t.Error("MR bead should not parse as a review")
+	}
+}
+
+func TestIsAuthor(t *testing.T) {
+	tests := []struct {
+		agent string
+		want  bool
+	}{
+		{"gastown/polecats/nux", true},
+		{"gastown/nux", true},
+		{"nux", true},
+		{"gastown/polecats/toast", false},
+		{"otherrig/polecats/nux", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isAuthor(tt.agent, "gastown", "nux"); got != tt.want {
+			t.Errorf("isAuthor(%q) = %v, want %v", tt.agent, got, tt.want)
+		}
+	}
+	if isAuthor("gastown/polecats/nux", "gastown", "") {
+		t.Error("unknown author should never match")
+	}
+}
@@ -0,0 +1,283 @@
+package refinery
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/steveyegge/gastown/internal/beads"
+)
+
+// Stacked MRs
+//
+// A polecat whose issue depends on work still in the merge queue starts
+// from that work's branch (see gt sling). Its MR records the parent MR
+// (stacked_on) and the commit the branch started from (stack_base), and is
+// blocked on the parent so it cannot merge first. While the parent is
+// open, the MR also records the parent head it sits on (stack_parent_head);
+// when the parent's branch moves, e.g. after review rework, the MR's own
+// commits are replayed onto the new head. Once the parent lands,
+// restacking replays only the MR's own commits (stack_base..branch) onto
+// the target. The parent's commits are already there, possibly rewritten
+// by the parent's own rebase or conflict resolution.
+
+var (
+	// ErrStackPending means a stacked MR's parent has not merged yet.
+	ErrStackPending = errors.New("waiting for stacked-on MR")
+
+	// ErrRestackConflict means the MR's own commits do not apply cleanly
+	// on the target after its parent merged.
+	ErrRestackConflict = errors.New("restack conflict")
+)
+
+// mrMerged reports whether a closed MR landed. The Engineer records a merge
+// commit; the patrol closes merged MRs with a free-form reason. Rejected,
+// superseded and conflicted MRs say so.
+func mrMerged(mr *beads.Issue) bool {
+	if fields := beads.ParseMRFields(mr); fields != nil {
+		if fields.MergeCommit != "" || fields.CloseReason == string(CloseReasonMerged) {
+			return true
+		}
+		if fields.CloseReason != "" {
+			return false
+		}
+	}
+	reason := strings.ToLower(mr.CloseReason)
+	for _, r := range []CloseReason{CloseReasonRejected, CloseReasonSuperseded, CloseReasonConflict} {
+		if strings.HasPrefix(reason, string(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+// StackedMRs returns the open MRs stacked on parentID, or every stacked MR
+// in the queue if parentID is empty.
+func (e *Engineer) StackedMRs(parentID string) ([]*MRInfo, error) {
+	issues, err := e.beads.List(beads.ListOptions{
+		Status:   "open",
+		Label:    "gt:merge-request",
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing merge requests: %w", err)
+	}
+
+	var mrs []*MRInfo
+	for _, issue := range issues {
+		fields := beads.ParseMRFields(issue)
+		if fields == nil || fields.StackedOn == "" {
+			continue
+		}
+		if parentID != "" && fields.StackedOn != parentID {
+			continue
+		}
+		mrs = append(mrs, &MRInfo{
+			ID:              issue.ID,
+			Branch:          fields.Branch,
+			Target:          fields.Target,
+			SourceIssue:     fields.SourceIssue,
+			Worker:          fields.Worker,
+			Title:           issue.Title,
+			Priority:        issue.Priority,
+			StackedOn:       fields.StackedOn,
+			StackBase:       fields.StackBase,
+			StackParentHead: fields.StackParentHead,
+		})
+	}
+	return mrs, nil
+}
+
+// Restack prepares a stacked MR for merging. Not-stacked MRs return nil.
+//
+// While the parent is open it returns ErrStackPending, after moving the MR
+// onto the parent's latest head if the parent branch changed. If the parent was
+// closed without merging, the stack follows its issue: a replacement MR in
+// the queue becomes the new parent, and open rework keeps the MR waiting.
+// Otherwise the MR's own commits are rebased onto its target, the branch is
+// force-pushed and the stack fields are cleared, leaving an ordinary MR.
+func (e *Engineer) Restack(mr *MRInfo) error {
+	if mr.StackedOn == "" {
+		return nil
+	}
+
+	parent, err := e.beads.Show(mr.StackedOn)
+	if err != nil && !errors.Is(err, beads.ErrNotFound) {
+		return fmt.Errorf("fetching stacked-on MR %s: %w", mr.StackedOn, err)
+	}
+	if parent != nil {
+		if parent.Status != "closed" {
+			if err := e.followParent(mr, parent); err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: %s has not merged", ErrStackPending, parent.ID)
+		}
+		if !mrMerged(parent) {
+			if pending, err := e.followStack(mr, parent); pending || err != nil {
+				return err
+			}
+		}
+	}
+
+	if mr.StackBase != "" {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Restacking %s onto %s (after %s)...\n", mr.Branch, mr.Target, mr.StackedOn)
+		if err := e.git.FetchBranch("origin", mr.Target); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: fetch origin/%s: %v (continuing)\n", mr.Target, err)
+		}
+		if err := e.replayStack(mr, "origin/"+mr.Target); err != nil {
+			return err
+		}
+	}
+
+	if err := e.updateStack(mr.ID, "", "", ""); err != nil {
+		return err
+	}
+	mr.StackedOn, mr.StackBase, mr.StackParentHead = "", "", ""
+	return nil
+}
+
+// replayStack rebases the MR's own commits (stack_base..branch) onto the
+// given ref and force-pushes the branch.
+func (e *Engineer) replayStack(mr *MRInfo, onto string) error {
+	err := e.git.RebaseOnto(onto, mr.StackBase, mr.Branch)
+	if err != nil {
+		_ = e.git.AbortRebase()
+	}
+	_ = e.git.Checkout(mr.Target)
+	if err != nil {
+		return fmt.Errorf("%w: %s onto %s: %v", ErrRestackConflict, mr.Branch, onto, err)
+	}
+	if err := e.git.Push("origin", mr.Branch, true); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to push restacked %s: %v\n", mr.Branch, err)
+	}
+	return nil
+}
+
+// followParent keeps an MR on top of its open parent and records the move.
+func (e *Engineer) followParent(mr *MRInfo, parent *beads.Issue) error {
+	fields := beads.ParseMRFields(parent)
+	if fields == nil || fields.Branch == "" {
+		return nil
+	}
+	moved, err := e.stackOnParent(mr, fields.Branch)
+	if err != nil || !moved {
+		return err
+	}
+	return e.updateStack(mr.ID, mr.StackedOn, mr.StackBase, mr.StackParentHead)
+}
+
+// stackOnParent replays the MR's own commits onto the parent branch's
+// current head if it differs from the head the MR was stacked on
+// (stack_parent_head, or stack_base for MRs that predate it). On a move it
+// updates mr's stack base and parent head and returns true.
+func (e *Engineer) stackOnParent(mr *MRInfo, parentBranch string) (bool, error) {
+	if mr.StackBase == "" {
+		return false, nil
+	}
+	if err := e.git.FetchBranch("origin", parentBranch); err != nil {
+		return false, fmt.Errorf("fetching stacked-on branch %s: %w", parentBranch, err)
+	}
+	head, err := e.git.Rev("origin/" + parentBranch)
+	if err != nil {
+		return false, fmt.Errorf("resolving stacked-on branch %s: %w", parentBranch, err)
+	}
+	known := mr.StackParentHead
+	if known == "" {
+		known = mr.StackBase
+	}
+	if head == known {
+		return false, nil
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] %s moved; restacking %s onto it...\n", parentBranch, mr.Branch)
+	if err := e.replayStack(mr, "origin/"+parentBranch); err != nil {
+		return false, err
+	}
+	mr.StackBase, mr.StackParentHead = head, head
+	return true, nil
+}
+
+// followStack handles a parent closed without merging. Returns true (with
+// an ErrStackPending error) if the MR should keep waiting.
+func (e *Engineer) followStack(mr *MRInfo, parent *beads.Issue) (bool, error) {
+	fields := beads.ParseMRFields(parent)
+	if fields == nil || fields.SourceIssue == "" {
+		return false, nil
+	}
+
+	replacement, err := e.beads.FindMRForIssue(fields.SourceIssue)
+	if err != nil {
+		return true, fmt.Errorf("finding replacement for %s: %w", parent.ID, err)
+	}
+	if replacement != nil && replacement.ID != mr.ID {
+		// The replacement's branch differs from the one recorded, so the
+		// next patrol restacks onto it
+		if err := e.updateStack(mr.ID, replacement.ID, mr.StackBase, ""); err != nil {
+			return true, err
+		}
+		if err := e.beads.AddDependency(mr.ID, replacement.ID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to block %s on %s: %v\n", mr.ID, replacement.ID, err)
+		}
+		mr.StackedOn, mr.StackParentHead = replacement.ID, ""
+		return true, fmt.Errorf("%w: %s was replaced by %s", ErrStackPending, parent.ID, replacement.ID)
+	}
+
+	if source, err := e.beads.Show(fields.SourceIssue); err == nil && source.Status != "closed" {
+		return true, fmt.Errorf("%w: %s closed without merging; %s is being reworked", ErrStackPending, parent.ID, fields.SourceIssue)
+	}
+
+	// The parent's work was abandoned: this MR stands on its own
+	return false, nil
+}
+
+// updateStack rewrites an MR's stack fields.
+func (e *Engineer) updateStack(mrID, stackedOn, stackBase, parentHead string) error {
+	issue, err := e.beads.Show(mrID)
+	if err != nil {
+		return fmt.Errorf("fetching MR %s: %w", mrID, err)
+	}
+	fields := beads.ParseMRFields(issue)
+	if fields == nil {
+		fields = &beads.MRFields{}
+	}
+	fields.StackedOn = stackedOn
+	fields.StackBase = stackBase
+	fields.StackParentHead = parentHead
+	desc := beads.SetMRFields(issue, fields)
+	if err := e.beads.Update(mrID, beads.UpdateOptions{Description: &desc}); err != nil {
+		return fmt.Errorf("updating MR %s: %w", mrID, err)
+	}
+	return nil
+}
+
+// checkStack restacks an MR before merging. ok is false if the MR must not
+// be merged yet; result says why.
+func (e *Engineer) checkStack(mr *MRInfo) (result ProcessResult, ok bool) {
+	err := e.Restack(mr)
+	switch {
+	case err == nil:
+		return ProcessResult{}, true
+	case errors.Is(err, ErrStackPending):
+		return ProcessResult{StackPending: true, Error: err.Error()}, false
+	default:
+		return ProcessResult{Conflict: errors.Is(err, ErrRestackConflict), Error: fmt.Sprintf("restacking: %v", err)}, false
+	}
+}
+
+// restackChildren moves MRs stacked on a just-merged MR onto its target so
+// they are ready when they reach the front of the queue. Failures are left
+// for processing time, when the MR is restacked again.
+func (e *Engineer) restackChildren(parentID string) {
+	children, err := e.StackedMRs(parentID)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to list MRs stacked on %s: %v\n", parentID, err)
+		return
+	}
+	for _, child := range children {
+		if err := e.Restack(child); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: could not restack %s: %v\n", child.ID, err)
+			continue
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Restacked %s onto %s\n", child.ID, child.Target)
+	}
+}
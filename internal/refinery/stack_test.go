@@ -0,0 +1,144 @@
+package refinery
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/git"
+)
+
+func TestMRMerged(t *testing.T) {
+	tests := []struct {
+		name string
+		mr   *beads.Issue
+		want bool
+	}{
+		{"engineer merge", &beads.Issue{Description: "branch: b\nmerge_commit: abc123\nclose_reason: merged"}, true},
+		{"patrol merge", &beads.Issue{Description: "branch: b", CloseReason: "Merged to main at abc123"}, true},
+		{"branch gone", &beads.Issue{Description: "branch: b", CloseReason: "Branch no longer exists"}, true},
+		{"rejected", &beads.Issue{Description: "branch: b", CloseReason: "rejected: changes requested in gt-rev1: nil check"}, false},
+		{"superseded", &beads.Issue{Description: "branch: b", CloseReason: "superseded"}, false},
+		{"close_reason field", &beads.Issue{Description: "branch: b\nclose_reason: conflict", CloseReason: "gave up"}, false},
+	}
+	for _, tt := range tests {
+		if got := mrMerged(tt.mr); got != tt.want {
+			t.Errorf("%s: mrMerged() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// newTestClone creates a bare origin with a main branch and clones it to
+// work, returning a helper that runs git in the clone and returns its output.
+func newTestClone(t *testing.T, work string) func(args ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin.git")
+
+	run := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run(root, "init", "--bare", "-b", "main", origin)
+	run(root, "clone", origin, work)
+	run(work, "config", "user.email", "test@test.com")
+	run(work, "config", "user.name", "Test User")
+	run(work, "checkout", "-b", "main")
+	run(work, "commit", "--allow-empty", "-m", "initial")
+	run(work, "push", "origin", "main")
+
+	return func(args ...string) string {
+		t.Helper()
+		return run(work, args...)
+	}
+}
+
+// commitFile writes name with content in dir and commits it.
+func commitFile(t *testing.T, run func(...string) string, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	run("add", name)
+	run("commit", "-m", "add "+name)
+}
+
+func TestStackOnParentUpdatedBeforeMerge(t *testing.T) {
+	work := filepath.Join(t.TempDir(), "work")
+	run := newTestClone(t, work)
+
+	// B is stacked on A's pushed branch
+	run("checkout", "-b", "a", "main")
+	commitFile(t, run, work, "a.txt", "a\n")
+	run("push", "origin", "a")
+	base := run("rev-parse", "HEAD")
+	run("checkout", "-b", "b", "a")
+	commitFile(t, run, work, "b.txt", "b\n")
+	run("push", "origin", "b")
+
+	// A is reworked before it merges: its commit is rewritten and another added
+	run("checkout", "a")
+	if err := os.WriteFile(filepath.Join(work, "a.txt"), []byte("a reworked\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	run("commit", "-a", "--amend", "-m", "add a.txt (reworked)")
+	commitFile(t, run, work, "a2.txt", "a2\n")
+	run("push", "--force", "origin", "a")
+	run("checkout", "main")
+
+	e := &Engineer{git: git.NewGit(work), output: io.Discard}
+	mr := &MRInfo{ID: "gt-b", Branch: "b", Target: "main", StackedOn: "gt-a", StackBase: base, StackParentHead: base}
+
+	moved, err := e.stackOnParent(mr, "a")
+	if err != nil {
+		t.Fatalf("stackOnParent: %v", err)
+	}
+	if !moved {
+		t.Fatal("stackOnParent did not notice A moved")
+	}
+	head := run("rev-parse", "origin/a")
+	if mr.StackBase != head || mr.StackParentHead != head {
+		t.Errorf("stack base/parent head = %s/%s, want %s", mr.StackBase, mr.StackParentHead, head)
+	}
+	run("fetch", "origin")
+	if got := run("rev-parse", "origin/b~1"); got != head {
+		t.Errorf("B sits on %s, want A's new head %s", got, head)
+	}
+	if got := run("rev-list", "--count", "origin/a..origin/b"); got != "1" {
+		t.Errorf("B has %s commits of its own on A, want 1", got)
+	}
+	if got := run("show", "origin/b:a.txt"); got != "a reworked" {
+		t.Errorf("B's a.txt = %q, want A's reworked version", got)
+	}
+
+	// Nothing to do until A moves again
+	if moved, err := e.stackOnParent(mr, "a"); err != nil || moved {
+		t.Errorf("stackOnParent again = %v, %v; want false, nil", moved, err)
+	}
+
+	// A squash-merges; only B's own commit is replayed onto main
+	run("merge", "--squash", "origin/a")
+	run("commit", "-m", "Merge a")
+	run("push", "origin", "main")
+	run("fetch", "origin")
+	if err := e.replayStack(mr, "origin/main"); err != nil {
+		t.Fatalf("replayStack: %v", err)
+	}
+	run("fetch", "origin")
+	if got := run("rev-list", "--count", "origin/main..origin/b"); got != "1" {
+		t.Errorf("B has %s commits on main, want 1", got)
+	}
+	if got := run("show", "origin/b:b.txt"); got != "b" {
+		t.Errorf("B's b.txt = %q, want b", got)
+	}
+}
@@ -150,6 +150,8 @@ type QueueItem struct {
 	Position  int       `json:"position"`
 	MR        *MergeRequest `json:"mr"`
 	Age       string    `json:"age"`
+	Priority  int       `json:"priority"`
+	Score     float64   `json:"score,omitempty"`
 }
 
 // State transition errors.
@@ -282,6 +284,13 @@ const (
 
 	// FailureCheckout indicates checkout of target branch failed.
 	FailureCheckout FailureType = "checkout_fail"
+
+	// FailurePreMergeCheck indicates a pre-merge check (leaked secret,
+	// oversized file, rig-defined check command) rejected the branch.
+	FailurePreMergeCheck FailureType = "premerge_check"
+
+	// FailureChangesRequested indicates a reviewer requested changes.
+	FailureChangesRequested FailureType = "changes_requested"
 )
 
 // FailureLabel returns the beads label for this failure type.
@@ -289,7 +298,7 @@ func (f FailureType) FailureLabel() string {
 	switch f {
 	case FailureConflict:
 		return "needs-rebase"
-	case FailureTestsFail, FailureBuildFail, FailureFlakyTest:
+	case FailureTestsFail, FailureBuildFail, FailureFlakyTest, FailurePreMergeCheck, FailureChangesRequested:
 		return "needs-fix"
 	case FailurePushFail:
 		return "needs-retry"
@@ -301,7 +310,7 @@ func (f FailureType) FailureLabel() string {
 // ShouldAssignToWorker returns true if this failure should be assigned back to the worker.
 func (f FailureType) ShouldAssignToWorker() bool {
 	switch f {
-	case FailureConflict, FailureTestsFail, FailureBuildFail, FailureFlakyTest:
+	case FailureConflict, FailureTestsFail, FailureBuildFail, FailureFlakyTest, FailurePreMergeCheck, FailureChangesRequested:
 		return true
 	default:
 		return false
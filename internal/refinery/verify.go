@@ -0,0 +1,442 @@
+package refinery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/events"
+	"github.com/steveyegge/gastown/internal/protocol"
+	"github.com/steveyegge/gastown/internal/util"
+)
+
+// maxVerifyHistory caps how far back the first-parent history is scanned
+// when matching MR merge commits.
+const maxVerifyHistory = 500
+
+// VerifyState records post-merge verification across runs.
+type VerifyState struct {
+	// Target is the branch being verified.
+	Target string `json:"target"`
+
+	// LastChecked is the target head that was last tested.
+	LastChecked string `json:"last_checked,omitempty"`
+
+	// LastGreen is the most recent target head whose tests passed.
+	LastGreen string `json:"last_green,omitempty"`
+
+	// CheckedAt is when the last verification ran.
+	CheckedAt time.Time `json:"checked_at"`
+
+	// Broken is true while the target head fails its tests.
+	Broken bool `json:"broken,omitempty"`
+
+	// CulpritMR and BugID identify the MR blamed for the breakage and the
+	// bug bead opened for it.
+	CulpritMR string `json:"culprit_mr,omitempty"`
+	BugID     string `json:"bug_id,omitempty"`
+}
+
+// VerifyResult describes the outcome of one post-merge verification.
+type VerifyResult struct {
+	Target  string `json:"target"`
+	Head    string `json:"head"`
+	Skipped string `json:"skipped,omitempty"` // reason verification did not run
+	Green   bool   `json:"green"`
+
+	// Set when the head is red.
+	Culprit   *MRInfo `json:"culprit,omitempty"`
+	Bisected  int     `json:"bisected,omitempty"` // merges considered
+	TestRuns  int     `json:"test_runs,omitempty"`
+	BugID     string  `json:"bug_id,omitempty"`
+	Unmatched string  `json:"unmatched,omitempty"` // why no culprit was found
+}
+
+// VerifyStateFile returns the path of the post-merge verification state for a rig.
+func VerifyStateFile(rigPath string) string {
+	return filepath.Join(rigPath, ".runtime", "refinery-verify.json")
+}
+
+// LoadVerifyState reads the verification state for a rig.
+// Returns an empty state if verification has never run.
+func LoadVerifyState(rigPath string) (*VerifyState, error) {
+	data, err := os.ReadFile(VerifyStateFile(rigPath))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &VerifyState{}, nil
+		}
+		return nil, err
+	}
+
+	var s VerifyState
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("parsing verify state: %w", err)
+	}
+	return &s, nil
+}
+
+func saveVerifyState(rigPath string, s *VerifyState) error {
+	path := VerifyStateFile(rigPath)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return util.AtomicWriteJSON(path, s)
+}
+
+// VerifyTarget runs post-merge verification: it tests the head of the target
+// branch and, if the tests fail, bisects across the MR merge commits since
+// the last green head to find the MR that broke it. The culprit gets a bug
+// bead linked to its MR and its worker is notified through the Witness.
+//
+// Unless force is set, verification is skipped when it is disabled, when the
+// last run was less than VerifyInterval ago, or when the head has not moved.
+func (e *Engineer) VerifyTarget(ctx context.Context, force bool) (*VerifyResult, error) {
+	target := e.config.TargetBranch
+	result := &VerifyResult{Target: target}
+
+	if e.config.TestCommand == "" {
+		result.Skipped = "no test_command configured"
+		return result, nil
+	}
+	if !force && !e.config.VerifyAfterMerge {
+		result.Skipped = "verify_after_merge is disabled"
+		return result, nil
+	}
+
+	state, err := LoadVerifyState(e.rig.Path)
+	if err != nil {
+		return nil, err
+	}
+	if state.Target != target {
+		// Target changed since the last run; history no longer applies
+		state = &VerifyState{Target: target}
+	}
+	if !force && time.Since(state.CheckedAt) < e.config.VerifyInterval {
+		result.Skipped = fmt.Sprintf("last verified %s ago", time.Since(state.CheckedAt).Round(time.Second))
+		return result, nil
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Verifying %s...\n", target)
+	if err := e.git.Checkout(target); err != nil {
+		return nil, fmt.Errorf("checking out %s: %w", target, err)
+	}
+	if err := e.git.Pull("origin", target); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: pull from origin/%s: %v (continuing)\n", target, err)
+	}
+	head, err := e.git.Rev("HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s head: %w", target, err)
+	}
+	result.Head = head
+
+	if !force && head == state.LastChecked {
+		state.CheckedAt = time.Now()
+		_ = saveVerifyState(e.rig.Path, state)
+		result.Skipped = "target head unchanged since last verification"
+		result.Green = !state.Broken
+		return result, nil
+	}
+
+	state.LastChecked = head
+	state.CheckedAt = time.Now()
+
+	tests := e.runTests(ctx)
+	result.TestRuns++
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if tests.Success {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] ✓ %s is green at %s\n", target, shortSHA(head))
+		state.LastGreen = head
+		state.Broken = false
+		state.CulpritMR = ""
+		state.BugID = ""
+		result.Green = true
+		return result, saveVerifyState(e.rig.Path, state)
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] ✗ %s is red at %s: %s\n", target, shortSHA(head), tests.Error)
+
+	// Already reported and still being worked on: don't bisect or file again
+	if state.Broken && state.BugID != "" {
+		if open, _ := e.IsBeadOpen(state.BugID); open {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Breakage already tracked in %s\n", state.BugID)
+			result.BugID = state.BugID
+			return result, saveVerifyState(e.rig.Path, state)
+		}
+	}
+	state.Broken = true
+
+	culprit, err := e.bisectCulprit(ctx, head, state.LastGreen, result)
+	if restoreErr := e.git.Checkout(target); restoreErr != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to return to %s: %v\n", target, restoreErr)
+	}
+	if err != nil {
+		_ = saveVerifyState(e.rig.Path, state)
+		return nil, err
+	}
+
+	if culprit == nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] No culprit MR found: %s\n", result.Unmatched)
+	} else {
+		result.Culprit = culprit
+		state.CulpritMR = culprit.ID
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Culprit: %s (%s by %s)\n", culprit.ID, culprit.Branch, culprit.Worker)
+	}
+
+	bugID, err := e.fileBreakageBug(head, tests.Error, culprit, result)
+	if err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to create bug bead: %v\n", err)
+	} else {
+		result.BugID = bugID
+		state.BugID = bugID
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Filed %s\n", bugID)
+	}
+	if culprit != nil {
+		e.notifyCulprit(culprit, bugID, tests.Error)
+	}
+
+	return result, saveVerifyState(e.rig.Path, state)
+}
+
+// bisectCulprit finds the first MR merge commit on the target's first-parent
+// history whose tests fail. Only merges after lastGreen (or the most recent
+// BisectLookback merges) are considered. Returns nil if the breakage cannot
+// be attributed to an MR.
+func (e *Engineer) bisectCulprit(ctx context.Context, head, lastGreen string, result *VerifyResult) (*MRInfo, error) {
+	history, err := e.git.FirstParentHistory(head, lastGreen, maxVerifyHistory)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s history: %w", result.Target, err)
+	}
+
+	merged, err := e.listMergedMRs(result.Target)
+	if err != nil {
+		return nil, err
+	}
+	candidates := orderMergedMRs(history, merged, e.config.BisectLookback)
+	result.Bisected = len(candidates)
+	if len(candidates) == 0 {
+		result.Unmatched = "no MR merges since the last green head"
+		return nil, nil
+	}
+
+	_, _ = fmt.Fprintf(e.output, "[Engineer] Bisecting %d merge(s)...\n", len(candidates))
+	fails := func(ref string) (bool, error) {
+		if err := e.git.Checkout(ref); err != nil {
+			return false, fmt.Errorf("checking out %s: %w", ref, err)
+		}
+		result.TestRuns++
+		r := e.runTests(ctx)
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		_, _ = fmt.Fprintf(e.output, "[Engineer]   %s: %s\n", shortSHA(ref), passFail(r.Success))
+		return !r.Success, nil
+	}
+
+	idx, err := bisectFirstFailing(len(candidates), func(i int) (bool, error) {
+		return fails(candidates[i].commit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if idx == len(candidates) {
+		result.Unmatched = "all MR merges pass; breakage came from a direct commit after the last merge"
+		return nil, nil
+	}
+
+	// Unless the culprit sits directly on the last green head, make sure its
+	// parent passes; otherwise the breakage predates the bisect window.
+	if idx == 0 {
+		parent, _ := e.git.Rev(candidates[0].commit + "^1")
+		if lastGreen == "" || parent != lastGreen {
+			failing, err := fails(candidates[0].commit + "^1")
+			if err != nil {
+				return nil, err
+			}
+			if failing {
+				result.Unmatched = fmt.Sprintf("breakage predates the last %d merges", len(candidates))
+				return nil, nil
+			}
+		}
+	}
+	return candidates[idx].mr, nil
+}
+
+// listMergedMRs returns closed MRs into target that recorded a merge commit.
+func (e *Engineer) listMergedMRs(target string) ([]*MRInfo, error) {
+	issues, err := e.beads.List(beads.ListOptions{
+		Status:   "closed",
+		Label:    "gt:merge-request",
+		Priority: -1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying merged MRs: %w", err)
+	}
+
+	var mrs []*MRInfo
+	for _, issue := range issues {
+		fields := beads.ParseMRFields(issue)
+		if fields == nil || fields.MergeCommit == "" {
+			continue
+		}
+		if fields.Target != "" && fields.Target != target {
+			continue
+		}
+		mrs = append(mrs, &MRInfo{
+			ID:          issue.ID,
+			Branch:      fields.Branch,
+			Target:      fields.Target,
+			SourceIssue: fields.SourceIssue,
+			Worker:      fields.Worker,
+			Rig:         fields.Rig,
+			Title:       issue.Title,
+			Priority:    issue.Priority,
+			AgentBead:   fields.AgentBead,
+			MergeCommit: fields.MergeCommit,
+		})
+	}
+	return mrs, nil
+}
+
+// mergeCandidate is an MR whose merge commit lies on the target history.
+type mergeCandidate struct {
+	mr     *MRInfo
+	commit string
+	pos    int // index in first-parent history (0 = newest)
+}
+
+// orderMergedMRs matches MR merge commits against the first-parent history
+// (newest first) and returns them oldest first, keeping at most the newest
+// lookback merges.
+func orderMergedMRs(history []string, mrs []*MRInfo, lookback int) []mergeCandidate {
+	var candidates []mergeCandidate
+	for _, mr := range mrs {
+		if mr.MergeCommit == "" {
+			continue
+		}
+		for pos, sha := range history {
+			if strings.HasPrefix(sha, mr.MergeCommit) || strings.HasPrefix(mr.MergeCommit, sha) {
+				candidates = append(candidates, mergeCandidate{mr: mr, commit: sha, pos: pos})
+				break
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].pos > candidates[j].pos
+	})
+	if lookback > 0 && len(candidates) > lookback {
+		candidates = candidates[len(candidates)-lookback:]
+	}
+	return candidates
+}
+
+// bisectFirstFailing returns the smallest index in [0, n) for which fails
+// reports true, assuming failures are monotonic (once broken, stays broken).
+// Returns n if no index fails.
+func bisectFirstFailing(n int, fails func(i int) (bool, error)) (int, error) {
+	lo, hi := 0, n
+	for lo < hi {
+		mid := (lo + hi) / 2
+		failing, err := fails(mid)
+		if err != nil {
+			return 0, err
+		}
+		if failing {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// fileBreakageBug opens a bug bead for a red target and links it to the
+// culprit MR (if one was found).
+func (e *Engineer) fileBreakageBug(head, testErr string, culprit *MRInfo, result *VerifyResult) (string, error) {
+	title := fmt.Sprintf("%s broken at %s", result.Target, shortSHA(head))
+	if culprit != nil {
+		what := culprit.Title
+		if what == "" {
+			what = culprit.Branch
+		}
+		title = fmt.Sprintf("%s broken by %s: %s", result.Target, culprit.ID, what)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Post-merge verification failed on %s.\n\n", result.Target))
+	sb.WriteString("## Metadata\n")
+	sb.WriteString(fmt.Sprintf("- Target: %s@%s\n", result.Target, head))
+	if culprit != nil {
+		sb.WriteString(fmt.Sprintf("- Culprit MR: %s\n", culprit.ID))
+		sb.WriteString(fmt.Sprintf("- Merge commit: %s\n", culprit.MergeCommit))
+		sb.WriteString(fmt.Sprintf("- Branch: %s\n", culprit.Branch))
+		sb.WriteString(fmt.Sprintf("- Worker: %s\n", culprit.Worker))
+		sb.WriteString(fmt.Sprintf("- Original issue: %s\n", culprit.SourceIssue))
+	} else {
+		sb.WriteString(fmt.Sprintf("- Culprit: unknown (%s)\n", result.Unmatched))
+	}
+	sb.WriteString(fmt.Sprintf("- Merges bisected: %d\n", result.Bisected))
+	sb.WriteString(fmt.Sprintf("- Test command: %s\n", e.config.TestCommand))
+	sb.WriteString(fmt.Sprintf("- Error: %s\n", testErr))
+	sb.WriteString("\nFix forward, or revert the culprit merge if the fix is not obvious.")
+
+	bug, err := e.beads.Create(beads.CreateOptions{
+		Title:       title,
+		Type:        "bug",
+		Priority:    1,
+		Description: sb.String(),
+		Actor:       e.rig.Name + "/refinery",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The MR is closed, so the dependency links the two without blocking the bug
+	if culprit != nil {
+		if err := e.beads.AddDependency(bug.ID, culprit.ID); err != nil {
+			_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to link %s to %s: %v\n", bug.ID, culprit.ID, err)
+		}
+	}
+	return bug.ID, nil
+}
+
+// notifyCulprit tells the culprit MR's worker (via its Witness) that their
+// merge broke the target, and records the failure on the feed.
+func (e *Engineer) notifyCulprit(culprit *MRInfo, bugID, testErr string) {
+	errMsg := fmt.Sprintf("merge %s broke %s after merging: %s", shortSHA(culprit.MergeCommit), e.config.TargetBranch, testErr)
+	if bugID != "" {
+		errMsg += fmt.Sprintf(" (tracked in %s)", bugID)
+	}
+
+	msg := protocol.NewMergeFailedMessage(e.rig.Name, culprit.Worker, culprit.Branch, culprit.SourceIssue, e.config.TargetBranch, "post-merge", errMsg)
+	if err := e.router.Send(msg); err != nil {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Warning: failed to send MERGE_FAILED to witness: %v\n", err)
+	} else {
+		_, _ = fmt.Fprintf(e.output, "[Engineer] Notified witness of post-merge failure for %s\n", culprit.Worker)
+	}
+
+	actor := e.rig.Name + "/refinery"
+	_ = events.LogFeed(events.TypeMergeFailed, actor, events.MergePayload(culprit.ID, culprit.Worker, culprit.Branch, "post-merge verification failed"))
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+func passFail(ok bool) string {
+	if ok {
+		return "pass"
+	}
+	return "FAIL"
+}
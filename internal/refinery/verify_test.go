@@ -0,0 +1,79 @@
+package refinery
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBisectFirstFailing(t *testing.T) {
+	tests := []struct {
+		name      string
+		n         int
+		firstBad  int
+		wantIndex int
+	}{
+		{"first is bad", 8, 0, 0},
+		{"middle is bad", 8, 5, 5},
+		{"last is bad", 8, 7, 7},
+		{"none bad", 8, 8, 8},
+		{"single bad", 1, 0, 0},
+		{"empty", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			got, err := bisectFirstFailing(tt.n, func(i int) (bool, error) {
+				calls++
+				return i >= tt.firstBad, nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.wantIndex {
+				t.Errorf("got %d, want %d", got, tt.wantIndex)
+			}
+			if calls > 4 {
+				t.Errorf("%d test runs for %d candidates, want logarithmic", calls, tt.n)
+			}
+		})
+	}
+}
+
+func TestBisectFirstFailingError(t *testing.T) {
+	boom := errors.New("checkout failed")
+	_, err := bisectFirstFailing(4, func(int) (bool, error) { return false, boom })
+	if !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+}
+
+func TestOrderMergedMRs(t *testing.T) {
+	// First-parent history, newest first
+	history := []string{"eeee1111", "dddd1111", "cccc1111", "bbbb1111", "aaaa1111"}
+	mrs := []*MRInfo{
+		{ID: "mr-d", MergeCommit: "dddd1111"},
+		{ID: "mr-b", MergeCommit: "bbbb"},     // abbreviated SHA
+		{ID: "mr-x", MergeCommit: "ffff1111"}, // not on this history
+		{ID: "mr-a", MergeCommit: "aaaa1111"},
+	}
+
+	got := orderMergedMRs(history, mrs, 0)
+	want := []string{"mr-a", "mr-b", "mr-d"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d candidates, want %d", len(got), len(want))
+	}
+	for i, id := range want {
+		if got[i].mr.ID != id {
+			t.Errorf("candidate %d = %s, want %s", i, got[i].mr.ID, id)
+		}
+	}
+	if got[1].commit != "bbbb1111" {
+		t.Errorf("commit = %s, want full SHA from history", got[1].commit)
+	}
+
+	// Lookback keeps the newest merges
+	got = orderMergedMRs(history, mrs, 2)
+	if len(got) != 2 || got[0].mr.ID != "mr-b" || got[1].mr.ID != "mr-d" {
+		t.Errorf("lookback 2: got %v, want [mr-b mr-d]", got)
+	}
+}
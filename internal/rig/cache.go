@@ -0,0 +1,212 @@
+package rig
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+// DefaultCacheDir is the cache root, relative to the rig, when none is configured.
+const DefaultCacheDir = ".cache"
+
+// CacheKinds maps each built-in cache kind to the environment variables it
+// sets and the subdirectory of the cache root each one points at. Kinds are
+// opt-in per rig: some (cargo's CARGO_HOME) also move the tool's config,
+// credentials and installed binaries.
+var CacheKinds = map[string]map[string]string{
+	"go":    {"GOMODCACHE": "go/mod", "GOCACHE": "go/build"},
+	"npm":   {"npm_config_cache": "npm"},
+	"yarn":  {"YARN_CACHE_FOLDER": "yarn"},
+	"pnpm":  {"npm_config_store_dir": "pnpm"},
+	"cargo": {"CARGO_HOME": "cargo"},
+	"pip":   {"PIP_CACHE_DIR": "pip"},
+}
+
+// Cache is one shared cache directory under the rig's cache root.
+type Cache struct {
+	Name     string    `json:"name"` // Subdirectory of the root (e.g. "go/mod")
+	Path     string    `json:"path"`
+	Env      []string  `json:"env"` // Variables pointing at this directory
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used,omitempty"` // Newest modification inside
+}
+
+// LoadCacheConfig returns the rig's cache settings, or nil if caching is off.
+func LoadCacheConfig(rigPath string) *config.CacheConfig {
+	settings, err := config.LoadRigSettings(config.RigSettingsPath(rigPath))
+	if err != nil || settings.Cache == nil || !settings.Cache.Enabled {
+		return nil
+	}
+	return settings.Cache
+}
+
+// CacheRoot returns the absolute cache root for a rig.
+func CacheRoot(rigPath string, cfg *config.CacheConfig) string {
+	dir := DefaultCacheDir
+	if cfg != nil && cfg.Dir != "" {
+		dir = cfg.Dir
+	}
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir)
+	}
+	return filepath.Join(rigPath, dir)
+}
+
+// Caches returns the caches a config declares, one per directory. Sizes
+// are not measured; see MeasureCaches.
+func Caches(rigPath string, cfg *config.CacheConfig) ([]*Cache, error) {
+	env := make(map[string]string)
+
+	for _, kind := range cfg.Kinds {
+		vars, ok := CacheKinds[kind]
+		if !ok {
+			return nil, fmt.Errorf("unknown cache kind %q", kind)
+		}
+		for k, v := range vars {
+			env[k] = v
+		}
+	}
+	for k, v := range cfg.Env {
+		sub := filepath.Clean(filepath.FromSlash(v))
+		if sub == "." || filepath.IsAbs(sub) || sub == ".." || strings.HasPrefix(sub, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("cache env %s: %q must be a subdirectory of the cache root", k, v)
+		}
+		env[k] = filepath.ToSlash(sub)
+	}
+
+	root := CacheRoot(rigPath, cfg)
+	byName := make(map[string]*Cache)
+	for k, sub := range env {
+		c, ok := byName[sub]
+		if !ok {
+			c = &Cache{Name: sub, Path: filepath.Join(root, filepath.FromSlash(sub))}
+			byName[sub] = c
+		}
+		c.Env = append(c.Env, k)
+	}
+
+	caches := make([]*Cache, 0, len(byName))
+	for _, c := range byName {
+		sort.Strings(c.Env)
+		caches = append(caches, c)
+	}
+	sort.Slice(caches, func(i, j int) bool { return caches[i].Name < caches[j].Name })
+	return caches, nil
+}
+
+// CacheEnv returns the environment variables that point a session at the
+// rig's shared caches, creating the cache directories. Returns nil if the
+// rig has no cache configured.
+func CacheEnv(rigPath string) (map[string]string, error) {
+	cfg := LoadCacheConfig(rigPath)
+	if cfg == nil {
+		return nil, nil
+	}
+	caches, err := Caches(rigPath, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	env := make(map[string]string)
+	for _, c := range caches {
+		if err := os.MkdirAll(c.Path, 0755); err != nil {
+			return nil, fmt.Errorf("creating cache %s: %w", c.Name, err)
+		}
+		for _, k := range c.Env {
+			env[k] = c.Path
+		}
+	}
+	return env, nil
+}
+
+// MeasureCaches fills in Size and LastUsed for each cache. Missing
+// directories count as empty.
+func MeasureCaches(caches []*Cache) error {
+	for _, c := range caches {
+		c.Size = 0
+		c.LastUsed = time.Time{}
+		err := filepath.WalkDir(c.Path, func(_ string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil // Removed while walking
+			}
+			if !d.IsDir() {
+				c.Size += info.Size()
+			}
+			if info.ModTime().After(c.LastUsed) {
+				c.LastUsed = info.ModTime()
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("measuring cache %s: %w", c.Name, err)
+		}
+	}
+	return nil
+}
+
+// TotalCacheSize sums the measured size of caches.
+func TotalCacheSize(caches []*Cache) int64 {
+	var total int64
+	for _, c := range caches {
+		total += c.Size
+	}
+	return total
+}
+
+// CachesToPrune picks which measured caches to clear so the total fits in
+// maxSize, least recently used first. maxSize <= 0 selects every cache.
+func CachesToPrune(caches []*Cache, maxSize int64) []*Cache {
+	byAge := make([]*Cache, 0, len(caches))
+	for _, c := range caches {
+		if c.Size > 0 {
+			byAge = append(byAge, c)
+		}
+	}
+	sort.SliceStable(byAge, func(i, j int) bool { return byAge[i].LastUsed.Before(byAge[j].LastUsed) })
+	if maxSize <= 0 {
+		return byAge
+	}
+
+	total := TotalCacheSize(caches)
+	var prune []*Cache
+	for _, c := range byAge {
+		if total <= maxSize {
+			break
+		}
+		prune = append(prune, c)
+		total -= c.Size
+	}
+	return prune
+}
+
+// ClearCache empties a cache directory. Go's module cache is read-only,
+// so write permission is restored before removal.
+func ClearCache(c *Cache) error {
+	_ = filepath.WalkDir(c.Path, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			_ = os.Chmod(p, 0755) //nolint:gosec // G302: cache dirs must be writable to delete
+		}
+		return nil
+	})
+	if err := os.RemoveAll(c.Path); err != nil {
+		return fmt.Errorf("clearing cache %s: %w", c.Name, err)
+	}
+	if err := os.MkdirAll(c.Path, 0755); err != nil {
+		return fmt.Errorf("recreating cache %s: %w", c.Name, err)
+	}
+	c.Size = 0
+	return nil
+}
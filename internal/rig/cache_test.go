@@ -0,0 +1,131 @@
+package rig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/config"
+)
+
+func TestCacheEnv(t *testing.T) {
+	rigPath := t.TempDir()
+
+	if env, err := CacheEnv(rigPath); err != nil || env != nil {
+		t.Fatalf("no settings: env=%v err=%v, want nil", env, err)
+	}
+
+	settings := config.NewRigSettings()
+	settings.Cache = &config.CacheConfig{
+		Enabled: true,
+		Kinds:   []string{"go"},
+		Env:     map[string]string{"GRADLE_USER_HOME": "gradle"},
+	}
+	if err := config.SaveRigSettings(config.RigSettingsPath(rigPath), settings); err != nil {
+		t.Fatal(err)
+	}
+
+	env, err := CacheEnv(rigPath)
+	if err != nil {
+		t.Fatalf("CacheEnv: %v", err)
+	}
+	root := filepath.Join(rigPath, DefaultCacheDir)
+	want := map[string]string{
+		"GOMODCACHE":       filepath.Join(root, "go", "mod"),
+		"GOCACHE":          filepath.Join(root, "go", "build"),
+		"GRADLE_USER_HOME": filepath.Join(root, "gradle"),
+	}
+	if len(env) != len(want) {
+		t.Fatalf("env = %v, want %v", env, want)
+	}
+	for k, v := range want {
+		if env[k] != v {
+			t.Errorf("env[%s] = %q, want %q", k, env[k], v)
+		}
+		if _, err := os.Stat(v); err != nil {
+			t.Errorf("cache dir %s not created: %v", v, err)
+		}
+	}
+}
+
+func TestCachesKindsOptIn(t *testing.T) {
+	caches, err := Caches("/rig", &config.CacheConfig{Enabled: true})
+	if err != nil {
+		t.Fatalf("Caches: %v", err)
+	}
+	if len(caches) != 0 {
+		t.Errorf("caches with no kinds = %v, want none", caches)
+	}
+}
+
+func TestCachesRejectsBadConfig(t *testing.T) {
+	if _, err := Caches("/rig", &config.CacheConfig{Kinds: []string{"maven"}}); err == nil {
+		t.Error("expected error for unknown kind")
+	}
+	if _, err := Caches("/rig", &config.CacheConfig{Kinds: []string{}, Env: map[string]string{"X": "../escape"}}); err == nil {
+		t.Error("expected error for env path outside the cache root")
+	}
+}
+
+func TestPruneCaches(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	mk := func(name string, size int, age time.Duration) *Cache {
+		dir := filepath.Join(root, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		file := filepath.Join(dir, "blob")
+		if err := os.WriteFile(file, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := now.Add(-age)
+		_ = os.Chtimes(file, old, old)
+		_ = os.Chtimes(dir, old, old)
+		return &Cache{Name: name, Path: dir}
+	}
+
+	caches := []*Cache{
+		mk("fresh", 100, time.Minute),
+		mk("stale", 100, 48*time.Hour),
+		mk("older", 100, 24*time.Hour),
+	}
+	// Go's module cache is read-only; clearing must still work.
+	if err := os.Chmod(caches[1].Path, 0555); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MeasureCaches(caches); err != nil {
+		t.Fatal(err)
+	}
+	if got := TotalCacheSize(caches); got != 300 {
+		t.Fatalf("total = %d, want 300", got)
+	}
+
+	prune := CachesToPrune(caches, 150)
+	if len(prune) != 2 || prune[0].Name != "stale" || prune[1].Name != "older" {
+		t.Fatalf("prune = %v, want stale then older", prune)
+	}
+	if all := CachesToPrune(caches, 0); len(all) != 3 {
+		t.Errorf("maxSize 0 should select every cache, got %d", len(all))
+	}
+	if none := CachesToPrune(caches, 1000); len(none) != 0 {
+		t.Errorf("under limit should select nothing, got %d", len(none))
+	}
+
+	for _, c := range prune {
+		if err := ClearCache(c); err != nil {
+			t.Fatalf("ClearCache(%s): %v", c.Name, err)
+		}
+	}
+	if err := MeasureCaches(caches); err != nil {
+		t.Fatal(err)
+	}
+	if caches[0].Size != 100 || caches[1].Size != 0 || caches[2].Size != 0 {
+		t.Errorf("sizes after prune = %d/%d/%d, want 100/0/0", caches[0].Size, caches[1].Size, caches[2].Size)
+	}
+	if _, err := os.Stat(caches[1].Path); err != nil {
+		t.Errorf("cleared cache dir should be recreated: %v", err)
+	}
+}
@@ -0,0 +1,427 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// gtBinary is the gt executable used for actions. Actions shell out to the
+// same CLI commands a user would type, so the TUI never diverges from them.
+var gtBinary = "gt"
+
+// ActionKind identifies an operation on the selected agent or event.
+type ActionKind int
+
+const (
+	ActionNudge ActionKind = iota
+	ActionPeek
+	ActionAttach
+	ActionOpenBead
+	ActionSling
+	ActionAck
+)
+
+// action is a resolved operation ready to run (after confirmation, if any).
+type action struct {
+	kind        ActionKind
+	title       string   // short description for status/confirm lines
+	args        []string // gt arguments
+	confirm     bool     // ask y/n before running
+	interactive bool     // hand the terminal to the command (attach)
+	showOutput  bool     // show captured output in the detail pane
+}
+
+// actionReadyMsg carries an action whose arguments had to be looked up
+// asynchronously (e.g. the top ready bead for sling).
+type actionReadyMsg struct {
+	action *action
+	err    error
+}
+
+// actionResultMsg reports the outcome of a completed action.
+type actionResultMsg struct {
+	action *action
+	output string
+	err    error
+}
+
+// agentAddress returns the address gt nudge/peek/sling accept for an agent.
+func agentAddress(a *Agent) string {
+	name := shortName(a.ID)
+	switch a.Role {
+	case "mayor", "deacon":
+		return a.Role
+	case "witness", "refinery":
+		if a.Rig != "" {
+			return a.Rig + "/" + a.Role
+		}
+	case "crew":
+		if a.Rig != "" {
+			return a.Rig + "/crew/" + name
+		}
+	case "polecat":
+		if a.Rig != "" {
+			return a.Rig + "/" + name
+		}
+	}
+	return a.ID
+}
+
+// agentIdentity returns the hook assignee identity for an agent.
+func agentIdentity(a *Agent) string {
+	switch a.Role {
+	case "mayor", "deacon":
+		return a.Role + "/"
+	case "polecat":
+		if a.Rig != "" {
+			return a.Rig + "/polecats/" + shortName(a.ID)
+		}
+	}
+	return agentAddress(a)
+}
+
+// attachArgs returns the gt command that attaches to an agent's session.
+func attachArgs(a *Agent) ([]string, error) {
+	switch a.Role {
+	case "mayor", "deacon":
+		return []string{a.Role, "attach"}, nil
+	case "witness", "refinery":
+		if a.Rig == "" {
+			return nil, fmt.Errorf("unknown rig for %s", a.ID)
+		}
+		return []string{a.Role, "attach", a.Rig}, nil
+	case "crew":
+		if a.Rig == "" {
+			return nil, fmt.Errorf("unknown rig for %s", a.ID)
+		}
+		return []string{"crew", "at", shortName(a.ID), "--rig", a.Rig}, nil
+	case "polecat":
+		if a.Rig == "" {
+			return nil, fmt.Errorf("unknown rig for %s", a.ID)
+		}
+		return []string{"session", "at", a.Rig + "/" + shortName(a.ID)}, nil
+	}
+	return nil, fmt.Errorf("don't know how to attach to %s", a.ID)
+}
+
+// isEscalationEvent reports whether an event refers to an open escalation.
+func isEscalationEvent(e *Event) bool {
+	return e.Type == "escalation_sent" && e.Target != ""
+}
+
+// isSlingEvent reports whether an event is a bead being created or updated
+// in a rig, the only events whose target may be work waiting to be slung.
+// Escalations, closes, merges and session events never are.
+func isSlingEvent(e *Event) bool {
+	return (e.Type == "create" || e.Type == "update") && e.Target != "" && e.Rig != ""
+}
+
+func shortName(id string) string {
+	parts := strings.Split(strings.TrimSuffix(id, "/"), "/")
+	return parts[len(parts)-1]
+}
+
+// buildAction resolves an action for the current selection. It returns a
+// tea.Cmd instead of an action when arguments must be looked up first.
+func buildAction(kind ActionKind, agent *Agent, event *Event, townRoot string) (*action, tea.Cmd, error) {
+	switch kind {
+	case ActionNudge:
+		if agent == nil {
+			return nil, nil, fmt.Errorf("select an agent to nudge")
+		}
+		addr := agentAddress(agent)
+		return &action{kind: kind, title: "nudge " + addr, args: []string{"nudge", addr}, confirm: true}, nil, nil
+
+	case ActionPeek:
+		if agent == nil {
+			return nil, nil, fmt.Errorf("select an agent to peek")
+		}
+		if agent.Role == "mayor" || agent.Role == "deacon" {
+			return nil, nil, fmt.Errorf("peek works on rig agents; use attach for %s", agent.Role)
+		}
+		addr := agentAddress(agent)
+		return &action{kind: kind, title: "peek " + addr, args: []string{"peek", addr, "60"}, showOutput: true}, nil, nil
+
+	case ActionAttach:
+		if agent == nil {
+			return nil, nil, fmt.Errorf("select an agent to attach")
+		}
+		args, err := attachArgs(agent)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &action{kind: kind, title: "attach to " + agentAddress(agent), args: args, confirm: true, interactive: true}, nil, nil
+
+	case ActionOpenBead:
+		if event != nil && event.Target != "" {
+			return &action{kind: kind, title: "show " + event.Target, args: []string{"show", event.Target}, showOutput: true}, nil, nil
+		}
+		if agent == nil {
+			return nil, nil, fmt.Errorf("select an agent or an event with a bead")
+		}
+		return nil, lookupHookedBead(agent, townRoot), nil
+
+	case ActionSling:
+		if event != nil && event.Target != "" {
+			if !isSlingEvent(event) {
+				return nil, nil, fmt.Errorf("only open, unassigned beads can be slung")
+			}
+			return nil, lookupOpenBead(event, townRoot), nil
+		}
+		if agent == nil || agent.Rig == "" {
+			return nil, nil, fmt.Errorf("select a rig agent or an event with a bead")
+		}
+		return nil, lookupReadyWork(agent, townRoot), nil
+
+	case ActionAck:
+		if event == nil || !isEscalationEvent(event) {
+			return nil, nil, fmt.Errorf("select an escalation event to acknowledge")
+		}
+		return &action{
+			kind:    kind,
+			title:   "acknowledge escalation " + event.Target,
+			args:    []string{"escalate", "ack", event.Target},
+			confirm: true,
+		}, nil, nil
+	}
+	return nil, nil, fmt.Errorf("unknown action")
+}
+
+// lookupHookedBead finds the bead on an agent's hook and shows it.
+func lookupHookedBead(agent *Agent, townRoot string) tea.Cmd {
+	identity := agentIdentity(agent)
+	return func() tea.Msg {
+		out, err := gtOutput(townRoot, "hook", "show", identity, "--json")
+		if err != nil {
+			return actionReadyMsg{err: fmt.Errorf("reading hook for %s: %w", identity, err)}
+		}
+		var info struct {
+			BeadID string `json:"bead_id"`
+		}
+		if err := json.Unmarshal([]byte(out), &info); err != nil {
+			return actionReadyMsg{err: fmt.Errorf("parsing hook for %s: %w", identity, err)}
+		}
+		if info.BeadID == "" {
+			return actionReadyMsg{err: fmt.Errorf("%s has nothing on its hook", identity)}
+		}
+		return actionReadyMsg{action: &action{
+			kind:       ActionOpenBead,
+			title:      "show " + info.BeadID,
+			args:       []string{"show", info.BeadID},
+			showOutput: true,
+		}}
+	}
+}
+
+// lookupOpenBead proposes slinging an event's bead to its rig, once the bead
+// is confirmed open and unassigned.
+func lookupOpenBead(event *Event, townRoot string) tea.Cmd {
+	id, rig := event.Target, event.Rig
+	return func() tea.Msg {
+		out, err := gtOutput(townRoot, "show", id, "--json")
+		if err != nil {
+			return actionReadyMsg{err: fmt.Errorf("reading %s: %w", id, err)}
+		}
+		var issues []struct {
+			Status   string `json:"status"`
+			Assignee string `json:"assignee"`
+		}
+		if err := json.Unmarshal([]byte(out), &issues); err != nil || len(issues) == 0 {
+			return actionReadyMsg{err: fmt.Errorf("parsing %s", id)}
+		}
+		if issue := issues[0]; issue.Status != "open" || issue.Assignee != "" {
+			return actionReadyMsg{err: fmt.Errorf("%s is not open and unassigned", id)}
+		}
+		return actionReadyMsg{action: &action{
+			kind:    ActionSling,
+			title:   fmt.Sprintf("sling %s to %s", id, rig),
+			args:    []string{"sling", id, rig},
+			confirm: true,
+		}}
+	}
+}
+
+// lookupReadyWork picks the highest-priority ready bead in the agent's rig
+// and proposes slinging it to the agent (or to the rig for non-workers).
+func lookupReadyWork(agent *Agent, townRoot string) tea.Cmd {
+	rig := agent.Rig
+	target := rig
+	if agent.Role == "polecat" || agent.Role == "crew" {
+		target = agentAddress(agent)
+	}
+	return func() tea.Msg {
+		out, err := gtOutput(townRoot, "ready", "--rig", rig, "--json")
+		if err != nil {
+			return actionReadyMsg{err: fmt.Errorf("listing ready work in %s: %w", rig, err)}
+		}
+		var result struct {
+			Sources []struct {
+				Issues []struct {
+					ID       string `json:"id"`
+					Title    string `json:"title"`
+					Priority int    `json:"priority"`
+				} `json:"issues"`
+			} `json:"sources"`
+		}
+		if err := json.Unmarshal([]byte(out), &result); err != nil {
+			return actionReadyMsg{err: fmt.Errorf("parsing ready work: %w", err)}
+		}
+
+		type candidate struct {
+			id, title string
+			priority  int
+		}
+		var candidates []candidate
+		for _, src := range result.Sources {
+			for _, issue := range src.Issues {
+				candidates = append(candidates, candidate{issue.ID, issue.Title, issue.Priority})
+			}
+		}
+		if len(candidates) == 0 {
+			return actionReadyMsg{err: fmt.Errorf("no ready work in %s", rig)}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].priority < candidates[j].priority
+		})
+
+		top := candidates[0]
+		return actionReadyMsg{action: &action{
+			kind:    ActionSling,
+			title:   fmt.Sprintf("sling %s (%s) to %s", top.id, top.title, target),
+			args:    []string{"sling", top.id, target},
+			confirm: true,
+		}}
+	}
+}
+
+// runAction executes an action. Interactive actions suspend the TUI and hand
+// the terminal to the command; others run in the background.
+func runAction(a *action, townRoot string) tea.Cmd {
+	if a.interactive {
+		cmd := exec.Command(gtBinary, a.args...) //nolint:gosec // G204: args are built from feed state, not shell input
+		cmd.Dir = townRoot
+		return tea.ExecProcess(cmd, func(err error) tea.Msg {
+			return actionResultMsg{action: a, err: err}
+		})
+	}
+	return func() tea.Msg {
+		out, err := gtOutput(townRoot, a.args...)
+		return actionResultMsg{action: a, output: out, err: err}
+	}
+}
+
+// gtOutput runs a gt command and returns its combined output.
+func gtOutput(townRoot string, args ...string) (string, error) {
+	cmd := exec.Command(gtBinary, args...) //nolint:gosec // G204: args are built from feed state, not shell input
+	cmd.Dir = townRoot
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return string(out), fmt.Errorf("%s", lastLine(msg))
+		}
+		return string(out), err
+	}
+	return string(out), nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(s, "\n")
+	return lines[len(lines)-1]
+}
+
+// startAction resolves an action for the current selection and either
+// prompts for input/confirmation or runs it right away.
+func (m *Model) startAction(kind ActionKind) tea.Cmd {
+	a, lookup, err := buildAction(kind, m.selectedAgent(), m.selectedEvent(), m.townRoot)
+	if err != nil {
+		m.setStatus(err.Error(), true)
+		return nil
+	}
+	if lookup != nil {
+		m.setStatus("looking up...", false)
+		return lookup
+	}
+	return m.beginAction(a)
+}
+
+// beginAction moves a resolved action into the right prompt state.
+func (m *Model) beginAction(a *action) tea.Cmd {
+	if a.kind == ActionNudge && len(a.args) == 2 {
+		m.inputAction = a
+		m.input = ""
+		return nil
+	}
+	if a.confirm {
+		m.pending = a
+		return nil
+	}
+	m.setStatus(a.title+"...", false)
+	return runAction(a, m.townRoot)
+}
+
+// finishAction reports an action's outcome.
+func (m *Model) finishAction(msg actionResultMsg) {
+	if msg.err != nil {
+		m.setStatus(fmt.Sprintf("✗ %s: %v", msg.action.title, msg.err), true)
+		return
+	}
+	m.setStatus("✓ "+msg.action.title, false)
+	if msg.action.showOutput {
+		m.detailTitle = msg.action.title
+		m.detailViewport.SetContent(strings.TrimRight(msg.output, "\n"))
+		m.detailViewport.GotoBottom()
+		m.showDetail = true
+	}
+}
+
+// handleInputKey edits the nudge message prompt.
+func (m *Model) handleInputKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.inputAction = nil
+		m.setStatus("cancelled", false)
+	case tea.KeyEnter:
+		a := m.inputAction
+		m.inputAction = nil
+		text := strings.TrimSpace(m.input)
+		if text == "" {
+			m.setStatus("cancelled: empty message", false)
+			return nil
+		}
+		a.args = append(a.args, text)
+		a.title = fmt.Sprintf("%s %q", a.title, text)
+		return m.beginAction(a)
+	case tea.KeyBackspace:
+		if r := []rune(m.input); len(r) > 0 {
+			m.input = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.input += " "
+	case tea.KeyRunes:
+		m.input += string(msg.Runes)
+	}
+	return nil
+}
+
+// handleConfirmKey answers the y/n confirmation prompt.
+func (m *Model) handleConfirmKey(msg tea.KeyMsg) tea.Cmd {
+	a := m.pending
+	m.pending = nil
+	switch msg.String() {
+	case "y", "Y":
+		m.setStatus(a.title+"...", false)
+		return runAction(a, m.townRoot)
+	}
+	m.setStatus("cancelled", false)
+	return nil
+}
+
+func (m *Model) setStatus(msg string, isErr bool) {
+	m.statusMsg = msg
+	m.statusErr = isErr
+}
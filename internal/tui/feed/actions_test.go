@@ -0,0 +1,193 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+var (
+	mayor    = &Agent{ID: "hq-mayor", Role: "mayor"}
+	witness  = &Agent{ID: "gt-gastown-witness", Role: "witness", Rig: "gastown"}
+	crew     = &Agent{ID: "gastown/crew/joe", Role: "crew", Rig: "gastown"}
+	polecat  = &Agent{ID: "gastown/polecats/Toast", Role: "polecat", Rig: "gastown"}
+	orphan   = &Agent{ID: "gastown/polecats/Nux", Role: "polecat"}
+	stranger = &Agent{ID: "someone", Role: "dog"}
+)
+
+func TestAgentAddress(t *testing.T) {
+	tests := []struct {
+		agent *Agent
+		want  string
+	}{
+		{mayor, "mayor"},
+		{&Agent{ID: "hq-deacon", Role: "deacon"}, "deacon"},
+		{witness, "gastown/witness"},
+		{&Agent{ID: "gt-gastown-refinery", Role: "refinery", Rig: "gastown"}, "gastown/refinery"},
+		{crew, "gastown/crew/joe"},
+		{polecat, "gastown/Toast"},
+		{orphan, "gastown/polecats/Nux"},
+		{stranger, "someone"},
+	}
+	for _, tt := range tests {
+		if got := agentAddress(tt.agent); got != tt.want {
+			t.Errorf("agentAddress(%s) = %q, want %q", tt.agent.ID, got, tt.want)
+		}
+	}
+}
+
+func TestAgentIdentity(t *testing.T) {
+	tests := []struct {
+		agent *Agent
+		want  string
+	}{
+		{mayor, "mayor/"},
+		{witness, "gastown/witness"},
+		{crew, "gastown/crew/joe"},
+		{polecat, "gastown/polecats/Toast"},
+		{orphan, "gastown/polecats/Nux"},
+	}
+	for _, tt := range tests {
+		if got := agentIdentity(tt.agent); got != tt.want {
+			t.Errorf("agentIdentity(%s) = %q, want %q", tt.agent.ID, got, tt.want)
+		}
+	}
+}
+
+func TestAttachArgs(t *testing.T) {
+	tests := []struct {
+		agent   *Agent
+		want    []string
+		wantErr bool
+	}{
+		{mayor, []string{"mayor", "attach"}, false},
+		{witness, []string{"witness", "attach", "gastown"}, false},
+		{crew, []string{"crew", "at", "joe", "--rig", "gastown"}, false},
+		{polecat, []string{"session", "at", "gastown/Toast"}, false},
+		{orphan, nil, true},
+		{stranger, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := attachArgs(tt.agent)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("attachArgs(%s) error = %v, wantErr %v", tt.agent.ID, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("attachArgs(%s) = %v, want %v", tt.agent.ID, got, tt.want)
+		}
+	}
+}
+
+func TestBuildAction(t *testing.T) {
+	bead := &Event{Type: "create", Target: "gt-abc", Rig: "gastown"}
+	escalation := &Event{Type: "escalation_sent", Target: "hq-esc1", Rig: "gastown"}
+	closed := &Event{Type: "complete", Target: "gt-abc", Rig: "gastown"}
+	merged := &Event{Type: "merged", Target: "gt-mr1", Rig: "gastown"}
+	slung := &Event{Type: "sling", Target: "gt-abc", Rig: "gastown"}
+
+	tests := []struct {
+		name        string
+		kind        ActionKind
+		agent       *Agent
+		event       *Event
+		want        []string
+		confirm     bool
+		interactive bool
+		lookup      bool
+		wantErr     bool
+	}{
+		{name: "nudge", kind: ActionNudge, agent: polecat, want: []string{"nudge", "gastown/Toast"}, confirm: true},
+		{name: "nudge without agent", kind: ActionNudge, wantErr: true},
+		{name: "peek", kind: ActionPeek, agent: crew, want: []string{"peek", "gastown/crew/joe", "60"}},
+		{name: "peek mayor", kind: ActionPeek, agent: mayor, wantErr: true},
+		{name: "attach", kind: ActionAttach, agent: witness, want: []string{"witness", "attach", "gastown"}, confirm: true, interactive: true},
+		{name: "attach without rig", kind: ActionAttach, agent: orphan, wantErr: true},
+		{name: "open event bead", kind: ActionOpenBead, agent: polecat, event: bead, want: []string{"show", "gt-abc"}},
+		{name: "open hooked bead", kind: ActionOpenBead, agent: polecat, lookup: true},
+		{name: "open without selection", kind: ActionOpenBead, wantErr: true},
+		{name: "sling event bead", kind: ActionSling, agent: polecat, event: bead, lookup: true},
+		{name: "sling escalation", kind: ActionSling, agent: polecat, event: escalation, wantErr: true},
+		{name: "sling closed bead", kind: ActionSling, agent: polecat, event: closed, wantErr: true},
+		{name: "sling merged", kind: ActionSling, agent: polecat, event: merged, wantErr: true},
+		{name: "sling already slung", kind: ActionSling, agent: polecat, event: slung, wantErr: true},
+		{name: "sling ready work", kind: ActionSling, agent: polecat, lookup: true},
+		{name: "sling to town agent", kind: ActionSling, agent: mayor, wantErr: true},
+		{name: "ack", kind: ActionAck, event: escalation, want: []string{"escalate", "ack", "hq-esc1"}, confirm: true},
+		{name: "ack non-escalation", kind: ActionAck, event: bead, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, lookup, err := buildAction(tt.kind, tt.agent, tt.event, t.TempDir())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.lookup {
+				if lookup == nil || a != nil {
+					t.Fatalf("want a lookup, got action %+v", a)
+				}
+				return
+			}
+			if a == nil {
+				t.Fatal("no action")
+			}
+			if a.kind != tt.kind || !reflect.DeepEqual(a.args, tt.want) {
+				t.Errorf("action = %v %v, want %v %v", a.kind, a.args, tt.kind, tt.want)
+			}
+			if a.confirm != tt.confirm || a.interactive != tt.interactive {
+				t.Errorf("confirm/interactive = %v/%v, want %v/%v", a.confirm, a.interactive, tt.confirm, tt.interactive)
+			}
+		})
+	}
+}
+
+func TestLookupOpenBead(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("gt stub is a shell script")
+	}
+	dir := t.TempDir()
+	stub := filepath.Join(dir, "gt")
+	if err := os.WriteFile(stub, []byte("#!/bin/sh\ncat \"$GT_SHOW\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := gtBinary
+	gtBinary = stub
+	defer func() { gtBinary = old }()
+
+	event := &Event{Type: "create", Target: "gt-abc", Rig: "gastown"}
+	tests := []struct {
+		name string
+		show string
+		want []string
+	}{
+		{"open and unassigned", `[{"status":"open"}]`, []string{"sling", "gt-abc", "gastown"}},
+		{"assigned", `[{"status":"open","assignee":"gastown/polecats/Toast"}]`, nil},
+		{"hooked", `[{"status":"hooked"}]`, nil},
+		{"closed", `[{"status":"closed"}]`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			show := filepath.Join(dir, "show.json")
+			if err := os.WriteFile(show, []byte(tt.show), 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("GT_SHOW", show)
+
+			msg := lookupOpenBead(event, dir)().(actionReadyMsg)
+			if tt.want == nil {
+				if msg.err == nil {
+					t.Errorf("want no sling, got %v", msg.action.args)
+				}
+				return
+			}
+			if msg.err != nil || !reflect.DeepEqual(msg.action.args, tt.want) || !msg.action.confirm {
+				t.Errorf("lookup = %+v, %v; want confirmed %v", msg.action, msg.err, tt.want)
+			}
+		})
+	}
+}
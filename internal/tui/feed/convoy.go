@@ -31,6 +31,8 @@ type Convoy struct {
 	Total     int       `json:"total"`
 	CreatedAt time.Time `json:"created_at"`
 	ClosedAt  time.Time `json:"closed_at,omitempty"`
+
+	tracked []trackedStatus // kept for replay (ConvoyStateAt)
 }
 
 // ConvoyState holds all convoy data for the panel
@@ -140,6 +142,7 @@ func enrichConvoy(beadsDir string, item convoyListItem) Convoy {
 
 	// Get tracked issues and their status
 	tracked := getTrackedIssueStatus(beadsDir, item.ID)
+	convoy.tracked = tracked
 	convoy.Total = len(tracked)
 	for _, t := range tracked {
 		if t.Status == "closed" {
@@ -151,8 +154,9 @@ func enrichConvoy(beadsDir string, item convoyListItem) Convoy {
 }
 
 type trackedStatus struct {
-	ID     string
-	Status string
+	ID       string
+	Status   string
+	ClosedAt time.Time
 }
 
 // getTrackedIssueStatus queries tracked issues and their status
@@ -198,15 +202,15 @@ func getTrackedIssueStatus(beadsDir, convoyID string) []trackedStatus {
 		}
 
 		// Get issue status
-		status := getIssueStatus(issueID)
-		tracked = append(tracked, trackedStatus{ID: issueID, Status: status})
+		status, closedAt := getIssueStatus(issueID)
+		tracked = append(tracked, trackedStatus{ID: issueID, Status: status, ClosedAt: closedAt})
 	}
 
 	return tracked
 }
 
-// getIssueStatus fetches just the status of an issue
-func getIssueStatus(issueID string) string {
+// getIssueStatus fetches the status of an issue and when it was closed
+func getIssueStatus(issueID string) (string, time.Time) {
 	ctx, cancel := context.WithTimeout(context.Background(), convoySubprocessTimeout)
 	defer cancel()
 
@@ -215,17 +219,89 @@ func getIssueStatus(issueID string) string {
 	cmd.Stdout = &stdout
 
 	if err := cmd.Run(); err != nil {
-		return "unknown"
+		return "unknown", time.Time{}
 	}
 
 	var issues []struct {
-		Status string `json:"status"`
+		Status   string `json:"status"`
+		ClosedAt string `json:"closed_at"`
 	}
 	if err := json.Unmarshal(stdout.Bytes(), &issues); err != nil || len(issues) == 0 {
-		return "unknown"
+		return "unknown", time.Time{}
+	}
+
+	closedAt, _ := time.Parse(time.RFC3339, issues[0].ClosedAt)
+	return issues[0].Status, closedAt
+}
+
+// FetchConvoyHistory retrieves open convoys plus convoys closed after since
+// (less the 24h landed window), so ConvoyStateAt can rebuild the panel for
+// any moment of a replay starting at since.
+func FetchConvoyHistory(townRoot string, since time.Time) ([]Convoy, error) {
+	townBeads := filepath.Join(townRoot, ".beads")
+
+	var convoys []Convoy
+	openConvoys, err := listConvoys(townBeads, "open")
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range openConvoys {
+		convoys = append(convoys, enrichConvoy(townBeads, c))
+	}
+
+	closedConvoys, err := listConvoys(townBeads, "closed")
+	if err == nil {
+		cutoff := since.Add(-24 * time.Hour)
+		for _, c := range closedConvoys {
+			convoy := enrichConvoy(townBeads, c)
+			if convoy.ClosedAt.IsZero() || convoy.ClosedAt.After(cutoff) {
+				convoys = append(convoys, convoy)
+			}
+		}
+	}
+
+	return convoys, nil
+}
+
+// ConvoyStateAt reconstructs the convoy panel as it was at t. Progress counts
+// tracked issues closed by t; issues closed without a recorded time count as
+// done. Convoys created after t are hidden.
+func ConvoyStateAt(convoys []Convoy, t time.Time) *ConvoyState {
+	state := &ConvoyState{
+		InProgress: make([]Convoy, 0),
+		Landed:     make([]Convoy, 0),
+		LastUpdate: t,
 	}
 
-	return issues[0].Status
+	for _, c := range convoys {
+		if !c.CreatedAt.IsZero() && c.CreatedAt.After(t) {
+			continue
+		}
+		if !c.ClosedAt.IsZero() && !c.ClosedAt.After(t) {
+			if c.ClosedAt.After(t.Add(-24 * time.Hour)) {
+				state.Landed = append(state.Landed, c)
+			}
+			continue
+		}
+
+		c.Status = "open"
+		c.Completed = 0
+		for _, tr := range c.tracked {
+			if tr.Status == "closed" && (tr.ClosedAt.IsZero() || !tr.ClosedAt.After(t)) {
+				c.Completed++
+			}
+		}
+		state.InProgress = append(state.InProgress, c)
+	}
+
+	sort.Slice(state.InProgress, func(i, j int) bool {
+		return state.InProgress[i].CreatedAt.Before(state.InProgress[j].CreatedAt)
+	})
+	sort.Slice(state.Landed, func(i, j int) bool {
+		return state.Landed[i].ClosedAt.After(state.Landed[j].ClosedAt)
+	})
+
+	return state
 }
 
 // Convoy panel styles
@@ -286,7 +362,7 @@ func (m *Model) renderConvoys() string {
 		lines = append(lines, "  "+AgentIdleStyle.Render("No active convoys"))
 	} else {
 		for _, c := range m.convoyState.InProgress {
-			lines = append(lines, renderConvoyLine(c, false))
+			lines = append(lines, renderConvoyLine(c, false, m.now()))
 		}
 	}
 
@@ -298,7 +374,7 @@ func (m *Model) renderConvoys() string {
 		lines = append(lines, "  "+AgentIdleStyle.Render("No recent landings"))
 	} else {
 		for _, c := range m.convoyState.Landed {
-			lines = append(lines, renderConvoyLine(c, true))
+			lines = append(lines, renderConvoyLine(c, true, m.now()))
 		}
 	}
 
@@ -306,7 +382,7 @@ func (m *Model) renderConvoys() string {
 }
 
 // renderConvoyLine renders a single convoy status line
-func renderConvoyLine(c Convoy, landed bool) string {
+func renderConvoyLine(c Convoy, landed bool, now time.Time) string {
 	// Format: "  hq-xyz  Title       2/4 ●●○○" or "  hq-xyz  Title       ✓ 2h ago"
 	id := ConvoyIDStyle.Render(c.ID)
 
@@ -319,7 +395,7 @@ func renderConvoyLine(c Convoy, landed bool) string {
 
 	if landed {
 		// Show checkmark and time since landing
-		age := formatAge(time.Since(c.ClosedAt))
+		age := formatAge(now.Sub(c.ClosedAt))
 		status := ConvoyLandedStyle.Render("✓") + " " + ConvoyAgeStyle.Render(age+" ago")
 		return fmt.Sprintf("  %s  %-20s  %s", id, title, status)
 	}
@@ -306,6 +306,11 @@ func parseGtEventLine(line string) *Event {
 		return nil
 	}
 
+	return gtEventToEvent(ge, line)
+}
+
+// gtEventToEvent converts a decoded gt event into a feed event
+func gtEventToEvent(ge GtEvent, line string) *Event {
 	t, err := time.Parse(time.RFC3339, ge.Timestamp)
 	if err != nil {
 		t = time.Now()
@@ -355,11 +360,17 @@ func parseGtEventLine(line string) *Event {
 	// Build message from event type and payload
 	message := buildEventMessage(ge.Type, ge.Payload)
 
+	// Escalations target the escalation bead so the feed can acknowledge them
+	target := getPayloadString(ge.Payload, "bead")
+	if target == "" {
+		target = getPayloadString(ge.Payload, "escalation_id")
+	}
+
 	return &Event{
 		Time:    t,
 		Type:    ge.Type,
 		Actor:   ge.Actor,
-		Target:  getPayloadString(ge.Payload, "bead"),
+		Target:  target,
 		Message: message,
 		Rig:     rig,
 		Role:    role,
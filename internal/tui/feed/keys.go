@@ -24,6 +24,20 @@ type KeyMap struct {
 	Expand  key.Binding
 	Refresh key.Binding
 
+	// Agent/event actions (run the matching gt command)
+	Nudge    key.Binding
+	Peek     key.Binding
+	Attach   key.Binding
+	OpenBead key.Binding
+	Sling    key.Binding
+	Ack      key.Binding
+
+	// Replay controls (only active in replay mode)
+	ReplayPause  key.Binding
+	ReplayStep   key.Binding
+	ReplayFaster key.Binding
+	ReplaySlower key.Binding
+
 	// Search/Filter
 	Search      key.Binding
 	Filter      key.Binding
@@ -93,6 +107,46 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("r"),
 			key.WithHelp("r", "refresh"),
 		),
+		Nudge: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", "nudge"),
+		),
+		Peek: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "peek"),
+		),
+		Attach: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "attach"),
+		),
+		OpenBead: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "show bead"),
+		),
+		Sling: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", "sling work"),
+		),
+		Ack: key.NewBinding(
+			key.WithKeys("A"),
+			key.WithHelp("A", "ack escalation"),
+		),
+		ReplayPause: key.NewBinding(
+			key.WithKeys(" "),
+			key.WithHelp("space", "pause/resume replay"),
+		),
+		ReplayStep: key.NewBinding(
+			key.WithKeys("."),
+			key.WithHelp(".", "step to next event"),
+		),
+		ReplayFaster: key.NewBinding(
+			key.WithKeys("+", "="),
+			key.WithHelp("+", "faster"),
+		),
+		ReplaySlower: key.NewBinding(
+			key.WithKeys("-"),
+			key.WithHelp("-", "slower"),
+		),
 		Search: key.NewBinding(
 			key.WithKeys("/"),
 			key.WithHelp("/", "search"),
@@ -126,6 +180,8 @@ func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.PageUp, k.PageDown, k.Top, k.Bottom},
 		{k.Tab, k.FocusTree, k.FocusConvoy, k.FocusFeed, k.Enter, k.Expand},
+		{k.Nudge, k.Peek, k.Attach, k.OpenBead, k.Sling, k.Ack},
+		{k.ReplayPause, k.ReplayStep, k.ReplayFaster, k.ReplaySlower},
 		{k.Search, k.Filter, k.ClearFilter, k.Refresh},
 		{k.Help, k.Quit},
 	}
@@ -75,6 +75,27 @@ type Model struct {
 	showHelp bool
 	filter   string
 
+	// Selection: cursor positions in the tree and feed panels
+	treeCursor int
+	feedCursor int
+	treeAgents []*Agent // agents in tree render order
+	treeLines  []int    // content line of each entry in treeAgents
+
+	// Actions
+	pending        *action // awaiting y/n confirmation
+	inputAction    *action // awaiting text input (nudge message)
+	input          string
+	statusMsg      string
+	statusErr      bool
+	detailViewport viewport.Model
+	detailTitle    string
+	showDetail     bool
+
+	// Replay mode: replay drives the clock and convoyHistory is the
+	// snapshot the convoy panel is rebuilt from
+	replay        *ReplaySource
+	convoyHistory []Convoy
+
 	// Event source
 	eventChan <-chan Event
 	done      chan struct{}
@@ -91,6 +112,7 @@ func NewModel() *Model {
 		treeViewport:   viewport.New(0, 0),
 		convoyViewport: viewport.New(0, 0),
 		feedViewport:   viewport.New(0, 0),
+		detailViewport: viewport.New(0, 0),
 		rigs:           make(map[string]*Rig),
 		events:         make([]Event, 0, 1000),
 		keys:           DefaultKeyMap(),
@@ -104,8 +126,30 @@ func (m *Model) SetTownRoot(townRoot string) {
 	m.townRoot = townRoot
 }
 
+// SetReplay switches the model to replay mode, driven by the given source.
+// The event channel must still be set (usually via a MultiSource).
+func (m *Model) SetReplay(r *ReplaySource) {
+	m.replay = r
+}
+
+// now returns the replay time in replay mode, otherwise the wall clock.
+func (m *Model) now() time.Time {
+	if m.replay != nil {
+		return m.replay.Now()
+	}
+	return time.Now()
+}
+
 // Init initializes the model
 func (m *Model) Init() tea.Cmd {
+	if m.replay != nil {
+		return tea.Batch(
+			m.listenForEvents(),
+			m.fetchConvoyHistory(),
+			tick(),
+			tea.SetWindowTitle("GT Feed (replay)"),
+		)
+	}
 	return tea.Batch(
 		m.listenForEvents(),
 		m.fetchConvoys(),
@@ -121,6 +165,11 @@ type convoyUpdateMsg struct {
 	state *ConvoyState
 }
 
+// convoyHistoryMsg carries the convoy snapshot used during replay
+type convoyHistoryMsg struct {
+	convoys []Convoy
+}
+
 // tickMsg is sent periodically to refresh the view
 type tickMsg time.Time
 
@@ -164,6 +213,19 @@ func (m *Model) fetchConvoys() tea.Cmd {
 	}
 }
 
+// fetchConvoyHistory returns a command that loads the convoys a replay needs
+func (m *Model) fetchConvoyHistory() tea.Cmd {
+	if m.townRoot == "" {
+		return nil
+	}
+	townRoot := m.townRoot
+	since := m.replay.Now()
+	return func() tea.Msg {
+		convoys, _ := FetchConvoyHistory(townRoot, since)
+		return convoyHistoryMsg{convoys: convoys}
+	}
+}
+
 // convoyRefreshTick returns a command that schedules the next convoy refresh
 func (m *Model) convoyRefreshTick() tea.Cmd {
 	return tea.Tick(10*time.Second, func(t time.Time) tea.Msg {
@@ -199,8 +261,30 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds, m.fetchConvoys())
 		}
 
+	case convoyHistoryMsg:
+		m.convoyHistory = msg.convoys
+		m.convoyState = ConvoyStateAt(m.convoyHistory, m.now())
+		m.updateViewContent()
+
 	case tickMsg:
+		if m.replay != nil {
+			// Rebuild time-dependent panels for the advancing replay clock
+			if m.convoyHistory != nil {
+				m.convoyState = ConvoyStateAt(m.convoyHistory, m.now())
+			}
+			m.updateViewContent()
+		}
 		cmds = append(cmds, tick())
+
+	case actionReadyMsg:
+		if msg.err != nil {
+			m.setStatus(msg.err.Error(), true)
+		} else {
+			cmds = append(cmds, m.beginAction(msg.action))
+		}
+
+	case actionResultMsg:
+		m.finishAction(msg)
 	}
 
 	// Update viewports
@@ -220,6 +304,68 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKey processes key presses
 func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Prompts capture all keys until answered
+	if m.inputAction != nil {
+		return m, m.handleInputKey(msg)
+	}
+	if m.pending != nil {
+		return m, m.handleConfirmKey(msg)
+	}
+	if m.showDetail {
+		switch {
+		case key.Matches(msg, m.keys.ClearFilter), key.Matches(msg, m.keys.Quit):
+			m.showDetail = false
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.detailViewport, cmd = m.detailViewport.Update(msg)
+		return m, cmd
+	}
+
+	if m.replay != nil {
+		switch {
+		case key.Matches(msg, m.keys.ReplayPause):
+			m.replay.TogglePause()
+			return m, nil
+		case key.Matches(msg, m.keys.ReplayStep):
+			m.replay.Step()
+			return m, nil
+		case key.Matches(msg, m.keys.ReplayFaster):
+			m.replay.ScaleSpeed(2)
+			return m, nil
+		case key.Matches(msg, m.keys.ReplaySlower):
+			m.replay.ScaleSpeed(0.5)
+			return m, nil
+		case key.Matches(msg, m.keys.Nudge), key.Matches(msg, m.keys.Peek),
+			key.Matches(msg, m.keys.Attach), key.Matches(msg, m.keys.Sling),
+			key.Matches(msg, m.keys.Ack):
+			m.setStatus("actions that change the town are disabled during replay", true)
+			return m, nil
+		}
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Nudge):
+		return m, m.startAction(ActionNudge)
+	case key.Matches(msg, m.keys.Peek):
+		return m, m.startAction(ActionPeek)
+	case key.Matches(msg, m.keys.Attach):
+		return m, m.startAction(ActionAttach)
+	case key.Matches(msg, m.keys.OpenBead):
+		return m, m.startAction(ActionOpenBead)
+	case key.Matches(msg, m.keys.Sling):
+		return m, m.startAction(ActionSling)
+	case key.Matches(msg, m.keys.Ack):
+		return m, m.startAction(ActionAck)
+
+	case key.Matches(msg, m.keys.Up) && m.focusedPanel != PanelConvoy:
+		m.moveCursor(-1)
+		return m, nil
+	case key.Matches(msg, m.keys.Down) && m.focusedPanel != PanelConvoy:
+		m.moveCursor(1)
+		return m, nil
+	}
+
 	switch {
 	case key.Matches(msg, m.keys.Quit):
 		m.closeOnce.Do(func() { close(m.done) })
@@ -315,6 +461,8 @@ func (m *Model) updateViewportSizes() {
 	m.convoyViewport.Height = convoyHeight
 	m.feedViewport.Width = contentWidth
 	m.feedViewport.Height = feedHeight
+	m.detailViewport.Width = contentWidth
+	m.detailViewport.Height = feedHeight
 
 	m.updateViewContent()
 }
@@ -388,6 +536,11 @@ func (m *Model) addEvent(e Event) {
 	// Add to event feed
 	m.events = append(m.events, e)
 
+	// Keep the same event selected as new ones arrive on top
+	if m.feedCursor > 0 {
+		m.feedCursor++
+	}
+
 	// Keep max 1000 events
 	if len(m.events) > 1000 {
 		m.events = m.events[len(m.events)-1000:]
@@ -405,3 +558,87 @@ func (m *Model) SetEventChannel(ch <-chan Event) {
 func (m *Model) View() string {
 	return m.render()
 }
+
+// moveCursor moves the selection in the focused panel and keeps it in view.
+func (m *Model) moveCursor(delta int) {
+	switch m.focusedPanel {
+	case PanelTree:
+		m.treeCursor = clamp(m.treeCursor+delta, 0, len(m.treeAgents)-1)
+	case PanelFeed:
+		m.feedCursor = clamp(m.feedCursor+delta, 0, m.visibleEventCount()-1)
+	}
+	m.updateViewContent()
+	m.ensureCursorVisible()
+}
+
+// ensureCursorVisible scrolls the focused viewport so the cursor line shows.
+func (m *Model) ensureCursorVisible() {
+	var vp *viewport.Model
+	line := 0
+	switch m.focusedPanel {
+	case PanelTree:
+		if m.treeCursor >= len(m.treeLines) {
+			return
+		}
+		vp, line = &m.treeViewport, m.treeLines[m.treeCursor]
+	case PanelFeed:
+		vp, line = &m.feedViewport, m.feedCursor
+	default:
+		return
+	}
+	if line < vp.YOffset {
+		vp.SetYOffset(line)
+	} else if vp.Height > 0 && line >= vp.YOffset+vp.Height {
+		vp.SetYOffset(line - vp.Height + 1)
+	}
+}
+
+// visibleEventCount returns how many events the feed panel renders.
+func (m *Model) visibleEventCount() int {
+	if len(m.events) > 100 {
+		return 100
+	}
+	return len(m.events)
+}
+
+// selectedEvent returns the event under the feed cursor, if the feed has focus.
+func (m *Model) selectedEvent() *Event {
+	if m.focusedPanel != PanelFeed || m.visibleEventCount() == 0 {
+		return nil
+	}
+	idx := len(m.events) - 1 - clamp(m.feedCursor, 0, m.visibleEventCount()-1)
+	return &m.events[idx]
+}
+
+// selectedAgent returns the agent under the tree cursor, or the actor of the
+// selected event when the feed has focus.
+func (m *Model) selectedAgent() *Agent {
+	switch m.focusedPanel {
+	case PanelTree:
+		if m.treeCursor < len(m.treeAgents) {
+			return m.treeAgents[m.treeCursor]
+		}
+	case PanelFeed:
+		e := m.selectedEvent()
+		if e == nil || e.Actor == "" {
+			return nil
+		}
+		if rig, ok := m.rigs[e.Rig]; ok {
+			if agent, ok := rig.Agents[e.Actor]; ok {
+				return agent
+			}
+		}
+		return &Agent{ID: e.Actor, Name: e.Actor, Role: e.Role, Rig: e.Rig}
+	}
+	return nil
+}
+
+func clamp(v, lo, hi int) int {
+	if v > hi {
+		v = hi
+	}
+	if v < lo {
+		v = lo
+	}
+	return v
+}
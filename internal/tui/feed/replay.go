@@ -0,0 +1,348 @@
+package feed
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// replayTick is how often (in wall time) the replay clock advances.
+const replayTick = 100 * time.Millisecond
+
+// ReplaySource replays recorded events from .events.jsonl/.feed.jsonl at a
+// configurable speed. It implements EventSource, so the TUI rebuilds the agent
+// tree and event stream exactly as it does for live sources.
+type ReplaySource struct {
+	events []Event
+	out    chan Event
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	clock  time.Time // replay (virtual) time
+	until  time.Time
+	speed  float64
+	paused bool
+	steps  int // pending single-step requests
+	next   int // index of the next event to emit
+}
+
+// ReplayWindow bounds a replay. Zero times mean "from the first event" and
+// "to the last event".
+type ReplayWindow struct {
+	Since time.Time
+	Until time.Time
+}
+
+// ReplayFiles returns the event logs to replay for a town. The raw
+// .events.jsonl is preferred; the curated .feed.jsonl is used when the raw
+// log is missing (it is a deduplicated subset, so the two are never merged).
+func ReplayFiles(townRoot string) ([]string, error) {
+	for _, name := range []string{".events.jsonl", ".feed.jsonl"} {
+		path := filepath.Join(townRoot, name)
+		if _, err := os.Stat(path); err == nil {
+			return []string{path}, nil
+		}
+	}
+	return nil, fmt.Errorf("no .events.jsonl or .feed.jsonl in %s", townRoot)
+}
+
+// NewReplaySource loads events within window from the given files and starts
+// replaying them at speed (1 = real time).
+func NewReplaySource(paths []string, window ReplayWindow, speed float64) (*ReplaySource, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("replay speed must be positive, got %v", speed)
+	}
+
+	var events []Event
+	for _, path := range paths {
+		loaded, err := loadReplayEvents(path, window)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, loaded...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+	if len(events) == 0 {
+		return nil, fmt.Errorf("no events to replay in the selected window")
+	}
+
+	start := window.Since
+	if start.IsZero() {
+		start = events[0].Time
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &ReplaySource{
+		events: events,
+		out:    make(chan Event, 100),
+		cancel: cancel,
+		clock:  start,
+		until:  window.Until,
+		speed:  speed,
+	}
+	go s.run(ctx)
+	return s, nil
+}
+
+// loadReplayEvents reads feed-visible events from one log file.
+func loadReplayEvents(path string, window ReplayWindow) ([]Event, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is a town event log
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	parse := parseGtEventLine
+	if filepath.Base(path) == ".feed.jsonl" {
+		parse = parseCuratedFeedLine
+	}
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		event := parse(scanner.Text())
+		if event == nil {
+			continue
+		}
+		if !window.Since.IsZero() && event.Time.Before(window.Since) {
+			continue
+		}
+		if !window.Until.IsZero() && event.Time.After(window.Until) {
+			continue
+		}
+		events = append(events, *event)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// run advances the replay clock and emits events whose time has come.
+func (s *ReplaySource) run(ctx context.Context) {
+	defer close(s.out)
+
+	ticker := time.NewTicker(replayTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		batch, finished := s.advance(replayTick)
+		for _, event := range batch {
+			select {
+			case s.out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if finished {
+			return
+		}
+	}
+}
+
+// advance moves the clock forward by elapsed wall time (or by one event when
+// stepping) and returns the events that became due.
+func (s *ReplaySource) advance(elapsed time.Duration) ([]Event, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.steps > 0:
+		s.steps--
+		if s.next < len(s.events) && s.events[s.next].Time.After(s.clock) {
+			s.clock = s.events[s.next].Time
+		}
+	case !s.paused:
+		s.clock = s.clock.Add(time.Duration(float64(elapsed) * s.speed))
+	default:
+		return nil, false
+	}
+
+	var batch []Event
+	for s.next < len(s.events) && !s.events[s.next].Time.After(s.clock) {
+		batch = append(batch, s.events[s.next])
+		s.next++
+	}
+
+	if s.next >= len(s.events) {
+		// Hold the clock at the end of the window so ages stay meaningful.
+		if !s.until.IsZero() && s.clock.After(s.until) {
+			s.clock = s.until
+		}
+		return batch, true
+	}
+	return batch, false
+}
+
+// Events returns the replayed event channel.
+func (s *ReplaySource) Events() <-chan Event {
+	return s.out
+}
+
+// Close stops the replay.
+func (s *ReplaySource) Close() error {
+	s.cancel()
+	return nil
+}
+
+// Now returns the current replay time.
+func (s *ReplaySource) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clock
+}
+
+// TogglePause pauses or resumes the replay clock.
+func (s *ReplaySource) TogglePause() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = !s.paused
+}
+
+// Step pauses the replay and jumps to the next event.
+func (s *ReplaySource) Step() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.paused = true
+	s.steps++
+}
+
+// ScaleSpeed multiplies the replay speed by factor, within 0.25x..10000x.
+func (s *ReplaySource) ScaleSpeed(factor float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.speed *= factor
+	if s.speed < 0.25 {
+		s.speed = 0.25
+	}
+	if s.speed > 10000 {
+		s.speed = 10000
+	}
+}
+
+// Status describes the replay position for the header.
+func (s *ReplaySource) Status() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := fmt.Sprintf("%gx", s.speed)
+	switch {
+	case s.next >= len(s.events):
+		state = "ended"
+	case s.paused:
+		state = "paused"
+	}
+	return fmt.Sprintf("REPLAY %s  %d/%d  %s",
+		s.clock.Format("Jan 2 15:04:05"), s.next, len(s.events), state)
+}
+
+// parseCuratedFeedLine parses a line from the curated .feed.jsonl. Curated
+// events carry a summary and no visibility field.
+func parseCuratedFeedLine(line string) *Event {
+	if strings.TrimSpace(line) == "" {
+		return nil
+	}
+
+	var fe struct {
+		GtEvent
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(line), &fe); err != nil {
+		return nil
+	}
+
+	event := gtEventToEvent(fe.GtEvent, line)
+	if fe.Summary != "" {
+		event.Message = fe.Summary
+	}
+	return event
+}
+
+// ParseReplaySpeed parses a speed like "20x", "20" or "0.5x".
+func ParseReplaySpeed(s string) (float64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "x"), 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid speed %q (want e.g. 20x)", s)
+	}
+	return v, nil
+}
+
+// ParseReplayWindow parses --since/--until values for a replay.
+//
+// Accepted forms: clock times (22:00, 22:00:30) meaning the most recent such
+// time, durations ago (90m, 8h), dates (2026-01-31), date-times
+// (2026-01-31 22:00, 2026-01-31T22:00) and RFC3339. When until is a clock
+// time earlier than since, it refers to the following day, so
+// "--since 22:00 --until 02:00" covers the night.
+func ParseReplayWindow(since, until string, now time.Time) (ReplayWindow, error) {
+	var w ReplayWindow
+	var err error
+	if since != "" {
+		if w.Since, _, err = parseReplayTime(since, now); err != nil {
+			return w, fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+	if until != "" {
+		var clockOnly bool
+		if w.Until, clockOnly, err = parseReplayTime(until, now); err != nil {
+			return w, fmt.Errorf("invalid --until: %w", err)
+		}
+		if clockOnly && !w.Since.IsZero() {
+			for !w.Until.After(w.Since) {
+				w.Until = w.Until.Add(24 * time.Hour)
+			}
+		}
+	}
+	if !w.Since.IsZero() && !w.Until.IsZero() && !w.Until.After(w.Since) {
+		return w, fmt.Errorf("--until must be after --since")
+	}
+	return w, nil
+}
+
+// parseReplayTime parses a single replay bound. clockOnly reports whether the
+// value was a bare time of day.
+func parseReplayTime(s string, now time.Time) (t time.Time, clockOnly bool, err error) {
+	s = strings.TrimSpace(s)
+
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if c, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			t = time.Date(now.Year(), now.Month(), now.Day(), c.Hour(), c.Minute(), c.Second(), 0, now.Location())
+			if t.After(now) {
+				t = t.Add(-24 * time.Hour)
+			}
+			return t, true, nil
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), false, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, false, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, false, nil
+		}
+	}
+
+	return time.Time{}, false, fmt.Errorf("unrecognized time %q", s)
+}
@@ -0,0 +1,150 @@
+package feed
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseReplayWindowOvernight(t *testing.T) {
+	now := time.Date(2026, 3, 10, 9, 30, 0, 0, time.UTC)
+
+	w, err := ParseReplayWindow("22:00", "02:00", now)
+	if err != nil {
+		t.Fatalf("ParseReplayWindow error: %v", err)
+	}
+	wantSince := time.Date(2026, 3, 9, 22, 0, 0, 0, time.UTC)
+	wantUntil := time.Date(2026, 3, 10, 2, 0, 0, 0, time.UTC)
+	if !w.Since.Equal(wantSince) || !w.Until.Equal(wantUntil) {
+		t.Errorf("window = %v..%v, want %v..%v", w.Since, w.Until, wantSince, wantUntil)
+	}
+
+	w, err = ParseReplayWindow("2h", "", now)
+	if err != nil || !w.Since.Equal(now.Add(-2*time.Hour)) || !w.Until.IsZero() {
+		t.Errorf("ParseReplayWindow(2h) = %v, %v", w, err)
+	}
+
+	if _, err := ParseReplayWindow("2026-03-10", "2026-03-09", now); err == nil {
+		t.Error("expected error for until before since")
+	}
+	if _, err := ParseReplayWindow("yesterday-ish", "", now); err == nil {
+		t.Error("expected error for unparseable time")
+	}
+}
+
+func TestParseReplaySpeed(t *testing.T) {
+	for in, want := range map[string]float64{"20x": 20, "20": 20, "0.5X": 0.5} {
+		got, err := ParseReplaySpeed(in)
+		if err != nil || got != want {
+			t.Errorf("ParseReplaySpeed(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "fast", "0x", "-2x"} {
+		if _, err := ParseReplaySpeed(in); err == nil {
+			t.Errorf("ParseReplaySpeed(%q) expected error", in)
+		}
+	}
+}
+
+func TestLoadReplayEvents(t *testing.T) {
+	dir := t.TempDir()
+	events := filepath.Join(dir, ".events.jsonl")
+	lines := `{"ts":"2026-03-09T21:59:00Z","type":"sling","actor":"mayor","payload":{"bead":"gt-1","target":"gastown"},"visibility":"feed"}
+{"ts":"2026-03-09T22:05:00Z","type":"hook","actor":"gastown/polecats/Toast","payload":{"bead":"gt-2"},"visibility":"feed"}
+{"ts":"2026-03-09T22:06:00Z","type":"session_start","actor":"gastown/polecats/Toast","visibility":"audit"}
+{"ts":"2026-03-10T02:01:00Z","type":"done","actor":"gastown/polecats/Toast","payload":{"bead":"gt-2"},"visibility":"both"}
+`
+	if err := os.WriteFile(events, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	window := ReplayWindow{
+		Since: time.Date(2026, 3, 9, 22, 0, 0, 0, time.UTC),
+		Until: time.Date(2026, 3, 10, 2, 0, 0, 0, time.UTC),
+	}
+	got, err := loadReplayEvents(events, window)
+	if err != nil {
+		t.Fatalf("loadReplayEvents error: %v", err)
+	}
+	if len(got) != 1 || got[0].Type != "hook" || got[0].Target != "gt-2" {
+		t.Errorf("loadReplayEvents = %+v, want only the in-window feed-visible hook", got)
+	}
+
+	curated := filepath.Join(dir, ".feed.jsonl")
+	line := `{"ts":"2026-03-09T23:00:00Z","source":"gt","type":"sling","actor":"mayor","summary":"slung 3 beads","count":3}` + "\n"
+	if err := os.WriteFile(curated, []byte(line), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = loadReplayEvents(curated, window)
+	if err != nil {
+		t.Fatalf("loadReplayEvents(.feed.jsonl) error: %v", err)
+	}
+	if len(got) != 1 || got[0].Message != "slung 3 beads" {
+		t.Errorf("curated events = %+v, want summary as message", got)
+	}
+}
+
+func TestReplayAdvance(t *testing.T) {
+	start := time.Date(2026, 3, 9, 22, 0, 0, 0, time.UTC)
+	s := &ReplaySource{
+		events: []Event{
+			{Time: start.Add(10 * time.Second), Type: "a"},
+			{Time: start.Add(time.Minute), Type: "b"},
+			{Time: start.Add(time.Hour), Type: "c"},
+		},
+		clock: start,
+		speed: 100,
+	}
+
+	// 100ms of wall time at 100x covers 10s of replay time.
+	batch, done := s.advance(100 * time.Millisecond)
+	if len(batch) != 1 || batch[0].Type != "a" || done {
+		t.Fatalf("first advance = %v, %v", batch, done)
+	}
+
+	s.TogglePause()
+	if batch, _ := s.advance(time.Second); len(batch) != 0 || !s.Now().Equal(start.Add(10*time.Second)) {
+		t.Errorf("paused advance moved the replay: %v at %v", batch, s.Now())
+	}
+
+	s.Step()
+	batch, _ = s.advance(time.Second)
+	if len(batch) != 1 || batch[0].Type != "b" || !s.Now().Equal(start.Add(time.Minute)) {
+		t.Errorf("step = %v at %v, want b at +1m", batch, s.Now())
+	}
+
+	s.Step()
+	batch, done = s.advance(time.Second)
+	if len(batch) != 1 || batch[0].Type != "c" || !done {
+		t.Errorf("final step = %v, %v; want c and done", batch, done)
+	}
+}
+
+func TestConvoyStateAt(t *testing.T) {
+	base := time.Date(2026, 3, 9, 22, 0, 0, 0, time.UTC)
+	convoys := []Convoy{
+		{
+			ID: "hq-cv-1", CreatedAt: base.Add(-time.Hour), ClosedAt: base.Add(2 * time.Hour), Status: "closed",
+			Total: 2, Completed: 2,
+			tracked: []trackedStatus{
+				{ID: "gt-1", Status: "closed", ClosedAt: base.Add(30 * time.Minute)},
+				{ID: "gt-2", Status: "closed", ClosedAt: base.Add(2 * time.Hour)},
+			},
+		},
+		{ID: "hq-cv-2", CreatedAt: base.Add(3 * time.Hour), Status: "open"},
+	}
+
+	state := ConvoyStateAt(convoys, base.Add(time.Hour))
+	if len(state.InProgress) != 1 || len(state.Landed) != 0 {
+		t.Fatalf("at +1h: in progress %d, landed %d; want 1, 0", len(state.InProgress), len(state.Landed))
+	}
+	if c := state.InProgress[0]; c.Completed != 1 || c.Total != 2 {
+		t.Errorf("at +1h: progress %d/%d, want 1/2", c.Completed, c.Total)
+	}
+
+	state = ConvoyStateAt(convoys, base.Add(4*time.Hour))
+	if len(state.InProgress) != 1 || state.InProgress[0].ID != "hq-cv-2" || len(state.Landed) != 1 {
+		t.Errorf("at +4h: in progress %v, landed %v", state.InProgress, state.Landed)
+	}
+}
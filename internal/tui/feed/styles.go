@@ -94,6 +94,18 @@ var (
 	HelpDescStyle = lipgloss.NewStyle().
 			Foreground(colorDim)
 
+	// Selection and prompt styles
+	CursorStyle = lipgloss.NewStyle().
+			Foreground(colorHighlight).
+			Bold(true)
+
+	PromptStyle = lipgloss.NewStyle().
+			Foreground(colorWarning).
+			Bold(true)
+
+	StatusOKStyle = lipgloss.NewStyle().
+			Foreground(colorSuccess)
+
 	// Focus indicator
 	FocusedBorderStyle = lipgloss.NewStyle().
 				Border(lipgloss.RoundedBorder()).
@@ -140,10 +152,11 @@ var (
 		"polecat_nudged":  "⚡",
 		"escalation_sent": "⬆",
 		// Merge events
-		"merge_started": "⚙",
-		"merged":        "✓",
-		"merge_failed":  "✗",
-		"merge_skipped": "⊘",
+		"merge_started":  "⚙",
+		"merged":         "✓",
+		"merge_failed":   "✗",
+		"merge_skipped":  "⊘",
+		"merge_reverted": "↶",
 		// General gt events
 		"sling":   "🎯",
 		"hook":    "🪝",
@@ -46,6 +46,9 @@ func (m *Model) render() string {
 // renderHeader renders the top header bar
 func (m *Model) renderHeader() string {
 	title := TitleStyle.Render("GT Feed")
+	if m.replay != nil {
+		title += " " + PromptStyle.Render(m.replay.Status())
+	}
 
 	filter := ""
 	if m.filter != "" {
@@ -72,8 +75,13 @@ func (m *Model) renderTreePanel() string {
 	return style.Width(m.width - 2).Render(m.treeViewport.View())
 }
 
-// renderFeedPanel renders the event feed panel with border
+// renderFeedPanel renders the event feed panel with border.
+// Action output (peek, show) temporarily replaces the feed until dismissed.
 func (m *Model) renderFeedPanel() string {
+	if m.showDetail {
+		title := RoleStyle.Render(m.detailTitle) + TimestampStyle.Render("  (esc to close)")
+		return FocusedBorderStyle.Width(m.width - 2).Render(title + "\n" + m.detailViewport.View())
+	}
 	style := StreamPanelStyle
 	if m.focusedPanel == PanelFeed {
 		style = FocusedBorderStyle
@@ -81,13 +89,22 @@ func (m *Model) renderFeedPanel() string {
 	return style.Width(m.width - 2).Render(m.feedViewport.View())
 }
 
-// renderTree renders the agent tree content
+// renderTree renders the agent tree content.
+// It also records the selectable agents and their line numbers for the cursor.
 func (m *Model) renderTree() string {
+	m.treeAgents = m.treeAgents[:0]
+	m.treeLines = m.treeLines[:0]
+
 	if len(m.rigs) == 0 {
 		return AgentIdleStyle.Render("No agents active")
 	}
 
 	var lines []string
+	addAgent := func(agent *Agent, line string) {
+		m.treeAgents = append(m.treeAgents, agent)
+		m.treeLines = append(m.treeLines, len(lines))
+		lines = append(lines, line)
+	}
 
 	// Sort rigs by name
 	rigNames := make([]string, 0, len(m.rigs))
@@ -121,17 +138,25 @@ func (m *Model) renderTree() string {
 
 			// For crew and polecats, show as expandable group
 			if role == "crew" || role == "polecat" {
-				lines = append(lines, m.renderAgentGroup(icon, role, agents))
+				lines = append(lines, m.renderGroupHeader(icon, role))
+				for _, agent := range agents {
+					addAgent(agent, m.renderAgent("", agent, 5))
+				}
 			} else {
 				// Single agents (mayor, witness, refinery)
 				for _, agent := range agents {
-					lines = append(lines, m.renderAgent(icon, agent, 2))
+					addAgent(agent, m.renderAgent(icon, agent, 2))
 				}
 			}
 		}
 	}
 
-	return strings.Join(lines, "\n")
+	m.treeCursor = clamp(m.treeCursor, 0, len(m.treeAgents)-1)
+	cursorLine := -1
+	if len(m.treeLines) > 0 {
+		cursorLine = m.treeLines[m.treeCursor]
+	}
+	return strings.Join(withCursor(lines, cursorLine, m.focusedPanel == PanelTree), "\n")
 }
 
 // groupAgentsByRole groups agents by their role
@@ -155,24 +180,31 @@ func (m *Model) groupAgentsByRole(agents map[string]*Agent) map[string][]*Agent
 	return result
 }
 
-// renderAgentGroup renders a group of agents (crew or polecats)
-func (m *Model) renderAgentGroup(icon, role string, agents []*Agent) string {
-	var lines []string
-
-	// Group header
+// renderGroupHeader renders the header line for a group of agents (crew or polecats)
+func (m *Model) renderGroupHeader(icon, role string) string {
 	plural := role
 	if role == "polecat" {
 		plural = "polecats"
 	}
 	header := fmt.Sprintf("  %s %s/", icon, plural)
-	lines = append(lines, RoleStyle.Render(header))
+	return RoleStyle.Render(header)
+}
 
-	// Individual agents
-	for _, agent := range agents {
-		lines = append(lines, m.renderAgent("", agent, 5))
+// withCursor prefixes each line with a selection gutter, marking cursorLine.
+// The marker is dimmed when the panel does not have focus.
+func withCursor(lines []string, cursorLine int, focused bool) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		switch {
+		case i != cursorLine:
+			out[i] = "  " + line
+		case focused:
+			out[i] = CursorStyle.Render("▸ ") + line
+		default:
+			out[i] = TimestampStyle.Render("▹ ") + line
+		}
 	}
-
-	return strings.Join(lines, "\n")
+	return out
 }
 
 // renderAgent renders a single agent line
@@ -201,7 +233,7 @@ func (m *Model) renderAgent(icon string, agent *Agent, indent int) string {
 	// Last activity
 	activity := ""
 	if agent.LastEvent != nil {
-		age := formatAge(time.Since(agent.LastEvent.Time))
+		age := formatAge(m.now().Sub(agent.LastEvent.Time))
 		msg := agent.LastEvent.Message
 		if len(msg) > 40 {
 			msg = msg[:37] + "..."
@@ -232,7 +264,8 @@ func (m *Model) renderFeed() string {
 		lines = append(lines, m.renderEvent(event))
 	}
 
-	return strings.Join(lines, "\n")
+	m.feedCursor = clamp(m.feedCursor, 0, len(lines)-1)
+	return strings.Join(withCursor(lines, m.feedCursor, m.focusedPanel == PanelFeed), "\n")
 }
 
 // renderEvent renders a single event line
@@ -255,7 +288,7 @@ func (m *Model) renderEvent(e Event) string {
 		symbolStyle = EventUpdateStyle
 	case "complete", "patrol_complete", "merged", "done":
 		symbolStyle = EventCompleteStyle
-	case "fail", "merge_failed":
+	case "fail", "merge_failed", "merge_reverted":
 		symbolStyle = EventFailStyle
 	case "delete":
 		symbolStyle = EventDeleteStyle
@@ -299,8 +332,18 @@ func (m *Model) renderEvent(e Event) string {
 	return fmt.Sprintf("%s %s %s%s", ts, styledSymbol, actor, msg)
 }
 
-// renderStatusBar renders the bottom status bar
+// renderStatusBar renders the bottom status bar.
+// Pending prompts and action results take over the left side.
 func (m *Model) renderStatusBar() string {
+	switch {
+	case m.inputAction != nil:
+		prompt := fmt.Sprintf("%s message: %s█  (enter send, esc cancel)", m.inputAction.title, m.input)
+		return StatusBarStyle.Width(m.width).Render(PromptStyle.Render(prompt))
+	case m.pending != nil:
+		prompt := fmt.Sprintf("%s? [y/N]", m.pending.title)
+		return StatusBarStyle.Width(m.width).Render(PromptStyle.Render(prompt))
+	}
+
 	// Panel indicator
 	var panelName string
 	switch m.focusedPanel {
@@ -321,6 +364,13 @@ func (m *Model) renderStatusBar() string {
 
 	// Combine
 	left := panel + " " + count
+	if m.statusMsg != "" {
+		status := StatusOKStyle.Render(m.statusMsg)
+		if m.statusErr {
+			status = EventFailStyle.Render(m.statusMsg)
+		}
+		left += "  " + status
+	}
 	gap := m.width - lipgloss.Width(left) - lipgloss.Width(help) - 4
 	if gap < 1 {
 		gap = 1
@@ -334,10 +384,21 @@ func (m *Model) renderShortHelp() string {
 	hints := []string{
 		HelpKeyStyle.Render("j/k") + HelpDescStyle.Render(":scroll"),
 		HelpKeyStyle.Render("tab") + HelpDescStyle.Render(":switch"),
-		HelpKeyStyle.Render("/") + HelpDescStyle.Render(":search"),
-		HelpKeyStyle.Render("q") + HelpDescStyle.Render(":quit"),
-		HelpKeyStyle.Render("?") + HelpDescStyle.Render(":help"),
 	}
+	if m.replay != nil {
+		hints = append(hints,
+			HelpKeyStyle.Render("space")+HelpDescStyle.Render(":pause"),
+			HelpKeyStyle.Render(".")+HelpDescStyle.Render(":step"),
+			HelpKeyStyle.Render("+/-")+HelpDescStyle.Render(":speed"),
+		)
+	} else {
+		hints = append(hints, HelpKeyStyle.Render("n/p/a/b/s")+HelpDescStyle.Render(":act"))
+	}
+	hints = append(hints,
+		HelpKeyStyle.Render("/")+HelpDescStyle.Render(":search"),
+		HelpKeyStyle.Render("q")+HelpDescStyle.Render(":quit"),
+		HelpKeyStyle.Render("?")+HelpDescStyle.Render(":help"),
+	)
 	return strings.Join(hints, "  ")
 }
 
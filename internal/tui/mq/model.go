@@ -0,0 +1,293 @@
+// Package mq provides an interactive TUI for a rig's merge queue.
+package mq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/steveyegge/gastown/internal/beads"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// refreshInterval is how often the queue and Engineer progress are reloaded.
+const refreshInterval = 2 * time.Second
+
+// Model is the bubbletea model for the merge queue TUI.
+type Model struct {
+	rig      *rig.Rig
+	items    []refinery.QueueItem
+	progress *refinery.Progress
+	cursor   int
+	err      error
+
+	// Action state
+	status    string
+	statusErr bool
+	rejecting bool   // reading a rejection reason
+	reason    string // rejection reason typed so far
+	showTests bool   // show test log instead of engineer output
+
+	// UI state
+	keys     KeyMap
+	help     help.Model
+	showHelp bool
+	width    int
+	height   int
+}
+
+// New creates a new merge queue TUI model for a rig.
+func New(r *rig.Rig) Model {
+	return Model{
+		rig:  r,
+		keys: DefaultKeyMap(),
+		help: help.New(),
+	}
+}
+
+// Init initializes the model.
+func (m Model) Init() tea.Cmd {
+	return m.fetch
+}
+
+// fetchMsg is the result of loading the queue and Engineer progress.
+type fetchMsg struct {
+	items    []refinery.QueueItem
+	progress *refinery.Progress
+	err      error
+	manual   bool // refresh after an action; the tick loop is already running
+}
+
+// tickMsg schedules the next refresh.
+type tickMsg time.Time
+
+// actionMsg reports the outcome of retry/reject/bump.
+type actionMsg struct {
+	text string
+	err  error
+}
+
+// fetch loads the queue (sorted by score) and the Engineer's progress.
+func (m Model) fetch() tea.Msg {
+	mgr := refinery.NewManager(m.rig)
+	mgr.SetOutput(io.Discard)
+	items, err := mgr.Queue()
+	if err != nil {
+		return fetchMsg{err: err}
+	}
+	progress, err := refinery.LoadProgress(m.rig.Path)
+	return fetchMsg{items: items, progress: progress, err: err}
+}
+
+// refetch reloads outside the periodic tick loop.
+func (m Model) refetch() tea.Msg {
+	msg := m.fetch().(fetchMsg)
+	msg.manual = true
+	return msg
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Update handles messages.
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.help.Width = msg.Width
+		return m, nil
+
+	case fetchMsg:
+		m.err = msg.err
+		if msg.err == nil {
+			m.items = msg.items
+			m.progress = msg.progress
+			if m.cursor >= len(m.items) {
+				m.cursor = max(len(m.items)-1, 0)
+			}
+		}
+		if msg.manual {
+			return m, nil
+		}
+		return m, tick()
+
+	case tickMsg:
+		return m, m.fetch
+
+	case actionMsg:
+		if msg.err != nil {
+			m.status, m.statusErr = fmt.Sprintf("✗ %v", msg.err), true
+		} else {
+			m.status, m.statusErr = "✓ "+msg.text, false
+		}
+		return m, m.refetch
+
+	case tea.KeyMsg:
+		if m.rejecting {
+			return m.handleReasonKey(msg)
+		}
+
+		switch {
+		case key.Matches(msg, m.keys.Quit):
+			return m, tea.Quit
+
+		case key.Matches(msg, m.keys.Help):
+			m.showHelp = !m.showHelp
+			return m, nil
+
+		case key.Matches(msg, m.keys.Up):
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Down):
+			if m.cursor < len(m.items)-1 {
+				m.cursor++
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Top):
+			m.cursor = 0
+			return m, nil
+
+		case key.Matches(msg, m.keys.Bottom):
+			m.cursor = max(len(m.items)-1, 0)
+			return m, nil
+
+		case key.Matches(msg, m.keys.Log):
+			m.showTests = !m.showTests
+			return m, nil
+
+		case key.Matches(msg, m.keys.Refresh):
+			return m, m.refetch
+
+		case key.Matches(msg, m.keys.Retry):
+			if mr := m.selected(); mr != nil {
+				return m, retryCmd(m.rig, mr.ID)
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Bump):
+			if item := m.selectedItem(); item != nil {
+				if item.Position == 0 {
+					m.status, m.statusErr = item.MR.ID+" is already being processed", true
+					return m, nil
+				}
+				return m, bumpCmd(m.rig, item.MR.ID, item.Priority)
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Reject):
+			if m.selected() != nil {
+				m.rejecting = true
+				m.reason = ""
+			}
+			return m, nil
+		}
+	}
+
+	return m, nil
+}
+
+// handleReasonKey edits the rejection reason prompt.
+func (m Model) handleReasonKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.rejecting = false
+		m.status, m.statusErr = "reject cancelled", false
+	case tea.KeyEnter:
+		m.rejecting = false
+		reason := strings.TrimSpace(m.reason)
+		mr := m.selected()
+		if reason == "" || mr == nil {
+			m.status, m.statusErr = "reject cancelled: a reason is required", true
+			return m, nil
+		}
+		return m, rejectCmd(m.rig, mr.ID, reason)
+	case tea.KeyBackspace:
+		if r := []rune(m.reason); len(r) > 0 {
+			m.reason = string(r[:len(r)-1])
+		}
+	case tea.KeySpace:
+		m.reason += " "
+	case tea.KeyRunes:
+		m.reason += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// selectedItem returns the queue item under the cursor.
+func (m Model) selectedItem() *refinery.QueueItem {
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return nil
+	}
+	return &m.items[m.cursor]
+}
+
+// selected returns the merge request under the cursor.
+func (m Model) selected() *refinery.MergeRequest {
+	if item := m.selectedItem(); item != nil {
+		return item.MR
+	}
+	return nil
+}
+
+// retryCmd clears a failed MR's error, or releases its claim so the
+// Engineer picks it up again on the next cycle.
+func retryCmd(r *rig.Rig, id string) tea.Cmd {
+	return func() tea.Msg {
+		mgr := refinery.NewManager(r)
+		mgr.SetOutput(io.Discard)
+		err := mgr.Retry(id, false)
+		if errors.Is(err, refinery.ErrMRNotFound) {
+			err = refinery.NewEngineer(r).ReleaseMR(id)
+		}
+		if err != nil {
+			return actionMsg{err: fmt.Errorf("retry %s: %w", id, err)}
+		}
+		return actionMsg{text: fmt.Sprintf("%s queued for retry", id)}
+	}
+}
+
+// rejectCmd closes an MR as rejected and notifies the worker.
+func rejectCmd(r *rig.Rig, id, reason string) tea.Cmd {
+	return func() tea.Msg {
+		mgr := refinery.NewManager(r)
+		mgr.SetOutput(io.Discard)
+		if _, err := mgr.RejectMR(id, reason, true); err != nil {
+			return actionMsg{err: fmt.Errorf("reject %s: %w", id, err)}
+		}
+		return actionMsg{text: fmt.Sprintf("rejected %s (worker notified)", id)}
+	}
+}
+
+// bumpCmd raises an MR's priority by one level (P2 → P1).
+func bumpCmd(r *rig.Rig, id string, priority int) tea.Cmd {
+	return func() tea.Msg {
+		if priority <= 0 {
+			return actionMsg{err: fmt.Errorf("%s is already P0", id)}
+		}
+		newPriority := priority - 1
+		b := beads.New(r.BeadsPath())
+		if err := b.Update(id, beads.UpdateOptions{Priority: &newPriority}); err != nil {
+			return actionMsg{err: fmt.Errorf("bump %s: %w", id, err)}
+		}
+		return actionMsg{text: fmt.Sprintf("%s bumped to P%d", id, newPriority)}
+	}
+}
+
+// View renders the model.
+func (m Model) View() string {
+	return m.renderView()
+}
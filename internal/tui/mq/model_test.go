@@ -0,0 +1,144 @@
+package mq
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/steveyegge/gastown/internal/refinery"
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func testModel() Model {
+	m := New(&rig.Rig{Name: "testrig", Path: "/nonexistent"})
+	for i, id := range []string{"gt-mr1", "gt-mr2", "gt-mr3"} {
+		m.items = append(m.items, refinery.QueueItem{Position: i, MR: &refinery.MergeRequest{ID: id}, Priority: 2})
+	}
+	return m
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+// press sends keys to the model and returns it with the last command.
+func press(m Model, keys ...tea.KeyMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		var next tea.Model
+		next, cmd = m.Update(k)
+		m = next.(Model)
+	}
+	return m, cmd
+}
+
+func TestNavigationKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []tea.KeyMsg
+		want int
+	}{
+		{"down", []tea.KeyMsg{runes("j")}, 1},
+		{"down arrow", []tea.KeyMsg{{Type: tea.KeyDown}}, 1},
+		{"down stops at end", []tea.KeyMsg{runes("j"), runes("j"), runes("j")}, 2},
+		{"up stops at top", []tea.KeyMsg{runes("k")}, 0},
+		{"bottom", []tea.KeyMsg{runes("G")}, 2},
+		{"bottom then top", []tea.KeyMsg{runes("G"), runes("g")}, 0},
+		{"bottom then up", []tea.KeyMsg{{Type: tea.KeyEnd}, {Type: tea.KeyUp}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, _ := press(testModel(), tt.keys...)
+			if m.cursor != tt.want {
+				t.Errorf("cursor = %d, want %d", m.cursor, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleKeys(t *testing.T) {
+	m, _ := press(testModel(), runes("?"), runes("l"))
+	if !m.showHelp || !m.showTests {
+		t.Errorf("showHelp/showTests = %v/%v, want true/true", m.showHelp, m.showTests)
+	}
+	m, _ = press(m, runes("?"), runes("l"))
+	if m.showHelp || m.showTests {
+		t.Errorf("showHelp/showTests = %v/%v after second press, want false/false", m.showHelp, m.showTests)
+	}
+}
+
+func TestActionKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []tea.KeyMsg
+		wantCmd bool
+		wantErr bool // status reports an error
+	}{
+		{name: "retry", keys: []tea.KeyMsg{runes("j"), runes("r")}, wantCmd: true},
+		{name: "bump queued", keys: []tea.KeyMsg{runes("j"), runes("+")}, wantCmd: true},
+		{name: "bump in progress", keys: []tea.KeyMsg{runes("b")}, wantErr: true},
+		{name: "refresh", keys: []tea.KeyMsg{{Type: tea.KeyCtrlR}}, wantCmd: true},
+		{name: "quit", keys: []tea.KeyMsg{runes("q")}, wantCmd: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, cmd := press(testModel(), tt.keys...)
+			if (cmd != nil) != tt.wantCmd {
+				t.Errorf("cmd = %v, wantCmd %v", cmd != nil, tt.wantCmd)
+			}
+			if m.statusErr != tt.wantErr {
+				t.Errorf("statusErr = %v (%q), want %v", m.statusErr, m.status, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRejectPrompt(t *testing.T) {
+	m, cmd := press(testModel(), runes("x"))
+	if !m.rejecting || cmd != nil {
+		t.Fatalf("rejecting = %v, cmd = %v; want prompt without command", m.rejecting, cmd != nil)
+	}
+
+	// Keys go to the prompt, not the bindings
+	m, _ = press(m, runes("q"), runes("j"), tea.KeyMsg{Type: tea.KeyBackspace}, runes("bad"), tea.KeyMsg{Type: tea.KeySpace}, runes("tests"))
+	if m.reason != "qbad tests" || m.cursor != 0 {
+		t.Errorf("reason = %q, cursor = %d", m.reason, m.cursor)
+	}
+
+	m, cmd = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.rejecting || cmd == nil {
+		t.Errorf("rejecting = %v, cmd = %v after enter; want reject command", m.rejecting, cmd != nil)
+	}
+}
+
+func TestRejectPromptCancelled(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []tea.KeyMsg
+		wantErr bool
+	}{
+		{"escape", []tea.KeyMsg{runes("x"), runes("why"), {Type: tea.KeyEsc}}, false},
+		{"empty reason", []tea.KeyMsg{runes("x"), {Type: tea.KeySpace}, {Type: tea.KeyEnter}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, cmd := press(testModel(), tt.keys...)
+			if m.rejecting || cmd != nil {
+				t.Errorf("rejecting = %v, cmd = %v; want cancelled", m.rejecting, cmd != nil)
+			}
+			if m.statusErr != tt.wantErr {
+				t.Errorf("statusErr = %v (%q), want %v", m.statusErr, m.status, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestActionKeysWithEmptyQueue(t *testing.T) {
+	m := New(&rig.Rig{Name: "testrig", Path: "/nonexistent"})
+	for _, k := range []string{"r", "+", "x", "j", "G"} {
+		next, cmd := m.Update(runes(k))
+		m = next.(Model)
+		if cmd != nil || m.rejecting || m.cursor != 0 {
+			t.Errorf("%s on empty queue: cmd = %v, rejecting = %v, cursor = %d", k, cmd != nil, m.rejecting, m.cursor)
+		}
+	}
+}
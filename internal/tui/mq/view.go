@@ -0,0 +1,261 @@
+package mq
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/steveyegge/gastown/internal/refinery"
+)
+
+// Styles for the merge queue TUI
+var (
+	titleStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("12"))
+
+	sectionStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("15"))
+
+	selectedStyle = lipgloss.NewStyle().
+			Background(lipgloss.Color("236")).
+			Foreground(lipgloss.Color("15"))
+
+	activeStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("11")) // yellow
+
+	doneStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("10")) // green
+
+	dimStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8")) // gray
+
+	helpStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("8"))
+
+	errorStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("9")) // red
+)
+
+// minLogLines is the smallest log tail shown when the terminal is short.
+const minLogLines = 5
+
+// renderView renders the entire view.
+func (m Model) renderView() string {
+	var b strings.Builder
+
+	// Title
+	b.WriteString(titleStyle.Render("Merge Queue: " + m.rig.Name))
+	b.WriteString("\n\n")
+
+	// Error message
+	if m.err != nil {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString(m.renderProgress())
+	b.WriteString("\n")
+	b.WriteString(m.renderQueue())
+	b.WriteString("\n")
+
+	// Log tail fills whatever height remains
+	used := strings.Count(b.String(), "\n") + 4
+	b.WriteString(m.renderLog(m.height - used))
+
+	// Prompt / status line
+	b.WriteString("\n")
+	switch {
+	case m.rejecting:
+		if mr := m.selected(); mr != nil {
+			b.WriteString(activeStyle.Render(fmt.Sprintf("reject %s reason: %s█", mr.ID, m.reason)))
+			b.WriteString(helpStyle.Render("  (enter confirm, esc cancel)"))
+		}
+	case m.status != "" && m.statusErr:
+		b.WriteString(errorStyle.Render(m.status))
+	case m.status != "":
+		b.WriteString(doneStyle.Render(m.status))
+	}
+	b.WriteString("\n")
+
+	// Help footer
+	if m.showHelp {
+		b.WriteString(m.help.View(m.keys))
+	} else {
+		b.WriteString(helpStyle.Render("j/k:navigate  r:retry  x:reject  +:bump  l:output/tests  q:quit  ?:help"))
+	}
+
+	return b.String()
+}
+
+// renderProgress renders the MR the Engineer is (or was last) working on.
+func (m Model) renderProgress() string {
+	var b strings.Builder
+	b.WriteString(sectionStyle.Render("Engineer"))
+	b.WriteString("\n")
+
+	p := m.progress
+	if p == nil {
+		b.WriteString(dimStyle.Render("  idle (no merge processed yet)"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	elapsed := p.UpdatedAt.Sub(p.StartedAt)
+	if p.Active() {
+		elapsed = time.Since(p.StartedAt)
+	}
+	b.WriteString(fmt.Sprintf("  %s  %s → %s  %s\n",
+		p.MRID, truncate(p.Branch, 40), p.Target,
+		dimStyle.Render(formatDuration(elapsed))))
+
+	// Step pipeline: ✓ completed, ● current, ○ pending
+	current := stepIndex(p.Step)
+	var steps []string
+	for i, s := range refinery.MergeSteps {
+		name := strings.ReplaceAll(string(s), "_", " ")
+		switch {
+		case p.Step == refinery.StepDone || i < current:
+			steps = append(steps, doneStyle.Render("✓ "+name))
+		case i == current && p.Step != refinery.StepFailed:
+			steps = append(steps, activeStyle.Render("● "+name))
+		default:
+			steps = append(steps, dimStyle.Render("○ "+name))
+		}
+	}
+	b.WriteString("  " + strings.Join(steps, dimStyle.Render(" ─ ")) + "\n")
+
+	switch p.Step {
+	case refinery.StepDone:
+		line := "  merged"
+		if p.MergeCommit != "" {
+			line += " at " + truncate(p.MergeCommit, 8)
+		}
+		b.WriteString(doneStyle.Render(line))
+		b.WriteString("\n")
+	case refinery.StepFailed:
+		b.WriteString(errorStyle.Render("  failed: " + truncate(p.Error, 100)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderQueue renders the queue table in processing order.
+func (m Model) renderQueue() string {
+	var b strings.Builder
+	b.WriteString(sectionStyle.Render(fmt.Sprintf("Queue (%d)", len(m.items))))
+	b.WriteString("\n")
+
+	if len(m.items) == 0 {
+		b.WriteString(dimStyle.Render("  queue is empty"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	b.WriteString(dimStyle.Render(fmt.Sprintf("  %-3s %-12s %-3s %6s  %-32s %-10s %s",
+		"#", "ID", "P", "SCORE", "BRANCH", "WORKER", "AGE")))
+	b.WriteString("\n")
+
+	for i, item := range m.items {
+		mr := item.MR
+		marker := " "
+		if item.Position == 0 {
+			marker = "●"
+		}
+		line := fmt.Sprintf("%s %-3d %-12s P%-2d %6.1f  %-32s %-10s %s",
+			marker,
+			item.Position+1,
+			truncate(mr.ID, 12),
+			item.Priority,
+			item.Score,
+			truncate(mr.Branch, 32),
+			truncate(mr.Worker, 10),
+			item.Age,
+		)
+
+		switch {
+		case i == m.cursor:
+			b.WriteString(selectedStyle.Render(line))
+		case item.Position == 0:
+			b.WriteString(activeStyle.Render(line))
+		default:
+			b.WriteString(line)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// renderLog renders the tail of the Engineer output or test log.
+func (m Model) renderLog(lines int) string {
+	var b strings.Builder
+
+	title, log := "Output", []string(nil)
+	if m.showTests {
+		title = "Test log"
+	}
+	if m.progress != nil {
+		log = m.progress.Output
+		if m.showTests {
+			log = m.progress.TestLog
+		}
+	}
+
+	b.WriteString(sectionStyle.Render(title))
+	b.WriteString("\n")
+	if len(log) == 0 {
+		b.WriteString(dimStyle.Render("  (no output)"))
+		b.WriteString("\n")
+		return b.String()
+	}
+
+	if lines < minLogLines {
+		lines = minLogLines
+	}
+	if len(log) > lines {
+		log = log[len(log)-lines:]
+	}
+	width := m.width - 2
+	if width < 20 {
+		width = 80
+	}
+	for _, line := range log {
+		b.WriteString(dimStyle.Render("  " + truncate(line, width)))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// stepIndex returns the position of s in refinery.MergeSteps, or -1.
+func stepIndex(s refinery.MergeStep) int {
+	for i, step := range refinery.MergeSteps {
+		if step == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatDuration renders an elapsed time compactly (e.g. "42s", "3m12s").
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// truncate shortens a string to the given rune length, preserving UTF-8.
+func truncate(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	runes := []rune(s)
+	if maxLen <= 3 {
+		return "..."
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
@@ -0,0 +1,42 @@
+package util
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"1024", 1024},
+		{"10MB", 10 << 20},
+		{"512kb", 512 << 10},
+		{"1.5GiB", 3 << 29},
+		{"2 M", 2 << 20},
+		{"0", 0},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if err != nil {
+			t.Errorf("ParseSize(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "MB", "ten", "-1KB"} {
+		if _, err := ParseSize(bad); err == nil {
+			t.Errorf("ParseSize(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	if got := FormatSize(10 << 20); got != "10.0MB" {
+		t.Errorf("FormatSize(10MiB) = %q", got)
+	}
+	if got := FormatSize(12); got != "12B" {
+		t.Errorf("FormatSize(12) = %q", got)
+	}
+}